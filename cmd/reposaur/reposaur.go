@@ -2,22 +2,45 @@ package reposaur
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"sort"
 	"strings"
-	"sync"
+	"time"
 
-	"github.com/reposaur/reposaur/pkg/detector"
 	"github.com/reposaur/reposaur/pkg/output"
 	"github.com/reposaur/reposaur/pkg/sdk"
 	"github.com/spf13/cobra"
 )
 
+var errPoliciesFailed = errors.New("one or more policies failed")
+
 type Params struct {
 	namespace    string
 	outputFormat string
 	policyPaths  []string
+	since        string
+	offline      bool
+	readOnly     bool
+	resolveDNS   bool
+	concurrency  int
+
+	excludeExperimental bool
+	excludeDeprecated   bool
+	strict              bool
+	warningsAsErrors    bool
+
+	baseline      string
+	writeBaseline string
+
+	template    string
+	inputs      string
+	skipInvalid bool
+	sourceDir   string
+	auditTrail  string
+	sortOrder   string
 }
 
 var cmd = &cobra.Command{
@@ -30,85 +53,142 @@ func NewCommand() *cobra.Command {
 	params := Params{}
 
 	cmd.RunE = func(cmd *cobra.Command, args []string) error {
-		var input interface{}
+		var opts []sdk.Option
 
-		err := json.NewDecoder(os.Stdin).Decode(&input)
-		if err != nil {
-			return err
-		}
+		if params.since != "" {
+			since, err := time.Parse(time.RFC3339, params.since)
+			if err != nil {
+				return fmt.Errorf("invalid since timestamp: %w", err)
+			}
 
-		rs, err := sdk.New(cmd.Context(), params.policyPaths)
-		if err != nil {
-			return err
+			opts = append(opts, sdk.WithSince(since))
 		}
 
-		var data []interface{}
+		opts = append(opts, sdk.WithConcurrency(params.concurrency))
 
-		switch i := input.(type) {
-		case map[string]interface{}:
-			data = append(data, i)
+		if params.offline {
+			opts = append(opts, sdk.WithOffline())
+		}
 
-		case []interface{}:
-			for _, d := range i {
-				data = append(data, d)
-			}
+		if params.readOnly {
+			opts = append(opts, sdk.WithAllowedMethods(sdk.ReadOnlyMethods...))
 		}
 
-		var (
-			wg       = sync.WaitGroup{}
-			reportCh = make(chan output.Report, len(data))
-		)
+		if params.resolveDNS && !params.offline {
+			opts = append(opts, sdk.WithDNSResolution(sdk.DefaultDNSTimeout))
+		}
 
-		wg.Add(len(data))
+		if params.excludeExperimental {
+			opts = append(opts, sdk.WithoutExperimental())
+		}
 
-		for _, d := range data {
-			namespace := params.namespace
+		if params.excludeDeprecated {
+			opts = append(opts, sdk.WithoutDeprecated())
+		}
 
-			if namespace == "" {
-				namespace, err = detector.DetectNamespace(d)
-				if err != nil {
-					return err
-				}
-			}
+		if params.strict {
+			opts = append(opts, sdk.WithStrict())
+		}
 
-			props, err := detector.DetectReportProperties(namespace, d)
+		if params.baseline != "" {
+			baseline, err := readBaseline(params.baseline)
 			if err != nil {
 				return err
 			}
 
-			go func(namespace string, props output.ReportProperties, data interface{}) {
-				r, err := rs.Check(cmd.Context(), namespace, data)
-				if err != nil {
-					panic(err)
-				}
+			opts = append(opts, sdk.WithBaseline(baseline))
+		}
+
+		if params.sourceDir != "" {
+			opts = append(opts, sdk.WithInlineSuppressions(sdk.DirSourceReader(params.sourceDir)))
+		}
 
-				r.Properties = props
-				reportCh <- r
+		var trail *output.AuditTrail
 
-				wg.Done()
-			}(namespace, props, d)
+		if params.auditTrail != "" {
+			trail = output.NewAuditTrail()
+			opts = append(opts, sdk.WithAuditTrail(trail))
 		}
 
-		wg.Wait()
-		close(reportCh)
+		order, err := output.ParseResultOrder(params.sortOrder)
+		if err != nil {
+			return err
+		}
+
+		rs, err := sdk.New(cmd.Context(), params.policyPaths, opts...)
+		if err != nil {
+			return err
+		}
 
 		var reports []output.Report
 
-		for r := range reportCh {
-			reports = append(reports, r)
+		if params.inputs != "" {
+			reports, err = checkGlob(cmd, rs, params.namespace, params.inputs, params.skipInvalid)
+		} else {
+			reports, err = checkStdin(cmd, rs, params.namespace)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		for i := range reports {
+			reports[i].Order = order
 		}
 
-		return writeOutput(
-			reports,
-			params.outputFormat,
-			os.Stdout,
-		)
+		if params.writeBaseline != "" {
+			if err := writeBaseline(params.writeBaseline, reports); err != nil {
+				return err
+			}
+		}
+
+		if trail != nil {
+			if err := writeAuditTrail(params.auditTrail, trail); err != nil {
+				return err
+			}
+		}
+
+		if params.template != "" {
+			err = writeTemplate(reports, params.template, os.Stdout)
+		} else {
+			err = writeOutput(
+				reports,
+				params.outputFormat,
+				os.Stdout,
+			)
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, r := range reports {
+			r.PromoteWarningsToFailures = params.warningsAsErrors
+
+			if r.ExitCode() != 0 {
+				cmd.SilenceUsage = true
+				return errPoliciesFailed
+			}
+		}
+
+		return nil
 	}
 
 	cmd.Flags().StringVarP(
 		&params.outputFormat,
 		"format", "f", "sarif",
-		"report output format (one of 'json' and 'sarif')",
+		"report output format (one of 'json', 'sarif', 'csv', 'markdown' and 'decision-log')",
+	)
+
+	cmd.Flags().StringVarP(
+		&params.inputs,
+		"inputs", "i", "",
+		"read the data from the JSON files matching this glob instead of stdin",
+	)
+
+	cmd.Flags().BoolVar(
+		&params.skipInvalid,
+		"skip-invalid", false,
+		"skip the malformed files matching --inputs with a warning instead of failing",
 	)
 
 	cmd.Flags().StringVarP(
@@ -123,12 +203,238 @@ func NewCommand() *cobra.Command {
 		"set the path to a policy or directory of policies",
 	)
 
+	cmd.Flags().StringVar(
+		&params.since,
+		"since", "",
+		"skip data not pushed or updated since this timestamp (RFC3339)",
+	)
+
+	cmd.Flags().IntVarP(
+		&params.concurrency,
+		"concurrency", "c", sdk.DefaultConcurrency,
+		"maximum number of inputs checked concurrently",
+	)
+
+	cmd.Flags().BoolVar(
+		&params.offline,
+		"offline", false,
+		"disable network access, policies doing requests will fail",
+	)
+
+	cmd.Flags().BoolVar(
+		&params.readOnly,
+		"read-only", false,
+		"only allow policies to do GET and HEAD requests and GraphQL queries, others will fail",
+	)
+
+	cmd.Flags().BoolVar(
+		&params.resolveDNS,
+		"resolve-dns", false,
+		"resolve host names in net.is_private_ip, unless offline",
+	)
+
+	cmd.Flags().BoolVar(
+		&params.excludeExperimental,
+		"exclude-experimental", false,
+		"skip rules marked as experimental",
+	)
+
+	cmd.Flags().BoolVar(
+		&params.excludeDeprecated,
+		"exclude-deprecated", false,
+		"skip rules marked as deprecated",
+	)
+
+	cmd.Flags().BoolVar(
+		&params.strict,
+		"strict", false,
+		"fail if a policy namespace doesn't have any rules",
+	)
+
+	cmd.Flags().BoolVar(
+		&params.warningsAsErrors,
+		"warnings-as-errors", false,
+		"exit with code 1 if warning rules fail",
+	)
+
+	cmd.Flags().StringVar(
+		&params.baseline,
+		"baseline", "",
+		"suppress the known failing results listed in this baseline file",
+	)
+
+	cmd.Flags().StringVar(
+		&params.writeBaseline,
+		"write-baseline", "",
+		"write the failing results to this baseline file",
+	)
+
+	cmd.Flags().StringVar(
+		&params.sourceDir,
+		"source-dir", "",
+		"suppress the results with suppression comments in the files of this directory",
+	)
+
+	cmd.Flags().StringVar(
+		&params.auditTrail,
+		"audit-trail", "",
+		"write a record of every decision and the API requests done to this file",
+	)
+
+	cmd.Flags().StringVar(
+		&params.sortOrder,
+		"sort", string(output.RuleOrder),
+		"order of the results in the reports (one of 'rule' and 'severity')",
+	)
+
+	cmd.Flags().StringVar(
+		&params.template,
+		"template", "",
+		"format the reports with this text/template file instead of the output format",
+	)
+
 	return cmd
 }
 
+// checkStdin checks the data read from stdin, either
+// a JSON document or an array of JSON documents.
+func checkStdin(cmd *cobra.Command, rs *sdk.Reposaur, namespace string) ([]output.Report, error) {
+	var input interface{}
+
+	if err := json.NewDecoder(os.Stdin).Decode(&input); err != nil {
+		return nil, err
+	}
+
+	var data []interface{}
+
+	switch i := input.(type) {
+	case map[string]interface{}:
+		data = append(data, i)
+
+	case []interface{}:
+		data = append(data, i...)
+	}
+
+	return rs.CheckMany(cmd.Context(), namespace, data)
+}
+
+// checkGlob checks the data in the JSON files matching pattern,
+// returning the reports ordered by path. Malformed files are
+// reported with a warning, and fail the check unless skipInvalid.
+func checkGlob(cmd *cobra.Command, rs *sdk.Reposaur, namespace, pattern string, skipInvalid bool) ([]output.Report, error) {
+	byPath, malformed, err := rs.CheckGlob(cmd.Context(), namespace, pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(byPath)+len(malformed))
+	for path := range byPath {
+		paths = append(paths, path)
+	}
+
+	for path := range malformed {
+		paths = append(paths, path)
+	}
+
+	sort.Strings(paths)
+
+	var reports []output.Report
+
+	for _, path := range paths {
+		if err, ok := malformed[path]; ok {
+			if skipInvalid {
+				cmd.PrintErrf("skipping malformed input %s: %s\n", path, err)
+			} else {
+				cmd.PrintErrf("malformed input %s: %s\n", path, err)
+			}
+
+			continue
+		}
+
+		reports = append(reports, byPath[path])
+	}
+
+	if len(malformed) > 0 && !skipInvalid {
+		return nil, fmt.Errorf("%d malformed inputs, use --skip-invalid to skip them", len(malformed))
+	}
+
+	return reports, nil
+}
+
+func readBaseline(path string) (output.Baseline, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return output.Baseline{}, err
+	}
+	defer f.Close()
+
+	return output.ReadBaseline(f)
+}
+
+func writeBaseline(path string, reports []output.Report) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := output.NewBaseline(reports).Write(f); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+func writeAuditTrail(path string, trail *output.AuditTrail) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := trail.Write(f); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+func writeTemplate(reports []output.Report, path string, w io.Writer) error {
+	tmpl, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range reports {
+		if err := output.WriteTemplate(w, r, string(tmpl)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func writeOutput(reports []output.Report, format string, w io.Writer) error {
 	format = strings.ToLower(format)
 
+	if format == "decision-log" {
+		return output.WriteDecisionLogs(w, reports)
+	}
+
+	if format == "csv" {
+		return output.WriteCSV(w, reports...)
+	}
+
+	if format == "markdown" {
+		for _, r := range reports {
+			if err := output.WriteMarkdown(w, r); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
 	if format != "json" && format != "sarif" {
 		return fmt.Errorf("unknown output format '%s'", format)
 	}
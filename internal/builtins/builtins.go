@@ -1,12 +1,233 @@
 package builtins
 
 import (
+	"context"
 	"net/http"
+	"time"
 
+	"github.com/open-policy-agent/opa/ast"
 	"github.com/open-policy-agent/opa/rego"
 )
 
-func RegisterBuiltins(client *http.Client) {
-	rego.RegisterBuiltin2(&GitHubRequestBuiltin, GitHubRequestBuiltinImpl(client))
-	rego.RegisterBuiltin2(&GitHubGraphQLBuiltin, GitHubGraphQLBuiltinImpl(client))
+// Option configures the built-in functions registered by
+// RegisterBuiltins.
+type Option func(*options)
+
+type options struct {
+	apiVersion      string
+	maxResponseSize int64
+	statsRetries    int
+	statsRetryDelay time.Duration
+	dnsTimeout      time.Duration
+}
+
+// WithAPIVersion sets the version of the GitHub REST API requested
+// by the built-in functions, DefaultAPIVersion by default.
+func WithAPIVersion(version string) Option {
+	return func(o *options) {
+		o.apiVersion = version
+	}
+}
+
+// WithMaxResponseSize sets the maximum size in bytes of the responses
+// read by `github.request` and `github.graphql`, which fail with
+// ErrResponseTooLarge when it's exceeded. DefaultMaxResponseSize by
+// default.
+func WithMaxResponseSize(size int64) Option {
+	return func(o *options) {
+		o.maxResponseSize = size
+	}
+}
+
+// WithStatsRetry sets how many times the requests of `github.contributors`
+// and `github.commit_activity` are retried while GitHub is still computing
+// the statistics, and the delay before the first retry, doubled after each
+// one. DefaultStatsRetries and DefaultStatsRetryDelay by default.
+func WithStatsRetry(retries int, delay time.Duration) Option {
+	return func(o *options) {
+		o.statsRetries = retries
+		o.statsRetryDelay = delay
+	}
+}
+
+// WithDNSResolution makes `net.is_private_ip` resolve host names,
+// bounding each lookup to timeout (e.g. DefaultDNSTimeout). Host
+// names aren't resolved by default.
+func WithDNSResolution(timeout time.Duration) Option {
+	return func(o *options) {
+		o.dnsTimeout = timeout
+	}
+}
+
+// ResolvesDNS returns whether built-in functions configured with opts
+// resolve host names, i.e. depend on DNS. See WithDNSResolution.
+func ResolvesDNS(opts ...Option) bool {
+	return newOptions(opts).dnsTimeout > 0
+}
+
+// defaultOptions are the options of the built-in
+// functions evaluated without any.
+var defaultOptions = newOptions(nil)
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		apiVersion:      DefaultAPIVersion,
+		maxResponseSize: DefaultMaxResponseSize,
+		statsRetries:    DefaultStatsRetries,
+		statsRetryDelay: DefaultStatsRetryDelay,
+	}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+type optionsContextKey struct{}
+
+// NewOptionsContext returns a copy of ctx carrying opts, used by
+// the built-in functions instead of the ones they were registered
+// with, so evaluations can have their own.
+func NewOptionsContext(ctx context.Context, opts ...Option) context.Context {
+	return context.WithValue(ctx, optionsContextKey{}, newOptions(opts))
+}
+
+// contextOptions returns the options carried by ctx,
+// or the defaults if there are none.
+func contextOptions(ctx context.Context) *options {
+	if ctx != nil {
+		if o, ok := ctx.Value(optionsContextKey{}).(*options); ok {
+			return o
+		}
+	}
+
+	return defaultOptions
+}
+
+// withOptions returns bctx with o in its context,
+// unless it already carries options.
+func withOptions(bctx rego.BuiltinContext, o *options) rego.BuiltinContext {
+	if bctx.Context == nil {
+		bctx.Context = context.Background()
+	}
+
+	if _, ok := bctx.Context.Value(optionsContextKey{}).(*options); !ok {
+		bctx.Context = context.WithValue(bctx.Context, optionsContextKey{}, o)
+	}
+
+	return bctx
+}
+
+func withOptions1(o *options, f rego.Builtin1) rego.Builtin1 {
+	return func(bctx rego.BuiltinContext, op1 *ast.Term) (*ast.Term, error) {
+		return f(withOptions(bctx, o), op1)
+	}
+}
+
+func withOptions2(o *options, f rego.Builtin2) rego.Builtin2 {
+	return func(bctx rego.BuiltinContext, op1, op2 *ast.Term) (*ast.Term, error) {
+		return f(withOptions(bctx, o), op1, op2)
+	}
+}
+
+func withOptions3(o *options, f rego.Builtin3) rego.Builtin3 {
+	return func(bctx rego.BuiltinContext, op1, op2, op3 *ast.Term) (*ast.Term, error) {
+		return f(withOptions(bctx, o), op1, op2, op3)
+	}
+}
+
+func withOptions4(o *options, f rego.Builtin4) rego.Builtin4 {
+	return func(bctx rego.BuiltinContext, op1, op2, op3, op4 *ast.Term) (*ast.Term, error) {
+		return f(withOptions(bctx, o), op1, op2, op3, op4)
+	}
+}
+
+func withOptionsDyn(o *options, f rego.BuiltinDyn) rego.BuiltinDyn {
+	return func(bctx rego.BuiltinContext, terms []*ast.Term) (*ast.Term, error) {
+		return f(withOptions(bctx, o), terms)
+	}
+}
+
+// RegisterBuiltins registers the built-in functions, doing requests
+// with client and configured by opts, unless the evaluation's context
+// carries another client (see util.NewClientContext) or other options
+// (see NewOptionsContext).
+func RegisterBuiltins(client *http.Client, opts ...Option) {
+	o := newOptions(opts)
+
+	rego.RegisterBuiltin2(&GitHubRequestBuiltin, withOptions2(o, GitHubRequestBuiltinImpl(client)))
+	rego.RegisterBuiltin2(&GitHubGraphQLBuiltin, withOptions2(o, GitHubGraphQLBuiltinImpl(client)))
+	rego.RegisterBuiltin2(&GitHubGraphQLFieldExistsBuiltin, withOptions2(o, GitHubGraphQLFieldExistsBuiltinImpl(client)))
+	rego.RegisterBuiltin3(&GitHubWorkflowsBuiltin, withOptions3(o, GitHubWorkflowsBuiltinImpl(client)))
+	rego.RegisterBuiltin2(&GitHubWorkflowPermissionsBuiltin, withOptions2(o, GitHubWorkflowPermissionsBuiltinImpl(client)))
+	rego.RegisterBuiltin2(&GitHubActionsSecretsBuiltin, withOptions2(o, GitHubActionsSecretsBuiltinImpl(client)))
+	rego.RegisterBuiltin2(&GitHubActionsVariablesBuiltin, withOptions2(o, GitHubActionsVariablesBuiltinImpl(client)))
+	rego.RegisterBuiltin3(&GitHubCodeownersBuiltin, withOptions3(o, GitHubCodeownersBuiltinImpl(client)))
+	rego.RegisterBuiltin3(&GitHubBranchProtectionBuiltin, withOptions3(o, GitHubBranchProtectionBuiltinImpl(client)))
+	rego.RegisterBuiltin4(&GitHubRequiresCheckBuiltin, withOptions4(o, GitHubRequiresCheckBuiltinImpl(client)))
+	rego.RegisterBuiltin2(&GitHubRulesetsBuiltin, withOptions2(o, GitHubRulesetsBuiltinImpl(client)))
+	rego.RegisterBuiltin1(&GitHubOrgRulesetsBuiltin, withOptions1(o, GitHubOrgRulesetsBuiltinImpl(client)))
+	rego.RegisterBuiltin3(&GitHubAuditLogBuiltin, withOptions3(o, GitHubAuditLogBuiltinImpl(client)))
+	rego.RegisterBuiltin2(&GitHubActionSHABuiltin, withOptions2(o, GitHubActionSHABuiltinImpl(client)))
+	rego.RegisterBuiltin3(&GitHubRefSHABuiltin, withOptions3(o, GitHubRefSHABuiltinImpl(client)))
+	rego.RegisterBuiltin3(&GitHubDependabotAlertsBuiltin, withOptions3(o, GitHubDependabotAlertsBuiltinImpl(client)))
+	rego.RegisterBuiltin2(&GitHubSBOMBuiltin, withOptions2(o, GitHubSBOMBuiltinImpl(client)))
+	rego.RegisterBuiltin2(&GitHubDependenciesBuiltin, withOptions2(o, GitHubDependenciesBuiltinImpl(client)))
+	rego.RegisterBuiltin3(&GitHubSecretScanningAlertsBuiltin, withOptions3(o, GitHubSecretScanningAlertsBuiltinImpl(client)))
+	rego.RegisterBuiltin1(&GitHubOrgBuiltin, withOptions1(o, GitHubOrgBuiltinImpl(client)))
+	rego.RegisterBuiltin1(&GitHubOrgSecurityBuiltin, withOptions1(o, GitHubOrgSecurityBuiltinImpl(client)))
+	rego.RegisterBuiltin2(&GitHubBillingBuiltin, withOptions2(o, GitHubBillingBuiltinImpl(client)))
+	rego.RegisterBuiltin2(&GitHubLicenseBuiltin, withOptions2(o, GitHubLicenseBuiltinImpl(client)))
+	rego.RegisterBuiltin2(&GitHubTopicsBuiltin, withOptions2(o, GitHubTopicsBuiltinImpl(client)))
+	rego.RegisterBuiltin2(&GitHubRepoMetadataBuiltin, withOptions2(o, GitHubRepoMetadataBuiltinImpl(client)))
+	rego.RegisterBuiltin3(&GitHubCommitBuiltin, withOptions3(o, GitHubCommitBuiltinImpl(client)))
+	rego.RegisterBuiltin3(&GitHubCommitsBuiltin, withOptions3(o, GitHubCommitsBuiltinImpl(client)))
+	rego.RegisterBuiltin2(&GitHubContributorsBuiltin, withOptions2(o, GitHubContributorsBuiltinImpl(client)))
+	rego.RegisterBuiltin2(&GitHubCommitActivityBuiltin, withOptions2(o, GitHubCommitActivityBuiltinImpl(client)))
+	rego.RegisterBuiltin3(&GitHubPRFilesBuiltin, withOptions3(o, GitHubPRFilesBuiltinImpl(client)))
+	rego.RegisterBuiltin2(&GitHubEnvironmentsBuiltin, withOptions2(o, GitHubEnvironmentsBuiltinImpl(client)))
+	rego.RegisterBuiltin2(&GitHubDeployKeysBuiltin, withOptions2(o, GitHubDeployKeysBuiltinImpl(client)))
+	rego.RegisterBuiltin2(&GitHubPagesBuiltin, withOptions2(o, GitHubPagesBuiltinImpl(client)))
+	rego.RegisterBuiltin2(&GitHubWebhooksBuiltin, withOptions2(o, GitHubWebhooksBuiltinImpl(client)))
+	rego.RegisterBuiltin1(&GitHubOrgWebhooksBuiltin, withOptions1(o, GitHubOrgWebhooksBuiltinImpl(client)))
+	rego.RegisterBuiltin1(&GitHubTeamsBuiltin, withOptions1(o, GitHubTeamsBuiltinImpl(client)))
+	rego.RegisterBuiltin2(&GitHubTeamReposBuiltin, withOptions2(o, GitHubTeamReposBuiltinImpl(client)))
+	rego.RegisterBuiltin3(&GitHubRepoCollaboratorsBuiltin, withOptions3(o, GitHubRepoCollaboratorsBuiltinImpl(client)))
+	rego.RegisterBuiltin2(&GitHubRepoInvitationsBuiltin, withOptions2(o, GitHubRepoInvitationsBuiltinImpl(client)))
+	rego.RegisterBuiltin1(&GitHubOrgInvitationsBuiltin, withOptions1(o, GitHubOrgInvitationsBuiltinImpl(client)))
+	rego.RegisterBuiltin1(&GitHubCopilotBuiltin, withOptions1(o, GitHubCopilotBuiltinImpl(client)))
+	rego.RegisterBuiltin2(&GitHubTemplatesBuiltin, withOptions2(o, GitHubTemplatesBuiltinImpl(client)))
+	rego.RegisterBuiltin2(&GitHubReleasesBuiltin, withOptions2(o, GitHubReleasesBuiltinImpl(client)))
+	rego.RegisterBuiltin2(&GitHubTagsBuiltin, withOptions2(o, GitHubTagsBuiltinImpl(client)))
+	rego.RegisterBuiltinDyn(&GitHubViewerBuiltin, withOptionsDyn(o, GitHubViewerBuiltinImpl(client)))
+	rego.RegisterBuiltin2(&GitHubPermissionGTEBuiltin, GitHubPermissionGTEBuiltinImpl)
+	rego.RegisterBuiltin1(&CronParseBuiltin, CronParseBuiltinImpl)
+	rego.RegisterBuiltin1(&CronValidBuiltin, CronValidBuiltinImpl)
+	rego.RegisterBuiltin1(&GitHubDecodeContentBuiltin, GitHubDecodeContentBuiltinImpl)
+	rego.RegisterBuiltin1(&YAMLUnmarshalAllBuiltin, YAMLUnmarshalAllBuiltinImpl)
+	rego.RegisterBuiltin2(&RegexMatchSafeBuiltin, RegexMatchSafeBuiltinImpl)
+	rego.RegisterBuiltin2(&JSONValidateSchemaBuiltin, JSONValidateSchemaBuiltinImpl)
+	rego.RegisterBuiltin2(&NetHostAllowedBuiltin, NetHostAllowedBuiltinImpl)
+	rego.RegisterBuiltin1(&NetIsPrivateIPBuiltin, withOptions1(o, NetIsPrivateIPBuiltinImpl))
+	rego.RegisterBuiltin3(&HashVerifyBuiltin, HashVerifyBuiltinImpl)
+	rego.RegisterBuiltin1(&JWTDecodeBuiltin, JWTDecodeBuiltinImpl)
+	rego.RegisterBuiltin2(&JWTVerifyRS256Builtin, JWTVerifyRS256BuiltinImpl)
+	rego.RegisterBuiltin2(&SPDXCompatibleBuiltin, SPDXCompatibleBuiltinImpl)
+	rego.RegisterBuiltin1(&TimeDaysSinceBuiltin, TimeDaysSinceBuiltinImpl)
+	rego.RegisterBuiltin2(&TimeIsOlderThanBuiltin, TimeIsOlderThanBuiltinImpl)
+}
+
+// builtinNow returns the evaluation time of the query,
+// falling back to the current time if it's not set.
+func builtinNow(bctx rego.BuiltinContext) time.Time {
+	if bctx.Time != nil {
+		if n, ok := bctx.Time.Value.(ast.Number); ok {
+			if ns, ok := n.Int64(); ok {
+				return time.Unix(0, ns).UTC()
+			}
+		}
+	}
+
+	return time.Now().UTC()
 }
@@ -0,0 +1,129 @@
+package builtins_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/open-policy-agent/opa/ast"
+)
+
+type rewriteTransport struct {
+	target *url.URL
+}
+
+func (t rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.URL.Scheme = t.target.Scheme
+	req.URL.Host = t.target.Host
+
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// newStubClient returns a client that sends
+// every request to handler.
+func newStubClient(t *testing.T, handler http.Handler) *http.Client {
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	u, _ := url.Parse(srv.URL)
+
+	return &http.Client{Transport: rewriteTransport{target: u}}
+}
+
+// objectTerm converts v to an object term, failing the test on error.
+func objectTerm(t *testing.T, v map[string]interface{}) *ast.Term {
+	t.Helper()
+
+	val, err := ast.InterfaceToValue(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return ast.NewTerm(val)
+}
+
+// contentsHandler serves the contents API of reposaur/test from
+// files, keyed by path. Directories, including the repository's
+// root, are derived from the paths.
+func contentsHandler(files map[string]string) http.Handler {
+	const prefix = "/repos/reposaur/test/contents"
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, prefix) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		p := strings.Trim(strings.TrimPrefix(r.URL.Path, prefix), "/")
+		if p == "" {
+			p = "."
+		}
+
+		if content, ok := files[p]; ok {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"type":     "file",
+				"path":     p,
+				"encoding": "base64",
+				"content":  encodeContent(content),
+			})
+
+			return
+		}
+
+		var (
+			entries []map[string]interface{}
+			dirs    = map[string]bool{}
+		)
+
+		for fp := range files {
+			if path.Dir(fp) == p {
+				entries = append(entries, map[string]interface{}{
+					"type": "file",
+					"path": fp,
+					"name": path.Base(fp),
+				})
+
+				continue
+			}
+
+			for d := path.Dir(fp); d != "."; d = path.Dir(d) {
+				if path.Dir(d) == p && !dirs[d] {
+					dirs[d] = true
+					entries = append(entries, map[string]interface{}{
+						"type": "dir",
+						"path": d,
+						"name": path.Base(d),
+					})
+				}
+			}
+		}
+
+		if entries == nil {
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"message": "Not Found"})
+
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(entries)
+	})
+}
+
+// encodeContent encodes content like the contents API,
+// in Base64 with line breaks every 60 characters.
+func encodeContent(content string) string {
+	encoded := base64.StdEncoding.EncodeToString([]byte(content))
+
+	var lines []string
+	for len(encoded) > 60 {
+		lines = append(lines, encoded[:60])
+		encoded = encoded[60:]
+	}
+
+	return strings.Join(append(lines, encoded), "\n") + "\n"
+}
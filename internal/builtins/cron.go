@@ -0,0 +1,355 @@
+package builtins
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/types"
+)
+
+// cronNextRuns is the number of upcoming run times
+// returned by cron.parse.
+const cronNextRuns = 5
+
+var CronParseBuiltin = rego.Function{
+	Name: "cron.parse",
+	Decl: types.NewFunction(
+		types.Args(types.S),
+		types.NewObject(nil, types.NewDynamicProperty(types.S, types.A)),
+	),
+	Memoize: true,
+}
+
+var CronValidBuiltin = rego.Function{
+	Name: "cron.valid",
+	Decl: types.NewFunction(
+		types.Args(types.S),
+		types.B,
+	),
+}
+
+// CronParseBuiltinImpl parses a cron expression and returns
+// the next run times (RFC3339) and the shortest interval
+// between them, in seconds.
+func CronParseBuiltinImpl(bctx rego.BuiltinContext, op1 *ast.Term) (*ast.Term, error) {
+	var expr string
+
+	if err := ast.As(op1.Value, &expr); err != nil {
+		return nil, err
+	}
+
+	schedule, err := parseCron(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		next     []interface{}
+		interval int64
+		t        = builtinNow(bctx)
+		prev     time.Time
+	)
+
+	for i := 0; i < cronNextRuns; i++ {
+		t = schedule.next(t)
+		if t.IsZero() {
+			break
+		}
+
+		if !prev.IsZero() {
+			if d := int64(t.Sub(prev).Seconds()); interval == 0 || d < interval {
+				interval = d
+			}
+		}
+
+		next = append(next, t.Format(time.RFC3339))
+		prev = t
+	}
+
+	val, err := ast.InterfaceToValue(map[string]interface{}{
+		"next":     next,
+		"interval": interval,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ast.NewTerm(val), nil
+}
+
+// CronValidBuiltinImpl returns whether a cron expression is valid.
+func CronValidBuiltinImpl(bctx rego.BuiltinContext, op1 *ast.Term) (*ast.Term, error) {
+	var expr string
+
+	if err := ast.As(op1.Value, &expr); err != nil {
+		return nil, err
+	}
+
+	_, err := parseCron(expr)
+
+	return ast.BooleanTerm(err == nil), nil
+}
+
+type cronField struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	cronSecondField = cronField{name: "second", min: 0, max: 59}
+	cronMinuteField = cronField{name: "minute", min: 0, max: 59}
+	cronHourField   = cronField{name: "hour", min: 0, max: 23}
+	cronDomField    = cronField{name: "day of month", min: 1, max: 31}
+	cronMonthField  = cronField{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	cronDowField = cronField{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// cronSchedule holds the set of allowed values
+// for each field as bitsets.
+type cronSchedule struct {
+	second, minute, hour, dom, month, dow uint64
+	domStar, dowStar                      bool
+}
+
+// parseCron parses a standard 5-field cron expression, an
+// extended 6-field expression (with leading seconds) or one
+// of the predefined descriptors (e.g. @daily).
+func parseCron(expr string) (*cronSchedule, error) {
+	expr = strings.TrimSpace(expr)
+
+	if d, ok := cronDescriptors[strings.ToLower(expr)]; ok {
+		expr = d
+	}
+
+	fields := strings.Fields(expr)
+
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return nil, fmt.Errorf("cron: expected 5 or 6 fields, got %d in '%s'", len(fields), expr)
+	}
+
+	var (
+		s   = &cronSchedule{}
+		err error
+	)
+
+	if s.second, err = cronSecondField.parse(fields[0]); err != nil {
+		return nil, err
+	}
+
+	if s.minute, err = cronMinuteField.parse(fields[1]); err != nil {
+		return nil, err
+	}
+
+	if s.hour, err = cronHourField.parse(fields[2]); err != nil {
+		return nil, err
+	}
+
+	if s.dom, err = cronDomField.parse(fields[3]); err != nil {
+		return nil, err
+	}
+
+	if s.month, err = cronMonthField.parse(fields[4]); err != nil {
+		return nil, err
+	}
+
+	if s.dow, err = cronDowField.parse(fields[5]); err != nil {
+		return nil, err
+	}
+
+	// 7 is an alias for sunday
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+
+	s.domStar = fields[3] == "*" || fields[3] == "?"
+	s.dowStar = fields[5] == "*" || fields[5] == "?"
+
+	return s, nil
+}
+
+func (f cronField) parse(expr string) (uint64, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(expr, ",") {
+		var (
+			rangeExpr = part
+			step      = 1
+			err       error
+		)
+
+		if i := strings.Index(part, "/"); i >= 0 {
+			rangeExpr = part[:i]
+
+			step, err = strconv.Atoi(part[i+1:])
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("cron: invalid step in %s field: '%s'", f.name, part)
+			}
+		}
+
+		var start, end int
+
+		switch {
+		case rangeExpr == "*" || rangeExpr == "?":
+			start, end = f.min, f.max
+
+		case strings.Contains(rangeExpr, "-"):
+			bounds := strings.SplitN(rangeExpr, "-", 2)
+
+			if start, err = f.value(bounds[0]); err != nil {
+				return 0, err
+			}
+
+			if end, err = f.value(bounds[1]); err != nil {
+				return 0, err
+			}
+
+		default:
+			if start, err = f.value(rangeExpr); err != nil {
+				return 0, err
+			}
+
+			end = start
+			if step > 1 {
+				end = f.max
+			}
+		}
+
+		if start > end {
+			return 0, fmt.Errorf("cron: invalid range in %s field: '%s'", f.name, part)
+		}
+
+		for v := start; v <= end; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+
+	return bits, nil
+}
+
+func (f cronField) value(s string) (int, error) {
+	if v, ok := f.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("cron: invalid value in %s field: '%s'", f.name, s)
+	}
+
+	if v < f.min || v > f.max {
+		return 0, fmt.Errorf("cron: %s value %d out of range [%d, %d]", f.name, v, f.min, f.max)
+	}
+
+	return v, nil
+}
+
+// next returns the first activation time strictly after t,
+// or the zero time if none is found in the next five years.
+func (s *cronSchedule) next(t time.Time) time.Time {
+	t = t.UTC().Truncate(time.Second).Add(time.Second)
+	yearLimit := t.Year() + 5
+	added := false
+
+wrap:
+	if t.Year() > yearLimit {
+		return time.Time{}
+	}
+
+	for s.month&(1<<uint(t.Month())) == 0 {
+		if !added {
+			added = true
+			t = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+		}
+
+		t = t.AddDate(0, 1, 0)
+		if t.Month() == time.January {
+			goto wrap
+		}
+	}
+
+	for !s.dayMatches(t) {
+		if !added {
+			added = true
+			t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		}
+
+		t = t.AddDate(0, 0, 1)
+		if t.Day() == 1 {
+			goto wrap
+		}
+	}
+
+	for s.hour&(1<<uint(t.Hour())) == 0 {
+		if !added {
+			added = true
+			t = t.Truncate(time.Hour)
+		}
+
+		t = t.Add(time.Hour)
+		if t.Hour() == 0 {
+			goto wrap
+		}
+	}
+
+	for s.minute&(1<<uint(t.Minute())) == 0 {
+		if !added {
+			added = true
+			t = t.Truncate(time.Minute)
+		}
+
+		t = t.Add(time.Minute)
+		if t.Minute() == 0 {
+			goto wrap
+		}
+	}
+
+	for s.second&(1<<uint(t.Second())) == 0 {
+		if !added {
+			added = true
+		}
+
+		t = t.Add(time.Second)
+		if t.Second() == 0 {
+			goto wrap
+		}
+	}
+
+	return t
+}
+
+// dayMatches follows the usual cron semantics: when both day of
+// month and day of week are restricted, either may match.
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+
+	return domMatch || dowMatch
+}
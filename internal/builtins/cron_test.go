@@ -0,0 +1,117 @@
+package builtins_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/reposaur/reposaur/internal/builtins"
+)
+
+func TestCronValid(t *testing.T) {
+	cases := map[string]bool{
+		"* * * * *":         true,
+		"0 * * * *":         true,
+		"*/15 9-17 * * 1-5": true,
+		"0 0 1 jan,jul *":   true,
+		"30 0 0 * * SUN":    true,
+		"@daily":            true,
+		"@hourly":           true,
+		"":                  false,
+		"* * * *":           false,
+		"60 * * * *":        false,
+		"* 24 * * *":        false,
+		"*/0 * * * *":       false,
+		"5-1 * * * *":       false,
+		"* * * foo *":       false,
+		"* * * * * * *":     false,
+		"@sometimes":        false,
+		"0 0 0 0 0":         false,
+	}
+
+	for expr, expected := range cases {
+		term, err := builtins.CronValidBuiltinImpl(rego.BuiltinContext{}, ast.StringTerm(expr))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := term.Value.Compare(ast.Boolean(expected)) == 0; !got {
+			t.Errorf("expected cron.valid(%q) to be %v", expr, expected)
+		}
+	}
+}
+
+func TestCronParse(t *testing.T) {
+	now := time.Date(2022, time.May, 2, 10, 20, 0, 0, time.UTC)
+	bctx := rego.BuiltinContext{
+		Time: ast.IntNumberTerm(int(now.UnixNano())),
+	}
+
+	term, err := builtins.CronParseBuiltinImpl(bctx, ast.StringTerm("0 */6 * * *"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got struct {
+		Next     []string `json:"next"`
+		Interval int64    `json:"interval"`
+	}
+
+	if err := ast.As(term.Value, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedNext := []string{
+		"2022-05-02T12:00:00Z",
+		"2022-05-02T18:00:00Z",
+		"2022-05-03T00:00:00Z",
+		"2022-05-03T06:00:00Z",
+		"2022-05-03T12:00:00Z",
+	}
+
+	if !reflect.DeepEqual(expectedNext, got.Next) {
+		t.Errorf("expected next runs to be %v, got %v", expectedNext, got.Next)
+	}
+
+	if expected := int64(6 * 60 * 60); got.Interval != expected {
+		t.Errorf("expected interval to be %d, got %d", expected, got.Interval)
+	}
+}
+
+func TestCronParseExtended(t *testing.T) {
+	now := time.Date(2022, time.May, 2, 10, 20, 0, 0, time.UTC)
+	bctx := rego.BuiltinContext{
+		Time: ast.IntNumberTerm(int(now.UnixNano())),
+	}
+
+	term, err := builtins.CronParseBuiltinImpl(bctx, ast.StringTerm("*/30 * * * * *"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got struct {
+		Next     []string `json:"next"`
+		Interval int64    `json:"interval"`
+	}
+
+	if err := ast.As(term.Value, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "2022-05-02T10:20:30Z"; got.Next[0] != expected {
+		t.Errorf("expected first run to be %s, got %s", expected, got.Next[0])
+	}
+
+	if expected := int64(30); got.Interval != expected {
+		t.Errorf("expected interval to be %d, got %d", expected, got.Interval)
+	}
+}
+
+func TestCronParseInvalid(t *testing.T) {
+	_, err := builtins.CronParseBuiltinImpl(rego.BuiltinContext{}, ast.StringTerm("* * *"))
+	if err == nil {
+		t.Error("expected error parsing invalid expression")
+	}
+}
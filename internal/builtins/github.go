@@ -0,0 +1,365 @@
+package builtins
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/reposaur/reposaur/pkg/util"
+)
+
+// DefaultAPIVersion is the version of the GitHub REST API requested
+// by default, pinned so responses don't drift as GitHub changes them.
+const DefaultAPIVersion = "2022-11-28"
+
+// defaultAccept is the media type GitHub recommends requesting.
+const defaultAccept = "application/vnd.github+json"
+
+// DefaultMaxResponseSize is the default maximum size of the responses
+// read by `github.request` and `github.graphql`, generous enough for
+// any legitimate response while bounding memory use.
+const DefaultMaxResponseSize = 64 << 20
+
+// ErrResponseTooLarge is returned when a response
+// exceeds the maximum size (see WithMaxResponseSize).
+var ErrResponseTooLarge = errors.New("response too large")
+
+var linkNextRegex = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+// githubGet does a GET request against the GitHub API and, if the
+// response is successful, decodes its body into v. The response
+// status code is returned so callers can handle unsuccessful
+// responses, e.g. treating 404 as undefined.
+func githubGet(ctx context.Context, client *http.Client, path string, v interface{}) (int, error) {
+	resp, err := githubDo(ctx, client, path)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return resp.StatusCode, nil
+	}
+
+	if v != nil {
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			return 0, err
+		}
+	}
+
+	return resp.StatusCode, nil
+}
+
+// githubGetPages does a GET request against the GitHub API and follows
+// the pagination links, returning every item of every page, or at most
+// limit items if it's positive. Endpoints that wrap items in an object
+// are supported by setting key to the name of the property that holds
+// them. If a page is unsuccessful, the items are nil and its status
+// code is returned.
+func githubGetPages(ctx context.Context, client *http.Client, path, key string, limit int) ([]interface{}, int, error) {
+	var (
+		items []interface{}
+		next  = withQuery(path, url.Values{"per_page": {"100"}})
+	)
+
+	for next != "" {
+		resp, err := githubDo(ctx, client, next)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+
+			return nil, resp.StatusCode, nil
+		}
+
+		var page []interface{}
+
+		if key == "" {
+			err = json.NewDecoder(resp.Body).Decode(&page)
+		} else {
+			var obj map[string]json.RawMessage
+
+			if err = json.NewDecoder(resp.Body).Decode(&obj); err == nil && obj[key] != nil {
+				err = json.Unmarshal(obj[key], &page)
+			}
+		}
+
+		resp.Body.Close()
+
+		if err != nil {
+			return nil, 0, err
+		}
+
+		items = append(items, page...)
+
+		if limit > 0 && len(items) >= limit {
+			items = items[:limit]
+			break
+		}
+
+		next = ""
+		if m := linkNextRegex.FindStringSubmatch(resp.Header.Get("Link")); m != nil {
+			next = m[1]
+		}
+	}
+
+	if items == nil {
+		items = []interface{}{}
+	}
+
+	return items, http.StatusOK, nil
+}
+
+// decodeItems decodes the items returned by
+// githubGetPages into v, e.g. a slice of structs.
+func decodeItems(items []interface{}, v interface{}) error {
+	b, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, v)
+}
+
+func githubDo(ctx context.Context, client *http.Client, path string) (*http.Response, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("User-Agent", "reposaur")
+	req.Header.Set("Accept", defaultAccept)
+	req.Header.Set("X-GitHub-Api-Version", contextOptions(ctx).apiVersion)
+
+	return contextClient(ctx, client).Do(req)
+}
+
+// contextClient returns the HTTP client carried by ctx, set by
+// engines with their own client, falling back to client. Its
+// requests are recorded by the request recorder carried by ctx,
+// if any (e.g. for an audit trail).
+func contextClient(ctx context.Context, client *http.Client) *http.Client {
+	client = resolveClient(ctx, client)
+
+	if rec, ok := util.RecorderFromContext(ctx); ok {
+		recording := *client
+		recording.Transport = util.NewRecordingTransport(client.Transport, rec)
+
+		return &recording
+	}
+
+	return client
+}
+
+// resolveClient returns the HTTP client carried by ctx,
+// falling back to client.
+func resolveClient(ctx context.Context, client *http.Client) *http.Client {
+	if c, ok := util.ClientFromContext(ctx); ok {
+		return c
+	}
+
+	return client
+}
+
+// clientIDs holds a random identifier for each HTTP client.
+var clientIDs sync.Map
+
+// clientID returns a random identifier of client, used to key cached
+// responses so the ones fetched with a client's credentials aren't
+// returned to another client.
+func clientID(client *http.Client) string {
+	if id, ok := clientIDs.Load(client); ok {
+		return id.(string)
+	}
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+
+	id, _ := clientIDs.LoadOrStore(client, hex.EncodeToString(b))
+
+	return id.(string)
+}
+
+// githubContent fetches a file using the contents API and returns
+// its decoded content. Returns false if the file doesn't exist.
+func githubContent(ctx context.Context, client *http.Client, owner, repo, path, ref string) ([]byte, bool, error) {
+	var file struct {
+		Type     string `json:"type"`
+		Content  string `json:"content"`
+		Encoding string `json:"encoding"`
+	}
+
+	reqPath := withQuery(repoPath(owner, repo, "contents", path), refQuery(ref))
+
+	status, err := githubGet(ctx, client, reqPath, &file)
+	if err != nil {
+		return nil, false, err
+	} else if status == http.StatusNotFound {
+		return nil, false, nil
+	} else if status != http.StatusOK {
+		return nil, false, fmt.Errorf("get %s content: unexpected status %d", path, status)
+	}
+
+	if file.Type != "file" {
+		return nil, false, nil
+	}
+
+	content, err := decodeContent(file.Content)
+	if err != nil {
+		return nil, false, fmt.Errorf("decode %s content: %w", path, err)
+	}
+
+	return content, true, nil
+}
+
+// decodeContent decodes Base64 content returned by the
+// contents API, which includes line breaks.
+func decodeContent(content string) ([]byte, error) {
+	content = strings.NewReplacer("\n", "", "\r", "").Replace(content)
+
+	return base64.StdEncoding.DecodeString(content)
+}
+
+// repoPath builds the API path of a repository resource,
+// escaping each part, e.g. /repos/{owner}/{repo}/contents/{path}.
+func repoPath(owner, repo string, parts ...string) string {
+	escaped := []string{"", "repos", url.PathEscape(owner), url.PathEscape(repo)}
+
+	for _, p := range parts {
+		for _, s := range strings.Split(p, "/") {
+			if s != "" {
+				escaped = append(escaped, url.PathEscape(s))
+			}
+		}
+	}
+
+	return strings.Join(escaped, "/")
+}
+
+// filterQuery builds the query of the filters in opts, which are
+// either a string or an array of strings, joined with commas.
+func filterQuery(opts map[string]interface{}, filters []string) (url.Values, error) {
+	query := url.Values{}
+
+	for _, k := range filters {
+		switch v := opts[k].(type) {
+		case nil:
+		case string:
+			query.Set(k, v)
+		case []interface{}:
+			var values []string
+			for _, s := range v {
+				values = append(values, fmt.Sprint(s))
+			}
+
+			query.Set(k, strings.Join(values, ","))
+		default:
+			return nil, fmt.Errorf("invalid %s option: must be a string or an array", k)
+		}
+	}
+
+	return query, nil
+}
+
+func refQuery(ref string) url.Values {
+	if ref == "" {
+		return nil
+	}
+
+	return url.Values{"ref": {ref}}
+}
+
+func withQuery(path string, query url.Values) string {
+	if len(query) == 0 {
+		return path
+	}
+
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+
+	return path + sep + query.Encode()
+}
+
+// repositoryExists returns true if the repository exists, e.g.
+// to tell apart a disabled feature from a missing repository.
+func repositoryExists(bctx rego.BuiltinContext, client *http.Client, owner, repo string) (bool, error) {
+	status, err := githubGet(bctx.Context, client, repoPath(owner, repo), nil)
+	if err != nil {
+		return false, err
+	}
+
+	switch status {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, fmt.Errorf("get repository: unexpected status %d", status)
+	}
+}
+
+// limitedReader reads from r until max bytes are read,
+// failing with ErrResponseTooLarge if there are more.
+type limitedReader struct {
+	r   io.Reader
+	max int64
+	n   int64
+}
+
+// limitResponse limits the size of body to the maximum
+// response size of ctx's options, if it's positive.
+func limitResponse(ctx context.Context, body io.Reader) io.Reader {
+	max := contextOptions(ctx).maxResponseSize
+	if max <= 0 {
+		return body
+	}
+
+	return &limitedReader{r: body, max: max, n: max}
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.n <= 0 {
+		// the limit is reached, but the response is
+		// only too large if there's more to read
+		var b [1]byte
+
+		n, err := l.r.Read(b[:])
+		if n > 0 {
+			return 0, fmt.Errorf("%w: exceeds %d bytes", ErrResponseTooLarge, l.max)
+		}
+
+		return 0, err
+	}
+
+	if int64(len(p)) > l.n {
+		p = p[:l.n]
+	}
+
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+
+	return n, err
+}
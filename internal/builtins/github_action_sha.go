@@ -0,0 +1,123 @@
+package builtins
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/types"
+)
+
+var fullSHARegex = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+var GitHubActionSHABuiltin = rego.Function{
+	Name: "github.action_sha",
+	Decl: types.NewFunction(
+		types.Args(types.S, types.S),
+		types.S,
+	),
+	Memoize: true,
+}
+
+type gitObject struct {
+	Object struct {
+		Type string `json:"type"`
+		SHA  string `json:"sha"`
+	} `json:"object"`
+}
+
+// GitHubActionSHABuiltinImpl resolves the ref of an action (e.g.
+// "actions/checkout" and "v3") to the SHA of the commit it points
+// to. Tags are looked up before branches and annotated tags are
+// dereferenced. The action may be in a subdirectory of its
+// repository, e.g. "github/codeql-action/init". Returns undefined
+// if the ref doesn't exist.
+func GitHubActionSHABuiltinImpl(client *http.Client) func(bctx rego.BuiltinContext, op1, op2 *ast.Term) (*ast.Term, error) {
+	return func(bctx rego.BuiltinContext, op1, op2 *ast.Term) (*ast.Term, error) {
+		var action, ref string
+
+		if err := ast.As(op1.Value, &action); err != nil {
+			return nil, err
+		} else if err := ast.As(op2.Value, &ref); err != nil {
+			return nil, err
+		}
+
+		parts := strings.SplitN(action, "/", 3)
+		if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid action %q", action)
+		}
+
+		sha, err := resolveRefSHA(bctx, client, parts[0], parts[1], ref)
+		if err != nil {
+			return nil, err
+		} else if sha == "" {
+			return nil, nil
+		}
+
+		return ast.StringTerm(sha), nil
+	}
+}
+
+// resolveRefSHA resolves ref to the SHA of the commit it points to.
+// Full SHAs are checked to exist, fully qualified refs (e.g.
+// "refs/tags/v1") are looked up by their kind and short refs are
+// looked up as tags before branches. Returns an empty SHA if the
+// ref doesn't exist.
+func resolveRefSHA(bctx rego.BuiltinContext, client *http.Client, owner, repo, ref string) (string, error) {
+	if fullSHARegex.MatchString(ref) {
+		status, err := githubGet(bctx.Context, client, repoPath(owner, repo, "git", "commits", ref), nil)
+		if err != nil {
+			return "", err
+		} else if status == http.StatusOK {
+			return ref, nil
+		} else if status != http.StatusNotFound && status != http.StatusUnprocessableEntity {
+			return "", fmt.Errorf("get commit: unexpected status %d", status)
+		}
+
+		return "", nil
+	}
+
+	kinds := []string{"tags", "heads"}
+
+	for _, kind := range kinds {
+		if qualified := "refs/" + kind + "/"; strings.HasPrefix(ref, qualified) {
+			kinds = []string{kind}
+			ref = strings.TrimPrefix(ref, qualified)
+
+			break
+		}
+	}
+
+	for _, kind := range kinds {
+		var obj gitObject
+
+		status, err := githubGet(bctx.Context, client, repoPath(owner, repo, "git", "ref", kind, ref), &obj)
+		if err != nil {
+			return "", err
+		} else if status == http.StatusNotFound {
+			continue
+		} else if status != http.StatusOK {
+			return "", fmt.Errorf("get ref: unexpected status %d", status)
+		}
+
+		// Annotated tags point to a tag object, which may
+		// point to another tag, before reaching the commit.
+		for obj.Object.Type == "tag" {
+			sha := obj.Object.SHA
+
+			status, err := githubGet(bctx.Context, client, repoPath(owner, repo, "git", "tags", sha), &obj)
+			if err != nil {
+				return "", err
+			} else if status != http.StatusOK {
+				return "", fmt.Errorf("get tag %s: unexpected status %d", sha, status)
+			}
+		}
+
+		return obj.Object.SHA, nil
+	}
+
+	return "", nil
+}
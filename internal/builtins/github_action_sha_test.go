@@ -0,0 +1,83 @@
+package builtins_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/reposaur/reposaur/internal/builtins"
+)
+
+const (
+	checkoutTagSHA    = "1111111111111111111111111111111111111111"
+	checkoutCommitSHA = "2222222222222222222222222222222222222222"
+	checkoutMainSHA   = "3333333333333333333333333333333333333333"
+)
+
+func actionRefsHandler() http.Handler {
+	responses := map[string]string{
+		"/repos/actions/checkout/git/ref/tags/v3":                `{"ref": "refs/tags/v3", "object": {"type": "tag", "sha": "` + checkoutTagSHA + `"}}`,
+		"/repos/actions/checkout/git/tags/" + checkoutTagSHA:     `{"sha": "` + checkoutTagSHA + `", "object": {"type": "commit", "sha": "` + checkoutCommitSHA + `"}}`,
+		"/repos/actions/checkout/git/ref/heads/main":             `{"ref": "refs/heads/main", "object": {"type": "commit", "sha": "` + checkoutMainSHA + `"}}`,
+		"/repos/actions/checkout/git/commits/" + checkoutMainSHA: `{"sha": "` + checkoutMainSHA + `"}`,
+		"/repos/github/codeql-action/git/ref/tags/v2":            `{"ref": "refs/tags/v2", "object": {"type": "commit", "sha": "` + checkoutMainSHA + `"}}`,
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		_, _ = w.Write([]byte(body))
+	})
+}
+
+func TestGitHubActionSHA(t *testing.T) {
+	impl := builtins.GitHubActionSHABuiltinImpl(newStubClient(t, actionRefsHandler()))
+
+	cases := []struct {
+		action   string
+		ref      string
+		expected string
+	}{
+		{"actions/checkout", "v3", checkoutCommitSHA},
+		{"actions/checkout", "main", checkoutMainSHA},
+		{"actions/checkout", checkoutMainSHA, checkoutMainSHA},
+		{"github/codeql-action/init", "v2", checkoutMainSHA},
+		{"actions/checkout", "v99", ""},
+		{"actions/checkout", strings.Repeat("4", 40), ""},
+	}
+
+	for _, c := range cases {
+		t.Run(c.action+"@"+c.ref, func(t *testing.T) {
+			term, err := impl(rego.BuiltinContext{}, ast.StringTerm(c.action), ast.StringTerm(c.ref))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if c.expected == "" {
+				if term != nil {
+					t.Errorf("expected undefined, got %v", term)
+				}
+
+				return
+			}
+
+			if term == nil || term.Value.Compare(ast.String(c.expected)) != 0 {
+				t.Errorf("expected %s, got %v", c.expected, term)
+			}
+		})
+	}
+}
+
+func TestGitHubActionSHAInvalidAction(t *testing.T) {
+	impl := builtins.GitHubActionSHABuiltinImpl(newStubClient(t, actionRefsHandler()))
+
+	if _, err := impl(rego.BuiltinContext{}, ast.StringTerm("checkout"), ast.StringTerm("v3")); err == nil {
+		t.Error("expected error for an action without owner")
+	}
+}
@@ -0,0 +1,94 @@
+package builtins
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/types"
+)
+
+var GitHubActionsSecretsBuiltin = rego.Function{
+	Name: "github.actions_secrets",
+	Decl: types.NewFunction(
+		types.Args(types.S, types.S),
+		types.NewArray(nil, types.NewObject(nil, types.NewDynamicProperty(types.S, types.A))),
+	),
+	Memoize: true,
+}
+
+var GitHubActionsVariablesBuiltin = rego.Function{
+	Name: "github.actions_variables",
+	Decl: types.NewFunction(
+		types.Args(types.S, types.S),
+		types.NewArray(nil, types.NewObject(nil, types.NewDynamicProperty(types.S, types.A))),
+	),
+	Memoize: true,
+}
+
+// ActionsConfigName is the metadata of a GitHub Actions
+// secret or variable of a repository, never its value.
+type ActionsConfigName struct {
+	Name      string `json:"name"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+// GitHubActionsSecretsBuiltinImpl fetches the names of the GitHub Actions
+// secrets defined in a repository, not including the ones inherited from
+// its organization. Repositories without secrets have an empty list.
+// Listing them requires admin access, so the result is undefined when
+// the request is forbidden or the repository doesn't exist.
+func GitHubActionsSecretsBuiltinImpl(client *http.Client) func(bctx rego.BuiltinContext, op1, op2 *ast.Term) (*ast.Term, error) {
+	return actionsConfigNamesImpl(client, "secrets")
+}
+
+// GitHubActionsVariablesBuiltinImpl fetches the names of the GitHub
+// Actions variables defined in a repository like github.actions_secrets.
+// Their values are never included.
+func GitHubActionsVariablesBuiltinImpl(client *http.Client) func(bctx rego.BuiltinContext, op1, op2 *ast.Term) (*ast.Term, error) {
+	return actionsConfigNamesImpl(client, "variables")
+}
+
+// actionsConfigNamesImpl lists the names of the repository's
+// GitHub Actions configuration of kind, secrets or variables.
+func actionsConfigNamesImpl(client *http.Client, kind string) func(bctx rego.BuiltinContext, op1, op2 *ast.Term) (*ast.Term, error) {
+	return func(bctx rego.BuiltinContext, op1, op2 *ast.Term) (*ast.Term, error) {
+		var owner, repo string
+
+		if err := ast.As(op1.Value, &owner); err != nil {
+			return nil, err
+		} else if err := ast.As(op2.Value, &repo); err != nil {
+			return nil, err
+		}
+
+		items, status, err := githubGetPages(bctx.Context, client, repoPath(owner, repo, "actions", kind), kind, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		switch status {
+		case http.StatusOK:
+		case http.StatusForbidden, http.StatusNotFound:
+			return nil, nil
+		default:
+			return nil, fmt.Errorf("get actions %s: unexpected status %d", kind, status)
+		}
+
+		// decoded into the metadata
+		// only, dropping any values
+		names := []ActionsConfigName{}
+
+		if err := decodeItems(items, &names); err != nil {
+			return nil, err
+		}
+
+		val, err := ast.InterfaceToValue(names)
+		if err != nil {
+			return nil, err
+		}
+
+		return ast.NewTerm(val), nil
+	}
+}
@@ -0,0 +1,126 @@
+package builtins_test
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/reposaur/reposaur/internal/builtins"
+)
+
+func newActionsSecretsStubClient(t *testing.T) *http.Client {
+	return newStubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/reposaur/reposaur/actions/secrets":
+			if r.URL.Query().Get("page") == "" {
+				w.Header().Set("Link", fmt.Sprintf(`<%s&page=2>; rel="next"`, r.URL.RequestURI()))
+				_, _ = w.Write([]byte(`{"total_count": 2, "secrets": [{"name": "GH_TOKEN", "created_at": "2022-01-01T00:00:00Z", "updated_at": "2022-02-01T00:00:00Z"}]}`))
+				return
+			}
+
+			_, _ = w.Write([]byte(`{"total_count": 2, "secrets": [{"name": "NPM_TOKEN", "created_at": "2022-03-01T00:00:00Z", "updated_at": "2022-03-01T00:00:00Z"}]}`))
+
+		case "/repos/reposaur/reposaur/actions/variables":
+			_, _ = w.Write([]byte(`{"total_count": 1, "variables": [{"name": "REGISTRY", "value": "ghcr.io/reposaur", "created_at": "2022-01-01T00:00:00Z", "updated_at": "2022-01-02T00:00:00Z"}]}`))
+
+		case "/repos/reposaur/empty/actions/secrets", "/repos/reposaur/empty/actions/variables":
+			_, _ = w.Write([]byte(`{"total_count": 0, "secrets": [], "variables": []}`))
+
+		case "/repos/reposaur/forbidden/actions/secrets", "/repos/reposaur/forbidden/actions/variables":
+			w.WriteHeader(http.StatusForbidden)
+
+		case "/repos/reposaur/broken/actions/secrets":
+			w.WriteHeader(http.StatusInternalServerError)
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestGitHubActionsSecrets(t *testing.T) {
+	impl := builtins.GitHubActionsSecretsBuiltinImpl(newActionsSecretsStubClient(t))
+
+	term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm("reposaur"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var secrets []builtins.ActionsConfigName
+	if err := ast.As(term.Value, &secrets); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []builtins.ActionsConfigName{
+		{Name: "GH_TOKEN", CreatedAt: "2022-01-01T00:00:00Z", UpdatedAt: "2022-02-01T00:00:00Z"},
+		{Name: "NPM_TOKEN", CreatedAt: "2022-03-01T00:00:00Z", UpdatedAt: "2022-03-01T00:00:00Z"},
+	}
+
+	if len(secrets) != len(expected) {
+		t.Fatalf("expected %d secrets, got %d", len(expected), len(secrets))
+	}
+
+	for i := range expected {
+		if secrets[i] != expected[i] {
+			t.Errorf("expected %+v, got %+v", expected[i], secrets[i])
+		}
+	}
+}
+
+func TestGitHubActionsVariables(t *testing.T) {
+	impl := builtins.GitHubActionsVariablesBuiltinImpl(newActionsSecretsStubClient(t))
+
+	term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm("reposaur"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(term.String(), "ghcr.io/reposaur") {
+		t.Errorf("expected the variable value to be omitted, got %v", term)
+	}
+
+	var variables []builtins.ActionsConfigName
+	if err := ast.As(term.Value, &variables); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := builtins.ActionsConfigName{Name: "REGISTRY", CreatedAt: "2022-01-01T00:00:00Z", UpdatedAt: "2022-01-02T00:00:00Z"}
+
+	if len(variables) != 1 || variables[0] != expected {
+		t.Errorf("expected [%+v], got %+v", expected, variables)
+	}
+}
+
+func TestGitHubActionsSecretsUnavailable(t *testing.T) {
+	client := newActionsSecretsStubClient(t)
+
+	impls := map[string]func(rego.BuiltinContext, *ast.Term, *ast.Term) (*ast.Term, error){
+		"secrets":   builtins.GitHubActionsSecretsBuiltinImpl(client),
+		"variables": builtins.GitHubActionsVariablesBuiltinImpl(client),
+	}
+
+	for kind, impl := range impls {
+		term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm("empty"))
+		if err != nil {
+			t.Fatal(err)
+		} else if !term.Equal(ast.ArrayTerm()) {
+			t.Errorf("%s: expected an empty list, got %v", kind, term)
+		}
+
+		for _, repo := range []string{"forbidden", "missing"} {
+			term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm(repo))
+			if err != nil {
+				t.Fatal(err)
+			} else if term != nil {
+				t.Errorf("%s: %s: expected undefined, got %v", kind, repo, term)
+			}
+		}
+	}
+
+	if _, err := impls["secrets"](rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm("broken")); err == nil {
+		t.Error("expected an error for an unexpected status")
+	}
+}
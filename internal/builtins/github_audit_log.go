@@ -0,0 +1,100 @@
+package builtins
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/types"
+)
+
+var GitHubAuditLogBuiltin = rego.Function{
+	Name: "github.audit_log",
+	Decl: types.NewFunction(
+		types.Args(
+			types.S,
+			types.S,
+			types.NewObject(nil, types.NewDynamicProperty(types.S, types.A)),
+		),
+		types.NewArray(nil, types.A),
+	),
+	Memoize: true,
+}
+
+// auditLogOptions are the options accepted
+// by the github.audit_log built-in.
+type auditLogOptions struct {
+	// Include is the type of events returned, one of
+	// web (default), git or all.
+	Include string `json:"include"`
+
+	// Order is the order of the events by
+	// timestamp, one of desc (default) or asc.
+	Order string `json:"order"`
+
+	// Limit is the maximum number of events returned,
+	// every matching event is returned if zero.
+	Limit int `json:"limit"`
+}
+
+// GitHubAuditLogBuiltinImpl queries the audit log of an organization
+// with a search phrase (e.g. "action:repo.access created:>=2022-06-01")
+// and returns the matching events. Querying the audit log requires
+// the credentials of an organization owner. Returns undefined if the
+// organization's audit log isn't available.
+func GitHubAuditLogBuiltinImpl(client *http.Client) func(bctx rego.BuiltinContext, op1, op2, op3 *ast.Term) (*ast.Term, error) {
+	return func(bctx rego.BuiltinContext, op1, op2, op3 *ast.Term) (*ast.Term, error) {
+		var (
+			org, phrase string
+			opts        auditLogOptions
+		)
+
+		if err := ast.As(op1.Value, &org); err != nil {
+			return nil, err
+		} else if err := ast.As(op2.Value, &phrase); err != nil {
+			return nil, err
+		} else if err := ast.As(op3.Value, &opts); err != nil {
+			return nil, err
+		}
+
+		query := url.Values{}
+
+		if phrase != "" {
+			query.Set("phrase", phrase)
+		}
+
+		if opts.Include != "" {
+			query.Set("include", opts.Include)
+		}
+
+		if opts.Order != "" {
+			query.Set("order", opts.Order)
+		}
+
+		path := withQuery("/orgs/"+url.PathEscape(org)+"/audit-log", query)
+
+		events, status, err := githubGetPages(bctx.Context, client, path, "", opts.Limit)
+		if err != nil {
+			return nil, err
+		}
+
+		switch status {
+		case http.StatusOK:
+		case http.StatusNotFound:
+			return nil, nil
+		case http.StatusForbidden:
+			return nil, fmt.Errorf("get audit log: forbidden, organization owner credentials are required")
+		default:
+			return nil, fmt.Errorf("get audit log: unexpected status %d", status)
+		}
+
+		val, err := ast.InterfaceToValue(events)
+		if err != nil {
+			return nil, err
+		}
+
+		return ast.NewTerm(val), nil
+	}
+}
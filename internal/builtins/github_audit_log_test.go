@@ -0,0 +1,125 @@
+package builtins_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/reposaur/reposaur/internal/builtins"
+)
+
+// auditLogHandler serves an audit log of n events
+// for the reposaur organization, in pages of 2.
+func auditLogHandler(t *testing.T, n int, queries *[]string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/orgs/reposaur/audit-log" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		*queries = append(*queries, r.URL.RawQuery)
+
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page == 0 {
+			page = 1
+		}
+
+		var events []map[string]interface{}
+		for i := (page - 1) * 2; i < page*2 && i < n; i++ {
+			events = append(events, map[string]interface{}{
+				"action":     "repo.access",
+				"repo":       fmt.Sprintf("reposaur/repo-%d", i),
+				"@timestamp": 1654041600000 + i,
+			})
+		}
+
+		if page*2 < n {
+			next := *r.URL
+			q := next.Query()
+			q.Set("page", strconv.Itoa(page+1))
+			next.RawQuery = q.Encode()
+			w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="next"`, next.RequestURI()))
+		}
+
+		if err := json.NewEncoder(w).Encode(events); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func TestGitHubAuditLog(t *testing.T) {
+	var queries []string
+
+	client := newStubClient(t, auditLogHandler(t, 5, &queries))
+	impl := builtins.GitHubAuditLogBuiltinImpl(client)
+
+	opts := objectTerm(t, map[string]interface{}{"include": "web"})
+
+	term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm("action:repo.access"), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var events []map[string]interface{}
+	if err := ast.As(term.Value, &events); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(events) != 5 {
+		t.Fatalf("expected 5 events, got %d", len(events))
+	}
+
+	if repo := events[4]["repo"]; repo != "reposaur/repo-4" {
+		t.Errorf("expected last event to be for reposaur/repo-4, got %v", repo)
+	}
+
+	if len(queries) != 3 {
+		t.Fatalf("expected 3 pages to be requested, got %d", len(queries))
+	}
+
+	query, _ := url.ParseQuery(queries[0])
+
+	if query.Get("phrase") != "action:repo.access" || query.Get("include") != "web" {
+		t.Errorf("expected phrase and include to be set, got %s", queries[0])
+	}
+}
+
+func TestGitHubAuditLogLimit(t *testing.T) {
+	var queries []string
+
+	client := newStubClient(t, auditLogHandler(t, 5, &queries))
+	impl := builtins.GitHubAuditLogBuiltinImpl(client)
+
+	opts := objectTerm(t, map[string]interface{}{"limit": 3})
+
+	term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm(""), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := term.Value.(*ast.Array).Len(); n != 3 {
+		t.Errorf("expected 3 events, got %d", n)
+	}
+
+	if len(queries) != 2 {
+		t.Errorf("expected 2 pages to be requested, got %d", len(queries))
+	}
+}
+
+func TestGitHubAuditLogForbidden(t *testing.T) {
+	client := newStubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+
+	impl := builtins.GitHubAuditLogBuiltinImpl(client)
+
+	_, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm(""), objectTerm(t, nil))
+	if err == nil {
+		t.Error("expected error when not an organization owner")
+	}
+}
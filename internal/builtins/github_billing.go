@@ -0,0 +1,77 @@
+package builtins
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/types"
+)
+
+// billingResources maps the resources accepted by github.billing
+// to the billing API endpoint of their usage.
+var billingResources = map[string]string{
+	"actions":  "actions",
+	"packages": "packages",
+	"storage":  "shared-storage",
+}
+
+var GitHubBillingBuiltin = rego.Function{
+	Name: "github.billing",
+	Decl: types.NewFunction(
+		types.Args(types.S, types.S),
+		types.NewObject(nil, types.NewDynamicProperty(types.S, types.A)),
+	),
+	Memoize: true,
+}
+
+// GitHubBillingBuiltinImpl fetches the usage of a billed resource of an
+// organization in the current billing cycle: actions (minutes), packages
+// (bandwidth) or storage (shared storage of Actions and Packages). The
+// usage is returned as reported by GitHub, with numeric values. Reading
+// billing requires the credentials of an organization owner or billing
+// manager, so the result is undefined when the request is forbidden or
+// the organization doesn't exist. Unknown resources halt the evaluation
+// with an error.
+func GitHubBillingBuiltinImpl(client *http.Client) func(bctx rego.BuiltinContext, op1, op2 *ast.Term) (*ast.Term, error) {
+	return func(bctx rego.BuiltinContext, op1, op2 *ast.Term) (*ast.Term, error) {
+		var org, resource string
+
+		if err := ast.As(op1.Value, &org); err != nil {
+			return nil, err
+		} else if err := ast.As(op2.Value, &resource); err != nil {
+			return nil, err
+		}
+
+		endpoint, ok := billingResources[resource]
+		if !ok {
+			return nil, fmt.Errorf("invalid billing resource %q: must be one of actions, packages and storage", resource)
+		}
+
+		var usage map[string]interface{}
+
+		path := fmt.Sprintf("/orgs/%s/settings/billing/%s", url.PathEscape(org), endpoint)
+
+		status, err := githubGet(bctx.Context, client, path, &usage)
+		if err != nil {
+			return nil, err
+		}
+
+		switch status {
+		case http.StatusOK:
+		case http.StatusForbidden, http.StatusNotFound:
+			return nil, nil
+		default:
+			return nil, fmt.Errorf("get %s billing: unexpected status %d", resource, status)
+		}
+
+		val, err := ast.InterfaceToValue(usage)
+		if err != nil {
+			return nil, err
+		}
+
+		return ast.NewTerm(val), nil
+	}
+}
@@ -0,0 +1,74 @@
+package builtins_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/reposaur/reposaur/internal/builtins"
+)
+
+func newBillingStubClient(t *testing.T) *http.Client {
+	return newStubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/orgs/reposaur/settings/billing/actions":
+			_, _ = w.Write([]byte(`{"total_minutes_used": 3200, "total_paid_minutes_used": 200, "included_minutes": 3000, "minutes_used_breakdown": {"UBUNTU": 3200}}`))
+
+		case "/orgs/reposaur/settings/billing/packages":
+			_, _ = w.Write([]byte(`{"total_gigabytes_bandwidth_used": 50, "total_paid_gigabytes_bandwidth_used": 0, "included_gigabytes_bandwidth": 100}`))
+
+		case "/orgs/reposaur/settings/billing/shared-storage":
+			_, _ = w.Write([]byte(`{"days_left_in_billing_cycle": 20, "estimated_paid_storage_for_month": 0, "estimated_storage_for_month": 1.5}`))
+
+		case "/orgs/forbidden/settings/billing/actions":
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"message": "Must have admin rights to Repository."}`))
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestGitHubBilling(t *testing.T) {
+	impl := builtins.GitHubBillingBuiltinImpl(newBillingStubClient(t))
+
+	cases := []struct {
+		resource string
+		key      string
+		expected *ast.Term
+	}{
+		{"actions", "total_paid_minutes_used", ast.IntNumberTerm(200)},
+		{"packages", "total_gigabytes_bandwidth_used", ast.IntNumberTerm(50)},
+		{"storage", "estimated_storage_for_month", ast.FloatNumberTerm(1.5)},
+	}
+
+	for _, c := range cases {
+		term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm(c.resource))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := term.Get(ast.StringTerm(c.key)); got == nil || !got.Equal(c.expected) {
+			t.Errorf("%s: expected %s to be %v, got %v", c.resource, c.key, c.expected, got)
+		}
+	}
+}
+
+func TestGitHubBillingUnavailable(t *testing.T) {
+	impl := builtins.GitHubBillingBuiltinImpl(newBillingStubClient(t))
+
+	for _, org := range []string{"forbidden", "missing"} {
+		term, err := impl(rego.BuiltinContext{}, ast.StringTerm(org), ast.StringTerm("actions"))
+		if err != nil {
+			t.Fatal(err)
+		} else if term != nil {
+			t.Errorf("%s: expected undefined, got %v", org, term)
+		}
+	}
+
+	if _, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm("seats")); err == nil {
+		t.Error("expected an error for an unknown resource")
+	}
+}
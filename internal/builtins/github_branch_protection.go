@@ -0,0 +1,267 @@
+package builtins
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/types"
+)
+
+var GitHubBranchProtectionBuiltin = rego.Function{
+	Name: "github.branch_protection",
+	Decl: types.NewFunction(
+		types.Args(types.S, types.S, types.S),
+		types.NewObject(nil, types.NewDynamicProperty(types.S, types.A)),
+	),
+	Memoize: true,
+}
+
+var GitHubRequiresCheckBuiltin = rego.Function{
+	Name: "github.requires_check",
+	Decl: types.NewFunction(
+		types.Args(types.S, types.S, types.S, types.S),
+		types.B,
+	),
+	Memoize: true,
+}
+
+// BranchProtection is a normalized view of the
+// protection settings of a branch.
+type BranchProtection struct {
+	EnforceAdmins          bool                   `json:"enforce_admins"`
+	RequiredReviews        BranchRequiredReviews  `json:"required_reviews"`
+	RequiredChecks         BranchRequiredChecks   `json:"required_checks"`
+	LinearHistory          bool                   `json:"linear_history"`
+	AllowForcePushes       bool                   `json:"allow_force_pushes"`
+	AllowDeletions         bool                   `json:"allow_deletions"`
+	ConversationResolution bool                   `json:"conversation_resolution"`
+	Signatures             bool                   `json:"signatures"`
+	Restrictions           *BranchPushRestriction `json:"restrictions"`
+}
+
+type BranchRequiredReviews struct {
+	Enabled                 bool `json:"enabled"`
+	Count                   int  `json:"count"`
+	DismissStaleReviews     bool `json:"dismiss_stale_reviews"`
+	RequireCodeOwners       bool `json:"require_code_owners"`
+	RequireLastPushApproval bool `json:"require_last_push_approval"`
+}
+
+type BranchRequiredChecks struct {
+	Enabled  bool     `json:"enabled"`
+	Strict   bool     `json:"strict"`
+	Contexts []string `json:"contexts"`
+
+	// Checks are the required checks with the app that must
+	// report them. Checks required through the legacy contexts
+	// API, or from any app, have a nil AppID.
+	Checks []BranchRequiredCheck `json:"checks"`
+}
+
+type BranchRequiredCheck struct {
+	Context string `json:"context"`
+	AppID   *int   `json:"app_id"`
+}
+
+type BranchPushRestriction struct {
+	Users []string `json:"users"`
+	Teams []string `json:"teams"`
+	Apps  []string `json:"apps"`
+}
+
+// branchProtectionResponse is the subset of the branch
+// protection API response that is normalized.
+type branchProtectionResponse struct {
+	EnforceAdmins              enabledSetting `json:"enforce_admins"`
+	RequiredPullRequestReviews *struct {
+		DismissStaleReviews          bool `json:"dismiss_stale_reviews"`
+		RequireCodeOwnerReviews      bool `json:"require_code_owner_reviews"`
+		RequiredApprovingReviewCount int  `json:"required_approving_review_count"`
+		RequireLastPushApproval      bool `json:"require_last_push_approval"`
+	} `json:"required_pull_request_reviews"`
+	RequiredStatusChecks *struct {
+		Strict   bool     `json:"strict"`
+		Contexts []string `json:"contexts"`
+		Checks   []struct {
+			Context string `json:"context"`
+			AppID   *int   `json:"app_id"`
+		} `json:"checks"`
+	} `json:"required_status_checks"`
+	RequiredLinearHistory          enabledSetting `json:"required_linear_history"`
+	AllowForcePushes               enabledSetting `json:"allow_force_pushes"`
+	AllowDeletions                 enabledSetting `json:"allow_deletions"`
+	RequiredConversationResolution enabledSetting `json:"required_conversation_resolution"`
+	RequiredSignatures             enabledSetting `json:"required_signatures"`
+	Restrictions                   *struct {
+		Users []struct {
+			Login string `json:"login"`
+		} `json:"users"`
+		Teams []struct {
+			Slug string `json:"slug"`
+		} `json:"teams"`
+		Apps []struct {
+			Slug string `json:"slug"`
+		} `json:"apps"`
+	} `json:"restrictions"`
+}
+
+type enabledSetting struct {
+	Enabled bool `json:"enabled"`
+}
+
+// GitHubBranchProtectionBuiltinImpl fetches the protection settings
+// of a branch and returns them normalized. Returns undefined if the
+// branch isn't protected.
+func GitHubBranchProtectionBuiltinImpl(client *http.Client) func(bctx rego.BuiltinContext, op1, op2, op3 *ast.Term) (*ast.Term, error) {
+	return func(bctx rego.BuiltinContext, op1, op2, op3 *ast.Term) (*ast.Term, error) {
+		var owner, repo, branch string
+
+		if err := ast.As(op1.Value, &owner); err != nil {
+			return nil, err
+		} else if err := ast.As(op2.Value, &repo); err != nil {
+			return nil, err
+		} else if err := ast.As(op3.Value, &branch); err != nil {
+			return nil, err
+		}
+
+		bp, err := fetchBranchProtection(bctx, client, owner, repo, branch)
+		if err != nil || bp == nil {
+			return nil, err
+		}
+
+		val, err := ast.InterfaceToValue(bp)
+		if err != nil {
+			return nil, err
+		}
+
+		return ast.NewTerm(val), nil
+	}
+}
+
+// GitHubRequiresCheckBuiltinImpl reports whether the protection of a
+// branch requires the status check with context (e.g. "ci/build")
+// to pass before merging, whether it was required through the
+// legacy contexts or the newer checks. Unprotected branches don't
+// require any checks.
+func GitHubRequiresCheckBuiltinImpl(client *http.Client) func(bctx rego.BuiltinContext, op1, op2, op3, op4 *ast.Term) (*ast.Term, error) {
+	return func(bctx rego.BuiltinContext, op1, op2, op3, op4 *ast.Term) (*ast.Term, error) {
+		var owner, repo, branch, context string
+
+		if err := ast.As(op1.Value, &owner); err != nil {
+			return nil, err
+		} else if err := ast.As(op2.Value, &repo); err != nil {
+			return nil, err
+		} else if err := ast.As(op3.Value, &branch); err != nil {
+			return nil, err
+		} else if err := ast.As(op4.Value, &context); err != nil {
+			return nil, err
+		}
+
+		bp, err := fetchBranchProtection(bctx, client, owner, repo, branch)
+		if err != nil {
+			return nil, err
+		} else if bp == nil {
+			return ast.BooleanTerm(false), nil
+		}
+
+		for _, c := range bp.RequiredChecks.Contexts {
+			if c == context {
+				return ast.BooleanTerm(true), nil
+			}
+		}
+
+		return ast.BooleanTerm(false), nil
+	}
+}
+
+// fetchBranchProtection fetches the protection settings of a
+// branch normalized, or nil if the branch isn't protected.
+func fetchBranchProtection(bctx rego.BuiltinContext, client *http.Client, owner, repo, branch string) (*BranchProtection, error) {
+	var resp branchProtectionResponse
+
+	status, err := githubGet(bctx.Context, client, repoPath(owner, repo, "branches", branch, "protection"), &resp)
+	if err != nil {
+		return nil, err
+	} else if status == http.StatusNotFound {
+		return nil, nil
+	} else if status != http.StatusOK {
+		return nil, fmt.Errorf("get branch protection: unexpected status %d", status)
+	}
+
+	bp := normalizeBranchProtection(resp)
+
+	return &bp, nil
+}
+
+func normalizeBranchProtection(resp branchProtectionResponse) BranchProtection {
+	bp := BranchProtection{
+		EnforceAdmins:          resp.EnforceAdmins.Enabled,
+		LinearHistory:          resp.RequiredLinearHistory.Enabled,
+		AllowForcePushes:       resp.AllowForcePushes.Enabled,
+		AllowDeletions:         resp.AllowDeletions.Enabled,
+		ConversationResolution: resp.RequiredConversationResolution.Enabled,
+		Signatures:             resp.RequiredSignatures.Enabled,
+		RequiredChecks:         BranchRequiredChecks{Contexts: []string{}, Checks: []BranchRequiredCheck{}},
+	}
+
+	if r := resp.RequiredPullRequestReviews; r != nil {
+		bp.RequiredReviews = BranchRequiredReviews{
+			Enabled:                 true,
+			Count:                   r.RequiredApprovingReviewCount,
+			DismissStaleReviews:     r.DismissStaleReviews,
+			RequireCodeOwners:       r.RequireCodeOwnerReviews,
+			RequireLastPushApproval: r.RequireLastPushApproval,
+		}
+	}
+
+	if c := resp.RequiredStatusChecks; c != nil {
+		bp.RequiredChecks.Enabled = true
+		bp.RequiredChecks.Strict = c.Strict
+
+		// Newer responses list checks with their app,
+		// contexts is kept for backwards compatibility.
+		seen := map[string]bool{}
+		for _, check := range c.Checks {
+			if !seen[check.Context] {
+				seen[check.Context] = true
+				bp.RequiredChecks.Contexts = append(bp.RequiredChecks.Contexts, check.Context)
+				bp.RequiredChecks.Checks = append(bp.RequiredChecks.Checks, BranchRequiredCheck{
+					Context: check.Context,
+					AppID:   check.AppID,
+				})
+			}
+		}
+
+		for _, ctx := range c.Contexts {
+			if !seen[ctx] {
+				seen[ctx] = true
+				bp.RequiredChecks.Contexts = append(bp.RequiredChecks.Contexts, ctx)
+				bp.RequiredChecks.Checks = append(bp.RequiredChecks.Checks, BranchRequiredCheck{Context: ctx})
+			}
+		}
+	}
+
+	if r := resp.Restrictions; r != nil {
+		bp.Restrictions = &BranchPushRestriction{
+			Users: []string{},
+			Teams: []string{},
+			Apps:  []string{},
+		}
+
+		for _, u := range r.Users {
+			bp.Restrictions.Users = append(bp.Restrictions.Users, u.Login)
+		}
+
+		for _, t := range r.Teams {
+			bp.Restrictions.Teams = append(bp.Restrictions.Teams, t.Slug)
+		}
+
+		for _, a := range r.Apps {
+			bp.Restrictions.Apps = append(bp.Restrictions.Apps, a.Slug)
+		}
+	}
+
+	return bp
+}
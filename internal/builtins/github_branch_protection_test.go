@@ -0,0 +1,160 @@
+package builtins_test
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/reposaur/reposaur/internal/builtins"
+)
+
+const testBranchProtection = `{
+	"url": "https://api.github.com/repos/reposaur/test/branches/main/protection",
+	"required_status_checks": {
+		"strict": true,
+		"contexts": ["ci/test"],
+		"checks": [
+			{"context": "ci/test", "app_id": null},
+			{"context": "lint", "app_id": 15368}
+		]
+	},
+	"enforce_admins": {"enabled": true},
+	"required_pull_request_reviews": {
+		"dismiss_stale_reviews": true,
+		"require_code_owner_reviews": false,
+		"required_approving_review_count": 2
+	},
+	"restrictions": {
+		"users": [{"login": "octocat"}],
+		"teams": [{"slug": "maintainers"}],
+		"apps": []
+	},
+	"required_linear_history": {"enabled": true},
+	"allow_force_pushes": {"enabled": false},
+	"allow_deletions": {"enabled": false},
+	"required_conversation_resolution": {"enabled": true}
+}`
+
+func TestGitHubBranchProtection(t *testing.T) {
+	client := newStubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/reposaur/test/branches/main/protection" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		_, _ = w.Write([]byte(testBranchProtection))
+	}))
+
+	impl := builtins.GitHubBranchProtectionBuiltinImpl(client)
+
+	term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm("test"), ast.StringTerm("main"))
+	if err != nil {
+		t.Fatal(err)
+	} else if term == nil {
+		t.Fatal("expected branch protection")
+	}
+
+	var bp builtins.BranchProtection
+	if err := ast.As(term.Value, &bp); err != nil {
+		t.Fatal(err)
+	}
+
+	lintAppID := 15368
+
+	expected := builtins.BranchProtection{
+		EnforceAdmins: true,
+		RequiredReviews: builtins.BranchRequiredReviews{
+			Enabled:             true,
+			Count:               2,
+			DismissStaleReviews: true,
+		},
+		RequiredChecks: builtins.BranchRequiredChecks{
+			Enabled:  true,
+			Strict:   true,
+			Contexts: []string{"ci/test", "lint"},
+			Checks: []builtins.BranchRequiredCheck{
+				{Context: "ci/test"},
+				{Context: "lint", AppID: &lintAppID},
+			},
+		},
+		LinearHistory:          true,
+		ConversationResolution: true,
+		Restrictions: &builtins.BranchPushRestriction{
+			Users: []string{"octocat"},
+			Teams: []string{"maintainers"},
+			Apps:  []string{},
+		},
+	}
+
+	if !reflect.DeepEqual(expected, bp) {
+		t.Errorf("expected %+v, got %+v", expected, bp)
+	}
+}
+
+func TestGitHubBranchProtectionUnprotected(t *testing.T) {
+	client := newStubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message": "Branch not protected"}`))
+	}))
+
+	impl := builtins.GitHubBranchProtectionBuiltinImpl(client)
+
+	term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm("test"), ast.StringTerm("main"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if term != nil {
+		t.Errorf("expected undefined, got %v", term)
+	}
+}
+
+func TestGitHubRequiresCheck(t *testing.T) {
+	client := newStubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/reposaur/test/branches/main/protection":
+			_, _ = w.Write([]byte(testBranchProtection))
+
+		case "/repos/reposaur/test/branches/legacy/protection":
+			_, _ = w.Write([]byte(`{"required_status_checks": {"strict": false, "contexts": ["ci/build"]}}`))
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message": "Branch not protected"}`))
+		}
+	}))
+
+	impl := builtins.GitHubRequiresCheckBuiltinImpl(client)
+
+	cases := []struct {
+		branch   string
+		context  string
+		expected bool
+	}{
+		{"main", "ci/test", true},
+		{"main", "lint", true},
+		{"main", "ci/build", false},
+		{"legacy", "ci/build", true},
+		{"legacy", "lint", false},
+		{"unprotected", "ci/test", false},
+	}
+
+	for _, c := range cases {
+		term, err := impl(
+			rego.BuiltinContext{},
+			ast.StringTerm("reposaur"),
+			ast.StringTerm("test"),
+			ast.StringTerm(c.branch),
+			ast.StringTerm(c.context),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !term.Equal(ast.BooleanTerm(c.expected)) {
+			t.Errorf("%s requires %s: expected %v, got %v", c.branch, c.context, c.expected, term)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package builtins
+
+import (
+	"bufio"
+	"bytes"
+	"net/http"
+	"strings"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/types"
+)
+
+// codeownersPaths are the locations GitHub looks for a
+// CODEOWNERS file in, in order of precedence.
+var codeownersPaths = []string{
+	".github/CODEOWNERS",
+	"CODEOWNERS",
+	"docs/CODEOWNERS",
+}
+
+var GitHubCodeownersBuiltin = rego.Function{
+	Name: "github.codeowners",
+	Decl: types.NewFunction(
+		types.Args(types.S, types.S, types.S),
+		types.NewObject(nil, types.NewDynamicProperty(types.S, types.A)),
+	),
+	Memoize: true,
+}
+
+// CodeownersRule is a single pattern of a CODEOWNERS file.
+type CodeownersRule struct {
+	Pattern string   `json:"pattern"`
+	Owners  []string `json:"owners"`
+	Section string   `json:"section,omitempty"`
+	Line    int      `json:"line"`
+}
+
+// GitHubCodeownersBuiltinImpl fetches the CODEOWNERS file of a
+// repository at ref (the default branch if empty) and returns its
+// path and parsed rules. Returns undefined if the repository doesn't
+// have a CODEOWNERS file.
+func GitHubCodeownersBuiltinImpl(client *http.Client) func(bctx rego.BuiltinContext, op1, op2, op3 *ast.Term) (*ast.Term, error) {
+	return func(bctx rego.BuiltinContext, op1, op2, op3 *ast.Term) (*ast.Term, error) {
+		var owner, repo, ref string
+
+		if err := ast.As(op1.Value, &owner); err != nil {
+			return nil, err
+		} else if err := ast.As(op2.Value, &repo); err != nil {
+			return nil, err
+		} else if err := ast.As(op3.Value, &ref); err != nil {
+			return nil, err
+		}
+
+		for _, p := range codeownersPaths {
+			content, ok, err := githubContent(bctx.Context, client, owner, repo, p, ref)
+			if err != nil {
+				return nil, err
+			} else if !ok {
+				continue
+			}
+
+			val, err := ast.InterfaceToValue(map[string]interface{}{
+				"path":  p,
+				"rules": parseCodeowners(content),
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			return ast.NewTerm(val), nil
+		}
+
+		return nil, nil
+	}
+}
+
+// parseCodeowners parses the rules of a CODEOWNERS file. Lines
+// starting with # are comments, and section headers such as
+// `[Docs] @org/docs` set the section (and default owners) of the
+// rules that follow.
+func parseCodeowners(content []byte) []CodeownersRule {
+	var (
+		rules         = []CodeownersRule{}
+		section       string
+		defaultOwners []string
+		scanner       = bufio.NewScanner(bytes.NewReader(content))
+	)
+
+	for n := 1; scanner.Scan(); n++ {
+		if name, owners, ok := codeownersSection(scanner.Text()); ok {
+			section, defaultOwners = name, owners
+			continue
+		}
+
+		fields := codeownersFields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		owners := fields[1:]
+		if len(owners) == 0 {
+			owners = defaultOwners
+		}
+
+		rules = append(rules, CodeownersRule{
+			Pattern: strings.ReplaceAll(fields[0], `\#`, "#"),
+			Owners:  append([]string{}, owners...),
+			Section: section,
+			Line:    n,
+		})
+	}
+
+	return rules
+}
+
+// codeownersFields splits a line into whitespace separated fields,
+// stopping at the first comment. Escaped characters (e.g. `\#` or
+// `\ `) are kept as part of the field.
+func codeownersFields(line string) []string {
+	var (
+		fields []string
+		field  strings.Builder
+	)
+
+	flush := func() {
+		if field.Len() > 0 {
+			fields = append(fields, field.String())
+			field.Reset()
+		}
+	}
+
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case c == '\\' && i+1 < len(line):
+			if line[i+1] == ' ' {
+				field.WriteByte(' ')
+			} else {
+				field.WriteString(line[i : i+2])
+			}
+			i++
+		case c == '#' && field.Len() == 0:
+			flush()
+			return fields
+		case c == ' ' || c == '\t':
+			flush()
+		default:
+			field.WriteByte(c)
+		}
+	}
+
+	flush()
+
+	return fields
+}
+
+// codeownersSection parses a section header line, e.g. `[Docs]`,
+// `^[Front End]` (optional) or `[Docs][2] @org/docs` (approvals),
+// returning the section's name and default owners.
+func codeownersSection(line string) (string, []string, bool) {
+	line = strings.TrimPrefix(strings.TrimSpace(line), "^")
+
+	if !strings.HasPrefix(line, "[") {
+		return "", nil, false
+	}
+
+	end := strings.Index(line, "]")
+	if end < 0 {
+		return "", nil, false
+	}
+
+	name, rest := line[1:end], line[end+1:]
+
+	// the number of required approvals
+	if strings.HasPrefix(rest, "[") {
+		if end := strings.Index(rest, "]"); end >= 0 {
+			rest = rest[end+1:]
+		}
+	}
+
+	return strings.TrimSpace(name), codeownersFields(rest), true
+}
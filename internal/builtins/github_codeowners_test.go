@@ -0,0 +1,93 @@
+package builtins_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/reposaur/reposaur/internal/builtins"
+)
+
+const testCodeowners = `# Default owners
+*       @reposaur/maintainers
+
+/docs/  @reposaur/docs @octocat # docs team and octocat
+\#notes.md @octocat
+*.go
+
+[Backend] @reposaur/backend
+/internal/
+/pkg/sdk/ @reposaur/sdk
+
+^[Optional][2]
+/examples/ @octocat
+
+[Front End] @reposaur/frontend @octocat
+/web/
+
+^[Design System][2] @reposaur/design
+/web/components/
+`
+
+func TestGitHubCodeowners(t *testing.T) {
+	client := newStubClient(t, contentsHandler(map[string]string{
+		"docs/CODEOWNERS":    "* @someone-else\n",
+		".github/CODEOWNERS": testCodeowners,
+	}))
+
+	impl := builtins.GitHubCodeownersBuiltinImpl(client)
+
+	term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm("test"), ast.StringTerm("main"))
+	if err != nil {
+		t.Fatal(err)
+	} else if term == nil {
+		t.Fatal("expected CODEOWNERS to be found")
+	}
+
+	var codeowners struct {
+		Path  string                    `json:"path"`
+		Rules []builtins.CodeownersRule `json:"rules"`
+	}
+
+	if err := ast.As(term.Value, &codeowners); err != nil {
+		t.Fatal(err)
+	}
+
+	if codeowners.Path != ".github/CODEOWNERS" {
+		t.Errorf("expected path to be .github/CODEOWNERS, got %s", codeowners.Path)
+	}
+
+	expected := []builtins.CodeownersRule{
+		{Pattern: "*", Owners: []string{"@reposaur/maintainers"}, Line: 2},
+		{Pattern: "/docs/", Owners: []string{"@reposaur/docs", "@octocat"}, Line: 4},
+		{Pattern: "#notes.md", Owners: []string{"@octocat"}, Line: 5},
+		{Pattern: "*.go", Owners: []string{}, Line: 6},
+		{Pattern: "/internal/", Owners: []string{"@reposaur/backend"}, Section: "Backend", Line: 9},
+		{Pattern: "/pkg/sdk/", Owners: []string{"@reposaur/sdk"}, Section: "Backend", Line: 10},
+		{Pattern: "/examples/", Owners: []string{"@octocat"}, Section: "Optional", Line: 13},
+		{Pattern: "/web/", Owners: []string{"@reposaur/frontend", "@octocat"}, Section: "Front End", Line: 16},
+		{Pattern: "/web/components/", Owners: []string{"@reposaur/design"}, Section: "Design System", Line: 19},
+	}
+
+	if !reflect.DeepEqual(expected, codeowners.Rules) {
+		t.Errorf("expected rules %+v, got %+v", expected, codeowners.Rules)
+	}
+}
+
+func TestGitHubCodeownersNotFound(t *testing.T) {
+	client := newStubClient(t, contentsHandler(map[string]string{
+		"README.md": "# Test",
+	}))
+
+	impl := builtins.GitHubCodeownersBuiltinImpl(client)
+
+	term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm("test"), ast.StringTerm(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if term != nil {
+		t.Errorf("expected undefined, got %v", term)
+	}
+}
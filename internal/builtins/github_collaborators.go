@@ -0,0 +1,109 @@
+package builtins
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/types"
+)
+
+// collaboratorAffiliations are the affiliations accepted
+// by github.repo_collaborators. Empty means all.
+var collaboratorAffiliations = map[string]bool{
+	"":        true,
+	"all":     true,
+	"direct":  true,
+	"outside": true,
+}
+
+var GitHubRepoCollaboratorsBuiltin = rego.Function{
+	Name: "github.repo_collaborators",
+	Decl: types.NewFunction(
+		types.Args(types.S, types.S, types.S),
+		types.NewArray(nil, types.NewObject(nil, types.NewDynamicProperty(types.S, types.A))),
+	),
+	Memoize: true,
+}
+
+// Collaborator is a user with access to a
+// repository and their permission level.
+type Collaborator struct {
+	Login      string `json:"login"`
+	Type       string `json:"type"`
+	SiteAdmin  bool   `json:"site_admin"`
+	Permission string `json:"permission"`
+	RoleName   string `json:"role_name"`
+}
+
+type collaboratorResponse struct {
+	Login       string          `json:"login"`
+	Type        string          `json:"type"`
+	SiteAdmin   bool            `json:"site_admin"`
+	RoleName    string          `json:"role_name"`
+	Permissions map[string]bool `json:"permissions"`
+}
+
+// GitHubRepoCollaboratorsBuiltinImpl fetches the collaborators of a
+// repository with the given affiliation: direct (including organization
+// members with direct access), outside or all (also empty). Unknown
+// affiliations halt the evaluation with an error. Returns undefined if
+// the repository doesn't exist.
+func GitHubRepoCollaboratorsBuiltinImpl(client *http.Client) func(bctx rego.BuiltinContext, op1, op2, op3 *ast.Term) (*ast.Term, error) {
+	return func(bctx rego.BuiltinContext, op1, op2, op3 *ast.Term) (*ast.Term, error) {
+		var owner, repo, affiliation string
+
+		if err := ast.As(op1.Value, &owner); err != nil {
+			return nil, err
+		} else if err := ast.As(op2.Value, &repo); err != nil {
+			return nil, err
+		} else if err := ast.As(op3.Value, &affiliation); err != nil {
+			return nil, err
+		}
+
+		if !collaboratorAffiliations[affiliation] {
+			return nil, fmt.Errorf("unknown affiliation '%s'", affiliation)
+		}
+
+		path := repoPath(owner, repo, "collaborators")
+		if affiliation != "" {
+			path = withQuery(path, url.Values{"affiliation": {affiliation}})
+		}
+
+		items, status, err := githubGetPages(bctx.Context, client, path, "", 0)
+		if err != nil {
+			return nil, err
+		} else if status == http.StatusNotFound {
+			return nil, nil
+		} else if status != http.StatusOK {
+			return nil, fmt.Errorf("get collaborators: unexpected status %d", status)
+		}
+
+		var resp []collaboratorResponse
+
+		if err := decodeItems(items, &resp); err != nil {
+			return nil, err
+		}
+
+		collaborators := make([]Collaborator, 0, len(resp))
+
+		for _, r := range resp {
+			collaborators = append(collaborators, Collaborator{
+				Login:      r.Login,
+				Type:       r.Type,
+				SiteAdmin:  r.SiteAdmin,
+				Permission: highestPermission(r.Permissions),
+				RoleName:   r.RoleName,
+			})
+		}
+
+		val, err := ast.InterfaceToValue(collaborators)
+		if err != nil {
+			return nil, err
+		}
+
+		return ast.NewTerm(val), nil
+	}
+}
@@ -0,0 +1,85 @@
+package builtins_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/reposaur/reposaur/internal/builtins"
+)
+
+func newCollaboratorsStubClient(t *testing.T) *http.Client {
+	return newStubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/reposaur/reposaur/collaborators" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if r.URL.Query().Get("affiliation") == "outside" {
+			_, _ = w.Write([]byte(`[{"login": "contractor", "type": "User", "role_name": "write", "permissions": {"push": true, "pull": true}}]`))
+			return
+		}
+
+		_, _ = w.Write([]byte(`[
+			{"login": "octocat", "type": "User", "role_name": "admin", "permissions": {"admin": true, "push": true, "pull": true}},
+			{"login": "contractor", "type": "User", "role_name": "write", "permissions": {"push": true, "pull": true}}
+		]`))
+	}))
+}
+
+func TestGitHubRepoCollaborators(t *testing.T) {
+	impl := builtins.GitHubRepoCollaboratorsBuiltinImpl(newCollaboratorsStubClient(t))
+
+	cases := []struct {
+		affiliation string
+		expected    []builtins.Collaborator
+	}{
+		{
+			affiliation: "",
+			expected: []builtins.Collaborator{
+				{Login: "octocat", Type: "User", Permission: "admin", RoleName: "admin"},
+				{Login: "contractor", Type: "User", Permission: "push", RoleName: "write"},
+			},
+		},
+		{
+			affiliation: "outside",
+			expected: []builtins.Collaborator{
+				{Login: "contractor", Type: "User", Permission: "push", RoleName: "write"},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm("reposaur"), ast.StringTerm(c.affiliation))
+		if err != nil {
+			t.Fatal(err)
+		} else if term == nil {
+			t.Fatal("expected collaborators")
+		}
+
+		var collaborators []builtins.Collaborator
+		if err := ast.As(term.Value, &collaborators); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(collaborators) != len(c.expected) {
+			t.Fatalf("expected %d collaborators with affiliation '%s', got %d", len(c.expected), c.affiliation, len(collaborators))
+		}
+
+		for i, e := range c.expected {
+			if collaborators[i] != e {
+				t.Errorf("expected collaborator %d to be %+v, got %+v", i, e, collaborators[i])
+			}
+		}
+	}
+}
+
+func TestGitHubRepoCollaboratorsUnknownAffiliation(t *testing.T) {
+	impl := builtins.GitHubRepoCollaboratorsBuiltinImpl(newCollaboratorsStubClient(t))
+
+	_, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm("reposaur"), ast.StringTerm("team"))
+	if err == nil {
+		t.Error("expected an error for an unknown affiliation")
+	}
+}
@@ -0,0 +1,259 @@
+package builtins
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/types"
+)
+
+// defaultCommitsLimit is the number of commits returned
+// by github.commits if the limit option isn't set.
+const defaultCommitsLimit = 100
+
+// commitsFilters are the options of github.commits
+// passed as filters to the commits API.
+var commitsFilters = []string{"sha", "path", "author", "committer", "since", "until"}
+
+var GitHubCommitBuiltin = rego.Function{
+	Name: "github.commit",
+	Decl: types.NewFunction(
+		types.Args(types.S, types.S, types.S),
+		types.NewObject(nil, types.NewDynamicProperty(types.S, types.A)),
+	),
+	Memoize: true,
+}
+
+var GitHubCommitsBuiltin = rego.Function{
+	Name: "github.commits",
+	Decl: types.NewFunction(
+		types.Args(
+			types.S,
+			types.S,
+			types.NewObject(nil, types.NewDynamicProperty(types.S, types.A)),
+		),
+		types.NewArray(nil, types.NewObject(nil, types.NewDynamicProperty(types.S, types.A))),
+	),
+	Memoize: true,
+}
+
+// Commit is a normalized view of a commit
+// and the verification of its signature.
+type Commit struct {
+	SHA          string             `json:"sha"`
+	Message      string             `json:"message"`
+	Author       CommitActor        `json:"author"`
+	Committer    CommitActor        `json:"committer"`
+	Parents      []string           `json:"parents"`
+	Verification CommitVerification `json:"verification"`
+}
+
+// CommitActor is the author or committer of a commit. Login
+// is empty if they aren't linked to a GitHub account.
+type CommitActor struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Date  string `json:"date"`
+	Login string `json:"login"`
+}
+
+// CommitVerification is the result of verifying the signature
+// of a commit. Unsigned commits aren't verified, with the
+// unsigned reason.
+type CommitVerification struct {
+	Verified  bool    `json:"verified"`
+	Reason    string  `json:"reason"`
+	Signature *string `json:"signature"`
+}
+
+// commitsOptions are the options of github.commits
+// that aren't filters.
+type commitsOptions struct {
+	// Limit is the maximum number of commits
+	// returned, defaultCommitsLimit if zero.
+	Limit int `json:"limit"`
+}
+
+type commitResponse struct {
+	SHA    string `json:"sha"`
+	Commit struct {
+		Message      string            `json:"message"`
+		Author       *gitActorResponse `json:"author"`
+		Committer    *gitActorResponse `json:"committer"`
+		Verification *struct {
+			Verified  bool    `json:"verified"`
+			Reason    string  `json:"reason"`
+			Signature *string `json:"signature"`
+		} `json:"verification"`
+	} `json:"commit"`
+	Author *struct {
+		Login string `json:"login"`
+	} `json:"author"`
+	Committer *struct {
+		Login string `json:"login"`
+	} `json:"committer"`
+	Parents []struct {
+		SHA string `json:"sha"`
+	} `json:"parents"`
+}
+
+type gitActorResponse struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Date  string `json:"date"`
+}
+
+// GitHubCommitBuiltinImpl fetches a commit of a repository by its SHA
+// (or any ref) and returns it normalized, with the verification of
+// its signature. Unsigned commits aren't an error, they're just not
+// verified. Returns undefined if the commit doesn't exist.
+func GitHubCommitBuiltinImpl(client *http.Client) func(bctx rego.BuiltinContext, op1, op2, op3 *ast.Term) (*ast.Term, error) {
+	return func(bctx rego.BuiltinContext, op1, op2, op3 *ast.Term) (*ast.Term, error) {
+		var owner, repo, sha string
+
+		if err := ast.As(op1.Value, &owner); err != nil {
+			return nil, err
+		} else if err := ast.As(op2.Value, &repo); err != nil {
+			return nil, err
+		} else if err := ast.As(op3.Value, &sha); err != nil {
+			return nil, err
+		}
+
+		var resp commitResponse
+
+		status, err := githubGet(bctx.Context, client, repoPath(owner, repo, "commits", sha), &resp)
+		if err != nil {
+			return nil, err
+		}
+
+		switch status {
+		case http.StatusOK:
+		case http.StatusNotFound, http.StatusUnprocessableEntity, http.StatusConflict:
+			// missing commits, invalid SHAs and empty repositories
+			return nil, nil
+		default:
+			return nil, fmt.Errorf("get commit: unexpected status %d", status)
+		}
+
+		val, err := ast.InterfaceToValue(normalizeCommit(resp))
+		if err != nil {
+			return nil, err
+		}
+
+		return ast.NewTerm(val), nil
+	}
+}
+
+// GitHubCommitsBuiltinImpl fetches the most recent commits of a
+// repository, newest first, and returns them normalized like
+// github.commit. The options filter them (sha, path, author,
+// committer, since and until, like the commits API) and limit
+// their number (100 by default). Empty repositories have an empty
+// list. Returns undefined if the repository doesn't exist.
+func GitHubCommitsBuiltinImpl(client *http.Client) func(bctx rego.BuiltinContext, op1, op2, op3 *ast.Term) (*ast.Term, error) {
+	return func(bctx rego.BuiltinContext, op1, op2, op3 *ast.Term) (*ast.Term, error) {
+		var (
+			owner, repo string
+			filters     map[string]interface{}
+			opts        commitsOptions
+		)
+
+		if err := ast.As(op1.Value, &owner); err != nil {
+			return nil, err
+		} else if err := ast.As(op2.Value, &repo); err != nil {
+			return nil, err
+		} else if err := ast.As(op3.Value, &filters); err != nil {
+			return nil, err
+		} else if err := ast.As(op3.Value, &opts); err != nil {
+			return nil, err
+		}
+
+		query, err := filterQuery(filters, commitsFilters)
+		if err != nil {
+			return nil, err
+		}
+
+		if opts.Limit < 0 {
+			return nil, fmt.Errorf("invalid limit option: must be a positive integer")
+		} else if opts.Limit == 0 {
+			opts.Limit = defaultCommitsLimit
+		}
+
+		items, status, err := githubGetPages(bctx.Context, client, withQuery(repoPath(owner, repo, "commits"), query), "", opts.Limit)
+		if err != nil {
+			return nil, err
+		}
+
+		switch status {
+		case http.StatusOK:
+		case http.StatusConflict:
+			// the repository is empty
+			items = []interface{}{}
+		case http.StatusNotFound:
+			return nil, nil
+		default:
+			return nil, fmt.Errorf("get commits: unexpected status %d", status)
+		}
+
+		var resp []commitResponse
+
+		if err := decodeItems(items, &resp); err != nil {
+			return nil, err
+		}
+
+		commits := make([]Commit, 0, len(resp))
+		for _, r := range resp {
+			commits = append(commits, normalizeCommit(r))
+		}
+
+		val, err := ast.InterfaceToValue(commits)
+		if err != nil {
+			return nil, err
+		}
+
+		return ast.NewTerm(val), nil
+	}
+}
+
+func normalizeCommit(resp commitResponse) Commit {
+	commit := Commit{
+		SHA:     resp.SHA,
+		Message: resp.Commit.Message,
+		Parents: make([]string, 0, len(resp.Parents)),
+		Verification: CommitVerification{
+			Reason: "unsigned",
+		},
+	}
+
+	if a := resp.Commit.Author; a != nil {
+		commit.Author = CommitActor{Name: a.Name, Email: a.Email, Date: a.Date}
+	}
+
+	if c := resp.Commit.Committer; c != nil {
+		commit.Committer = CommitActor{Name: c.Name, Email: c.Email, Date: c.Date}
+	}
+
+	if resp.Author != nil {
+		commit.Author.Login = resp.Author.Login
+	}
+
+	if resp.Committer != nil {
+		commit.Committer.Login = resp.Committer.Login
+	}
+
+	for _, p := range resp.Parents {
+		commit.Parents = append(commit.Parents, p.SHA)
+	}
+
+	if v := resp.Commit.Verification; v != nil {
+		commit.Verification = CommitVerification{
+			Verified:  v.Verified,
+			Reason:    v.Reason,
+			Signature: v.Signature,
+		}
+	}
+
+	return commit
+}
@@ -0,0 +1,131 @@
+package builtins_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/reposaur/reposaur/internal/builtins"
+)
+
+const testVerifiedCommit = `{
+	"sha": "abc",
+	"commit": {
+		"message": "Add policies",
+		"author": {"name": "Octocat", "email": "octocat@github.com", "date": "2022-06-01T00:00:00Z"},
+		"committer": {"name": "GitHub", "email": "noreply@github.com", "date": "2022-06-01T00:00:00Z"},
+		"verification": {"verified": true, "reason": "valid", "signature": "-----BEGIN PGP SIGNATURE-----"}
+	},
+	"author": {"login": "octocat"},
+	"committer": {"login": "web-flow"},
+	"parents": [{"sha": "def"}]
+}`
+
+const testUnsignedCommit = `{
+	"sha": "def",
+	"commit": {
+		"message": "Initial commit",
+		"author": {"name": "Someone", "email": "someone@example.com", "date": "2022-05-01T00:00:00Z"},
+		"committer": {"name": "Someone", "email": "someone@example.com", "date": "2022-05-01T00:00:00Z"},
+		"verification": {"verified": false, "reason": "unsigned", "signature": null}
+	},
+	"author": null,
+	"committer": null,
+	"parents": []
+}`
+
+func newCommitsStubClient(t *testing.T) *http.Client {
+	return newStubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/reposaur/reposaur/commits/abc":
+			_, _ = w.Write([]byte(testVerifiedCommit))
+
+		case "/repos/reposaur/reposaur/commits/def":
+			_, _ = w.Write([]byte(testUnsignedCommit))
+
+		case "/repos/reposaur/reposaur/commits":
+			if r.URL.Query().Get("sha") != "main" {
+				t.Errorf("expected the sha filter, got %s", r.URL.RawQuery)
+			}
+
+			_, _ = w.Write([]byte("[" + testVerifiedCommit + "," + testUnsignedCommit + "]"))
+
+		case "/repos/reposaur/empty/commits":
+			w.WriteHeader(http.StatusConflict)
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestGitHubCommit(t *testing.T) {
+	impl := builtins.GitHubCommitBuiltinImpl(newCommitsStubClient(t))
+
+	cases := []struct {
+		sha      string
+		verified bool
+		reason   string
+		login    string
+	}{
+		{"abc", true, "valid", "octocat"},
+		{"def", false, "unsigned", ""},
+	}
+
+	for _, c := range cases {
+		term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm("reposaur"), ast.StringTerm(c.sha))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var commit builtins.Commit
+		if err := ast.As(term.Value, &commit); err != nil {
+			t.Fatal(err)
+		}
+
+		if commit.Verification.Verified != c.verified || commit.Verification.Reason != c.reason {
+			t.Errorf("%s: unexpected verification %+v", c.sha, commit.Verification)
+		}
+
+		if commit.Author.Login != c.login {
+			t.Errorf("%s: expected author login '%s', got '%s'", c.sha, c.login, commit.Author.Login)
+		}
+	}
+
+	term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm("reposaur"), ast.StringTerm("missing"))
+	if err != nil {
+		t.Fatal(err)
+	} else if term != nil {
+		t.Errorf("expected undefined, got %v", term)
+	}
+}
+
+func TestGitHubCommits(t *testing.T) {
+	impl := builtins.GitHubCommitsBuiltinImpl(newCommitsStubClient(t))
+
+	term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm("reposaur"), objectTerm(t, map[string]interface{}{"sha": "main", "limit": 10}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var commits []builtins.Commit
+	if err := ast.As(term.Value, &commits); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(commits) != 2 || !commits[0].Verification.Verified || commits[1].Verification.Verified {
+		t.Errorf("unexpected commits: %+v", commits)
+	}
+
+	if len(commits[0].Parents) != 1 || commits[0].Parents[0] != "def" {
+		t.Errorf("expected the parents of the first commit, got %v", commits[0].Parents)
+	}
+
+	term, err = impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm("empty"), objectTerm(t, nil))
+	if err != nil {
+		t.Fatal(err)
+	} else if term == nil || term.Value.Compare(ast.NewArray()) != 0 {
+		t.Errorf("expected an empty list, got %v", term)
+	}
+}
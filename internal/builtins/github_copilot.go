@@ -0,0 +1,141 @@
+package builtins
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/types"
+)
+
+var GitHubCopilotBuiltin = rego.Function{
+	Name: "github.copilot",
+	Decl: types.NewFunction(
+		types.Args(types.S),
+		types.NewObject(nil, types.NewDynamicProperty(types.S, types.A)),
+	),
+	Memoize: true,
+}
+
+// Copilot is a normalized view of the GitHub Copilot settings of an
+// organization. SeatManagement is one of "assign_all", "assign_selected",
+// "disabled" and "unconfigured", and Copilot is enabled when seats are
+// assigned to all or selected members. PublicCodeSuggestions is one of
+// "allow", "block" and "unconfigured". The chat and CLI features are
+// only true when they're explicitly enabled.
+type Copilot struct {
+	Enabled               bool         `json:"enabled"`
+	PlanType              string       `json:"plan_type"`
+	SeatManagement        string       `json:"seat_management"`
+	Seats                 CopilotSeats `json:"seats"`
+	IDEChat               bool         `json:"ide_chat"`
+	PlatformChat          bool         `json:"platform_chat"`
+	CLI                   bool         `json:"cli"`
+	PublicCodeSuggestions string       `json:"public_code_suggestions"`
+}
+
+// CopilotSeats is the breakdown of the Copilot
+// seats of an organization in the billing cycle.
+type CopilotSeats struct {
+	Total               int `json:"total"`
+	Active              int `json:"active"`
+	Inactive            int `json:"inactive"`
+	AddedThisCycle      int `json:"added_this_cycle"`
+	PendingInvitation   int `json:"pending_invitation"`
+	PendingCancellation int `json:"pending_cancellation"`
+}
+
+type copilotResponse struct {
+	SeatBreakdown struct {
+		Total               int `json:"total"`
+		AddedThisCycle      int `json:"added_this_cycle"`
+		PendingInvitation   int `json:"pending_invitation"`
+		PendingCancellation int `json:"pending_cancellation"`
+		ActiveThisCycle     int `json:"active_this_cycle"`
+		InactiveThisCycle   int `json:"inactive_this_cycle"`
+	} `json:"seat_breakdown"`
+	SeatManagementSetting string `json:"seat_management_setting"`
+	IDEChat               string `json:"ide_chat"`
+	PlatformChat          string `json:"platform_chat"`
+	CLI                   string `json:"cli"`
+	PublicCodeSuggestions string `json:"public_code_suggestions"`
+	PlanType              string `json:"plan_type"`
+}
+
+// GitHubCopilotBuiltinImpl fetches the Copilot seat and policy settings
+// of an organization. Organizations without a Copilot subscription, for
+// which GitHub doesn't have the settings, have Copilot disabled. Reading
+// them requires owner credentials, so the result is undefined when the
+// request is unauthorized or forbidden.
+func GitHubCopilotBuiltinImpl(client *http.Client) func(bctx rego.BuiltinContext, op1 *ast.Term) (*ast.Term, error) {
+	return func(bctx rego.BuiltinContext, op1 *ast.Term) (*ast.Term, error) {
+		var org string
+
+		if err := ast.As(op1.Value, &org); err != nil {
+			return nil, err
+		}
+
+		var resp copilotResponse
+
+		status, err := githubGet(bctx.Context, client, "/orgs/"+url.PathEscape(org)+"/copilot/billing", &resp)
+		if err != nil {
+			return nil, err
+		}
+
+		var copilot Copilot
+
+		switch status {
+		case http.StatusOK:
+			copilot = newCopilot(resp)
+		case http.StatusNotFound, http.StatusUnprocessableEntity:
+			copilot = newCopilot(copilotResponse{SeatManagementSetting: "disabled"})
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return nil, nil
+		default:
+			return nil, fmt.Errorf("get copilot: unexpected status %d", status)
+		}
+
+		val, err := ast.InterfaceToValue(copilot)
+		if err != nil {
+			return nil, err
+		}
+
+		return ast.NewTerm(val), nil
+	}
+}
+
+// newCopilot normalizes the Copilot settings of an organization.
+func newCopilot(resp copilotResponse) Copilot {
+	seatManagement := copilotSetting(resp.SeatManagementSetting)
+
+	return Copilot{
+		Enabled:        seatManagement == "assign_all" || seatManagement == "assign_selected",
+		PlanType:       strings.ToLower(resp.PlanType),
+		SeatManagement: seatManagement,
+		Seats: CopilotSeats{
+			Total:               resp.SeatBreakdown.Total,
+			Active:              resp.SeatBreakdown.ActiveThisCycle,
+			Inactive:            resp.SeatBreakdown.InactiveThisCycle,
+			AddedThisCycle:      resp.SeatBreakdown.AddedThisCycle,
+			PendingInvitation:   resp.SeatBreakdown.PendingInvitation,
+			PendingCancellation: resp.SeatBreakdown.PendingCancellation,
+		},
+		IDEChat:               copilotSetting(resp.IDEChat) == "enabled",
+		PlatformChat:          copilotSetting(resp.PlatformChat) == "enabled",
+		CLI:                   copilotSetting(resp.CLI) == "enabled",
+		PublicCodeSuggestions: copilotSetting(resp.PublicCodeSuggestions),
+	}
+}
+
+// copilotSetting returns the lowercase value of a
+// setting, or "unconfigured" if it isn't set.
+func copilotSetting(s string) string {
+	if s == "" {
+		return "unconfigured"
+	}
+
+	return strings.ToLower(s)
+}
@@ -0,0 +1,118 @@
+package builtins_test
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/reposaur/reposaur/internal/builtins"
+)
+
+const testCopilotBilling = `{
+	"seat_breakdown": {
+		"total": 12,
+		"added_this_cycle": 9,
+		"pending_invitation": 1,
+		"pending_cancellation": 2,
+		"active_this_cycle": 10,
+		"inactive_this_cycle": 2
+	},
+	"seat_management_setting": "assign_selected",
+	"ide_chat": "enabled",
+	"platform_chat": "disabled",
+	"public_code_suggestions": "block",
+	"plan_type": "Business"
+}`
+
+func newCopilotStubClient(t *testing.T) *http.Client {
+	return newStubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/orgs/reposaur/copilot/billing":
+			_, _ = w.Write([]byte(testCopilotBilling))
+
+		case "/orgs/forbidden/copilot/billing":
+			w.WriteHeader(http.StatusForbidden)
+
+		case "/orgs/unauthorized/copilot/billing":
+			w.WriteHeader(http.StatusUnauthorized)
+
+		case "/orgs/broken/copilot/billing":
+			w.WriteHeader(http.StatusInternalServerError)
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestGitHubCopilot(t *testing.T) {
+	impl := builtins.GitHubCopilotBuiltinImpl(newCopilotStubClient(t))
+
+	term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var copilot builtins.Copilot
+	if err := ast.As(term.Value, &copilot); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := builtins.Copilot{
+		Enabled:        true,
+		PlanType:       "business",
+		SeatManagement: "assign_selected",
+		Seats: builtins.CopilotSeats{
+			Total:               12,
+			Active:              10,
+			Inactive:            2,
+			AddedThisCycle:      9,
+			PendingInvitation:   1,
+			PendingCancellation: 2,
+		},
+		IDEChat:               true,
+		PublicCodeSuggestions: "block",
+	}
+
+	if !reflect.DeepEqual(copilot, expected) {
+		t.Errorf("expected %+v, got %+v", expected, copilot)
+	}
+}
+
+func TestGitHubCopilotWithoutSubscription(t *testing.T) {
+	impl := builtins.GitHubCopilotBuiltinImpl(newCopilotStubClient(t))
+
+	term, err := impl(rego.BuiltinContext{}, ast.StringTerm("missing"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var copilot builtins.Copilot
+	if err := ast.As(term.Value, &copilot); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := builtins.Copilot{SeatManagement: "disabled", PublicCodeSuggestions: "unconfigured"}
+
+	if !reflect.DeepEqual(copilot, expected) {
+		t.Errorf("expected %+v, got %+v", expected, copilot)
+	}
+}
+
+func TestGitHubCopilotErrors(t *testing.T) {
+	impl := builtins.GitHubCopilotBuiltinImpl(newCopilotStubClient(t))
+
+	for _, org := range []string{"forbidden", "unauthorized"} {
+		if term, err := impl(rego.BuiltinContext{}, ast.StringTerm(org)); err != nil {
+			t.Fatal(err)
+		} else if term != nil {
+			t.Errorf("%s: expected undefined, got %v", org, term)
+		}
+	}
+
+	if _, err := impl(rego.BuiltinContext{}, ast.StringTerm("broken")); err == nil {
+		t.Error("expected an unexpected status to fail")
+	}
+}
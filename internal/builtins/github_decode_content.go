@@ -0,0 +1,47 @@
+package builtins
+
+import (
+	"fmt"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/types"
+)
+
+// GitHubDecodeContentBuiltin complements OPA's base64.decode, which
+// fails on the line breaks GitHub wraps Base64 content with.
+var GitHubDecodeContentBuiltin = rego.Function{
+	Name: "github.decode_content",
+	Decl: types.NewFunction(
+		types.Args(types.NewObject(nil, types.NewDynamicProperty(types.S, types.A))),
+		types.S,
+	),
+}
+
+// GitHubDecodeContentBuiltinImpl decodes the content of a file returned
+// by the contents API (e.g. the body of a `github.request` response),
+// which is Base64-encoded with line breaks. Returns undefined if the
+// object doesn't have content, e.g. it's a directory or a file too large
+// to be returned inline (encoding "none"), and halts policy execution
+// if the content isn't valid Base64.
+func GitHubDecodeContentBuiltinImpl(bctx rego.BuiltinContext, op1 *ast.Term) (*ast.Term, error) {
+	var file struct {
+		Content  *string `json:"content"`
+		Encoding string  `json:"encoding"`
+	}
+
+	if err := ast.As(op1.Value, &file); err != nil {
+		return nil, err
+	}
+
+	if file.Content == nil || (file.Encoding != "" && file.Encoding != "base64") {
+		return nil, nil
+	}
+
+	content, err := decodeContent(*file.Content)
+	if err != nil {
+		return nil, fmt.Errorf("decode content: %w", err)
+	}
+
+	return ast.StringTerm(string(content)), nil
+}
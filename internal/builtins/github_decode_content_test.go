@@ -0,0 +1,96 @@
+package builtins_test
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/reposaur/reposaur/internal/builtins"
+)
+
+const testContentBlob = `# Default owners
+*       @reposaur/maintainers
+
+/docs/  @reposaur/docs
+*.go    @reposaur/go-reviewers
+`
+
+// githubBase64 encodes content like the contents API,
+// wrapping the Base64 at 60 characters per line.
+func githubBase64(content string) string {
+	encoded := base64.StdEncoding.EncodeToString([]byte(content))
+
+	var lines []string
+	for len(encoded) > 60 {
+		lines = append(lines, encoded[:60])
+		encoded = encoded[60:]
+	}
+
+	return strings.Join(append(lines, encoded), "\n") + "\n"
+}
+
+func TestGitHubDecodeContent(t *testing.T) {
+	file := objectTerm(t, map[string]interface{}{
+		"type":     "file",
+		"encoding": "base64",
+		"size":     len(testContentBlob),
+		"name":     "CODEOWNERS",
+		"path":     ".github/CODEOWNERS",
+		"content":  githubBase64(testContentBlob),
+		"sha":      "3d21ec53a331a6f037a91c368710b99387d012c1",
+	})
+
+	term, err := builtins.GitHubDecodeContentBuiltinImpl(rego.BuiltinContext{}, file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !term.Equal(ast.StringTerm(testContentBlob)) {
+		t.Errorf("expected the decoded content, got %v", term)
+	}
+
+	crlf := objectTerm(t, map[string]interface{}{
+		"content": strings.ReplaceAll(githubBase64(testContentBlob), "\n", "\r\n"),
+	})
+
+	if term, err := builtins.GitHubDecodeContentBuiltinImpl(rego.BuiltinContext{}, crlf); err != nil {
+		t.Fatal(err)
+	} else if !term.Equal(ast.StringTerm(testContentBlob)) {
+		t.Errorf("expected the decoded content without an encoding, got %v", term)
+	}
+
+	empty := objectTerm(t, map[string]interface{}{"encoding": "base64", "content": ""})
+
+	if term, err := builtins.GitHubDecodeContentBuiltinImpl(rego.BuiltinContext{}, empty); err != nil {
+		t.Fatal(err)
+	} else if !term.Equal(ast.StringTerm("")) {
+		t.Errorf("expected an empty file to be empty, got %v", term)
+	}
+}
+
+func TestGitHubDecodeContentUndefined(t *testing.T) {
+	cases := map[string]map[string]interface{}{
+		"directory": {"type": "dir", "name": "docs", "path": "docs"},
+		"too large": {"type": "file", "encoding": "none", "content": "", "size": 2 << 20},
+		"response":  {"statusCode": 404, "body": map[string]interface{}{"message": "Not Found"}},
+	}
+
+	for name, obj := range cases {
+		term, err := builtins.GitHubDecodeContentBuiltinImpl(rego.BuiltinContext{}, objectTerm(t, obj))
+		if err != nil {
+			t.Fatal(err)
+		} else if term != nil {
+			t.Errorf("%s: expected undefined, got %v", name, term)
+		}
+	}
+}
+
+func TestGitHubDecodeContentInvalid(t *testing.T) {
+	file := objectTerm(t, map[string]interface{}{"encoding": "base64", "content": "not base64!\n"})
+
+	if _, err := builtins.GitHubDecodeContentBuiltinImpl(rego.BuiltinContext{}, file); err == nil {
+		t.Error("expected an error for invalid Base64")
+	}
+}
@@ -0,0 +1,110 @@
+package builtins
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/types"
+)
+
+// dependabotAlertFilters are the options of github.dependabot_alerts
+// that are passed to the API to filter alerts. Each accepts a string
+// or an array of strings.
+var dependabotAlertFilters = []string{"state", "severity", "ecosystem", "package", "scope"}
+
+var GitHubDependabotAlertsBuiltin = rego.Function{
+	Name: "github.dependabot_alerts",
+	Decl: types.NewFunction(
+		types.Args(
+			types.S,
+			types.S,
+			types.NewObject(nil, types.NewDynamicProperty(types.S, types.A)),
+		),
+		types.NewObject(nil, types.NewDynamicProperty(types.S, types.A)),
+	),
+	Memoize: true,
+}
+
+// GitHubDependabotAlertsBuiltinImpl fetches the Dependabot alerts of
+// a repository, filtered by the options, and returns whether alerts
+// are enabled and the alerts. Returns undefined if the repository
+// doesn't exist.
+func GitHubDependabotAlertsBuiltinImpl(client *http.Client) func(bctx rego.BuiltinContext, op1, op2, op3 *ast.Term) (*ast.Term, error) {
+	return func(bctx rego.BuiltinContext, op1, op2, op3 *ast.Term) (*ast.Term, error) {
+		var (
+			owner, repo string
+			opts        map[string]interface{}
+		)
+
+		if err := ast.As(op1.Value, &owner); err != nil {
+			return nil, err
+		} else if err := ast.As(op2.Value, &repo); err != nil {
+			return nil, err
+		} else if err := ast.As(op3.Value, &opts); err != nil {
+			return nil, err
+		}
+
+		query, err := filterQuery(opts, dependabotAlertFilters)
+		if err != nil {
+			return nil, err
+		}
+
+		path := withQuery(repoPath(owner, repo, "dependabot", "alerts"), query)
+
+		alerts, status, err := githubGetPages(bctx.Context, client, path, "", 0)
+		if err != nil {
+			return nil, err
+		}
+
+		result := map[string]interface{}{
+			"enabled": true,
+			"alerts":  alerts,
+		}
+
+		switch status {
+		case http.StatusOK:
+		case http.StatusNotFound:
+			return nil, nil
+		case http.StatusForbidden:
+			enabled, err := dependabotAlertsEnabled(bctx, client, owner, repo)
+			if err != nil {
+				return nil, err
+			} else if enabled {
+				return nil, fmt.Errorf("get dependabot alerts: forbidden")
+			}
+
+			result["enabled"] = false
+			result["alerts"] = []interface{}{}
+		default:
+			return nil, fmt.Errorf("get dependabot alerts: unexpected status %d", status)
+		}
+
+		val, err := ast.InterfaceToValue(result)
+		if err != nil {
+			return nil, err
+		}
+
+		return ast.NewTerm(val), nil
+	}
+}
+
+// dependabotAlertsEnabled checks if vulnerability alerts are enabled
+// in a repository. The alerts API is forbidden both when they're
+// disabled and when the credentials can't access them.
+func dependabotAlertsEnabled(bctx rego.BuiltinContext, client *http.Client, owner, repo string) (bool, error) {
+	status, err := githubGet(bctx.Context, client, repoPath(owner, repo, "vulnerability-alerts"), nil)
+	if err != nil {
+		return false, err
+	}
+
+	switch status {
+	case http.StatusNoContent:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, fmt.Errorf("get vulnerability alerts status: unexpected status %d", status)
+	}
+}
@@ -0,0 +1,123 @@
+package builtins_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/reposaur/reposaur/internal/builtins"
+)
+
+// dependabotAlertsHandler serves 3 alerts for reposaur/test in
+// pages of 2, using cursor pagination like the alerts API.
+func dependabotAlertsHandler(t *testing.T, queries *[]string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/reposaur/test/dependabot/alerts" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		*queries = append(*queries, r.URL.RawQuery)
+
+		alerts := []map[string]interface{}{
+			{"number": 1, "state": "open", "security_advisory": map[string]interface{}{"severity": "critical"}},
+			{"number": 2, "state": "open", "security_advisory": map[string]interface{}{"severity": "critical"}},
+		}
+
+		if r.URL.Query().Get("after") == "" {
+			w.Header().Set("Link", fmt.Sprintf(`<%s&after=cursor>; rel="next"`, r.URL.RequestURI()))
+		} else {
+			alerts = []map[string]interface{}{
+				{"number": 3, "state": "open", "security_advisory": map[string]interface{}{"severity": "high"}},
+			}
+		}
+
+		if err := json.NewEncoder(w).Encode(alerts); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func TestGitHubDependabotAlerts(t *testing.T) {
+	var queries []string
+
+	impl := builtins.GitHubDependabotAlertsBuiltinImpl(newStubClient(t, dependabotAlertsHandler(t, &queries)))
+	opts := objectTerm(t, map[string]interface{}{
+		"state":    "open",
+		"severity": []interface{}{"critical", "high"},
+	})
+
+	term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm("test"), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var result struct {
+		Enabled bool                     `json:"enabled"`
+		Alerts  []map[string]interface{} `json:"alerts"`
+	}
+
+	if err := ast.As(term.Value, &result); err != nil {
+		t.Fatal(err)
+	}
+
+	if !result.Enabled {
+		t.Error("expected alerts to be enabled")
+	}
+
+	if len(result.Alerts) != 3 {
+		t.Fatalf("expected 3 alerts, got %d", len(result.Alerts))
+	}
+
+	if len(queries) != 2 {
+		t.Fatalf("expected 2 pages to be requested, got %d", len(queries))
+	}
+
+	if expected := "severity=critical%2Chigh&state=open&per_page=100"; queries[0] != expected {
+		t.Errorf("expected query to be %s, got %s", expected, queries[0])
+	}
+}
+
+func TestGitHubDependabotAlertsDisabled(t *testing.T) {
+	impl := builtins.GitHubDependabotAlertsBuiltinImpl(newStubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/reposaur/test/dependabot/alerts":
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"message": "Dependabot alerts are disabled for this repository."}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})))
+
+	term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm("test"), objectTerm(t, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := objectTerm(t, map[string]interface{}{
+		"enabled": false,
+		"alerts":  []interface{}{},
+	})
+
+	if term.Value.Compare(expected.Value) != 0 {
+		t.Errorf("expected %v, got %v", expected, term)
+	}
+}
+
+func TestGitHubDependabotAlertsForbidden(t *testing.T) {
+	impl := builtins.GitHubDependabotAlertsBuiltinImpl(newStubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/reposaur/test/vulnerability-alerts":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusForbidden)
+		}
+	})))
+
+	if _, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm("test"), objectTerm(t, nil)); err == nil {
+		t.Error("expected error when alerts are enabled but forbidden")
+	}
+}
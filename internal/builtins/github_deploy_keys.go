@@ -0,0 +1,115 @@
+package builtins
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/types"
+)
+
+var GitHubDeployKeysBuiltin = rego.Function{
+	Name: "github.deploy_keys",
+	Decl: types.NewFunction(
+		types.Args(types.S, types.S),
+		types.NewArray(nil, types.NewObject(nil, types.NewDynamicProperty(types.S, types.A))),
+	),
+	Memoize: true,
+}
+
+// DeployKey is a normalized view of a deploy key of a repository.
+type DeployKey struct {
+	ID    int    `json:"id"`
+	Title string `json:"title"`
+	Key   string `json:"key"`
+
+	// ReadOnly is false for keys that can push to the repository.
+	ReadOnly bool `json:"read_only"`
+	Verified bool `json:"verified"`
+
+	// CreatedAt and LastUsed are RFC3339 timestamps, LastUsed
+	// is empty if the key was never used (or isn't known).
+	CreatedAt string `json:"created_at"`
+	LastUsed  string `json:"last_used"`
+
+	// AddedBy is the login of the user who added the key.
+	AddedBy string `json:"added_by"`
+}
+
+type deployKeyResponse struct {
+	ID        int     `json:"id"`
+	Title     string  `json:"title"`
+	Key       string  `json:"key"`
+	ReadOnly  bool    `json:"read_only"`
+	Verified  bool    `json:"verified"`
+	CreatedAt string  `json:"created_at"`
+	LastUsed  *string `json:"last_used"`
+	AddedBy   *string `json:"added_by"`
+}
+
+// GitHubDeployKeysBuiltinImpl fetches the deploy keys of a repository
+// and returns them normalized. Repositories without deploy keys have
+// an empty list. Listing them requires admin access to the repository,
+// so the result is undefined when the request is forbidden or the
+// repository doesn't exist.
+func GitHubDeployKeysBuiltinImpl(client *http.Client) func(bctx rego.BuiltinContext, op1, op2 *ast.Term) (*ast.Term, error) {
+	return func(bctx rego.BuiltinContext, op1, op2 *ast.Term) (*ast.Term, error) {
+		var owner, repo string
+
+		if err := ast.As(op1.Value, &owner); err != nil {
+			return nil, err
+		} else if err := ast.As(op2.Value, &repo); err != nil {
+			return nil, err
+		}
+
+		items, status, err := githubGetPages(bctx.Context, client, repoPath(owner, repo, "keys"), "", 0)
+		if err != nil {
+			return nil, err
+		}
+
+		switch status {
+		case http.StatusOK:
+		case http.StatusForbidden, http.StatusNotFound:
+			return nil, nil
+		default:
+			return nil, fmt.Errorf("get deploy keys: unexpected status %d", status)
+		}
+
+		var resp []deployKeyResponse
+
+		if err := decodeItems(items, &resp); err != nil {
+			return nil, err
+		}
+
+		keys := make([]DeployKey, 0, len(resp))
+
+		for _, r := range resp {
+			key := DeployKey{
+				ID:        r.ID,
+				Title:     r.Title,
+				Key:       r.Key,
+				ReadOnly:  r.ReadOnly,
+				Verified:  r.Verified,
+				CreatedAt: r.CreatedAt,
+			}
+
+			if r.LastUsed != nil {
+				key.LastUsed = *r.LastUsed
+			}
+
+			if r.AddedBy != nil {
+				key.AddedBy = *r.AddedBy
+			}
+
+			keys = append(keys, key)
+		}
+
+		val, err := ast.InterfaceToValue(keys)
+		if err != nil {
+			return nil, err
+		}
+
+		return ast.NewTerm(val), nil
+	}
+}
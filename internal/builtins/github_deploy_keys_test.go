@@ -0,0 +1,108 @@
+package builtins_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/reposaur/reposaur/internal/builtins"
+)
+
+const testDeployKeys = `[
+	{
+		"id": 1,
+		"key": "ssh-ed25519 AAAA",
+		"title": "deploy",
+		"verified": true,
+		"created_at": "2022-01-01T00:00:00Z",
+		"read_only": false,
+		"added_by": "octocat",
+		"last_used": "2022-06-01T00:00:00Z"
+	},
+	{
+		"id": 2,
+		"key": "ssh-rsa AAAA",
+		"title": "mirror",
+		"verified": true,
+		"created_at": "2022-02-01T00:00:00Z",
+		"read_only": true
+	}
+]`
+
+func newDeployKeysStubClient(t *testing.T) *http.Client {
+	return newStubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/reposaur/reposaur/keys":
+			_, _ = w.Write([]byte(testDeployKeys))
+
+		case "/repos/reposaur/empty/keys":
+			_, _ = w.Write([]byte(`[]`))
+
+		case "/repos/reposaur/forbidden/keys":
+			w.WriteHeader(http.StatusForbidden)
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestGitHubDeployKeys(t *testing.T) {
+	impl := builtins.GitHubDeployKeysBuiltinImpl(newDeployKeysStubClient(t))
+
+	term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm("reposaur"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var keys []builtins.DeployKey
+	if err := ast.As(term.Value, &keys); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(keys) != 2 {
+		t.Fatalf("expected 2 deploy keys, got %d", len(keys))
+	}
+
+	expected := builtins.DeployKey{
+		ID:        1,
+		Title:     "deploy",
+		Key:       "ssh-ed25519 AAAA",
+		ReadOnly:  false,
+		Verified:  true,
+		CreatedAt: "2022-01-01T00:00:00Z",
+		LastUsed:  "2022-06-01T00:00:00Z",
+		AddedBy:   "octocat",
+	}
+
+	if keys[0] != expected {
+		t.Errorf("expected %+v, got %+v", expected, keys[0])
+	}
+
+	if !keys[1].ReadOnly || keys[1].LastUsed != "" || keys[1].AddedBy != "" {
+		t.Errorf("expected a read-only key without usage, got %+v", keys[1])
+	}
+}
+
+func TestGitHubDeployKeysEmpty(t *testing.T) {
+	impl := builtins.GitHubDeployKeysBuiltinImpl(newDeployKeysStubClient(t))
+
+	term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm("empty"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !term.Equal(ast.ArrayTerm()) {
+		t.Errorf("expected an empty list, got %v", term)
+	}
+
+	for _, repo := range []string{"forbidden", "missing"} {
+		term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm(repo))
+		if err != nil {
+			t.Fatal(err)
+		} else if term != nil {
+			t.Errorf("%s: expected undefined, got %v", repo, term)
+		}
+	}
+}
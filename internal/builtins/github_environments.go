@@ -0,0 +1,166 @@
+package builtins
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/types"
+)
+
+// Branches that can deploy to an environment.
+const (
+	DeploymentBranchesAll       = "all"
+	DeploymentBranchesProtected = "protected"
+	DeploymentBranchesCustom    = "custom"
+)
+
+var GitHubEnvironmentsBuiltin = rego.Function{
+	Name: "github.environments",
+	Decl: types.NewFunction(
+		types.Args(types.S, types.S),
+		types.NewArray(nil, types.NewObject(nil, types.NewDynamicProperty(types.S, types.A))),
+	),
+	Memoize: true,
+}
+
+// Environment is a normalized view of a deployment
+// environment and its protection rules.
+type Environment struct {
+	Name              string                `json:"name"`
+	RequiredReviewers []EnvironmentReviewer `json:"required_reviewers"`
+	PreventSelfReview bool                  `json:"prevent_self_review"`
+	WaitTimer         int                   `json:"wait_timer"`
+
+	// DeploymentBranches is which branches can deploy
+	// to the environment: all, protected or custom.
+	DeploymentBranches string `json:"deployment_branches"`
+}
+
+// EnvironmentReviewer is a user or team
+// required to review deployments.
+type EnvironmentReviewer struct {
+	// Type is either User or Team and Login
+	// is the user's login or the team's slug.
+	Type  string `json:"type"`
+	Login string `json:"login"`
+}
+
+// environmentResponse is the subset of the environments
+// API response that is normalized.
+type environmentResponse struct {
+	Name            string `json:"name"`
+	ProtectionRules []struct {
+		Type              string `json:"type"`
+		WaitTimer         int    `json:"wait_timer"`
+		PreventSelfReview bool   `json:"prevent_self_review"`
+		Reviewers         []struct {
+			Type     string `json:"type"`
+			Reviewer struct {
+				Login string `json:"login"`
+				Slug  string `json:"slug"`
+			} `json:"reviewer"`
+		} `json:"reviewers"`
+	} `json:"protection_rules"`
+	DeploymentBranchPolicy *struct {
+		ProtectedBranches    bool `json:"protected_branches"`
+		CustomBranchPolicies bool `json:"custom_branch_policies"`
+	} `json:"deployment_branch_policy"`
+}
+
+// GitHubEnvironmentsBuiltinImpl fetches the deployment environments
+// of a repository and returns them with their protection rules
+// normalized. Repositories without environments have an empty list.
+// Returns undefined if the repository doesn't exist.
+func GitHubEnvironmentsBuiltinImpl(client *http.Client) func(bctx rego.BuiltinContext, op1, op2 *ast.Term) (*ast.Term, error) {
+	return func(bctx rego.BuiltinContext, op1, op2 *ast.Term) (*ast.Term, error) {
+		var owner, repo string
+
+		if err := ast.As(op1.Value, &owner); err != nil {
+			return nil, err
+		} else if err := ast.As(op2.Value, &repo); err != nil {
+			return nil, err
+		}
+
+		items, status, err := githubGetPages(bctx.Context, client, repoPath(owner, repo, "environments"), "environments", 0)
+		if err != nil {
+			return nil, err
+		}
+
+		switch status {
+		case http.StatusOK:
+		case http.StatusNotFound:
+			// environments aren't available in
+			// private repositories of some plans
+			exists, err := repositoryExists(bctx, client, owner, repo)
+			if err != nil {
+				return nil, err
+			} else if !exists {
+				return nil, nil
+			}
+
+			items = []interface{}{}
+		default:
+			return nil, fmt.Errorf("get environments: unexpected status %d", status)
+		}
+
+		var resp []environmentResponse
+
+		if err := decodeItems(items, &resp); err != nil {
+			return nil, err
+		}
+
+		envs := make([]Environment, 0, len(resp))
+		for _, r := range resp {
+			envs = append(envs, normalizeEnvironment(r))
+		}
+
+		val, err := ast.InterfaceToValue(envs)
+		if err != nil {
+			return nil, err
+		}
+
+		return ast.NewTerm(val), nil
+	}
+}
+
+func normalizeEnvironment(resp environmentResponse) Environment {
+	env := Environment{
+		Name:               resp.Name,
+		RequiredReviewers:  []EnvironmentReviewer{},
+		DeploymentBranches: DeploymentBranchesAll,
+	}
+
+	for _, rule := range resp.ProtectionRules {
+		switch rule.Type {
+		case "wait_timer":
+			env.WaitTimer = rule.WaitTimer
+
+		case "required_reviewers":
+			env.PreventSelfReview = rule.PreventSelfReview
+
+			for _, r := range rule.Reviewers {
+				login := r.Reviewer.Login
+				if r.Type == "Team" {
+					login = r.Reviewer.Slug
+				}
+
+				env.RequiredReviewers = append(env.RequiredReviewers, EnvironmentReviewer{
+					Type:  r.Type,
+					Login: login,
+				})
+			}
+		}
+	}
+
+	if p := resp.DeploymentBranchPolicy; p != nil {
+		if p.ProtectedBranches {
+			env.DeploymentBranches = DeploymentBranchesProtected
+		} else if p.CustomBranchPolicies {
+			env.DeploymentBranches = DeploymentBranchesCustom
+		}
+	}
+
+	return env
+}
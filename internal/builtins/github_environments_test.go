@@ -0,0 +1,127 @@
+package builtins_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/reposaur/reposaur/internal/builtins"
+)
+
+const testEnvironments = `{
+	"total_count": 2,
+	"environments": [
+		{
+			"name": "production",
+			"protection_rules": [
+				{"type": "wait_timer", "wait_timer": 30},
+				{
+					"type": "required_reviewers",
+					"prevent_self_review": true,
+					"reviewers": [
+						{"type": "User", "reviewer": {"login": "octocat"}},
+						{"type": "Team", "reviewer": {"slug": "release", "name": "Release"}}
+					]
+				},
+				{"type": "branch_policy"}
+			],
+			"deployment_branch_policy": {"protected_branches": true, "custom_branch_policies": false}
+		},
+		{
+			"name": "staging",
+			"protection_rules": [],
+			"deployment_branch_policy": null
+		}
+	]
+}`
+
+func newEnvironmentsStubClient(t *testing.T) *http.Client {
+	return newStubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/reposaur/reposaur/environments":
+			_, _ = w.Write([]byte(testEnvironments))
+
+		case "/repos/reposaur/empty/environments":
+			_, _ = w.Write([]byte(`{"total_count": 0}`))
+
+		case "/repos/reposaur/private":
+			_, _ = w.Write([]byte(`{"name": "private"}`))
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestGitHubEnvironments(t *testing.T) {
+	impl := builtins.GitHubEnvironmentsBuiltinImpl(newEnvironmentsStubClient(t))
+
+	term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm("reposaur"))
+	if err != nil {
+		t.Fatal(err)
+	} else if term == nil {
+		t.Fatal("expected environments")
+	}
+
+	var envs []builtins.Environment
+	if err := ast.As(term.Value, &envs); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(envs) != 2 {
+		t.Fatalf("expected 2 environments, got %d", len(envs))
+	}
+
+	prod := envs[0]
+
+	if prod.WaitTimer != 30 || !prod.PreventSelfReview || prod.DeploymentBranches != builtins.DeploymentBranchesProtected {
+		t.Errorf("unexpected production protection rules: %+v", prod)
+	}
+
+	expected := []builtins.EnvironmentReviewer{{Type: "User", Login: "octocat"}, {Type: "Team", Login: "release"}}
+
+	if len(prod.RequiredReviewers) != len(expected) {
+		t.Fatalf("expected %d reviewers, got %v", len(expected), prod.RequiredReviewers)
+	}
+
+	for i, r := range expected {
+		if prod.RequiredReviewers[i] != r {
+			t.Errorf("expected reviewer %d to be %v, got %v", i, r, prod.RequiredReviewers[i])
+		}
+	}
+
+	staging := envs[1]
+
+	if staging.WaitTimer != 0 || len(staging.RequiredReviewers) != 0 || staging.DeploymentBranches != builtins.DeploymentBranchesAll {
+		t.Errorf("expected staging to be unprotected, got %+v", staging)
+	}
+}
+
+func TestGitHubEnvironmentsEmpty(t *testing.T) {
+	impl := builtins.GitHubEnvironmentsBuiltinImpl(newEnvironmentsStubClient(t))
+
+	for _, repo := range []string{"empty", "private"} {
+		term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm(repo))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if term == nil || term.Value.Compare(ast.NewArray()) != 0 {
+			t.Errorf("expected %s to have no environments, got %v", repo, term)
+		}
+	}
+}
+
+func TestGitHubEnvironmentsNotFound(t *testing.T) {
+	impl := builtins.GitHubEnvironmentsBuiltinImpl(newEnvironmentsStubClient(t))
+
+	term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm("missing"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if term != nil {
+		t.Errorf("expected undefined, got %v", term)
+	}
+}
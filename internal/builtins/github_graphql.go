@@ -2,9 +2,12 @@ package builtins
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
+	"unicode"
 
 	"github.com/open-policy-agent/opa/ast"
 	"github.com/open-policy-agent/opa/rego"
@@ -34,33 +37,22 @@ func GitHubGraphQLBuiltinImpl(client *http.Client) func(bctx rego.BuiltinContext
 			return nil, err
 		}
 
-		body := map[string]interface{}{
-			"query":     query,
-			"variables": variables,
+		// queries are sent with POST too, so only
+		// mutations need the method to be allowed
+		if graphqlMutates(query) {
+			if err := checkMethodAllowed(bctx.Context, http.MethodPost); err != nil {
+				return nil, fmt.Errorf("graphql mutation: %w", err)
+			}
 		}
 
-		buf := &bytes.Buffer{}
-		enc := json.NewEncoder(buf)
-		enc.SetEscapeHTML(false)
-		if err := enc.Encode(body); err != nil {
-			return nil, err
-		}
-
-		req, err := http.NewRequest(http.MethodPost, "/graphql", buf)
-		if err != nil {
-			return nil, err
-		}
-
-		req.Header.Set("User-Agent", "reposaur")
-		req.Header.Set("Content-Type", "application/json")
-
 		finalResp := GitHubResponse{}
-		resp, err := client.Do(req)
+		resp, err := githubGraphQL(bctx.Context, client, query, variables)
 		if err != nil {
 			return nil, err
 		}
+		defer resp.Body.Close()
 
-		dec := json.NewDecoder(resp.Body)
+		dec := json.NewDecoder(limitResponse(bctx.Context, resp.Body))
 		if err := dec.Decode(&finalResp.Body); err != nil {
 			return nil, err
 		}
@@ -80,3 +72,87 @@ func GitHubGraphQLBuiltinImpl(client *http.Client) func(bctx rego.BuiltinContext
 		return ast.NewTerm(val), nil
 	}
 }
+
+// graphqlMutates returns true if the GraphQL document query has
+// a mutation or subscription operation, i.e. an operation keyword
+// outside of selection sets, variable definitions and arguments,
+// strings and comments. Operation or fragment names spelled like
+// those keywords are mistaken for them, which errs on the safe side.
+func graphqlMutates(query string) bool {
+	depth := 0
+
+	for i := 0; i < len(query); i++ {
+		switch c := query[i]; {
+		case c == '#':
+			for i < len(query) && query[i] != '\n' && query[i] != '\r' {
+				i++
+			}
+
+		case strings.HasPrefix(query[i:], `"""`):
+			end := strings.Index(query[i+3:], `"""`)
+			if end < 0 {
+				return false
+			}
+
+			i += end + 5
+
+		case c == '"':
+			for i++; i < len(query) && query[i] != '"'; i++ {
+				if query[i] == '\\' {
+					i++
+				}
+			}
+
+		case c == '{' || c == '(' || c == '[':
+			depth++
+
+		case c == '}' || c == ')' || c == ']':
+			depth--
+
+		case c == '_' || unicode.IsLetter(rune(c)):
+			start := i
+			for i+1 < len(query) && (query[i+1] == '_' || unicode.IsLetter(rune(query[i+1])) || unicode.IsDigit(rune(query[i+1]))) {
+				i++
+			}
+
+			if name := query[start : i+1]; depth <= 0 && (name == "mutation" || name == "subscription") {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// graphqlEndpoint is the path of the GitHub GraphQL API.
+const graphqlEndpoint = "/graphql"
+
+// githubGraphQL does a query with variables against
+// the GitHub GraphQL API.
+func githubGraphQL(ctx context.Context, client *http.Client, query string, variables map[string]interface{}) (*http.Response, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	body := map[string]interface{}{
+		"query":     query,
+		"variables": variables,
+	}
+
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(body); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, graphqlEndpoint, buf)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("User-Agent", "reposaur")
+	req.Header.Set("Content-Type", "application/json")
+
+	return contextClient(ctx, client).Do(req)
+}
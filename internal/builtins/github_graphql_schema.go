@@ -0,0 +1,157 @@
+package builtins
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/types"
+)
+
+// graphqlIntrospectionQuery fetches the fields of every type of
+// the schema, including the input fields of input objects.
+const graphqlIntrospectionQuery = `query {
+  __schema {
+    types {
+      name
+      fields(includeDeprecated: true) { name }
+      inputFields { name }
+    }
+  }
+}`
+
+var GitHubGraphQLFieldExistsBuiltin = rego.Function{
+	Name: "github.graphql_field_exists",
+	Decl: types.NewFunction(
+		types.Args(types.S, types.S),
+		types.B,
+	),
+	Memoize: true,
+}
+
+// graphqlSchema holds the field names of each type of a
+// schema. A nil schema means introspection is unavailable.
+type graphqlSchema map[string]map[string]bool
+
+// graphqlSchemaCache holds the introspected schema of each
+// client's endpoint, so introspection is done once per run.
+type graphqlSchemaCache struct {
+	mu      sync.Mutex
+	schemas map[string]graphqlSchema
+}
+
+// GitHubGraphQLFieldExistsBuiltinImpl reports whether the type of the
+// GitHub GraphQL schema has the field, so policies can fail fast on
+// typos before querying. The schema is introspected once per run and
+// client. If the endpoint disables introspection the result is
+// undefined.
+func GitHubGraphQLFieldExistsBuiltinImpl(client *http.Client) func(bctx rego.BuiltinContext, op1, op2 *ast.Term) (*ast.Term, error) {
+	schemas := &graphqlSchemaCache{schemas: map[string]graphqlSchema{}}
+
+	return func(bctx rego.BuiltinContext, op1, op2 *ast.Term) (*ast.Term, error) {
+		var typeName, field string
+
+		if err := ast.As(op1.Value, &typeName); err != nil {
+			return nil, err
+		} else if err := ast.As(op2.Value, &field); err != nil {
+			return nil, err
+		}
+
+		schema, err := schemas.get(bctx.Context, resolveClient(bctx.Context, client))
+		if err != nil {
+			return nil, err
+		}
+
+		if schema == nil {
+			return nil, nil
+		}
+
+		return ast.BooleanTerm(schema[typeName][field]), nil
+	}
+}
+
+// get returns the schema of the client's endpoint, introspecting
+// it if it isn't cached. Failed requests aren't cached.
+func (c *graphqlSchemaCache) get(ctx context.Context, client *http.Client) (graphqlSchema, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := clientID(client)
+
+	if schema, ok := c.schemas[key]; ok {
+		return schema, nil
+	}
+
+	schema, err := introspectGraphQL(ctx, client)
+	if err != nil {
+		return nil, fmt.Errorf("graphql introspection: %w", err)
+	}
+
+	c.schemas[key] = schema
+
+	return schema, nil
+}
+
+// introspectGraphQL fetches the schema of the GitHub GraphQL API,
+// returning nil if introspection is disabled, i.e. the query is
+// answered without a schema. Other responses, e.g. server errors,
+// fail so they aren't mistaken for a disabled introspection.
+func introspectGraphQL(ctx context.Context, client *http.Client) (graphqlSchema, error) {
+	resp, err := githubGraphQL(ctx, client, graphqlIntrospectionQuery, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Message string `json:"message"`
+		Data    struct {
+			Schema *struct {
+				Types []struct {
+					Name        string `json:"name"`
+					Fields      []struct{ Name string }
+					InputFields []struct{ Name string } `json:"inputFields"`
+				} `json:"types"`
+			} `json:"__schema"`
+		} `json:"data"`
+	}
+
+	if resp.StatusCode == http.StatusForbidden {
+		_ = json.NewDecoder(resp.Body).Decode(&body)
+		return nil, fmt.Errorf("forbidden: %s", body.Message)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+
+	if body.Data.Schema == nil {
+		return nil, nil
+	}
+
+	schema := graphqlSchema{}
+
+	for _, t := range body.Data.Schema.Types {
+		fields := map[string]bool{}
+
+		for _, f := range t.Fields {
+			fields[f.Name] = true
+		}
+
+		for _, f := range t.InputFields {
+			fields[f.Name] = true
+		}
+
+		schema[t.Name] = fields
+	}
+
+	return schema, nil
+}
@@ -0,0 +1,162 @@
+package builtins_test
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/reposaur/reposaur/internal/builtins"
+	"github.com/reposaur/reposaur/pkg/util"
+)
+
+const testIntrospectionResponse = `{
+  "data": {
+    "__schema": {
+      "types": [
+        {"name": "Repository", "fields": [{"name": "name"}, {"name": "isArchived"}], "inputFields": null},
+        {"name": "CreateIssueInput", "fields": null, "inputFields": [{"name": "title"}]}
+      ]
+    }
+  }
+}`
+
+func TestGitHubGraphQLFieldExists(t *testing.T) {
+	var calls int64
+
+	client := newStubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&calls, 1)
+
+		if r.Method != http.MethodPost || r.URL.Path != "/graphql" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		_, _ = w.Write([]byte(testIntrospectionResponse))
+	}))
+
+	impl := builtins.GitHubGraphQLFieldExistsBuiltinImpl(client)
+
+	cases := []struct {
+		typeName string
+		field    string
+		exists   bool
+	}{
+		{"Repository", "isArchived", true},
+		{"Repository", "isArchivd", false},
+		{"CreateIssueInput", "title", true},
+		{"Unknown", "name", false},
+	}
+
+	for _, c := range cases {
+		term, err := impl(rego.BuiltinContext{}, ast.StringTerm(c.typeName), ast.StringTerm(c.field))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := term.Value.Compare(ast.Boolean(c.exists)) == 0; !got {
+			t.Errorf("expected %s.%s to exist to be %v", c.typeName, c.field, c.exists)
+		}
+	}
+
+	if calls := atomic.LoadInt64(&calls); calls != 1 {
+		t.Errorf("expected the schema to be introspected once, got %d requests", calls)
+	}
+}
+
+func TestGitHubGraphQLFieldExistsIntrospectionDisabled(t *testing.T) {
+	var calls int64
+
+	client := newStubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&calls, 1)
+
+		_, _ = w.Write([]byte(`{"errors": [{"message": "introspection is disabled"}]}`))
+	}))
+
+	impl := builtins.GitHubGraphQLFieldExistsBuiltinImpl(client)
+
+	for i := 0; i < 2; i++ {
+		term, err := impl(rego.BuiltinContext{}, ast.StringTerm("Repository"), ast.StringTerm("name"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if term != nil {
+			t.Errorf("expected undefined, got %v", term)
+		}
+	}
+
+	if calls := atomic.LoadInt64(&calls); calls != 1 {
+		t.Errorf("expected the disabled introspection to be cached, got %d requests", calls)
+	}
+}
+
+func TestGitHubGraphQLFieldExistsForbidden(t *testing.T) {
+	client := newStubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"message": "rate limit exceeded"}`))
+	}))
+
+	impl := builtins.GitHubGraphQLFieldExistsBuiltinImpl(client)
+
+	if _, err := impl(rego.BuiltinContext{}, ast.StringTerm("Repository"), ast.StringTerm("name")); err == nil {
+		t.Error("expected an error for a forbidden response")
+	}
+}
+
+func TestGitHubGraphQLFieldExistsTransientError(t *testing.T) {
+	var calls int64
+
+	client := newStubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt64(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+
+		_, _ = w.Write([]byte(testIntrospectionResponse))
+	}))
+
+	impl := builtins.GitHubGraphQLFieldExistsBuiltinImpl(client)
+
+	if _, err := impl(rego.BuiltinContext{}, ast.StringTerm("Repository"), ast.StringTerm("name")); err == nil {
+		t.Fatal("expected an error for a server error")
+	}
+
+	term, err := impl(rego.BuiltinContext{}, ast.StringTerm("Repository"), ast.StringTerm("name"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if term == nil || !term.Equal(ast.BooleanTerm(true)) {
+		t.Errorf("expected the schema to be introspected again, got %v", term)
+	}
+}
+
+func TestGitHubGraphQLFieldExistsPerClient(t *testing.T) {
+	disabled := newStubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"errors": [{"message": "introspection is disabled"}]}`))
+	}))
+
+	enabled := newStubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(testIntrospectionResponse))
+	}))
+
+	impl := builtins.GitHubGraphQLFieldExistsBuiltinImpl(disabled)
+
+	if term, err := impl(rego.BuiltinContext{}, ast.StringTerm("Repository"), ast.StringTerm("name")); err != nil || term != nil {
+		t.Fatalf("expected undefined for the default client, got %v, %v", term, err)
+	}
+
+	bctx := rego.BuiltinContext{Context: util.NewClientContext(context.Background(), enabled)}
+
+	term, err := impl(bctx, ast.StringTerm("Repository"), ast.StringTerm("name"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if term == nil || !term.Equal(ast.BooleanTerm(true)) {
+		t.Errorf("expected the schema of the context's client, got %v", term)
+	}
+}
@@ -0,0 +1,76 @@
+package builtins_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/reposaur/reposaur/internal/builtins"
+	"github.com/reposaur/reposaur/pkg/util"
+)
+
+func TestGitHubGraphQLReadOnly(t *testing.T) {
+	var requests int
+
+	client := newStubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = w.Write([]byte(`{"data": {}}`))
+	}))
+
+	impl := builtins.GitHubGraphQLBuiltinImpl(client)
+	bctx := rego.BuiltinContext{Context: util.NewAllowedMethodsContext(context.Background(), []string{"GET", "HEAD"})}
+
+	testCases := []struct {
+		name    string
+		query   string
+		mutates bool
+	}{
+		{"query", `query { viewer { login } }`, false},
+		{"shorthand query", `{ viewer { login } }`, false},
+		{"named query", `query Mutation($mutation: String = "mutation") { repository(name: $mutation) { id } }`, false},
+		{"field named mutation", `query { mutation: viewer { login } }`, false},
+		{"keyword in comment", "# mutation { deleteRepository }\nquery { viewer { login } }", false},
+		{"keyword in block string", `query { search(query: """ } mutation { """) { issueCount } }`, false},
+		{"mutation", `mutation { addStar(input: {starrableId: "1"}) { clientMutationId } }`, true},
+		{"mutation after query", `query A { viewer { login } } mutation B { addStar(input: {starrableId: "1"}) { clientMutationId } }`, true},
+		{"subscription", `subscription { viewer { login } }`, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			requests = 0
+
+			_, err := impl(bctx, ast.StringTerm(tc.query), objectTerm(t, nil))
+
+			if tc.mutates {
+				if err == nil || !strings.Contains(err.Error(), "graphql mutation: method POST isn't allowed") {
+					t.Errorf("expected the mutation not to be allowed, got %v", err)
+				}
+
+				if requests != 0 {
+					t.Errorf("expected no requests, got %d", requests)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if requests != 1 {
+				t.Errorf("expected the query to be done, got %d requests", requests)
+			}
+		})
+	}
+
+	// mutations are allowed when POST is
+	bctx = rego.BuiltinContext{Context: util.NewAllowedMethodsContext(context.Background(), []string{"GET", "POST"})}
+
+	if _, err := impl(bctx, ast.StringTerm(`mutation { addStar(input: {starrableId: "1"}) { clientMutationId } }`), objectTerm(t, nil)); err != nil {
+		t.Errorf("expected the mutation to be allowed, got %v", err)
+	}
+}
@@ -0,0 +1,191 @@
+package builtins
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/types"
+)
+
+var GitHubRepoInvitationsBuiltin = rego.Function{
+	Name: "github.repo_invitations",
+	Decl: types.NewFunction(
+		types.Args(types.S, types.S),
+		types.NewArray(nil, types.NewObject(nil, types.NewDynamicProperty(types.S, types.A))),
+	),
+	Memoize: true,
+}
+
+var GitHubOrgInvitationsBuiltin = rego.Function{
+	Name: "github.org_invitations",
+	Decl: types.NewFunction(
+		types.Args(types.S),
+		types.NewArray(nil, types.NewObject(nil, types.NewDynamicProperty(types.S, types.A))),
+	),
+	Memoize: true,
+}
+
+// Invitation is a normalized view of a pending repository or
+// organization invitation. Invitee is empty if the invitation
+// was sent to an email address without a GitHub account. The
+// permission of organization invitations is the invitee's role.
+type Invitation struct {
+	ID         int    `json:"id"`
+	Invitee    string `json:"invitee"`
+	Email      string `json:"email"`
+	Inviter    string `json:"inviter"`
+	Permission string `json:"permission"`
+	CreatedAt  string `json:"created_at"`
+	Expired    bool   `json:"expired"`
+}
+
+type invitationUser struct {
+	Login string `json:"login"`
+}
+
+type repoInvitationResponse struct {
+	ID          int             `json:"id"`
+	Invitee     *invitationUser `json:"invitee"`
+	Inviter     *invitationUser `json:"inviter"`
+	Permissions string          `json:"permissions"`
+	CreatedAt   string          `json:"created_at"`
+	Expired     bool            `json:"expired"`
+}
+
+type orgInvitationResponse struct {
+	ID        int             `json:"id"`
+	Login     *string         `json:"login"`
+	Email     *string         `json:"email"`
+	Role      string          `json:"role"`
+	Inviter   *invitationUser `json:"inviter"`
+	CreatedAt string          `json:"created_at"`
+	FailedAt  *string         `json:"failed_at"`
+}
+
+// GitHubRepoInvitationsBuiltinImpl fetches the pending invitations to
+// collaborate on a repository, with their invitee and permission.
+// Repositories without pending invitations have an empty list. Listing
+// them requires admin access, so the result is undefined when the
+// request is forbidden or the repository doesn't exist.
+func GitHubRepoInvitationsBuiltinImpl(client *http.Client) func(bctx rego.BuiltinContext, op1, op2 *ast.Term) (*ast.Term, error) {
+	return func(bctx rego.BuiltinContext, op1, op2 *ast.Term) (*ast.Term, error) {
+		var owner, repo string
+
+		if err := ast.As(op1.Value, &owner); err != nil {
+			return nil, err
+		} else if err := ast.As(op2.Value, &repo); err != nil {
+			return nil, err
+		}
+
+		var resp []repoInvitationResponse
+
+		ok, err := fetchInvitations(bctx, client, repoPath(owner, repo, "invitations"), &resp)
+		if err != nil || !ok {
+			return nil, err
+		}
+
+		invitations := make([]Invitation, 0, len(resp))
+
+		for _, r := range resp {
+			invitations = append(invitations, Invitation{
+				ID:         r.ID,
+				Invitee:    invitationLogin(r.Invitee),
+				Inviter:    invitationLogin(r.Inviter),
+				Permission: r.Permissions,
+				CreatedAt:  r.CreatedAt,
+				Expired:    r.Expired,
+			})
+		}
+
+		val, err := ast.InterfaceToValue(invitations)
+		if err != nil {
+			return nil, err
+		}
+
+		return ast.NewTerm(val), nil
+	}
+}
+
+// GitHubOrgInvitationsBuiltinImpl fetches the pending invitations to
+// join an organization like github.repo_invitations. Invitations that
+// failed to be delivered are expired. Listing them requires owner
+// credentials, so the result is undefined when the request is forbidden
+// or the organization doesn't exist.
+func GitHubOrgInvitationsBuiltinImpl(client *http.Client) func(bctx rego.BuiltinContext, op1 *ast.Term) (*ast.Term, error) {
+	return func(bctx rego.BuiltinContext, op1 *ast.Term) (*ast.Term, error) {
+		var org string
+
+		if err := ast.As(op1.Value, &org); err != nil {
+			return nil, err
+		}
+
+		var resp []orgInvitationResponse
+
+		ok, err := fetchInvitations(bctx, client, "/orgs/"+url.PathEscape(org)+"/invitations", &resp)
+		if err != nil || !ok {
+			return nil, err
+		}
+
+		invitations := make([]Invitation, 0, len(resp))
+
+		for _, r := range resp {
+			inv := Invitation{
+				ID:         r.ID,
+				Inviter:    invitationLogin(r.Inviter),
+				Permission: r.Role,
+				CreatedAt:  r.CreatedAt,
+				Expired:    r.FailedAt != nil,
+			}
+
+			if r.Login != nil {
+				inv.Invitee = *r.Login
+			}
+
+			if r.Email != nil {
+				inv.Email = *r.Email
+			}
+
+			invitations = append(invitations, inv)
+		}
+
+		val, err := ast.InterfaceToValue(invitations)
+		if err != nil {
+			return nil, err
+		}
+
+		return ast.NewTerm(val), nil
+	}
+}
+
+// fetchInvitations fetches every page of invitations at path
+// into v. Returns false if the request is forbidden or the
+// repository or organization doesn't exist.
+func fetchInvitations(bctx rego.BuiltinContext, client *http.Client, path string, v interface{}) (bool, error) {
+	items, status, err := githubGetPages(bctx.Context, client, path, "", 0)
+	if err != nil {
+		return false, err
+	}
+
+	switch status {
+	case http.StatusOK:
+	case http.StatusForbidden, http.StatusNotFound:
+		return false, nil
+	default:
+		return false, fmt.Errorf("get invitations: unexpected status %d", status)
+	}
+
+	return true, decodeItems(items, v)
+}
+
+// invitationLogin returns the login of
+// user, or empty if there isn't one.
+func invitationLogin(user *invitationUser) string {
+	if user == nil {
+		return ""
+	}
+
+	return user.Login
+}
@@ -0,0 +1,157 @@
+package builtins_test
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/reposaur/reposaur/internal/builtins"
+)
+
+const testRepoInvitations = `[
+	{
+		"id": 1,
+		"repository": {"full_name": "reposaur/reposaur"},
+		"invitee": {"login": "octocat"},
+		"inviter": {"login": "maintainer"},
+		"permissions": "admin",
+		"created_at": "2022-01-01T00:00:00Z",
+		"expired": false
+	},
+	{
+		"id": 2,
+		"repository": {"full_name": "reposaur/reposaur"},
+		"invitee": {"login": "hubot"},
+		"inviter": null,
+		"permissions": "read",
+		"created_at": "2021-01-01T00:00:00Z",
+		"expired": true
+	}
+]`
+
+const testOrgInvitations = `[
+	{
+		"id": 3,
+		"login": null,
+		"email": "contractor@example.com",
+		"role": "direct_member",
+		"created_at": "2022-02-01T00:00:00Z",
+		"failed_at": null,
+		"inviter": {"login": "maintainer"},
+		"team_count": 1
+	},
+	{
+		"id": 4,
+		"login": "octocat",
+		"email": null,
+		"role": "admin",
+		"created_at": "2022-03-01T00:00:00Z",
+		"failed_at": "2022-03-08T00:00:00Z",
+		"inviter": {"login": "maintainer"},
+		"team_count": 0
+	}
+]`
+
+func newInvitationsStubClient(t *testing.T) *http.Client {
+	return newStubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/reposaur/reposaur/invitations":
+			_, _ = w.Write([]byte(testRepoInvitations))
+
+		case "/orgs/reposaur/invitations":
+			_, _ = w.Write([]byte(testOrgInvitations))
+
+		case "/repos/reposaur/empty/invitations", "/orgs/empty/invitations":
+			_, _ = w.Write([]byte(`[]`))
+
+		case "/repos/reposaur/forbidden/invitations", "/orgs/forbidden/invitations":
+			w.WriteHeader(http.StatusForbidden)
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestGitHubRepoInvitations(t *testing.T) {
+	impl := builtins.GitHubRepoInvitationsBuiltinImpl(newInvitationsStubClient(t))
+
+	term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm("reposaur"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var invitations []builtins.Invitation
+	if err := ast.As(term.Value, &invitations); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []builtins.Invitation{
+		{ID: 1, Invitee: "octocat", Inviter: "maintainer", Permission: "admin", CreatedAt: "2022-01-01T00:00:00Z"},
+		{ID: 2, Invitee: "hubot", Permission: "read", CreatedAt: "2021-01-01T00:00:00Z", Expired: true},
+	}
+
+	if !reflect.DeepEqual(invitations, expected) {
+		t.Errorf("expected %+v, got %+v", expected, invitations)
+	}
+}
+
+func TestGitHubOrgInvitations(t *testing.T) {
+	impl := builtins.GitHubOrgInvitationsBuiltinImpl(newInvitationsStubClient(t))
+
+	term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var invitations []builtins.Invitation
+	if err := ast.As(term.Value, &invitations); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []builtins.Invitation{
+		{ID: 3, Email: "contractor@example.com", Inviter: "maintainer", Permission: "direct_member", CreatedAt: "2022-02-01T00:00:00Z"},
+		{ID: 4, Invitee: "octocat", Inviter: "maintainer", Permission: "admin", CreatedAt: "2022-03-01T00:00:00Z", Expired: true},
+	}
+
+	if !reflect.DeepEqual(invitations, expected) {
+		t.Errorf("expected %+v, got %+v", expected, invitations)
+	}
+}
+
+func TestGitHubInvitationsNone(t *testing.T) {
+	client := newInvitationsStubClient(t)
+
+	repoImpl := builtins.GitHubRepoInvitationsBuiltinImpl(client)
+	orgImpl := builtins.GitHubOrgInvitationsBuiltinImpl(client)
+
+	term, err := repoImpl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm("empty"))
+	if err != nil {
+		t.Fatal(err)
+	} else if !term.Equal(ast.ArrayTerm()) {
+		t.Errorf("expected an empty list, got %v", term)
+	}
+
+	term, err = orgImpl(rego.BuiltinContext{}, ast.StringTerm("empty"))
+	if err != nil {
+		t.Fatal(err)
+	} else if !term.Equal(ast.ArrayTerm()) {
+		t.Errorf("expected an empty list, got %v", term)
+	}
+
+	for _, name := range []string{"forbidden", "missing"} {
+		if term, err := repoImpl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm(name)); err != nil {
+			t.Fatal(err)
+		} else if term != nil {
+			t.Errorf("%s: expected undefined, got %v", name, term)
+		}
+
+		if term, err := orgImpl(rego.BuiltinContext{}, ast.StringTerm(name)); err != nil {
+			t.Fatal(err)
+		} else if term != nil {
+			t.Errorf("%s: expected undefined, got %v", name, term)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package builtins
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/types"
+)
+
+// noAssertionLicense is the SPDX ID GitHub returns when a
+// license file was found but its license wasn't identified.
+const noAssertionLicense = "NOASSERTION"
+
+var GitHubLicenseBuiltin = rego.Function{
+	Name: "github.license",
+	Decl: types.NewFunction(
+		types.Args(types.S, types.S),
+		types.S,
+	),
+	Memoize: true,
+}
+
+// GitHubLicenseBuiltinImpl returns the SPDX ID of the license detected
+// in a repository. Returns undefined if the repository doesn't have a
+// license or it wasn't identified.
+func GitHubLicenseBuiltinImpl(client *http.Client) func(bctx rego.BuiltinContext, op1, op2 *ast.Term) (*ast.Term, error) {
+	return func(bctx rego.BuiltinContext, op1, op2 *ast.Term) (*ast.Term, error) {
+		var owner, repo string
+
+		if err := ast.As(op1.Value, &owner); err != nil {
+			return nil, err
+		} else if err := ast.As(op2.Value, &repo); err != nil {
+			return nil, err
+		}
+
+		var resp struct {
+			License *struct {
+				SPDXID string `json:"spdx_id"`
+			} `json:"license"`
+		}
+
+		status, err := githubGet(bctx.Context, client, repoPath(owner, repo, "license"), &resp)
+		if err != nil {
+			return nil, err
+		} else if status == http.StatusNotFound {
+			return nil, nil
+		} else if status != http.StatusOK {
+			return nil, fmt.Errorf("get license: unexpected status %d", status)
+		}
+
+		if resp.License == nil || resp.License.SPDXID == "" || resp.License.SPDXID == noAssertionLicense {
+			return nil, nil
+		}
+
+		return ast.StringTerm(resp.License.SPDXID), nil
+	}
+}
@@ -0,0 +1,65 @@
+package builtins_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/reposaur/reposaur/internal/builtins"
+)
+
+func newLicenseStubClient(t *testing.T) *http.Client {
+	return newStubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/reposaur/licensed/license":
+			_, _ = w.Write([]byte(`{"name": "LICENSE", "license": {"key": "mit", "spdx_id": "MIT"}}`))
+
+		case "/repos/reposaur/other/license":
+			_, _ = w.Write([]byte(`{"name": "LICENSE", "license": {"key": "other", "spdx_id": "NOASSERTION"}}`))
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestGitHubLicense(t *testing.T) {
+	impl := builtins.GitHubLicenseBuiltinImpl(newLicenseStubClient(t))
+
+	term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm("licensed"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if term == nil || term.Value.Compare(ast.String("MIT")) != 0 {
+		t.Errorf("expected license to be MIT, got %v", term)
+	}
+}
+
+func TestGitHubLicenseUndefined(t *testing.T) {
+	impl := builtins.GitHubLicenseBuiltinImpl(newLicenseStubClient(t))
+
+	for _, repo := range []string{"unlicensed", "other"} {
+		term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm(repo))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if term != nil {
+			t.Errorf("expected license of %s to be undefined, got %v", repo, term)
+		}
+	}
+}
+
+func TestGitHubLicenseError(t *testing.T) {
+	client := newStubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+
+	impl := builtins.GitHubLicenseBuiltinImpl(client)
+
+	if _, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm("licensed")); err == nil {
+		t.Error("expected an error")
+	}
+}
@@ -0,0 +1,125 @@
+package builtins
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/types"
+)
+
+var GitHubOrgBuiltin = rego.Function{
+	Name: "github.org",
+	Decl: types.NewFunction(
+		types.Args(types.S),
+		types.NewObject(nil, types.NewDynamicProperty(types.S, types.A)),
+	),
+	Memoize: true,
+}
+
+// OrgSettings is a normalized view of the settings of an
+// organization. Settings that are only visible to organization
+// owners are nil when the credentials used aren't an owner's,
+// in which case Admin is false.
+type OrgSettings struct {
+	Login                       string            `json:"login"`
+	Name                        string            `json:"name"`
+	Admin                       bool              `json:"admin"`
+	Plan                        *string           `json:"plan"`
+	TwoFactorRequired           *bool             `json:"two_factor_required"`
+	WebCommitSignoffRequired    *bool             `json:"web_commit_signoff_required"`
+	DefaultRepositoryPermission *string           `json:"default_repository_permission"`
+	Members                     OrgMemberSettings `json:"members"`
+}
+
+// OrgMemberSettings are the privileges
+// of the members of an organization.
+type OrgMemberSettings struct {
+	CreateRepositories         *bool `json:"create_repositories"`
+	CreatePublicRepositories   *bool `json:"create_public_repositories"`
+	CreatePrivateRepositories  *bool `json:"create_private_repositories"`
+	CreateInternalRepositories *bool `json:"create_internal_repositories"`
+	CreatePages                *bool `json:"create_pages"`
+	ForkPrivateRepositories    *bool `json:"fork_private_repositories"`
+}
+
+// orgResponse is the subset of the organization
+// API response that is normalized.
+type orgResponse struct {
+	Login string `json:"login"`
+	Name  string `json:"name"`
+	Plan  *struct {
+		Name string `json:"name"`
+	} `json:"plan"`
+	TwoFactorRequirementEnabled          *bool   `json:"two_factor_requirement_enabled"`
+	WebCommitSignoffRequired             *bool   `json:"web_commit_signoff_required"`
+	DefaultRepositoryPermission          *string `json:"default_repository_permission"`
+	MembersCanCreateRepositories         *bool   `json:"members_can_create_repositories"`
+	MembersCanCreatePublicRepositories   *bool   `json:"members_can_create_public_repositories"`
+	MembersCanCreatePrivateRepositories  *bool   `json:"members_can_create_private_repositories"`
+	MembersCanCreateInternalRepositories *bool   `json:"members_can_create_internal_repositories"`
+	MembersCanCreatePages                *bool   `json:"members_can_create_pages"`
+	MembersCanForkPrivateRepositories    *bool   `json:"members_can_fork_private_repositories"`
+}
+
+// GitHubOrgBuiltinImpl fetches the settings of an organization
+// and returns them normalized. Returns undefined if the
+// organization doesn't exist.
+func GitHubOrgBuiltinImpl(client *http.Client) func(bctx rego.BuiltinContext, op1 *ast.Term) (*ast.Term, error) {
+	return func(bctx rego.BuiltinContext, op1 *ast.Term) (*ast.Term, error) {
+		var org string
+
+		if err := ast.As(op1.Value, &org); err != nil {
+			return nil, err
+		}
+
+		var resp orgResponse
+
+		status, err := githubGet(bctx.Context, client, "/orgs/"+url.PathEscape(org), &resp)
+		if err != nil {
+			return nil, err
+		} else if status == http.StatusNotFound {
+			return nil, nil
+		} else if status != http.StatusOK {
+			return nil, fmt.Errorf("get organization: unexpected status %d", status)
+		}
+
+		val, err := ast.InterfaceToValue(normalizeOrg(resp))
+		if err != nil {
+			return nil, err
+		}
+
+		return ast.NewTerm(val), nil
+	}
+}
+
+func normalizeOrg(resp orgResponse) OrgSettings {
+	org := OrgSettings{
+		Login: resp.Login,
+		Name:  resp.Name,
+
+		// the 2FA requirement is only returned to owners,
+		// so it tells whether the other settings are complete
+		Admin: resp.TwoFactorRequirementEnabled != nil,
+
+		TwoFactorRequired:           resp.TwoFactorRequirementEnabled,
+		WebCommitSignoffRequired:    resp.WebCommitSignoffRequired,
+		DefaultRepositoryPermission: resp.DefaultRepositoryPermission,
+		Members: OrgMemberSettings{
+			CreateRepositories:         resp.MembersCanCreateRepositories,
+			CreatePublicRepositories:   resp.MembersCanCreatePublicRepositories,
+			CreatePrivateRepositories:  resp.MembersCanCreatePrivateRepositories,
+			CreateInternalRepositories: resp.MembersCanCreateInternalRepositories,
+			CreatePages:                resp.MembersCanCreatePages,
+			ForkPrivateRepositories:    resp.MembersCanForkPrivateRepositories,
+		},
+	}
+
+	if resp.Plan != nil {
+		org.Plan = &resp.Plan.Name
+	}
+
+	return org
+}
@@ -0,0 +1,96 @@
+package builtins
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/types"
+)
+
+var GitHubOrgSecurityBuiltin = rego.Function{
+	Name: "github.org_security",
+	Decl: types.NewFunction(
+		types.Args(types.S),
+		types.NewObject(nil, types.NewDynamicProperty(types.S, types.A)),
+	),
+	Memoize: true,
+}
+
+// OrgSecurity are the security and analysis features an
+// organization enables for its new repositories. They're only
+// visible to organization owners, so they're nil when the
+// credentials used aren't an owner's, in which case Admin is
+// false.
+type OrgSecurity struct {
+	Admin                        bool  `json:"admin"`
+	AdvancedSecurity             *bool `json:"advanced_security"`
+	DependencyGraph              *bool `json:"dependency_graph"`
+	DependabotAlerts             *bool `json:"dependabot_alerts"`
+	DependabotSecurityUpdates    *bool `json:"dependabot_security_updates"`
+	SecretScanning               *bool `json:"secret_scanning"`
+	SecretScanningPushProtection *bool `json:"secret_scanning_push_protection"`
+}
+
+// orgSecurityResponse is the subset of the organization
+// API response with the security and analysis settings.
+type orgSecurityResponse struct {
+	TwoFactorRequirementEnabled                           *bool `json:"two_factor_requirement_enabled"`
+	AdvancedSecurityEnabledForNewRepositories             *bool `json:"advanced_security_enabled_for_new_repositories"`
+	DependencyGraphEnabledForNewRepositories              *bool `json:"dependency_graph_enabled_for_new_repositories"`
+	DependabotAlertsEnabledForNewRepositories             *bool `json:"dependabot_alerts_enabled_for_new_repositories"`
+	DependabotSecurityUpdatesEnabledForNewRepositories    *bool `json:"dependabot_security_updates_enabled_for_new_repositories"`
+	SecretScanningEnabledForNewRepositories               *bool `json:"secret_scanning_enabled_for_new_repositories"`
+	SecretScanningPushProtectionEnabledForNewRepositories *bool `json:"secret_scanning_push_protection_enabled_for_new_repositories"`
+}
+
+// GitHubOrgSecurityBuiltinImpl fetches the security and analysis
+// settings of an organization. If the credentials used aren't an
+// owner's, including when the request is forbidden, the settings
+// are nil instead of halting the evaluation. Returns undefined if
+// the organization doesn't exist.
+func GitHubOrgSecurityBuiltinImpl(client *http.Client) func(bctx rego.BuiltinContext, op1 *ast.Term) (*ast.Term, error) {
+	return func(bctx rego.BuiltinContext, op1 *ast.Term) (*ast.Term, error) {
+		var org string
+
+		if err := ast.As(op1.Value, &org); err != nil {
+			return nil, err
+		}
+
+		var resp orgSecurityResponse
+
+		status, err := githubGet(bctx.Context, client, "/orgs/"+url.PathEscape(org), &resp)
+		if err != nil {
+			return nil, err
+		}
+
+		switch status {
+		case http.StatusOK:
+		case http.StatusForbidden:
+			// insufficient permissions, the settings stay nil
+		case http.StatusNotFound:
+			return nil, nil
+		default:
+			return nil, fmt.Errorf("get organization: unexpected status %d", status)
+		}
+
+		val, err := ast.InterfaceToValue(OrgSecurity{
+			// the 2FA requirement is only returned to owners,
+			// so it tells whether the settings are visible
+			Admin:                        resp.TwoFactorRequirementEnabled != nil,
+			AdvancedSecurity:             resp.AdvancedSecurityEnabledForNewRepositories,
+			DependencyGraph:              resp.DependencyGraphEnabledForNewRepositories,
+			DependabotAlerts:             resp.DependabotAlertsEnabledForNewRepositories,
+			DependabotSecurityUpdates:    resp.DependabotSecurityUpdatesEnabledForNewRepositories,
+			SecretScanning:               resp.SecretScanningEnabledForNewRepositories,
+			SecretScanningPushProtection: resp.SecretScanningPushProtectionEnabledForNewRepositories,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return ast.NewTerm(val), nil
+	}
+}
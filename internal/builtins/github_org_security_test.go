@@ -0,0 +1,73 @@
+package builtins_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/reposaur/reposaur/internal/builtins"
+)
+
+const testOrgSecurityOwner = `{
+	"login": "reposaur",
+	"two_factor_requirement_enabled": true,
+	"advanced_security_enabled_for_new_repositories": true,
+	"dependency_graph_enabled_for_new_repositories": true,
+	"dependabot_alerts_enabled_for_new_repositories": true,
+	"dependabot_security_updates_enabled_for_new_repositories": false,
+	"secret_scanning_enabled_for_new_repositories": true,
+	"secret_scanning_push_protection_enabled_for_new_repositories": false
+}`
+
+func orgSecurity(t *testing.T, client *http.Client, org string) *builtins.OrgSecurity {
+	t.Helper()
+
+	term, err := builtins.GitHubOrgSecurityBuiltinImpl(client)(rego.BuiltinContext{}, ast.StringTerm(org))
+	if err != nil {
+		t.Fatal(err)
+	} else if term == nil {
+		return nil
+	}
+
+	var sec builtins.OrgSecurity
+	if err := ast.As(term.Value, &sec); err != nil {
+		t.Fatal(err)
+	}
+
+	return &sec
+}
+
+func TestGitHubOrgSecurity(t *testing.T) {
+	sec := orgSecurity(t, newOrgStubClient(t, testOrgSecurityOwner), "reposaur")
+
+	if sec == nil || !sec.Admin {
+		t.Fatalf("expected the settings of an owner, got %+v", sec)
+	}
+
+	if !*sec.AdvancedSecurity || !*sec.SecretScanning || *sec.SecretScanningPushProtection || *sec.DependabotSecurityUpdates {
+		t.Errorf("unexpected settings: %+v", sec)
+	}
+
+	if orgSecurity(t, newOrgStubClient(t, testOrgSecurityOwner), "missing") != nil {
+		t.Error("expected undefined for a missing organization")
+	}
+}
+
+func TestGitHubOrgSecurityInsufficientPermissions(t *testing.T) {
+	forbidden := newStubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"message": "Resource not accessible by integration"}`))
+	}))
+
+	for name, client := range map[string]*http.Client{
+		"member":    newOrgStubClient(t, testOrgMember),
+		"forbidden": forbidden,
+	} {
+		sec := orgSecurity(t, client, "reposaur")
+
+		if sec == nil || sec.Admin || sec.AdvancedSecurity != nil || sec.SecretScanning != nil {
+			t.Errorf("%s: expected settings without values, got %+v", name, sec)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package builtins_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/reposaur/reposaur/internal/builtins"
+)
+
+const testOrgOwner = `{
+	"login": "reposaur",
+	"name": "Reposaur",
+	"plan": {"name": "team"},
+	"two_factor_requirement_enabled": true,
+	"web_commit_signoff_required": false,
+	"default_repository_permission": "read",
+	"members_can_create_repositories": true,
+	"members_can_create_public_repositories": false,
+	"members_can_create_private_repositories": true,
+	"members_can_create_internal_repositories": true,
+	"members_can_create_pages": true,
+	"members_can_fork_private_repositories": false
+}`
+
+const testOrgMember = `{
+	"login": "reposaur",
+	"name": "Reposaur"
+}`
+
+func newOrgStubClient(t *testing.T, body string) *http.Client {
+	return newStubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/orgs/reposaur" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		_, _ = w.Write([]byte(body))
+	}))
+}
+
+func TestGitHubOrg(t *testing.T) {
+	impl := builtins.GitHubOrgBuiltinImpl(newOrgStubClient(t, testOrgOwner))
+
+	term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"))
+	if err != nil {
+		t.Fatal(err)
+	} else if term == nil {
+		t.Fatal("expected organization settings")
+	}
+
+	var org builtins.OrgSettings
+	if err := ast.As(term.Value, &org); err != nil {
+		t.Fatal(err)
+	}
+
+	if !org.Admin {
+		t.Error("expected admin to be true")
+	}
+
+	if org.Plan == nil || *org.Plan != "team" {
+		t.Errorf("expected plan to be team, got %v", org.Plan)
+	}
+
+	if org.TwoFactorRequired == nil || !*org.TwoFactorRequired {
+		t.Errorf("expected two factor to be required, got %v", org.TwoFactorRequired)
+	}
+
+	if org.DefaultRepositoryPermission == nil || *org.DefaultRepositoryPermission != "read" {
+		t.Errorf("expected default permission to be read, got %v", org.DefaultRepositoryPermission)
+	}
+
+	if m := org.Members.CreatePublicRepositories; m == nil || *m {
+		t.Errorf("expected members not to create public repositories, got %v", m)
+	}
+
+	if m := org.Members.ForkPrivateRepositories; m == nil || *m {
+		t.Errorf("expected members not to fork private repositories, got %v", m)
+	}
+}
+
+func TestGitHubOrgWithoutOwnerPermissions(t *testing.T) {
+	impl := builtins.GitHubOrgBuiltinImpl(newOrgStubClient(t, testOrgMember))
+
+	term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"two_factor_required", "default_repository_permission", "plan"} {
+		v := term.Get(ast.StringTerm(key))
+		if v == nil || !v.Equal(ast.NullTerm()) {
+			t.Errorf("expected %s to be null, got %v", key, v)
+		}
+	}
+
+	if v := term.Get(ast.StringTerm("admin")); !v.Equal(ast.BooleanTerm(false)) {
+		t.Errorf("expected admin to be false, got %v", v)
+	}
+}
+
+func TestGitHubOrgNotFound(t *testing.T) {
+	impl := builtins.GitHubOrgBuiltinImpl(newOrgStubClient(t, testOrgOwner))
+
+	term, err := impl(rego.BuiltinContext{}, ast.StringTerm("unknown"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if term != nil {
+		t.Errorf("expected undefined, got %v", term)
+	}
+}
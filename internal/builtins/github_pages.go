@@ -0,0 +1,113 @@
+package builtins
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/types"
+)
+
+var GitHubPagesBuiltin = rego.Function{
+	Name: "github.pages",
+	Decl: types.NewFunction(
+		types.Args(types.S, types.S),
+		types.NewObject(nil, types.NewDynamicProperty(types.S, types.A)),
+	),
+	Memoize: true,
+}
+
+// Pages is a normalized view of the GitHub Pages site of a repository.
+type Pages struct {
+	URL    string `json:"url"`
+	CNAME  string `json:"cname"`
+	Status string `json:"status"`
+
+	// BuildType is either legacy, built from Source,
+	// or workflow, built by a GitHub Actions workflow.
+	BuildType string       `json:"build_type"`
+	Source    *PagesSource `json:"source"`
+
+	HTTPSEnforced bool `json:"https_enforced"`
+
+	// Visibility is either public or private, private
+	// sites being only visible to repository readers.
+	Visibility string `json:"visibility"`
+}
+
+// PagesSource is the branch and directory
+// a Pages site is built from.
+type PagesSource struct {
+	Branch string `json:"branch"`
+	Path   string `json:"path"`
+}
+
+type pagesResponse struct {
+	HTMLURL       string       `json:"html_url"`
+	CNAME         *string      `json:"cname"`
+	Status        *string      `json:"status"`
+	BuildType     string       `json:"build_type"`
+	Source        *PagesSource `json:"source"`
+	HTTPSEnforced bool         `json:"https_enforced"`
+	Public        *bool        `json:"public"`
+}
+
+// GitHubPagesBuiltinImpl fetches the GitHub Pages site of a repository
+// and returns its configuration normalized. Returns undefined if Pages
+// is disabled or the repository doesn't exist.
+func GitHubPagesBuiltinImpl(client *http.Client) func(bctx rego.BuiltinContext, op1, op2 *ast.Term) (*ast.Term, error) {
+	return func(bctx rego.BuiltinContext, op1, op2 *ast.Term) (*ast.Term, error) {
+		var owner, repo string
+
+		if err := ast.As(op1.Value, &owner); err != nil {
+			return nil, err
+		} else if err := ast.As(op2.Value, &repo); err != nil {
+			return nil, err
+		}
+
+		var resp pagesResponse
+
+		status, err := githubGet(bctx.Context, client, repoPath(owner, repo, "pages"), &resp)
+		if err != nil {
+			return nil, err
+		} else if status == http.StatusNotFound {
+			return nil, nil
+		} else if status != http.StatusOK {
+			return nil, fmt.Errorf("get pages: unexpected status %d", status)
+		}
+
+		pages := Pages{
+			URL:           resp.HTMLURL,
+			BuildType:     resp.BuildType,
+			Source:        resp.Source,
+			HTTPSEnforced: resp.HTTPSEnforced,
+			Visibility:    "public",
+		}
+
+		if resp.CNAME != nil {
+			pages.CNAME = *resp.CNAME
+		}
+
+		if resp.Status != nil {
+			pages.Status = *resp.Status
+		}
+
+		// sites created before workflow
+		// builds existed have no build type
+		if pages.BuildType == "" {
+			pages.BuildType = "legacy"
+		}
+
+		if resp.Public != nil && !*resp.Public {
+			pages.Visibility = "private"
+		}
+
+		val, err := ast.InterfaceToValue(pages)
+		if err != nil {
+			return nil, err
+		}
+
+		return ast.NewTerm(val), nil
+	}
+}
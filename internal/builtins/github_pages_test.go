@@ -0,0 +1,91 @@
+package builtins_test
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/reposaur/reposaur/internal/builtins"
+)
+
+func newPagesStubClient(t *testing.T) *http.Client {
+	return newStubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/reposaur/reposaur/pages":
+			_, _ = w.Write([]byte(`{
+				"html_url": "https://docs.reposaur.com",
+				"cname": "docs.reposaur.com",
+				"status": "built",
+				"build_type": "legacy",
+				"source": {"branch": "gh-pages", "path": "/"},
+				"https_enforced": true,
+				"public": true
+			}`))
+
+		case "/repos/reposaur/internal/pages":
+			_, _ = w.Write([]byte(`{
+				"html_url": "https://reposaur.github.io/internal",
+				"cname": null,
+				"status": null,
+				"build_type": "workflow",
+				"https_enforced": false,
+				"public": false
+			}`))
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestGitHubPages(t *testing.T) {
+	impl := builtins.GitHubPagesBuiltinImpl(newPagesStubClient(t))
+
+	cases := map[string]builtins.Pages{
+		"reposaur": {
+			URL:           "https://docs.reposaur.com",
+			CNAME:         "docs.reposaur.com",
+			Status:        "built",
+			BuildType:     "legacy",
+			Source:        &builtins.PagesSource{Branch: "gh-pages", Path: "/"},
+			HTTPSEnforced: true,
+			Visibility:    "public",
+		},
+		"internal": {
+			URL:        "https://reposaur.github.io/internal",
+			BuildType:  "workflow",
+			Visibility: "private",
+		},
+	}
+
+	for repo, expected := range cases {
+		term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm(repo))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var pages builtins.Pages
+		if err := ast.As(term.Value, &pages); err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(pages, expected) {
+			t.Errorf("%s: expected %+v, got %+v", repo, expected, pages)
+		}
+	}
+}
+
+func TestGitHubPagesDisabled(t *testing.T) {
+	impl := builtins.GitHubPagesBuiltinImpl(newPagesStubClient(t))
+
+	term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm("disabled"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if term != nil {
+		t.Errorf("expected undefined, got %v", term)
+	}
+}
@@ -0,0 +1,81 @@
+package builtins
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/types"
+)
+
+// permissionLevels maps GitHub's permission levels, and their
+// aliases used by some endpoints, to their rank.
+var permissionLevels = map[string]int{
+	"none":     0,
+	"pull":     1,
+	"read":     1,
+	"triage":   2,
+	"push":     3,
+	"write":    3,
+	"maintain": 4,
+	"admin":    5,
+}
+
+// permissionsByRank are the permission levels returned in the
+// permissions object of some endpoints, from highest to lowest.
+var permissionsByRank = []string{"admin", "maintain", "push", "triage", "pull"}
+
+// highestPermission returns the highest permission level
+// in a permissions object, e.g. {"admin": false, "push":
+// true, "pull": true} is push, or none if there isn't any.
+func highestPermission(permissions map[string]bool) string {
+	for _, p := range permissionsByRank {
+		if permissions[p] {
+			return p
+		}
+	}
+
+	return "none"
+}
+
+var GitHubPermissionGTEBuiltin = rego.Function{
+	Name: "github.permission_gte",
+	Decl: types.NewFunction(
+		types.Args(types.S, types.S),
+		types.B,
+	),
+}
+
+// GitHubPermissionGTEBuiltinImpl returns whether the permission
+// level a is at least the permission level b.
+func GitHubPermissionGTEBuiltinImpl(bctx rego.BuiltinContext, op1, op2 *ast.Term) (*ast.Term, error) {
+	var a, b string
+
+	if err := ast.As(op1.Value, &a); err != nil {
+		return nil, err
+	} else if err := ast.As(op2.Value, &b); err != nil {
+		return nil, err
+	}
+
+	rankA, err := permissionRank(a)
+	if err != nil {
+		return nil, err
+	}
+
+	rankB, err := permissionRank(b)
+	if err != nil {
+		return nil, err
+	}
+
+	return ast.BooleanTerm(rankA >= rankB), nil
+}
+
+func permissionRank(p string) (int, error) {
+	rank, ok := permissionLevels[strings.ToLower(p)]
+	if !ok {
+		return 0, fmt.Errorf("unknown permission level '%s'", p)
+	}
+
+	return rank, nil
+}
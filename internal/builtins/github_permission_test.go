@@ -0,0 +1,58 @@
+package builtins_test
+
+import (
+	"testing"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/reposaur/reposaur/internal/builtins"
+)
+
+func TestGitHubPermissionGTE(t *testing.T) {
+	ordering := []string{"pull", "triage", "push", "maintain", "admin"}
+
+	for i, a := range ordering {
+		for j, b := range ordering {
+			term, err := builtins.GitHubPermissionGTEBuiltinImpl(rego.BuiltinContext{}, ast.StringTerm(a), ast.StringTerm(b))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if expected := i >= j; term.Value.Compare(ast.Boolean(expected)) != 0 {
+				t.Errorf("expected github.permission_gte(%s, %s) to be %v", a, b, expected)
+			}
+		}
+	}
+}
+
+func TestGitHubPermissionGTEAliases(t *testing.T) {
+	cases := []struct {
+		a, b     string
+		expected bool
+	}{
+		{"write", "push", true},
+		{"read", "pull", true},
+		{"read", "write", false},
+		{"ADMIN", "write", true},
+	}
+
+	for _, c := range cases {
+		term, err := builtins.GitHubPermissionGTEBuiltinImpl(rego.BuiltinContext{}, ast.StringTerm(c.a), ast.StringTerm(c.b))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if term.Value.Compare(ast.Boolean(c.expected)) != 0 {
+			t.Errorf("expected github.permission_gte(%s, %s) to be %v", c.a, c.b, c.expected)
+		}
+	}
+}
+
+func TestGitHubPermissionGTEUnknown(t *testing.T) {
+	for _, c := range [][2]string{{"owner", "pull"}, {"admin", "superuser"}} {
+		_, err := builtins.GitHubPermissionGTEBuiltinImpl(rego.BuiltinContext{}, ast.StringTerm(c[0]), ast.StringTerm(c[1]))
+		if err == nil {
+			t.Errorf("expected error comparing %s and %s", c[0], c[1])
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package builtins
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/types"
+)
+
+// hunkHeaderRegex matches the header of a hunk in
+// a unified diff, e.g. "@@ -1,3 +1,4 @@ func main".
+var hunkHeaderRegex = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@ ?(.*)$`)
+
+var GitHubPRFilesBuiltin = rego.Function{
+	Name: "github.pr_files",
+	Decl: types.NewFunction(
+		types.Args(types.S, types.S, types.N),
+		types.NewArray(nil, types.NewObject(nil, types.NewDynamicProperty(types.S, types.A))),
+	),
+	Memoize: true,
+}
+
+// PRFile is a file changed in a pull request.
+type PRFile struct {
+	Filename         string  `json:"filename"`
+	PreviousFilename *string `json:"previous_filename"`
+	Status           string  `json:"status"`
+	Additions        int     `json:"additions"`
+	Deletions        int     `json:"deletions"`
+	Changes          int     `json:"changes"`
+	Patch            *string `json:"patch"`
+	Hunks            []Hunk  `json:"hunks"`
+
+	// Binary is true when the file doesn't have a patch nor
+	// changed lines, i.e. it's likely a binary file. Truncated
+	// is true when it has changed lines but the patch was
+	// omitted because it's too large.
+	Binary    bool `json:"binary"`
+	Truncated bool `json:"truncated"`
+}
+
+// Hunk is a hunk of the patch of a file, with its lines
+// prefixed with "+", "-" or " " like in a unified diff.
+type Hunk struct {
+	Header   string   `json:"header"`
+	OldStart int      `json:"old_start"`
+	OldLines int      `json:"old_lines"`
+	NewStart int      `json:"new_start"`
+	NewLines int      `json:"new_lines"`
+	Lines    []string `json:"lines"`
+}
+
+// GitHubPRFilesBuiltinImpl fetches every file changed in a pull
+// request (up to the 3000 files returned by the API) with its status
+// and patch split in hunks. Returns undefined if the pull request
+// doesn't exist.
+func GitHubPRFilesBuiltinImpl(client *http.Client) func(bctx rego.BuiltinContext, op1, op2, op3 *ast.Term) (*ast.Term, error) {
+	return func(bctx rego.BuiltinContext, op1, op2, op3 *ast.Term) (*ast.Term, error) {
+		var (
+			owner, repo string
+			number      int
+		)
+
+		if err := ast.As(op1.Value, &owner); err != nil {
+			return nil, err
+		} else if err := ast.As(op2.Value, &repo); err != nil {
+			return nil, err
+		} else if err := ast.As(op3.Value, &number); err != nil {
+			return nil, err
+		}
+
+		path := repoPath(owner, repo, "pulls", strconv.Itoa(number), "files")
+
+		items, status, err := githubGetPages(bctx.Context, client, path, "", 0)
+		if err != nil {
+			return nil, err
+		} else if status == http.StatusNotFound {
+			return nil, nil
+		} else if status != http.StatusOK {
+			return nil, fmt.Errorf("get pull request files: unexpected status %d", status)
+		}
+
+		files := make([]PRFile, 0, len(items))
+
+		for _, item := range items {
+			obj, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			files = append(files, newPRFile(obj))
+		}
+
+		val, err := ast.InterfaceToValue(files)
+		if err != nil {
+			return nil, err
+		}
+
+		return ast.NewTerm(val), nil
+	}
+}
+
+func newPRFile(obj map[string]interface{}) PRFile {
+	file := PRFile{Hunks: []Hunk{}}
+
+	file.Filename, _ = obj["filename"].(string)
+	file.Status, _ = obj["status"].(string)
+	file.Additions = jsonInt(obj["additions"])
+	file.Deletions = jsonInt(obj["deletions"])
+	file.Changes = jsonInt(obj["changes"])
+
+	if prev, ok := obj["previous_filename"].(string); ok {
+		file.PreviousFilename = &prev
+	}
+
+	if patch, ok := obj["patch"].(string); ok {
+		file.Patch = &patch
+		file.Hunks = parseHunks(patch)
+	} else if file.Changes > 0 {
+		file.Truncated = true
+	} else if file.Status != "renamed" {
+		file.Binary = true
+	}
+
+	return file
+}
+
+// parseHunks splits a unified diff patch in hunks.
+func parseHunks(patch string) []Hunk {
+	var (
+		hunks = []Hunk{}
+		curr  *Hunk
+	)
+
+	for _, line := range strings.Split(patch, "\n") {
+		if m := hunkHeaderRegex.FindStringSubmatch(line); m != nil {
+			hunks = append(hunks, Hunk{
+				Header:   m[5],
+				OldStart: atoiDefault(m[1], 0),
+				OldLines: atoiDefault(m[2], 1),
+				NewStart: atoiDefault(m[3], 0),
+				NewLines: atoiDefault(m[4], 1),
+				Lines:    []string{},
+			})
+			curr = &hunks[len(hunks)-1]
+
+			continue
+		}
+
+		if curr != nil && line != "" {
+			curr.Lines = append(curr.Lines, line)
+		}
+	}
+
+	return hunks
+}
+
+func atoiDefault(s string, def int) int {
+	if n, err := strconv.Atoi(s); err == nil {
+		return n
+	}
+
+	return def
+}
+
+// jsonInt returns the integer value of a
+// decoded JSON number, or 0 if it isn't one.
+func jsonInt(v interface{}) int {
+	if f, ok := v.(float64); ok {
+		return int(f)
+	}
+
+	return 0
+}
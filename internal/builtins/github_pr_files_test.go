@@ -0,0 +1,105 @@
+package builtins_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/reposaur/reposaur/internal/builtins"
+)
+
+const testPatch = "@@ -1,3 +1,4 @@ package main\n import \"fmt\"\n+import \"os\"\n \n func main() {\n@@ -10 +11,2 @@\n-\treturn\n+\tos.Exit(0)\n+\treturn"
+
+func newPRFilesStubClient(t *testing.T) *http.Client {
+	return newStubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/reposaur/reposaur/pulls/1/files" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		files := []map[string]interface{}{
+			{"filename": "main.go", "status": "modified", "additions": 3, "deletions": 1, "changes": 4, "patch": testPatch},
+			{"filename": "logo.png", "status": "added", "additions": 0, "deletions": 0, "changes": 0},
+		}
+
+		if r.URL.Query().Get("page") == "" {
+			w.Header().Set("Link", fmt.Sprintf(`<%s&page=2>; rel="next"`, r.URL.RequestURI()))
+		} else {
+			files = []map[string]interface{}{
+				{"filename": "big.json", "status": "modified", "additions": 20000, "deletions": 0, "changes": 20000},
+				{"filename": "new.go", "previous_filename": "old.go", "status": "renamed", "changes": 0},
+			}
+		}
+
+		if err := json.NewEncoder(w).Encode(files); err != nil {
+			t.Error(err)
+		}
+	}))
+}
+
+func TestGitHubPRFiles(t *testing.T) {
+	impl := builtins.GitHubPRFilesBuiltinImpl(newPRFilesStubClient(t))
+
+	term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm("reposaur"), ast.IntNumberTerm(1))
+	if err != nil {
+		t.Fatal(err)
+	} else if term == nil {
+		t.Fatal("expected pull request files")
+	}
+
+	var files []builtins.PRFile
+	if err := ast.As(term.Value, &files); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(files) != 4 {
+		t.Fatalf("expected the files of both pages, got %d", len(files))
+	}
+
+	goFile := files[0]
+	if goFile.Patch == nil || len(goFile.Hunks) != 2 {
+		t.Fatalf("expected main.go to have a patch with 2 hunks, got %+v", goFile)
+	}
+
+	first := goFile.Hunks[0]
+	if first.OldStart != 1 || first.OldLines != 3 || first.NewStart != 1 || first.NewLines != 4 || first.Header != "package main" {
+		t.Errorf("unexpected first hunk %+v", first)
+	}
+
+	if len(first.Lines) != 4 || first.Lines[1] != `+import "os"` {
+		t.Errorf("unexpected first hunk lines %q", first.Lines)
+	}
+
+	second := goFile.Hunks[1]
+	if second.OldStart != 10 || second.OldLines != 1 || second.NewStart != 11 || second.NewLines != 2 || len(second.Lines) != 3 {
+		t.Errorf("unexpected second hunk %+v", second)
+	}
+
+	if logo := files[1]; !logo.Binary || logo.Truncated || len(logo.Hunks) != 0 {
+		t.Errorf("expected logo.png to be binary, got %+v", logo)
+	}
+
+	if big := files[2]; big.Binary || !big.Truncated {
+		t.Errorf("expected big.json to be truncated, got %+v", big)
+	}
+
+	if renamed := files[3]; renamed.Binary || renamed.PreviousFilename == nil || *renamed.PreviousFilename != "old.go" {
+		t.Errorf("expected new.go to be renamed from old.go, got %+v", renamed)
+	}
+}
+
+func TestGitHubPRFilesNotFound(t *testing.T) {
+	impl := builtins.GitHubPRFilesBuiltinImpl(newPRFilesStubClient(t))
+
+	term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm("reposaur"), ast.IntNumberTerm(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if term != nil {
+		t.Errorf("expected undefined, got %v", term)
+	}
+}
@@ -0,0 +1,45 @@
+package builtins
+
+import (
+	"net/http"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/types"
+)
+
+var GitHubRefSHABuiltin = rego.Function{
+	Name: "github.ref_sha",
+	Decl: types.NewFunction(
+		types.Args(types.S, types.S, types.S),
+		types.S,
+	),
+	Memoize: true,
+}
+
+// GitHubRefSHABuiltinImpl resolves a ref of a repository (e.g. "v1.0.0",
+// "main" or "refs/tags/v1.0.0") to the SHA of the commit it points to.
+// Short refs are looked up as tags before branches and annotated tags
+// are dereferenced. Returns undefined if the ref doesn't exist.
+func GitHubRefSHABuiltinImpl(client *http.Client) func(bctx rego.BuiltinContext, op1, op2, op3 *ast.Term) (*ast.Term, error) {
+	return func(bctx rego.BuiltinContext, op1, op2, op3 *ast.Term) (*ast.Term, error) {
+		var owner, repo, ref string
+
+		if err := ast.As(op1.Value, &owner); err != nil {
+			return nil, err
+		} else if err := ast.As(op2.Value, &repo); err != nil {
+			return nil, err
+		} else if err := ast.As(op3.Value, &ref); err != nil {
+			return nil, err
+		}
+
+		sha, err := resolveRefSHA(bctx, client, owner, repo, ref)
+		if err != nil {
+			return nil, err
+		} else if sha == "" {
+			return nil, nil
+		}
+
+		return ast.StringTerm(sha), nil
+	}
+}
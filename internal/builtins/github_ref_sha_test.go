@@ -0,0 +1,47 @@
+package builtins_test
+
+import (
+	"testing"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/reposaur/reposaur/internal/builtins"
+)
+
+func TestGitHubRefSHA(t *testing.T) {
+	impl := builtins.GitHubRefSHABuiltinImpl(newStubClient(t, actionRefsHandler()))
+
+	cases := []struct {
+		ref      string
+		expected string
+	}{
+		{"v3", checkoutCommitSHA},
+		{"refs/tags/v3", checkoutCommitSHA},
+		{"main", checkoutMainSHA},
+		{"refs/heads/main", checkoutMainSHA},
+		{"refs/heads/v3", ""},
+		{"refs/tags/main", ""},
+		{"v99", ""},
+	}
+
+	for _, c := range cases {
+		t.Run(c.ref, func(t *testing.T) {
+			term, err := impl(rego.BuiltinContext{}, ast.StringTerm("actions"), ast.StringTerm("checkout"), ast.StringTerm(c.ref))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if c.expected == "" {
+				if term != nil {
+					t.Errorf("expected undefined, got %v", term)
+				}
+
+				return
+			}
+
+			if term == nil || term.Value.Compare(ast.String(c.expected)) != 0 {
+				t.Errorf("expected %s, got %v", c.expected, term)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package builtins
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/types"
+)
+
+var GitHubReleasesBuiltin = rego.Function{
+	Name: "github.releases",
+	Decl: types.NewFunction(
+		types.Args(types.S, types.S),
+		types.NewArray(nil, types.NewObject(nil, types.NewDynamicProperty(types.S, types.A))),
+	),
+	Memoize: true,
+}
+
+var GitHubTagsBuiltin = rego.Function{
+	Name: "github.tags",
+	Decl: types.NewFunction(
+		types.Args(types.S, types.S),
+		types.NewArray(nil, types.NewObject(nil, types.NewDynamicProperty(types.S, types.A))),
+	),
+	Memoize: true,
+}
+
+// Release is a normalized view of a release of a repository.
+type Release struct {
+	ID              int    `json:"id"`
+	TagName         string `json:"tag_name"`
+	Name            string `json:"name"`
+	TargetCommitish string `json:"target_commitish"`
+	Draft           bool   `json:"draft"`
+	Prerelease      bool   `json:"prerelease"`
+
+	// CreatedAt and PublishedAt are RFC3339 timestamps,
+	// PublishedAt is empty if the release is a draft.
+	CreatedAt   string `json:"created_at"`
+	PublishedAt string `json:"published_at"`
+
+	// Author is the login of the user who created the release.
+	Author string `json:"author"`
+	URL    string `json:"url"`
+	Assets int    `json:"assets"`
+}
+
+// Tag is a tag of a repository and the SHA of its commit.
+type Tag struct {
+	Name string `json:"name"`
+	SHA  string `json:"sha"`
+}
+
+type releaseResponse struct {
+	ID              int     `json:"id"`
+	TagName         string  `json:"tag_name"`
+	Name            *string `json:"name"`
+	TargetCommitish string  `json:"target_commitish"`
+	Draft           bool    `json:"draft"`
+	Prerelease      bool    `json:"prerelease"`
+	CreatedAt       string  `json:"created_at"`
+	PublishedAt     *string `json:"published_at"`
+	HTMLURL         string  `json:"html_url"`
+	Author          *struct {
+		Login string `json:"login"`
+	} `json:"author"`
+	Assets []interface{} `json:"assets"`
+}
+
+type tagResponse struct {
+	Name   string `json:"name"`
+	Commit struct {
+		SHA string `json:"sha"`
+	} `json:"commit"`
+}
+
+// GitHubReleasesBuiltinImpl fetches every release of a repository and
+// returns them normalized, most recently created first. Draft releases
+// are only listed with push access to the repository. Repositories
+// without releases have an empty list, and the result is undefined if
+// the repository doesn't exist.
+func GitHubReleasesBuiltinImpl(client *http.Client) func(bctx rego.BuiltinContext, op1, op2 *ast.Term) (*ast.Term, error) {
+	return func(bctx rego.BuiltinContext, op1, op2 *ast.Term) (*ast.Term, error) {
+		var owner, repo string
+
+		if err := ast.As(op1.Value, &owner); err != nil {
+			return nil, err
+		} else if err := ast.As(op2.Value, &repo); err != nil {
+			return nil, err
+		}
+
+		var resp []releaseResponse
+
+		ok, err := fetchRepoList(bctx, client, repoPath(owner, repo, "releases"), "releases", &resp)
+		if err != nil || !ok {
+			return nil, err
+		}
+
+		releases := make([]Release, 0, len(resp))
+
+		for _, r := range resp {
+			release := Release{
+				ID:              r.ID,
+				TagName:         r.TagName,
+				TargetCommitish: r.TargetCommitish,
+				Draft:           r.Draft,
+				Prerelease:      r.Prerelease,
+				CreatedAt:       r.CreatedAt,
+				URL:             r.HTMLURL,
+				Assets:          len(r.Assets),
+			}
+
+			if r.Name != nil {
+				release.Name = *r.Name
+			}
+
+			if r.PublishedAt != nil {
+				release.PublishedAt = *r.PublishedAt
+			}
+
+			if r.Author != nil {
+				release.Author = r.Author.Login
+			}
+
+			releases = append(releases, release)
+		}
+
+		val, err := ast.InterfaceToValue(releases)
+		if err != nil {
+			return nil, err
+		}
+
+		return ast.NewTerm(val), nil
+	}
+}
+
+// GitHubTagsBuiltinImpl fetches every tag of a repository with the SHA
+// of its commit, in the order returned by GitHub. Repositories without
+// tags have an empty list, and the result is undefined if the
+// repository doesn't exist.
+func GitHubTagsBuiltinImpl(client *http.Client) func(bctx rego.BuiltinContext, op1, op2 *ast.Term) (*ast.Term, error) {
+	return func(bctx rego.BuiltinContext, op1, op2 *ast.Term) (*ast.Term, error) {
+		var owner, repo string
+
+		if err := ast.As(op1.Value, &owner); err != nil {
+			return nil, err
+		} else if err := ast.As(op2.Value, &repo); err != nil {
+			return nil, err
+		}
+
+		var resp []tagResponse
+
+		ok, err := fetchRepoList(bctx, client, repoPath(owner, repo, "tags"), "tags", &resp)
+		if err != nil || !ok {
+			return nil, err
+		}
+
+		tags := make([]Tag, 0, len(resp))
+
+		for _, r := range resp {
+			tags = append(tags, Tag{Name: r.Name, SHA: r.Commit.SHA})
+		}
+
+		val, err := ast.InterfaceToValue(tags)
+		if err != nil {
+			return nil, err
+		}
+
+		return ast.NewTerm(val), nil
+	}
+}
+
+// fetchRepoList fetches every page of the list of a repository's
+// resource at path into v. Returns false if the repository doesn't
+// exist.
+func fetchRepoList(bctx rego.BuiltinContext, client *http.Client, path, name string, v interface{}) (bool, error) {
+	items, status, err := githubGetPages(bctx.Context, client, path, "", 0)
+	if err != nil {
+		return false, err
+	}
+
+	switch status {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, fmt.Errorf("get %s: unexpected status %d", name, status)
+	}
+
+	return true, decodeItems(items, v)
+}
@@ -0,0 +1,190 @@
+package builtins_test
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/reposaur/reposaur/internal/builtins"
+)
+
+const testReleasesFirstPage = `[
+	{
+		"id": 3,
+		"tag_name": "v1.1.0",
+		"name": null,
+		"target_commitish": "main",
+		"draft": true,
+		"prerelease": false,
+		"created_at": "2022-06-01T00:00:00Z",
+		"published_at": null,
+		"html_url": "https://github.com/reposaur/reposaur/releases/tag/untagged-1",
+		"author": {"login": "octocat"},
+		"assets": []
+	},
+	{
+		"id": 2,
+		"tag_name": "v1.0.0-rc.1",
+		"name": "v1.0.0 RC 1",
+		"target_commitish": "main",
+		"draft": false,
+		"prerelease": true,
+		"created_at": "2022-05-01T00:00:00Z",
+		"published_at": "2022-05-02T00:00:00Z",
+		"html_url": "https://github.com/reposaur/reposaur/releases/tag/v1.0.0-rc.1",
+		"author": {"login": "octocat"},
+		"assets": [{"id": 1}, {"id": 2}]
+	}
+]`
+
+const testReleasesSecondPage = `[
+	{
+		"id": 1,
+		"tag_name": "v0.1.0",
+		"name": "v0.1.0",
+		"target_commitish": "main",
+		"draft": false,
+		"prerelease": false,
+		"created_at": "2022-01-01T00:00:00Z",
+		"published_at": "2022-01-01T00:00:00Z",
+		"html_url": "https://github.com/reposaur/reposaur/releases/tag/v0.1.0",
+		"author": null,
+		"assets": []
+	}
+]`
+
+const testTags = `[
+	{"name": "v1.0.0-rc.1", "commit": {"sha": "aaa", "url": "https://api.github.com/repos/reposaur/reposaur/commits/aaa"}},
+	{"name": "v0.1.0", "commit": {"sha": "bbb", "url": "https://api.github.com/repos/reposaur/reposaur/commits/bbb"}}
+]`
+
+func newReleasesStubClient(t *testing.T) *http.Client {
+	return newStubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/reposaur/reposaur/releases":
+			if r.URL.Query().Get("page") == "" {
+				w.Header().Set("Link", fmt.Sprintf(`<%s&page=2>; rel="next"`, r.URL.RequestURI()))
+				_, _ = w.Write([]byte(testReleasesFirstPage))
+			} else {
+				_, _ = w.Write([]byte(testReleasesSecondPage))
+			}
+
+		case "/repos/reposaur/reposaur/tags":
+			_, _ = w.Write([]byte(testTags))
+
+		case "/repos/reposaur/empty/releases", "/repos/reposaur/empty/tags":
+			_, _ = w.Write([]byte(`[]`))
+
+		case "/repos/reposaur/broken/releases", "/repos/reposaur/broken/tags":
+			w.WriteHeader(http.StatusInternalServerError)
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestGitHubReleases(t *testing.T) {
+	impl := builtins.GitHubReleasesBuiltinImpl(newReleasesStubClient(t))
+
+	term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm("reposaur"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var releases []builtins.Release
+	if err := ast.As(term.Value, &releases); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []builtins.Release{
+		{
+			ID:              3,
+			TagName:         "v1.1.0",
+			TargetCommitish: "main",
+			Draft:           true,
+			CreatedAt:       "2022-06-01T00:00:00Z",
+			Author:          "octocat",
+			URL:             "https://github.com/reposaur/reposaur/releases/tag/untagged-1",
+		},
+		{
+			ID:              2,
+			TagName:         "v1.0.0-rc.1",
+			Name:            "v1.0.0 RC 1",
+			TargetCommitish: "main",
+			Prerelease:      true,
+			CreatedAt:       "2022-05-01T00:00:00Z",
+			PublishedAt:     "2022-05-02T00:00:00Z",
+			Author:          "octocat",
+			URL:             "https://github.com/reposaur/reposaur/releases/tag/v1.0.0-rc.1",
+			Assets:          2,
+		},
+		{
+			ID:              1,
+			TagName:         "v0.1.0",
+			Name:            "v0.1.0",
+			TargetCommitish: "main",
+			CreatedAt:       "2022-01-01T00:00:00Z",
+			PublishedAt:     "2022-01-01T00:00:00Z",
+			URL:             "https://github.com/reposaur/reposaur/releases/tag/v0.1.0",
+		},
+	}
+
+	if !reflect.DeepEqual(releases, expected) {
+		t.Errorf("expected %+v, got %+v", expected, releases)
+	}
+}
+
+func TestGitHubTags(t *testing.T) {
+	impl := builtins.GitHubTagsBuiltinImpl(newReleasesStubClient(t))
+
+	term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm("reposaur"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tags []builtins.Tag
+	if err := ast.As(term.Value, &tags); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []builtins.Tag{
+		{Name: "v1.0.0-rc.1", SHA: "aaa"},
+		{Name: "v0.1.0", SHA: "bbb"},
+	}
+
+	if !reflect.DeepEqual(tags, expected) {
+		t.Errorf("expected %+v, got %+v", expected, tags)
+	}
+}
+
+func TestGitHubReleasesAndTagsNone(t *testing.T) {
+	client := newReleasesStubClient(t)
+
+	impls := map[string]func(rego.BuiltinContext, *ast.Term, *ast.Term) (*ast.Term, error){
+		"releases": builtins.GitHubReleasesBuiltinImpl(client),
+		"tags":     builtins.GitHubTagsBuiltinImpl(client),
+	}
+
+	for name, impl := range impls {
+		term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm("empty"))
+		if err != nil {
+			t.Fatal(err)
+		} else if !term.Equal(ast.ArrayTerm()) {
+			t.Errorf("%s: expected an empty list, got %v", name, term)
+		}
+
+		if term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm("missing")); err != nil {
+			t.Fatal(err)
+		} else if term != nil {
+			t.Errorf("%s: expected undefined, got %v", name, term)
+		}
+
+		if _, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm("broken")); err == nil {
+			t.Errorf("%s: expected an unexpected status to fail", name)
+		}
+	}
+}
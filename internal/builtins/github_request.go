@@ -2,6 +2,7 @@ package builtins
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -9,12 +10,27 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/open-policy-agent/opa/ast"
 	"github.com/open-policy-agent/opa/rego"
 	"github.com/open-policy-agent/opa/types"
+	"github.com/reposaur/reposaur/pkg/cache"
+	"github.com/reposaur/reposaur/pkg/util"
 )
 
+// requestOptionsKey is the key of the data object holding
+// options for the request, e.g. {"request": {"query": true}}.
+const requestOptionsKey = "request"
+
+// requestOptions are the keys of the request options, other
+// keys of the request object are sent to GitHub as parameters.
+var requestOptions = []string{"query", "accept", "api_version"}
+
+// requestCacheKeyPrefix prefixes the client's identifier
+// and URL of requests to build their key in a shared cache.
+const requestCacheKeyPrefix = "github.request:"
+
 var GitHubRequestBuiltin = rego.Function{
 	Name: "github.request",
 	Decl: types.NewFunction(
@@ -38,6 +54,22 @@ func GitHubRequestBuiltinImpl(client *http.Client) func(bctx rego.BuiltinContext
 			return nil, err
 		}
 
+		// options for the request itself, not sent to GitHub
+		reqOpts, err := takeRequestOptions(data)
+		if err != nil {
+			return nil, err
+		}
+
+		accept, version := defaultAccept, contextOptions(bctx.Context).apiVersion
+
+		if v, ok := reqOpts["accept"].(string); ok {
+			accept = v
+		}
+
+		if v, ok := reqOpts["api_version"].(string); ok {
+			version = v
+		}
+
 		reqSlice := strings.Split(unparsedReq, " ")
 		method := reqSlice[0]
 		path := reqSlice[1]
@@ -62,7 +94,15 @@ func GitHubRequestBuiltinImpl(client *http.Client) func(bctx rego.BuiltinContext
 		qs := u.Query()
 		method = strings.ToUpper(method)
 
-		if method == http.MethodGet || method == http.MethodPost {
+		if err := checkMethodAllowed(bctx.Context, method); err != nil {
+			return nil, err
+		}
+
+		// only GET requests send the parameters in the query string,
+		// unless explicitly requested, others send them in the body
+		useQuery := method == http.MethodGet || reqOpts["query"] == true
+
+		if useQuery {
 			for k, v := range data {
 				v, err := parseValueToString(v)
 				if err != nil {
@@ -77,28 +117,65 @@ func GitHubRequestBuiltinImpl(client *http.Client) func(bctx rego.BuiltinContext
 		u.RawQuery = qs.Encode()
 
 		buf := &bytes.Buffer{}
-		enc := json.NewEncoder(buf)
-		enc.SetEscapeHTML(false)
-		if err := enc.Encode(data); err != nil {
-			return nil, err
+
+		if !useQuery {
+			enc := json.NewEncoder(buf)
+			enc.SetEscapeHTML(false)
+			if err := enc.Encode(data); err != nil {
+				return nil, err
+			}
+		}
+
+		// only successful GET responses are shared
+		// with other evaluations through the cache
+		c, useCache := cache.FromContext(bctx.Context)
+		useCache = useCache && method == http.MethodGet
+		cacheKey := requestCacheKeyPrefix + clientID(resolveClient(bctx.Context, client)) + " " + accept + " " + version + " " + u.String()
+
+		finalResp := GitHubResponse{}
+
+		if useCache {
+			// concurrent evaluations requesting the same URL wait
+			// for the first one, then use its cached response
+			unlock := inflightRequests.lock(cacheKey)
+			defer unlock()
+
+			cached, ok, err := c.Get(bctx.Context, cacheKey)
+			if err != nil {
+				return nil, fmt.Errorf("cache get: %w", err)
+			}
+
+			if ok {
+				if err := json.Unmarshal(cached, &finalResp); err != nil {
+					return nil, fmt.Errorf("cache get: %w", err)
+				}
+
+				return responseTerm(finalResp)
+			}
 		}
 
-		req, err := http.NewRequest(method, u.String(), buf)
+		ctx := bctx.Context
+		if ctx == nil {
+			ctx = context.Background()
+		}
+
+		req, err := http.NewRequestWithContext(ctx, method, u.String(), buf)
 		if err != nil {
 			return nil, err
 		}
 
 		req.Header.Set("User-Agent", "reposaur")
 		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", accept)
+		req.Header.Set("X-GitHub-Api-Version", version)
 
-		finalResp := GitHubResponse{}
-		resp, err := client.Do(req)
+		resp, err := contextClient(bctx.Context, client).Do(req)
 		if err != nil {
 			return nil, err
 		}
 		defer resp.Body.Close()
 
-		dec := json.NewDecoder(resp.Body)
+		dec := json.NewDecoder(limitResponse(bctx.Context, resp.Body))
 		if err := dec.Decode(&finalResp.Body); err != nil {
 			return nil, err
 		}
@@ -110,15 +187,127 @@ func GitHubRequestBuiltinImpl(client *http.Client) func(bctx rego.BuiltinContext
 			return nil, fmt.Errorf("forbidden: %s", b["message"])
 		}
 
-		val, err := ast.InterfaceToValue(finalResp)
-		if err != nil {
-			return nil, err
+		if useCache && resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
+			b, err := json.Marshal(finalResp)
+			if err != nil {
+				return nil, err
+			}
+
+			if err := c.Set(bctx.Context, cacheKey, b); err != nil {
+				return nil, fmt.Errorf("cache set: %w", err)
+			}
 		}
 
-		return ast.NewTerm(val), nil
+		return responseTerm(finalResp)
 	}
 }
 
+// checkMethodAllowed returns an error if ctx doesn't allow
+// requests with method (see util.NewAllowedMethodsContext).
+func checkMethodAllowed(ctx context.Context, method string) error {
+	allowed, ok := util.AllowedMethodsFromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	for _, m := range allowed {
+		if m == method {
+			return nil
+		}
+	}
+
+	if len(allowed) == 0 {
+		return fmt.Errorf("method %s isn't allowed: no methods are allowed", method)
+	}
+
+	return fmt.Errorf("method %s isn't allowed: must be one of %s", method, strings.Join(allowed, ", "))
+}
+
+// takeRequestOptions removes the request options from data and
+// returns them. The request object can also hold a parameter named
+// request, whose keys are kept, but a value that isn't an object
+// conflicts with the options.
+func takeRequestOptions(data map[string]interface{}) (map[string]interface{}, error) {
+	v, ok := data[requestOptionsKey]
+	if !ok {
+		return nil, nil
+	}
+
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%q parameter conflicts with the request options: must be an object", requestOptionsKey)
+	}
+
+	opts := map[string]interface{}{}
+
+	for _, k := range requestOptions {
+		if o, ok := obj[k]; ok {
+			opts[k] = o
+			delete(obj, k)
+		}
+	}
+
+	if len(obj) == 0 {
+		delete(data, requestOptionsKey)
+	}
+
+	return opts, nil
+}
+
+// inflightRequests serializes cached requests by their
+// cache key, so a URL is requested once by concurrent
+// evaluations sharing a cache.
+var inflightRequests = &keyedMutex{locks: map[string]*keyedLock{}}
+
+// keyedMutex is a set of mutexes by key, which are
+// removed when they're no longer used.
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*keyedLock
+}
+
+type keyedLock struct {
+	sync.Mutex
+	refs int
+}
+
+// lock locks the mutex of key and returns
+// the function unlocking it.
+func (m *keyedMutex) lock(key string) func() {
+	m.mu.Lock()
+
+	l, ok := m.locks[key]
+	if !ok {
+		l = &keyedLock{}
+		m.locks[key] = l
+	}
+
+	l.refs++
+	m.mu.Unlock()
+
+	l.Lock()
+
+	return func() {
+		l.Unlock()
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		if l.refs--; l.refs == 0 {
+			delete(m.locks, key)
+		}
+	}
+}
+
+func responseTerm(resp GitHubResponse) (*ast.Term, error) {
+	val, err := ast.InterfaceToValue(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return ast.NewTerm(val), nil
+}
+
 func parseValueToString(v interface{}) (string, error) {
 	switch tv := v.(type) {
 	case string:
@@ -0,0 +1,410 @@
+package builtins_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/reposaur/reposaur/internal/builtins"
+	"github.com/reposaur/reposaur/pkg/cache"
+	"github.com/reposaur/reposaur/pkg/util"
+	"github.com/rs/zerolog"
+)
+
+type recordedRequest struct {
+	method string
+	path   string
+	query  map[string][]string
+	body   string
+}
+
+func newRecordingClient(t *testing.T, rec *recordedRequest) *http.Client {
+	return newStubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+
+		*rec = recordedRequest{
+			method: r.Method,
+			path:   r.URL.Path,
+			query:  r.URL.Query(),
+			body:   string(b),
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true})
+	}))
+}
+
+func TestGitHubRequestPostBodyIsJSON(t *testing.T) {
+	rec := &recordedRequest{}
+	impl := builtins.GitHubRequestBuiltinImpl(newRecordingClient(t, rec))
+
+	_, err := impl(
+		rego.BuiltinContext{},
+		ast.StringTerm("POST /repos/{owner}/{repo}/issues"),
+		objectTerm(t, map[string]interface{}{
+			"owner": "reposaur",
+			"repo":  "test",
+			"title": "Some title",
+			"body":  "Some body",
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rec.path != "/repos/reposaur/test/issues" {
+		t.Errorf("expected path to be /repos/reposaur/test/issues, got %s", rec.path)
+	}
+
+	if len(rec.query) != 0 {
+		t.Errorf("expected no query parameters, got %v", rec.query)
+	}
+
+	var body map[string]interface{}
+	if err := json.Unmarshal([]byte(rec.body), &body); err != nil {
+		t.Fatalf("expected body to be JSON: %s", err)
+	}
+
+	if body["title"] != "Some title" || body["body"] != "Some body" {
+		t.Errorf("expected title and body in JSON body, got %v", body)
+	}
+}
+
+func TestGitHubRequestPostWithQueryOption(t *testing.T) {
+	rec := &recordedRequest{}
+	impl := builtins.GitHubRequestBuiltinImpl(newRecordingClient(t, rec))
+
+	_, err := impl(
+		rego.BuiltinContext{},
+		ast.StringTerm("POST /markdown/raw"),
+		objectTerm(t, map[string]interface{}{
+			"mode":    "gfm",
+			"request": map[string]interface{}{"query": true},
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := rec.query["mode"]; len(got) != 1 || got[0] != "gfm" {
+		t.Errorf("expected mode in query string, got %v", rec.query)
+	}
+
+	if _, ok := rec.query["request"]; ok {
+		t.Error("expected request options not to be sent")
+	}
+}
+
+func TestGitHubRequestParameterNamedRequest(t *testing.T) {
+	rec := &recordedRequest{}
+	impl := builtins.GitHubRequestBuiltinImpl(newRecordingClient(t, rec))
+
+	// other keys than the request options are kept as the parameter
+	_, err := impl(
+		rego.BuiltinContext{},
+		ast.StringTerm("POST /repos/reposaur/test/dispatches"),
+		objectTerm(t, map[string]interface{}{
+			"event_type": "check",
+			"request":    map[string]interface{}{"api_version": "2026-01-01", "id": "1"},
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var body map[string]interface{}
+	if err := json.Unmarshal([]byte(rec.body), &body); err != nil {
+		t.Fatalf("expected body to be JSON: %s", err)
+	}
+
+	if req, ok := body["request"].(map[string]interface{}); !ok || len(req) != 1 || req["id"] != "1" {
+		t.Errorf("expected the request parameter without the options, got %v", body["request"])
+	}
+
+	// values that aren't objects conflict with the options
+	_, err = impl(
+		rego.BuiltinContext{},
+		ast.StringTerm("POST /repos/reposaur/test/dispatches"),
+		objectTerm(t, map[string]interface{}{"request": "value"}),
+	)
+	if err == nil || !strings.Contains(err.Error(), "conflicts with the request options") {
+		t.Errorf("expected a conflict error, got %v", err)
+	}
+}
+
+func TestGitHubRequestGetUsesQuery(t *testing.T) {
+	rec := &recordedRequest{}
+	impl := builtins.GitHubRequestBuiltinImpl(newRecordingClient(t, rec))
+
+	_, err := impl(
+		rego.BuiltinContext{},
+		ast.StringTerm("GET /orgs/{org}/repos"),
+		objectTerm(t, map[string]interface{}{
+			"org":      "reposaur",
+			"per_page": 100,
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := rec.query["per_page"]; len(got) != 1 || got[0] != "100" {
+		t.Errorf("expected per_page in query string, got %v", rec.query)
+	}
+
+	if rec.body != "" {
+		t.Errorf("expected empty body, got %s", rec.body)
+	}
+}
+
+func TestGitHubRequestUsesCache(t *testing.T) {
+	var calls int
+
+	client := newStubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"login": "reposaur"})
+	}))
+
+	impl := builtins.GitHubRequestBuiltinImpl(client)
+	bctx := rego.BuiltinContext{
+		Context: cache.NewContext(context.Background(), cache.NewMemory(0)),
+	}
+
+	for i := 0; i < 2; i++ {
+		resp, err := impl(
+			bctx,
+			ast.StringTerm("GET /orgs/{org}"),
+			objectTerm(t, map[string]interface{}{"org": "reposaur"}),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		login := resp.Get(ast.StringTerm("body")).Get(ast.StringTerm("login"))
+		if login == nil || !login.Equal(ast.StringTerm("reposaur")) {
+			t.Errorf("expected cached response to be returned, got %v", resp)
+		}
+	}
+
+	if calls != 1 {
+		t.Errorf("expected 1 request, got %d", calls)
+	}
+}
+
+func TestGitHubRequestCacheIsPerClient(t *testing.T) {
+	newClient := func(login string) *http.Client {
+		return newStubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"login": login})
+		}))
+	}
+
+	impl := builtins.GitHubRequestBuiltinImpl(newClient("default"))
+	ctx := cache.NewContext(context.Background(), cache.NewMemory(0))
+
+	for _, login := range []string{"default", "engine"} {
+		bctx := rego.BuiltinContext{Context: ctx}
+		if login != "default" {
+			bctx.Context = util.NewClientContext(ctx, newClient(login))
+		}
+
+		resp, err := impl(bctx, ast.StringTerm("GET /user"), objectTerm(t, map[string]interface{}{}))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if actual := resp.Get(ast.StringTerm("body")).Get(ast.StringTerm("login")); !actual.Equal(ast.StringTerm(login)) {
+			t.Errorf("expected the response of the %s client, got %v", login, actual)
+		}
+	}
+}
+
+func TestGitHubRequestDoesNotCacheErrors(t *testing.T) {
+	var calls int
+
+	client := newStubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"message": "Not Found"})
+	}))
+
+	impl := builtins.GitHubRequestBuiltinImpl(client)
+	bctx := rego.BuiltinContext{
+		Context: cache.NewContext(context.Background(), cache.NewMemory(0)),
+	}
+
+	for i := 0; i < 2; i++ {
+		_, err := impl(
+			bctx,
+			ast.StringTerm("GET /orgs/{org}"),
+			objectTerm(t, map[string]interface{}{"org": "reposaur"}),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if calls != 2 {
+		t.Errorf("expected 2 requests, got %d", calls)
+	}
+}
+
+func TestGitHubRequestSendsAPIVersion(t *testing.T) {
+	var headers []http.Header
+
+	client := newStubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = append(headers, r.Header.Clone())
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{})
+	}))
+
+	impl := builtins.GitHubRequestBuiltinImpl(client)
+
+	reqs := []map[string]interface{}{
+		{"org": "reposaur"},
+		{"org": "reposaur", "request": map[string]interface{}{
+			"accept":      "application/vnd.github.raw",
+			"api_version": "2026-01-01",
+		}},
+	}
+
+	for _, data := range reqs {
+		if _, err := impl(rego.BuiltinContext{}, ast.StringTerm("GET /orgs/{org}"), objectTerm(t, data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cases := []struct {
+		accept  string
+		version string
+	}{
+		{"application/vnd.github+json", builtins.DefaultAPIVersion},
+		{"application/vnd.github.raw", "2026-01-01"},
+	}
+
+	for i, c := range cases {
+		if got := headers[i].Get("Accept"); got != c.accept {
+			t.Errorf("request %d: expected Accept to be %s, got '%s'", i, c.accept, got)
+		}
+
+		if got := headers[i].Get("X-GitHub-Api-Version"); got != c.version {
+			t.Errorf("request %d: expected X-GitHub-Api-Version to be %s, got '%s'", i, c.version, got)
+		}
+	}
+}
+
+func TestGitHubRequestMaxResponseSize(t *testing.T) {
+	client := newStubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := `{"login": "reposaur"}`
+		if r.URL.Path == "/orgs/huge" {
+			body = `{"login": "` + strings.Repeat("a", 1024) + `"}`
+		}
+
+		_, _ = w.Write([]byte(body))
+	}))
+
+	impl := builtins.GitHubRequestBuiltinImpl(client)
+	bctx := rego.BuiltinContext{Context: builtins.NewOptionsContext(context.Background(), builtins.WithMaxResponseSize(64))}
+
+	if _, err := impl(bctx, ast.StringTerm("GET /orgs/reposaur"), objectTerm(t, nil)); err != nil {
+		t.Errorf("expected a response under the limit to be read, got %s", err)
+	}
+
+	_, err := impl(bctx, ast.StringTerm("GET /orgs/huge"), objectTerm(t, nil))
+	if !errors.Is(err, builtins.ErrResponseTooLarge) {
+		t.Errorf("expected ErrResponseTooLarge, got %v", err)
+	}
+}
+
+func TestGitHubRequestContextClient(t *testing.T) {
+	registered := newStubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"login": "registered"}`))
+	}))
+
+	engine := newStubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"login": "engine"}`))
+	}))
+
+	impl := builtins.GitHubRequestBuiltinImpl(registered)
+	bctx := rego.BuiltinContext{Context: util.NewClientContext(context.Background(), engine)}
+
+	term, err := impl(bctx, ast.StringTerm("GET /orgs/reposaur"), objectTerm(t, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	login := term.Get(ast.StringTerm("body")).Get(ast.StringTerm("login"))
+	if !login.Equal(ast.StringTerm("engine")) {
+		t.Errorf("expected the context's client to be used, got %v", login)
+	}
+}
+
+func TestGitHubRequestAllowedMethods(t *testing.T) {
+	var requests []string
+
+	client := newStubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+
+	impl := builtins.GitHubRequestBuiltinImpl(client)
+	ctx := util.NewAllowedMethodsContext(context.Background(), []string{"get", "head"})
+
+	if _, err := impl(rego.BuiltinContext{Context: ctx}, ast.StringTerm("get /orgs/reposaur"), objectTerm(t, nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := impl(rego.BuiltinContext{Context: ctx}, ast.StringTerm("POST /repos/reposaur/test/issues"), objectTerm(t, map[string]interface{}{"title": "Test"}))
+	if err == nil || !strings.Contains(err.Error(), "method POST isn't allowed: must be one of GET, HEAD") {
+		t.Errorf("expected POST not to be allowed, got %v", err)
+	}
+
+	if len(requests) != 1 || requests[0] != http.MethodGet {
+		t.Errorf("expected only the GET request to be done, got %v", requests)
+	}
+}
+
+func TestGitHubRequestCancelledWhileThrottled(t *testing.T) {
+	var calls int64
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&calls, 1)
+
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	t.Cleanup(srv.Close)
+
+	t.Setenv("GITHUB_HOST", strings.TrimPrefix(srv.URL, "https://"))
+
+	trustServer := func(tr *http.Transport) {
+		tr.TLSClientConfig = srv.Client().Transport.(*http.Transport).TLSClientConfig
+	}
+
+	client := util.NewTokenHTTPClient(context.Background(), zerolog.Nop(), "token", trustServer)
+	impl := builtins.GitHubRequestBuiltinImpl(client)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	started := time.Now()
+
+	_, err := impl(rego.BuiltinContext{Context: ctx}, ast.StringTerm("GET /orgs/reposaur"), objectTerm(t, nil))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the throttled request to be cancelled, got %v", err)
+	}
+
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Errorf("expected the request to stop when cancelled, took %s", elapsed)
+	}
+
+	if calls := atomic.LoadInt64(&calls); calls != 1 {
+		t.Errorf("expected 1 request, got %d", calls)
+	}
+}
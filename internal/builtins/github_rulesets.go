@@ -0,0 +1,265 @@
+package builtins
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/types"
+)
+
+var GitHubRulesetsBuiltin = rego.Function{
+	Name: "github.rulesets",
+	Decl: types.NewFunction(
+		types.Args(types.S, types.S),
+		types.NewArray(nil, types.NewObject(nil, types.NewDynamicProperty(types.S, types.A))),
+	),
+	Memoize: true,
+}
+
+var GitHubOrgRulesetsBuiltin = rego.Function{
+	Name: "github.org_rulesets",
+	Decl: types.NewFunction(
+		types.Args(types.S),
+		types.NewArray(nil, types.NewObject(nil, types.NewDynamicProperty(types.S, types.A))),
+	),
+	Memoize: true,
+}
+
+// Ruleset is a normalized view of a repository
+// or organization ruleset.
+type Ruleset struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+
+	// Target is what the ruleset applies
+	// to: branch, tag or push.
+	Target string `json:"target"`
+
+	// Enforcement is either active, evaluate or disabled.
+	Enforcement string `json:"enforcement"`
+
+	// Source is the repository (owner/name) or organization
+	// that defines the ruleset, of SourceType Repository or
+	// Organization.
+	Source     string `json:"source"`
+	SourceType string `json:"source_type"`
+
+	// Include and Exclude are the ref name patterns
+	// (e.g. ~DEFAULT_BRANCH or refs/heads/release/*)
+	// the ruleset applies to.
+	Include []string `json:"include"`
+	Exclude []string `json:"exclude"`
+
+	// Conditions are the ruleset's conditions as returned
+	// by GitHub, e.g. to select repositories by name.
+	Conditions map[string]interface{} `json:"conditions"`
+
+	Rules        []RulesetRule        `json:"rules"`
+	BypassActors []RulesetBypassActor `json:"bypass_actors"`
+}
+
+// RulesetRule is a rule of a ruleset, e.g. pull_request
+// or required_status_checks, with its parameters.
+type RulesetRule struct {
+	Type       string                 `json:"type"`
+	Parameters map[string]interface{} `json:"parameters"`
+}
+
+// RulesetBypassActor is an actor that can bypass a ruleset.
+type RulesetBypassActor struct {
+	// ActorID is nil for actors without an ID, e.g. OrganizationAdmin.
+	ActorID   *int   `json:"actor_id"`
+	ActorType string `json:"actor_type"`
+
+	// BypassMode is either always or pull_request.
+	BypassMode string `json:"bypass_mode"`
+}
+
+type rulesetResponse struct {
+	ID          int                    `json:"id"`
+	Name        string                 `json:"name"`
+	Target      string                 `json:"target"`
+	Enforcement string                 `json:"enforcement"`
+	Source      string                 `json:"source"`
+	SourceType  string                 `json:"source_type"`
+	Conditions  map[string]interface{} `json:"conditions"`
+	Rules       []struct {
+		Type       string                 `json:"type"`
+		Parameters map[string]interface{} `json:"parameters"`
+	} `json:"rules"`
+	BypassActors []RulesetBypassActor `json:"bypass_actors"`
+}
+
+// GitHubRulesetsBuiltinImpl fetches the rulesets that apply to a
+// repository, including the ones defined by its organization, and
+// returns them normalized. Repositories without rulesets have an
+// empty list. Returns undefined if the repository doesn't exist.
+func GitHubRulesetsBuiltinImpl(client *http.Client) func(bctx rego.BuiltinContext, op1, op2 *ast.Term) (*ast.Term, error) {
+	return func(bctx rego.BuiltinContext, op1, op2 *ast.Term) (*ast.Term, error) {
+		var owner, repo string
+
+		if err := ast.As(op1.Value, &owner); err != nil {
+			return nil, err
+		} else if err := ast.As(op2.Value, &repo); err != nil {
+			return nil, err
+		}
+
+		rulesets, status, err := fetchRulesets(bctx, client, repoPath(owner, repo, "rulesets"))
+		if err != nil {
+			return nil, err
+		}
+
+		switch status {
+		case http.StatusOK:
+		case http.StatusNotFound:
+			// rulesets aren't available in
+			// private repositories of some plans
+			exists, err := repositoryExists(bctx, client, owner, repo)
+			if err != nil {
+				return nil, err
+			} else if !exists {
+				return nil, nil
+			}
+
+			rulesets = []Ruleset{}
+		default:
+			return nil, fmt.Errorf("get rulesets: unexpected status %d", status)
+		}
+
+		val, err := ast.InterfaceToValue(rulesets)
+		if err != nil {
+			return nil, err
+		}
+
+		return ast.NewTerm(val), nil
+	}
+}
+
+// GitHubOrgRulesetsBuiltinImpl fetches the rulesets defined by an
+// organization and returns them normalized. Organizations without
+// rulesets have an empty list. Returns undefined if the organization
+// doesn't exist.
+func GitHubOrgRulesetsBuiltinImpl(client *http.Client) func(bctx rego.BuiltinContext, op1 *ast.Term) (*ast.Term, error) {
+	return func(bctx rego.BuiltinContext, op1 *ast.Term) (*ast.Term, error) {
+		var org string
+
+		if err := ast.As(op1.Value, &org); err != nil {
+			return nil, err
+		}
+
+		rulesets, status, err := fetchRulesets(bctx, client, "/orgs/"+url.PathEscape(org)+"/rulesets")
+		if err != nil {
+			return nil, err
+		} else if status == http.StatusNotFound {
+			return nil, nil
+		} else if status != http.StatusOK {
+			return nil, fmt.Errorf("get organization rulesets: unexpected status %d", status)
+		}
+
+		val, err := ast.InterfaceToValue(rulesets)
+		if err != nil {
+			return nil, err
+		}
+
+		return ast.NewTerm(val), nil
+	}
+}
+
+// fetchRulesets lists the rulesets at path and fetches each
+// of them, as the list doesn't include their conditions and
+// rules. If a request is unsuccessful, the rulesets are nil
+// and its status code is returned.
+func fetchRulesets(bctx rego.BuiltinContext, client *http.Client, path string) ([]Ruleset, int, error) {
+	items, status, err := githubGetPages(bctx.Context, client, path, "", 0)
+	if err != nil || status != http.StatusOK {
+		return nil, status, err
+	}
+
+	var list []struct {
+		ID int `json:"id"`
+	}
+
+	if err := decodeItems(items, &list); err != nil {
+		return nil, 0, err
+	}
+
+	rulesets := make([]Ruleset, 0, len(list))
+
+	for _, l := range list {
+		var resp rulesetResponse
+
+		status, err := githubGet(bctx.Context, client, path+"/"+strconv.Itoa(l.ID), &resp)
+		if err != nil || status != http.StatusOK {
+			return nil, status, err
+		}
+
+		rulesets = append(rulesets, normalizeRuleset(resp))
+	}
+
+	return rulesets, http.StatusOK, nil
+}
+
+func normalizeRuleset(resp rulesetResponse) Ruleset {
+	rs := Ruleset{
+		ID:           resp.ID,
+		Name:         resp.Name,
+		Target:       resp.Target,
+		Enforcement:  resp.Enforcement,
+		Source:       resp.Source,
+		SourceType:   resp.SourceType,
+		Include:      []string{},
+		Exclude:      []string{},
+		Conditions:   resp.Conditions,
+		Rules:        make([]RulesetRule, 0, len(resp.Rules)),
+		BypassActors: resp.BypassActors,
+	}
+
+	// rulesets created before tag
+	// rulesets existed have no target
+	if rs.Target == "" {
+		rs.Target = "branch"
+	}
+
+	if rs.Conditions == nil {
+		rs.Conditions = map[string]interface{}{}
+	}
+
+	if rs.BypassActors == nil {
+		rs.BypassActors = []RulesetBypassActor{}
+	}
+
+	if refName, ok := rs.Conditions["ref_name"].(map[string]interface{}); ok {
+		rs.Include = stringSlice(refName["include"])
+		rs.Exclude = stringSlice(refName["exclude"])
+	}
+
+	for _, r := range resp.Rules {
+		params := r.Parameters
+		if params == nil {
+			params = map[string]interface{}{}
+		}
+
+		rs.Rules = append(rs.Rules, RulesetRule{Type: r.Type, Parameters: params})
+	}
+
+	return rs
+}
+
+// stringSlice returns the strings in v, which is
+// expected to be a decoded JSON array of strings.
+func stringSlice(v interface{}) []string {
+	items, _ := v.([]interface{})
+	s := make([]string, 0, len(items))
+
+	for _, item := range items {
+		if str, ok := item.(string); ok {
+			s = append(s, str)
+		}
+	}
+
+	return s
+}
@@ -0,0 +1,173 @@
+package builtins_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/reposaur/reposaur/internal/builtins"
+)
+
+const testRuleset = `{
+	"id": 42,
+	"name": "main",
+	"target": "branch",
+	"source_type": "Repository",
+	"source": "reposaur/reposaur",
+	"enforcement": "active",
+	"conditions": {
+		"ref_name": {"include": ["~DEFAULT_BRANCH"], "exclude": ["refs/heads/dev/*"]}
+	},
+	"rules": [
+		{"type": "deletion"},
+		{"type": "pull_request", "parameters": {"required_approving_review_count": 2}}
+	],
+	"bypass_actors": [
+		{"actor_id": null, "actor_type": "OrganizationAdmin", "bypass_mode": "always"},
+		{"actor_id": 7, "actor_type": "Team", "bypass_mode": "pull_request"}
+	]
+}`
+
+const testOrgRuleset = `{
+	"id": 7,
+	"name": "tags",
+	"source_type": "Organization",
+	"source": "reposaur",
+	"enforcement": "evaluate",
+	"rules": [{"type": "creation"}]
+}`
+
+func newRulesetsStubClient(t *testing.T) *http.Client {
+	return newStubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/reposaur/reposaur/rulesets":
+			_, _ = w.Write([]byte(`[{"id": 42, "name": "main"}]`))
+
+		case "/repos/reposaur/reposaur/rulesets/42":
+			_, _ = w.Write([]byte(testRuleset))
+
+		case "/repos/reposaur/empty/rulesets", "/orgs/empty/rulesets":
+			_, _ = w.Write([]byte(`[]`))
+
+		case "/orgs/reposaur/rulesets":
+			_, _ = w.Write([]byte(`[{"id": 7, "name": "tags"}]`))
+
+		case "/orgs/reposaur/rulesets/7":
+			_, _ = w.Write([]byte(testOrgRuleset))
+
+		case "/repos/reposaur/private":
+			_, _ = w.Write([]byte(`{"name": "private"}`))
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestGitHubRulesets(t *testing.T) {
+	impl := builtins.GitHubRulesetsBuiltinImpl(newRulesetsStubClient(t))
+
+	term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm("reposaur"))
+	if err != nil {
+		t.Fatal(err)
+	} else if term == nil {
+		t.Fatal("expected rulesets")
+	}
+
+	var rulesets []builtins.Ruleset
+	if err := ast.As(term.Value, &rulesets); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rulesets) != 1 {
+		t.Fatalf("expected 1 ruleset, got %d", len(rulesets))
+	}
+
+	rs := rulesets[0]
+
+	if rs.Target != "branch" || rs.Enforcement != "active" || rs.SourceType != "Repository" {
+		t.Errorf("unexpected ruleset: %+v", rs)
+	}
+
+	if len(rs.Include) != 1 || rs.Include[0] != "~DEFAULT_BRANCH" || len(rs.Exclude) != 1 {
+		t.Errorf("expected the ref name conditions, got %v and %v", rs.Include, rs.Exclude)
+	}
+
+	if len(rs.Rules) != 2 || rs.Rules[1].Type != "pull_request" || fmt.Sprint(rs.Rules[1].Parameters["required_approving_review_count"]) != "2" {
+		t.Errorf("unexpected rules: %+v", rs.Rules)
+	}
+
+	if len(rs.BypassActors) != 2 || rs.BypassActors[0].ActorID != nil || *rs.BypassActors[1].ActorID != 7 {
+		t.Errorf("unexpected bypass actors: %+v", rs.BypassActors)
+	}
+}
+
+func TestGitHubRulesetsEmpty(t *testing.T) {
+	impl := builtins.GitHubRulesetsBuiltinImpl(newRulesetsStubClient(t))
+
+	cases := []struct {
+		repo      string
+		undefined bool
+	}{
+		{"empty", false},
+		{"private", false},
+		{"missing", true},
+	}
+
+	for _, c := range cases {
+		term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm(c.repo))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if c.undefined {
+			if term != nil {
+				t.Errorf("%s: expected undefined, got %v", c.repo, term)
+			}
+
+			continue
+		}
+
+		if term == nil || term.Value.Compare(ast.NewArray()) != 0 {
+			t.Errorf("%s: expected an empty list, got %v", c.repo, term)
+		}
+	}
+}
+
+func TestGitHubOrgRulesets(t *testing.T) {
+	impl := builtins.GitHubOrgRulesetsBuiltinImpl(newRulesetsStubClient(t))
+
+	term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var rulesets []builtins.Ruleset
+	if err := ast.As(term.Value, &rulesets); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rulesets) != 1 || rulesets[0].Target != "branch" || rulesets[0].Enforcement != "evaluate" {
+		t.Errorf("unexpected rulesets: %+v", rulesets)
+	}
+
+	if len(rulesets[0].BypassActors) != 0 || len(rulesets[0].Include) != 0 {
+		t.Errorf("expected empty bypass actors and conditions, got %+v", rulesets[0])
+	}
+
+	term, err = impl(rego.BuiltinContext{}, ast.StringTerm("empty"))
+	if err != nil {
+		t.Fatal(err)
+	} else if term == nil || term.Value.Compare(ast.NewArray()) != 0 {
+		t.Errorf("expected an empty list, got %v", term)
+	}
+
+	term, err = impl(rego.BuiltinContext{}, ast.StringTerm("missing"))
+	if err != nil {
+		t.Fatal(err)
+	} else if term != nil {
+		t.Errorf("expected undefined, got %v", term)
+	}
+}
@@ -0,0 +1,235 @@
+package builtins
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/types"
+)
+
+// purlRefType is the type of the SBOM package external
+// references that hold their Package URL.
+const purlRefType = "purl"
+
+var GitHubSBOMBuiltin = rego.Function{
+	Name: "github.sbom",
+	Decl: types.NewFunction(
+		types.Args(types.S, types.S),
+		types.NewObject(nil, types.NewDynamicProperty(types.S, types.A)),
+	),
+	Memoize: true,
+}
+
+var GitHubDependenciesBuiltin = rego.Function{
+	Name: "github.dependencies",
+	Decl: types.NewFunction(
+		types.Args(types.S, types.S),
+		types.NewArray(nil, types.NewObject(nil, types.NewDynamicProperty(types.S, types.A))),
+	),
+	Memoize: true,
+}
+
+// Dependency is a package of the dependency graph of a repository.
+// Ecosystem is the type of its Package URL (e.g. npm, golang, pypi).
+// License is empty if it wasn't identified.
+type Dependency struct {
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	Ecosystem string `json:"ecosystem"`
+	PURL      string `json:"purl"`
+	License   string `json:"license"`
+}
+
+type sbomResponse struct {
+	SBOM json.RawMessage `json:"sbom"`
+}
+
+type sbomPackage struct {
+	SPDXID           string `json:"SPDXID"`
+	Name             string `json:"name"`
+	VersionInfo      string `json:"versionInfo"`
+	LicenseConcluded string `json:"licenseConcluded"`
+	LicenseDeclared  string `json:"licenseDeclared"`
+	ExternalRefs     []struct {
+		ReferenceType    string `json:"referenceType"`
+		ReferenceLocator string `json:"referenceLocator"`
+	} `json:"externalRefs"`
+}
+
+type sbomRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+// GitHubSBOMBuiltinImpl fetches the SPDX SBOM of a repository exported
+// from its dependency graph, as is. Returns undefined if the dependency
+// graph is disabled or the repository doesn't exist.
+func GitHubSBOMBuiltinImpl(client *http.Client) func(bctx rego.BuiltinContext, op1, op2 *ast.Term) (*ast.Term, error) {
+	return func(bctx rego.BuiltinContext, op1, op2 *ast.Term) (*ast.Term, error) {
+		var owner, repo string
+
+		if err := ast.As(op1.Value, &owner); err != nil {
+			return nil, err
+		} else if err := ast.As(op2.Value, &repo); err != nil {
+			return nil, err
+		}
+
+		sbom, ok, err := fetchSBOM(bctx.Context, client, owner, repo)
+		if err != nil || !ok {
+			return nil, err
+		}
+
+		var doc interface{}
+		if err := json.Unmarshal(sbom, &doc); err != nil {
+			return nil, fmt.Errorf("decode sbom: %w", err)
+		}
+
+		val, err := ast.InterfaceToValue(doc)
+		if err != nil {
+			return nil, err
+		}
+
+		return ast.NewTerm(val), nil
+	}
+}
+
+// GitHubDependenciesBuiltinImpl summarizes the packages of the dependency
+// graph of a repository, found in its manifests, sorted by ecosystem and
+// name. The repository itself isn't included. Returns undefined if the
+// dependency graph is disabled or the repository doesn't exist.
+func GitHubDependenciesBuiltinImpl(client *http.Client) func(bctx rego.BuiltinContext, op1, op2 *ast.Term) (*ast.Term, error) {
+	return func(bctx rego.BuiltinContext, op1, op2 *ast.Term) (*ast.Term, error) {
+		var owner, repo string
+
+		if err := ast.As(op1.Value, &owner); err != nil {
+			return nil, err
+		} else if err := ast.As(op2.Value, &repo); err != nil {
+			return nil, err
+		}
+
+		sbom, ok, err := fetchSBOM(bctx.Context, client, owner, repo)
+		if err != nil || !ok {
+			return nil, err
+		}
+
+		var doc struct {
+			Packages      []sbomPackage      `json:"packages"`
+			Relationships []sbomRelationship `json:"relationships"`
+		}
+
+		if err := json.Unmarshal(sbom, &doc); err != nil {
+			return nil, fmt.Errorf("decode sbom: %w", err)
+		}
+
+		// the packages the document describes
+		// are the repository, not dependencies
+		described := map[string]bool{}
+
+		for _, r := range doc.Relationships {
+			if r.RelationshipType == "DESCRIBES" {
+				described[r.RelatedSPDXElement] = true
+			}
+		}
+
+		deps := []Dependency{}
+
+		for _, p := range doc.Packages {
+			if described[p.SPDXID] {
+				continue
+			}
+
+			dep := Dependency{
+				Name:    p.Name,
+				Version: p.VersionInfo,
+				License: sbomLicense(p.LicenseConcluded, p.LicenseDeclared),
+			}
+
+			for _, ref := range p.ExternalRefs {
+				if ref.ReferenceType == purlRefType {
+					dep.PURL = ref.ReferenceLocator
+					dep.Ecosystem = purlType(ref.ReferenceLocator)
+					break
+				}
+			}
+
+			deps = append(deps, dep)
+		}
+
+		sort.SliceStable(deps, func(i, j int) bool {
+			if deps[i].Ecosystem != deps[j].Ecosystem {
+				return deps[i].Ecosystem < deps[j].Ecosystem
+			}
+
+			return deps[i].Name < deps[j].Name
+		})
+
+		val, err := ast.InterfaceToValue(deps)
+		if err != nil {
+			return nil, err
+		}
+
+		return ast.NewTerm(val), nil
+	}
+}
+
+// fetchSBOM fetches the SPDX document of the SBOM of a repository.
+// Returns false if the dependency graph is disabled (forbidden) or
+// the repository doesn't exist.
+func fetchSBOM(ctx context.Context, client *http.Client, owner, repo string) (json.RawMessage, bool, error) {
+	var resp sbomResponse
+
+	status, err := githubGet(ctx, client, repoPath(owner, repo, "dependency-graph", "sbom"), &resp)
+	if err != nil {
+		return nil, false, err
+	}
+
+	switch status {
+	case http.StatusOK:
+	case http.StatusForbidden, http.StatusNotFound:
+		return nil, false, nil
+	default:
+		return nil, false, fmt.Errorf("get sbom: unexpected status %d", status)
+	}
+
+	if len(resp.SBOM) == 0 || string(resp.SBOM) == "null" {
+		return nil, false, nil
+	}
+
+	return resp.SBOM, true, nil
+}
+
+// sbomLicense returns the concluded license of a package, falling
+// back to the declared one, or empty if neither was identified.
+func sbomLicense(licenses ...string) string {
+	for _, l := range licenses {
+		if l != "" && l != noAssertionLicense {
+			return l
+		}
+	}
+
+	return ""
+}
+
+// purlType returns the type of a Package URL,
+// e.g. "npm" for "pkg:npm/lodash@4.17.21".
+func purlType(purl string) string {
+	purl = strings.TrimPrefix(purl, "pkg:")
+
+	if i := strings.Index(purl, "/"); i >= 0 {
+		purl = purl[:i]
+	}
+
+	if t, err := url.PathUnescape(purl); err == nil {
+		purl = t
+	}
+
+	return strings.ToLower(purl)
+}
@@ -0,0 +1,151 @@
+package builtins_test
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/reposaur/reposaur/internal/builtins"
+)
+
+const testSBOM = `{
+	"sbom": {
+		"SPDXID": "SPDXRef-DOCUMENT",
+		"spdxVersion": "SPDX-2.3",
+		"name": "com.github.reposaur/reposaur",
+		"packages": [
+			{
+				"SPDXID": "SPDXRef-com.github.reposaur-reposaur",
+				"name": "com.github.reposaur/reposaur",
+				"versionInfo": "",
+				"licenseConcluded": "MIT"
+			},
+			{
+				"SPDXID": "SPDXRef-npm-lodash-4.17.21",
+				"name": "npm:lodash",
+				"versionInfo": "4.17.21",
+				"licenseConcluded": "NOASSERTION",
+				"licenseDeclared": "MIT",
+				"externalRefs": [
+					{"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl", "referenceLocator": "pkg:npm/lodash@4.17.21"}
+				]
+			},
+			{
+				"SPDXID": "SPDXRef-go-github.com-rs-zerolog-1.26.1",
+				"name": "go:github.com/rs/zerolog",
+				"versionInfo": "1.26.1",
+				"licenseConcluded": "NOASSERTION",
+				"externalRefs": [
+					{"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl", "referenceLocator": "pkg:golang/github.com/rs/zerolog@1.26.1"}
+				]
+			}
+		],
+		"relationships": [
+			{"spdxElementId": "SPDXRef-DOCUMENT", "relationshipType": "DESCRIBES", "relatedSpdxElement": "SPDXRef-com.github.reposaur-reposaur"},
+			{"spdxElementId": "SPDXRef-com.github.reposaur-reposaur", "relationshipType": "DEPENDS_ON", "relatedSpdxElement": "SPDXRef-npm-lodash-4.17.21"},
+			{"spdxElementId": "SPDXRef-com.github.reposaur-reposaur", "relationshipType": "DEPENDS_ON", "relatedSpdxElement": "SPDXRef-go-github.com-rs-zerolog-1.26.1"}
+		]
+	}
+}`
+
+func newSBOMStubClient(t *testing.T) *http.Client {
+	return newStubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/reposaur/reposaur/dependency-graph/sbom":
+			_, _ = w.Write([]byte(testSBOM))
+
+		case "/repos/reposaur/disabled/dependency-graph/sbom":
+			w.WriteHeader(http.StatusForbidden)
+
+		case "/repos/reposaur/broken/dependency-graph/sbom":
+			w.WriteHeader(http.StatusInternalServerError)
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestGitHubSBOM(t *testing.T) {
+	impl := builtins.GitHubSBOMBuiltinImpl(newSBOMStubClient(t))
+
+	term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm("reposaur"))
+	if err != nil {
+		t.Fatal(err)
+	} else if term == nil {
+		t.Fatal("expected an SBOM")
+	}
+
+	var sbom struct {
+		SPDXVersion string        `json:"spdxVersion"`
+		Packages    []interface{} `json:"packages"`
+	}
+
+	if err := ast.As(term.Value, &sbom); err != nil {
+		t.Fatal(err)
+	}
+
+	if sbom.SPDXVersion != "SPDX-2.3" || len(sbom.Packages) != 3 {
+		t.Errorf("expected the SPDX document as is, got %v", term)
+	}
+}
+
+func TestGitHubDependencies(t *testing.T) {
+	impl := builtins.GitHubDependenciesBuiltinImpl(newSBOMStubClient(t))
+
+	term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm("reposaur"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var deps []builtins.Dependency
+	if err := ast.As(term.Value, &deps); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []builtins.Dependency{
+		{
+			Name:      "go:github.com/rs/zerolog",
+			Version:   "1.26.1",
+			Ecosystem: "golang",
+			PURL:      "pkg:golang/github.com/rs/zerolog@1.26.1",
+		},
+		{
+			Name:      "npm:lodash",
+			Version:   "4.17.21",
+			Ecosystem: "npm",
+			PURL:      "pkg:npm/lodash@4.17.21",
+			License:   "MIT",
+		},
+	}
+
+	if !reflect.DeepEqual(deps, expected) {
+		t.Errorf("expected %+v, got %+v", expected, deps)
+	}
+}
+
+func TestGitHubSBOMUnavailable(t *testing.T) {
+	client := newSBOMStubClient(t)
+
+	impls := map[string]func(rego.BuiltinContext, *ast.Term, *ast.Term) (*ast.Term, error){
+		"sbom":         builtins.GitHubSBOMBuiltinImpl(client),
+		"dependencies": builtins.GitHubDependenciesBuiltinImpl(client),
+	}
+
+	for name, impl := range impls {
+		for _, repo := range []string{"disabled", "missing"} {
+			term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm(repo))
+			if err != nil {
+				t.Fatal(err)
+			} else if term != nil {
+				t.Errorf("%s: %s: expected undefined, got %v", name, repo, term)
+			}
+		}
+
+		if _, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm("broken")); err == nil {
+			t.Errorf("%s: expected an error for an unexpected status", name)
+		}
+	}
+}
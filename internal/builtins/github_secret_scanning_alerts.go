@@ -0,0 +1,93 @@
+package builtins
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/types"
+)
+
+// secretScanningAlertFilters are the options of
+// github.secret_scanning_alerts that are passed to the API to
+// filter alerts. Each accepts a string or an array of strings.
+var secretScanningAlertFilters = []string{"state", "secret_type", "resolution", "validity"}
+
+var GitHubSecretScanningAlertsBuiltin = rego.Function{
+	Name: "github.secret_scanning_alerts",
+	Decl: types.NewFunction(
+		types.Args(
+			types.S,
+			types.S,
+			types.NewObject(nil, types.NewDynamicProperty(types.S, types.A)),
+		),
+		types.NewObject(nil, types.NewDynamicProperty(types.S, types.A)),
+	),
+	Memoize: true,
+}
+
+// GitHubSecretScanningAlertsBuiltinImpl fetches the secret scanning
+// alerts of a repository, filtered by the options, and returns whether
+// secret scanning is enabled and the alerts. Returns undefined if the
+// repository doesn't exist.
+func GitHubSecretScanningAlertsBuiltinImpl(client *http.Client) func(bctx rego.BuiltinContext, op1, op2, op3 *ast.Term) (*ast.Term, error) {
+	return func(bctx rego.BuiltinContext, op1, op2, op3 *ast.Term) (*ast.Term, error) {
+		var (
+			owner, repo string
+			opts        map[string]interface{}
+		)
+
+		if err := ast.As(op1.Value, &owner); err != nil {
+			return nil, err
+		} else if err := ast.As(op2.Value, &repo); err != nil {
+			return nil, err
+		} else if err := ast.As(op3.Value, &opts); err != nil {
+			return nil, err
+		}
+
+		query, err := filterQuery(opts, secretScanningAlertFilters)
+		if err != nil {
+			return nil, err
+		}
+
+		path := withQuery(repoPath(owner, repo, "secret-scanning", "alerts"), query)
+
+		alerts, status, err := githubGetPages(bctx.Context, client, path, "", 0)
+		if err != nil {
+			return nil, err
+		}
+
+		result := map[string]interface{}{
+			"enabled": true,
+			"alerts":  alerts,
+		}
+
+		switch status {
+		case http.StatusOK:
+		case http.StatusNotFound:
+			// the alerts API is also not found
+			// when secret scanning is disabled
+			exists, err := repositoryExists(bctx, client, owner, repo)
+			if err != nil {
+				return nil, err
+			} else if !exists {
+				return nil, nil
+			}
+
+			result["enabled"] = false
+			result["alerts"] = []interface{}{}
+		case http.StatusForbidden:
+			return nil, fmt.Errorf("get secret scanning alerts: forbidden, requires admin access to the repository")
+		default:
+			return nil, fmt.Errorf("get secret scanning alerts: unexpected status %d", status)
+		}
+
+		val, err := ast.InterfaceToValue(result)
+		if err != nil {
+			return nil, err
+		}
+
+		return ast.NewTerm(val), nil
+	}
+}
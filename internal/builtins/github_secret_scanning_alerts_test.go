@@ -0,0 +1,121 @@
+package builtins_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/reposaur/reposaur/internal/builtins"
+)
+
+// secretScanningAlertsHandler serves 3 alerts for reposaur/test
+// in pages of 2. Secret scanning is disabled for reposaur/disabled.
+func secretScanningAlertsHandler(t *testing.T, queries *[]string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/reposaur/disabled":
+			_, _ = w.Write([]byte(`{"name": "disabled"}`))
+			return
+		case "/repos/reposaur/test/secret-scanning/alerts":
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message": "Secret scanning is disabled on this repository."}`))
+			return
+		}
+
+		*queries = append(*queries, r.URL.RawQuery)
+
+		alerts := []map[string]interface{}{
+			{"number": 1, "state": "open", "secret_type": "github_personal_access_token"},
+			{"number": 2, "state": "open", "secret_type": "aws_access_key_id"},
+		}
+
+		if r.URL.Query().Get("page") == "" {
+			w.Header().Set("Link", fmt.Sprintf(`<%s&page=2>; rel="next"`, r.URL.RequestURI()))
+		} else {
+			alerts = []map[string]interface{}{
+				{"number": 3, "state": "open", "secret_type": "slack_api_token"},
+			}
+		}
+
+		if err := json.NewEncoder(w).Encode(alerts); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func TestGitHubSecretScanningAlerts(t *testing.T) {
+	var queries []string
+
+	impl := builtins.GitHubSecretScanningAlertsBuiltinImpl(newStubClient(t, secretScanningAlertsHandler(t, &queries)))
+
+	term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm("test"), objectTerm(t, map[string]interface{}{
+		"state": "open",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var result struct {
+		Enabled bool                     `json:"enabled"`
+		Alerts  []map[string]interface{} `json:"alerts"`
+	}
+
+	if err := ast.As(term.Value, &result); err != nil {
+		t.Fatal(err)
+	}
+
+	if !result.Enabled {
+		t.Error("expected secret scanning to be enabled")
+	}
+
+	if len(result.Alerts) != 3 {
+		t.Fatalf("expected 3 alerts, got %d", len(result.Alerts))
+	}
+
+	if len(queries) != 2 {
+		t.Fatalf("expected 2 pages to be requested, got %d", len(queries))
+	}
+
+	if expected := "state=open&per_page=100"; queries[0] != expected {
+		t.Errorf("expected query to be %s, got %s", expected, queries[0])
+	}
+}
+
+func TestGitHubSecretScanningAlertsDisabled(t *testing.T) {
+	var queries []string
+
+	impl := builtins.GitHubSecretScanningAlertsBuiltinImpl(newStubClient(t, secretScanningAlertsHandler(t, &queries)))
+
+	term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm("disabled"), objectTerm(t, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := objectTerm(t, map[string]interface{}{
+		"enabled": false,
+		"alerts":  []interface{}{},
+	})
+
+	if term.Value.Compare(expected.Value) != 0 {
+		t.Errorf("expected %v, got %v", expected, term)
+	}
+}
+
+func TestGitHubSecretScanningAlertsNotFound(t *testing.T) {
+	var queries []string
+
+	impl := builtins.GitHubSecretScanningAlertsBuiltinImpl(newStubClient(t, secretScanningAlertsHandler(t, &queries)))
+
+	term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm("unknown"), objectTerm(t, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if term != nil {
+		t.Errorf("expected undefined, got %v", term)
+	}
+}
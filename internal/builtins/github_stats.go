@@ -0,0 +1,274 @@
+package builtins
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/types"
+)
+
+// DefaultStatsRetries is the number of times a statistics request is
+// retried while GitHub is still computing them (202 Accepted).
+const DefaultStatsRetries = 4
+
+// DefaultStatsRetryDelay is the delay before the first retry of a
+// statistics request, doubled after each retry.
+const DefaultStatsRetryDelay = time.Second
+
+var GitHubContributorsBuiltin = rego.Function{
+	Name: "github.contributors",
+	Decl: types.NewFunction(
+		types.Args(types.S, types.S),
+		types.NewArray(nil, types.NewObject(nil, types.NewDynamicProperty(types.S, types.A))),
+	),
+	Memoize: true,
+}
+
+var GitHubCommitActivityBuiltin = rego.Function{
+	Name: "github.commit_activity",
+	Decl: types.NewFunction(
+		types.Args(types.S, types.S),
+		types.NewObject(nil, types.NewDynamicProperty(types.S, types.A)),
+	),
+	Memoize: true,
+}
+
+// Contributor is the commit activity of a contributor over the
+// whole history of the default branch. FirstWeek and LastWeek
+// are the start of the first and last weeks with commits.
+type Contributor struct {
+	Login     string `json:"login"`
+	Commits   int    `json:"commits"`
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+	FirstWeek string `json:"first_week"`
+	LastWeek  string `json:"last_week"`
+}
+
+// CommitActivity is the commit activity of the last year of a
+// repository. LastActiveWeek is the start of the last week with
+// commits, empty if there were none.
+type CommitActivity struct {
+	Total          int                  `json:"total"`
+	ActiveWeeks    int                  `json:"active_weeks"`
+	LastActiveWeek string               `json:"last_active_week"`
+	Weeks          []CommitActivityWeek `json:"weeks"`
+}
+
+// CommitActivityWeek is the number of commits of a week,
+// in total and by day starting on Sunday.
+type CommitActivityWeek struct {
+	Week  string `json:"week"`
+	Total int    `json:"total"`
+	Days  []int  `json:"days"`
+}
+
+type contributorResponse struct {
+	Total  int `json:"total"`
+	Author *struct {
+		Login string `json:"login"`
+	} `json:"author"`
+	Weeks []struct {
+		Week      int64 `json:"w"`
+		Additions int   `json:"a"`
+		Deletions int   `json:"d"`
+		Commits   int   `json:"c"`
+	} `json:"weeks"`
+}
+
+type commitActivityResponse struct {
+	Days  []int `json:"days"`
+	Total int   `json:"total"`
+	Week  int64 `json:"week"`
+}
+
+// GitHubContributorsBuiltinImpl fetches the contributors of a repository
+// and their commit activity, sorted by number of commits. Returns
+// undefined if the repository doesn't exist or GitHub is still computing
+// the statistics after retrying.
+func GitHubContributorsBuiltinImpl(client *http.Client) func(bctx rego.BuiltinContext, op1, op2 *ast.Term) (*ast.Term, error) {
+	return func(bctx rego.BuiltinContext, op1, op2 *ast.Term) (*ast.Term, error) {
+		var owner, repo string
+
+		if err := ast.As(op1.Value, &owner); err != nil {
+			return nil, err
+		} else if err := ast.As(op2.Value, &repo); err != nil {
+			return nil, err
+		}
+
+		var resp []contributorResponse
+
+		status, err := githubGetStats(bctx.Context, client, repoPath(owner, repo, "stats", "contributors"), &resp)
+		if err != nil {
+			return nil, err
+		}
+
+		switch status {
+		case http.StatusOK, http.StatusNoContent:
+		case http.StatusAccepted, http.StatusNotFound:
+			return nil, nil
+		default:
+			return nil, fmt.Errorf("get contributors: unexpected status %d", status)
+		}
+
+		contributors := make([]Contributor, 0, len(resp))
+
+		for _, r := range resp {
+			c := Contributor{Commits: r.Total}
+
+			if r.Author != nil {
+				c.Login = r.Author.Login
+			}
+
+			for _, w := range r.Weeks {
+				c.Additions += w.Additions
+				c.Deletions += w.Deletions
+
+				if w.Commits == 0 {
+					continue
+				}
+
+				if c.FirstWeek == "" {
+					c.FirstWeek = statsWeek(w.Week)
+				}
+
+				c.LastWeek = statsWeek(w.Week)
+			}
+
+			contributors = append(contributors, c)
+		}
+
+		sort.SliceStable(contributors, func(i, j int) bool {
+			return contributors[i].Commits > contributors[j].Commits
+		})
+
+		val, err := ast.InterfaceToValue(contributors)
+		if err != nil {
+			return nil, err
+		}
+
+		return ast.NewTerm(val), nil
+	}
+}
+
+// GitHubCommitActivityBuiltinImpl fetches the weekly commit activity of
+// the last year of a repository. Returns undefined if the repository
+// doesn't exist or GitHub is still computing the statistics after
+// retrying.
+func GitHubCommitActivityBuiltinImpl(client *http.Client) func(bctx rego.BuiltinContext, op1, op2 *ast.Term) (*ast.Term, error) {
+	return func(bctx rego.BuiltinContext, op1, op2 *ast.Term) (*ast.Term, error) {
+		var owner, repo string
+
+		if err := ast.As(op1.Value, &owner); err != nil {
+			return nil, err
+		} else if err := ast.As(op2.Value, &repo); err != nil {
+			return nil, err
+		}
+
+		var resp []commitActivityResponse
+
+		status, err := githubGetStats(bctx.Context, client, repoPath(owner, repo, "stats", "commit_activity"), &resp)
+		if err != nil {
+			return nil, err
+		}
+
+		switch status {
+		case http.StatusOK, http.StatusNoContent:
+		case http.StatusAccepted, http.StatusNotFound:
+			return nil, nil
+		default:
+			return nil, fmt.Errorf("get commit activity: unexpected status %d", status)
+		}
+
+		activity := CommitActivity{Weeks: make([]CommitActivityWeek, 0, len(resp))}
+
+		for _, r := range resp {
+			week := CommitActivityWeek{
+				Week:  statsWeek(r.Week),
+				Total: r.Total,
+				Days:  r.Days,
+			}
+
+			if week.Days == nil {
+				week.Days = []int{}
+			}
+
+			activity.Total += r.Total
+
+			if r.Total > 0 {
+				activity.ActiveWeeks++
+				activity.LastActiveWeek = week.Week
+			}
+
+			activity.Weeks = append(activity.Weeks, week)
+		}
+
+		val, err := ast.InterfaceToValue(activity)
+		if err != nil {
+			return nil, err
+		}
+
+		return ast.NewTerm(val), nil
+	}
+}
+
+// githubGetStats does a GET request against the statistics API like
+// githubGet, retrying while GitHub is computing them in the background
+// (202 Accepted). If they're still being computed after the retries
+// of ctx's options (see WithStatsRetry), the 202 status code is returned. Repositories without commits have
+// no statistics (204 No Content) and v isn't decoded.
+func githubGetStats(ctx context.Context, client *http.Client, path string, v interface{}) (int, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	opts := contextOptions(ctx)
+	delay := opts.statsRetryDelay
+
+	for attempt := 0; ; attempt++ {
+		resp, err := githubDo(ctx, client, path)
+		if err != nil {
+			return 0, err
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+
+			if resp.StatusCode != http.StatusAccepted || attempt >= opts.statsRetries {
+				return resp.StatusCode, nil
+			}
+
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return 0, ctx.Err()
+			}
+
+			delay *= 2
+
+			continue
+		}
+
+		defer resp.Body.Close()
+
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			return 0, err
+		}
+
+		return resp.StatusCode, nil
+	}
+}
+
+// statsWeek formats the start of a week returned
+// by the statistics API as a Unix timestamp.
+func statsWeek(ts int64) string {
+	return time.Unix(ts, 0).UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,198 @@
+package builtins_test
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/reposaur/reposaur/internal/builtins"
+)
+
+const testContributors = `[
+	{
+		"total": 1,
+		"author": {"login": "octocat"},
+		"weeks": [
+			{"w": 1640476800, "a": 10, "d": 2, "c": 1},
+			{"w": 1641081600, "a": 0, "d": 0, "c": 0}
+		]
+	},
+	{
+		"total": 5,
+		"author": {"login": "reposaur"},
+		"weeks": [
+			{"w": 1640476800, "a": 0, "d": 0, "c": 0},
+			{"w": 1641081600, "a": 100, "d": 20, "c": 3},
+			{"w": 1641686400, "a": 5, "d": 5, "c": 2}
+		]
+	}
+]`
+
+const testCommitActivity = `[
+	{"days": [0, 0, 0, 0, 0, 0, 0], "total": 0, "week": 1640476800},
+	{"days": [0, 1, 2, 0, 0, 0, 0], "total": 3, "week": 1641081600},
+	{"days": [0, 0, 0, 0, 0, 0, 0], "total": 0, "week": 1641686400}
+]`
+
+// statsContext retries statistics requests quickly.
+var statsContext = rego.BuiltinContext{
+	Context: builtins.NewOptionsContext(context.Background(), builtins.WithStatsRetry(2, time.Millisecond)),
+}
+
+// newStatsStubClient returns a client whose statistics are being computed
+// (202 Accepted) for the first pending requests, and never for "computing".
+func newStatsStubClient(t *testing.T, pending int32) (*http.Client, *int32) {
+	var requests int32
+
+	client := newStubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) <= pending {
+			w.WriteHeader(http.StatusAccepted)
+			_, _ = w.Write([]byte(`{}`))
+			return
+		}
+
+		switch r.URL.Path {
+		case "/repos/reposaur/reposaur/stats/contributors":
+			_, _ = w.Write([]byte(testContributors))
+
+		case "/repos/reposaur/reposaur/stats/commit_activity":
+			_, _ = w.Write([]byte(testCommitActivity))
+
+		case "/repos/reposaur/empty/stats/contributors", "/repos/reposaur/empty/stats/commit_activity":
+			w.WriteHeader(http.StatusNoContent)
+
+		case "/repos/reposaur/computing/stats/contributors", "/repos/reposaur/computing/stats/commit_activity":
+			w.WriteHeader(http.StatusAccepted)
+			_, _ = w.Write([]byte(`{}`))
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	return client, &requests
+}
+
+func TestGitHubContributors(t *testing.T) {
+	client, requests := newStatsStubClient(t, 2)
+	impl := builtins.GitHubContributorsBuiltinImpl(client)
+
+	term, err := impl(statsContext, ast.StringTerm("reposaur"), ast.StringTerm("reposaur"))
+	if err != nil {
+		t.Fatal(err)
+	} else if term == nil {
+		t.Fatal("expected contributors")
+	}
+
+	if n := atomic.LoadInt32(requests); n != 3 {
+		t.Errorf("expected 3 requests, got %d", n)
+	}
+
+	var contributors []builtins.Contributor
+	if err := ast.As(term.Value, &contributors); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []builtins.Contributor{
+		{
+			Login:     "reposaur",
+			Commits:   5,
+			Additions: 105,
+			Deletions: 25,
+			FirstWeek: "2022-01-02T00:00:00Z",
+			LastWeek:  "2022-01-09T00:00:00Z",
+		},
+		{
+			Login:     "octocat",
+			Commits:   1,
+			Additions: 10,
+			Deletions: 2,
+			FirstWeek: "2021-12-26T00:00:00Z",
+			LastWeek:  "2021-12-26T00:00:00Z",
+		},
+	}
+
+	if !reflect.DeepEqual(contributors, expected) {
+		t.Errorf("expected %+v, got %+v", expected, contributors)
+	}
+}
+
+func TestGitHubCommitActivity(t *testing.T) {
+	client, requests := newStatsStubClient(t, 1)
+	impl := builtins.GitHubCommitActivityBuiltinImpl(client)
+
+	term, err := impl(statsContext, ast.StringTerm("reposaur"), ast.StringTerm("reposaur"))
+	if err != nil {
+		t.Fatal(err)
+	} else if term == nil {
+		t.Fatal("expected commit activity")
+	}
+
+	if n := atomic.LoadInt32(requests); n != 2 {
+		t.Errorf("expected 2 requests, got %d", n)
+	}
+
+	var activity builtins.CommitActivity
+	if err := ast.As(term.Value, &activity); err != nil {
+		t.Fatal(err)
+	}
+
+	if activity.Total != 3 || activity.ActiveWeeks != 1 || activity.LastActiveWeek != "2022-01-02T00:00:00Z" {
+		t.Errorf("unexpected activity %+v", activity)
+	}
+
+	if len(activity.Weeks) != 3 || !reflect.DeepEqual(activity.Weeks[1].Days, []int{0, 1, 2, 0, 0, 0, 0}) {
+		t.Errorf("unexpected weeks %+v", activity.Weeks)
+	}
+}
+
+func TestGitHubStatsUnavailable(t *testing.T) {
+	client, requests := newStatsStubClient(t, 0)
+
+	contributors := builtins.GitHubContributorsBuiltinImpl(client)
+	activity := builtins.GitHubCommitActivityBuiltinImpl(client)
+
+	term, err := contributors(statsContext, ast.StringTerm("reposaur"), ast.StringTerm("empty"))
+	if err != nil {
+		t.Fatal(err)
+	} else if !term.Equal(ast.ArrayTerm()) {
+		t.Errorf("expected no contributors, got %v", term)
+	}
+
+	term, err = activity(statsContext, ast.StringTerm("reposaur"), ast.StringTerm("empty"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var empty builtins.CommitActivity
+	if err := ast.As(term.Value, &empty); err != nil {
+		t.Fatal(err)
+	} else if empty.Total != 0 || empty.LastActiveWeek != "" || len(empty.Weeks) != 0 {
+		t.Errorf("expected no activity, got %+v", empty)
+	}
+
+	atomic.StoreInt32(requests, 0)
+
+	term, err = contributors(statsContext, ast.StringTerm("reposaur"), ast.StringTerm("computing"))
+	if err != nil {
+		t.Fatal(err)
+	} else if term != nil {
+		t.Errorf("expected undefined while computing, got %v", term)
+	}
+
+	if n := atomic.LoadInt32(requests); n != 3 {
+		t.Errorf("expected the request to be retried twice, got %d requests", n)
+	}
+
+	term, err = activity(statsContext, ast.StringTerm("reposaur"), ast.StringTerm("missing"))
+	if err != nil {
+		t.Fatal(err)
+	} else if term != nil {
+		t.Errorf("expected undefined, got %v", term)
+	}
+}
@@ -0,0 +1,166 @@
+package builtins
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/types"
+)
+
+var GitHubTeamsBuiltin = rego.Function{
+	Name: "github.teams",
+	Decl: types.NewFunction(
+		types.Args(types.S),
+		types.NewArray(nil, types.NewObject(nil, types.NewDynamicProperty(types.S, types.A))),
+	),
+	Memoize: true,
+}
+
+var GitHubTeamReposBuiltin = rego.Function{
+	Name: "github.team_repos",
+	Decl: types.NewFunction(
+		types.Args(types.S, types.S),
+		types.NewArray(nil, types.NewObject(nil, types.NewDynamicProperty(types.S, types.A))),
+	),
+	Memoize: true,
+}
+
+// Team is a normalized view of a team of an organization.
+type Team struct {
+	Slug       string  `json:"slug"`
+	Name       string  `json:"name"`
+	Privacy    string  `json:"privacy"`
+	Permission string  `json:"permission"`
+	Parent     *string `json:"parent"`
+}
+
+// TeamRepo is a repository a team has access to,
+// with the permission level of the team.
+type TeamRepo struct {
+	Name       string `json:"name"`
+	FullName   string `json:"full_name"`
+	Permission string `json:"permission"`
+	RoleName   string `json:"role_name"`
+}
+
+type teamResponse struct {
+	Slug       string `json:"slug"`
+	Name       string `json:"name"`
+	Privacy    string `json:"privacy"`
+	Permission string `json:"permission"`
+	Parent     *struct {
+		Slug string `json:"slug"`
+	} `json:"parent"`
+}
+
+type teamRepoResponse struct {
+	Name        string          `json:"name"`
+	FullName    string          `json:"full_name"`
+	RoleName    string          `json:"role_name"`
+	Permissions map[string]bool `json:"permissions"`
+}
+
+// GitHubTeamsBuiltinImpl fetches every team of an organization,
+// normalized with the slug of their parent team (if any). Returns
+// undefined if the organization doesn't exist.
+func GitHubTeamsBuiltinImpl(client *http.Client) func(bctx rego.BuiltinContext, op1 *ast.Term) (*ast.Term, error) {
+	return func(bctx rego.BuiltinContext, op1 *ast.Term) (*ast.Term, error) {
+		var org string
+
+		if err := ast.As(op1.Value, &org); err != nil {
+			return nil, err
+		}
+
+		items, status, err := githubGetPages(bctx.Context, client, "/orgs/"+url.PathEscape(org)+"/teams", "", 0)
+		if err != nil {
+			return nil, err
+		} else if status == http.StatusNotFound {
+			return nil, nil
+		} else if status != http.StatusOK {
+			return nil, fmt.Errorf("get teams: unexpected status %d", status)
+		}
+
+		var resp []teamResponse
+
+		if err := decodeItems(items, &resp); err != nil {
+			return nil, err
+		}
+
+		teams := make([]Team, 0, len(resp))
+
+		for _, r := range resp {
+			team := Team{
+				Slug:       r.Slug,
+				Name:       r.Name,
+				Privacy:    r.Privacy,
+				Permission: r.Permission,
+			}
+
+			if r.Parent != nil {
+				team.Parent = &r.Parent.Slug
+			}
+
+			teams = append(teams, team)
+		}
+
+		val, err := ast.InterfaceToValue(teams)
+		if err != nil {
+			return nil, err
+		}
+
+		return ast.NewTerm(val), nil
+	}
+}
+
+// GitHubTeamReposBuiltinImpl fetches every repository a team has access
+// to, with the team's permission level. Returns undefined if the team
+// doesn't exist.
+func GitHubTeamReposBuiltinImpl(client *http.Client) func(bctx rego.BuiltinContext, op1, op2 *ast.Term) (*ast.Term, error) {
+	return func(bctx rego.BuiltinContext, op1, op2 *ast.Term) (*ast.Term, error) {
+		var org, slug string
+
+		if err := ast.As(op1.Value, &org); err != nil {
+			return nil, err
+		} else if err := ast.As(op2.Value, &slug); err != nil {
+			return nil, err
+		}
+
+		path := fmt.Sprintf("/orgs/%s/teams/%s/repos", url.PathEscape(org), url.PathEscape(slug))
+
+		items, status, err := githubGetPages(bctx.Context, client, path, "", 0)
+		if err != nil {
+			return nil, err
+		} else if status == http.StatusNotFound {
+			return nil, nil
+		} else if status != http.StatusOK {
+			return nil, fmt.Errorf("get team repositories: unexpected status %d", status)
+		}
+
+		var resp []teamRepoResponse
+
+		if err := decodeItems(items, &resp); err != nil {
+			return nil, err
+		}
+
+		repos := make([]TeamRepo, 0, len(resp))
+
+		for _, r := range resp {
+			repos = append(repos, TeamRepo{
+				Name:       r.Name,
+				FullName:   r.FullName,
+				Permission: highestPermission(r.Permissions),
+				RoleName:   r.RoleName,
+			})
+		}
+
+		val, err := ast.InterfaceToValue(repos)
+		if err != nil {
+			return nil, err
+		}
+
+		return ast.NewTerm(val), nil
+	}
+}
@@ -0,0 +1,107 @@
+package builtins_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/reposaur/reposaur/internal/builtins"
+)
+
+func newTeamsStubClient(t *testing.T) *http.Client {
+	return newStubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/orgs/reposaur/teams":
+			if r.URL.Query().Get("page") == "" {
+				w.Header().Set("Link", fmt.Sprintf(`<%s&page=2>; rel="next"`, r.URL.RequestURI()))
+				_, _ = w.Write([]byte(`[{"slug": "maintainers", "name": "Maintainers", "privacy": "closed", "permission": "pull", "parent": null}]`))
+				return
+			}
+
+			_, _ = w.Write([]byte(`[{"slug": "release", "name": "Release", "privacy": "secret", "permission": "pull", "parent": {"slug": "maintainers"}}]`))
+
+		case "/orgs/reposaur/teams/maintainers/repos":
+			_, _ = w.Write([]byte(`[
+				{"name": "reposaur", "full_name": "reposaur/reposaur", "role_name": "admin", "permissions": {"admin": true, "maintain": true, "push": true, "triage": true, "pull": true}},
+				{"name": "policies", "full_name": "reposaur/policies", "role_name": "write", "permissions": {"admin": false, "maintain": false, "push": true, "triage": true, "pull": true}}
+			]`))
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestGitHubTeams(t *testing.T) {
+	impl := builtins.GitHubTeamsBuiltinImpl(newTeamsStubClient(t))
+
+	term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"))
+	if err != nil {
+		t.Fatal(err)
+	} else if term == nil {
+		t.Fatal("expected teams")
+	}
+
+	var teams []builtins.Team
+	if err := ast.As(term.Value, &teams); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(teams) != 2 {
+		t.Fatalf("expected the teams of both pages, got %d", len(teams))
+	}
+
+	if teams[0].Slug != "maintainers" || teams[0].Parent != nil {
+		t.Errorf("expected maintainers without a parent, got %+v", teams[0])
+	}
+
+	if teams[1].Slug != "release" || teams[1].Parent == nil || *teams[1].Parent != "maintainers" {
+		t.Errorf("expected release to be a child of maintainers, got %+v", teams[1])
+	}
+}
+
+func TestGitHubTeamRepos(t *testing.T) {
+	impl := builtins.GitHubTeamReposBuiltinImpl(newTeamsStubClient(t))
+
+	term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm("maintainers"))
+	if err != nil {
+		t.Fatal(err)
+	} else if term == nil {
+		t.Fatal("expected team repositories")
+	}
+
+	var repos []builtins.TeamRepo
+	if err := ast.As(term.Value, &repos); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []builtins.TeamRepo{
+		{Name: "reposaur", FullName: "reposaur/reposaur", Permission: "admin", RoleName: "admin"},
+		{Name: "policies", FullName: "reposaur/policies", Permission: "push", RoleName: "write"},
+	}
+
+	if len(repos) != len(expected) {
+		t.Fatalf("expected %d repositories, got %d", len(expected), len(repos))
+	}
+
+	for i, r := range expected {
+		if repos[i] != r {
+			t.Errorf("expected repository %d to be %+v, got %+v", i, r, repos[i])
+		}
+	}
+}
+
+func TestGitHubTeamReposNotFound(t *testing.T) {
+	impl := builtins.GitHubTeamReposBuiltinImpl(newTeamsStubClient(t))
+
+	term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm("missing"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if term != nil {
+		t.Errorf("expected undefined, got %v", term)
+	}
+}
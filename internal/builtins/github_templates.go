@@ -0,0 +1,194 @@
+package builtins
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/types"
+)
+
+// templatesDirs are the directories GitHub looks for
+// issue and pull request templates in, in order of
+// precedence. The repository's root is empty.
+var templatesDirs = []string{".github", "", "docs"}
+
+var GitHubTemplatesBuiltin = rego.Function{
+	Name: "github.templates",
+	Decl: types.NewFunction(
+		types.Args(types.S, types.S),
+		types.NewObject(nil, types.NewDynamicProperty(types.S, types.A)),
+	),
+	Memoize: true,
+}
+
+// Templates are the issue and pull request templates of a
+// repository. IssueConfig is the configuration of the template
+// chooser (ISSUE_TEMPLATE/config.yml), if there's one.
+type Templates struct {
+	IssueTemplates       []TemplateFile `json:"issue_templates"`
+	IssueConfig          *TemplateFile  `json:"issue_config,omitempty"`
+	PullRequestTemplates []TemplateFile `json:"pull_request_templates"`
+}
+
+// TemplateFile is an issue or pull request template file.
+type TemplateFile struct {
+	Path    string `json:"path"`
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
+
+type templateEntry struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+// GitHubTemplatesBuiltinImpl fetches the issue and pull request
+// templates of a repository in its default branch, with their content.
+// Templates are looked for in the .github and docs directories and the
+// repository's root, either as a single ISSUE_TEMPLATE.md or
+// PULL_REQUEST_TEMPLATE.md file or as files in an ISSUE_TEMPLATE or
+// PULL_REQUEST_TEMPLATE directory, case-insensitively. The lists are
+// empty if the repository doesn't have templates.
+func GitHubTemplatesBuiltinImpl(client *http.Client) func(bctx rego.BuiltinContext, op1, op2 *ast.Term) (*ast.Term, error) {
+	return func(bctx rego.BuiltinContext, op1, op2 *ast.Term) (*ast.Term, error) {
+		var owner, repo string
+
+		if err := ast.As(op1.Value, &owner); err != nil {
+			return nil, err
+		} else if err := ast.As(op2.Value, &repo); err != nil {
+			return nil, err
+		}
+
+		templates, err := fetchTemplates(bctx, client, owner, repo)
+		if err != nil {
+			return nil, err
+		}
+
+		val, err := ast.InterfaceToValue(templates)
+		if err != nil {
+			return nil, err
+		}
+
+		return ast.NewTerm(val), nil
+	}
+}
+
+func fetchTemplates(bctx rego.BuiltinContext, client *http.Client, owner, repo string) (Templates, error) {
+	templates := Templates{
+		IssueTemplates:       []TemplateFile{},
+		PullRequestTemplates: []TemplateFile{},
+	}
+
+	fetch := func(p string) (TemplateFile, bool, error) {
+		content, ok, err := githubContent(bctx.Context, client, owner, repo, p, "")
+		if err != nil || !ok {
+			return TemplateFile{}, false, err
+		}
+
+		return TemplateFile{Path: p, Name: path.Base(p), Content: string(content)}, true, nil
+	}
+
+	for _, dir := range templatesDirs {
+		entries, err := listTemplateEntries(bctx, client, owner, repo, dir)
+		if err != nil {
+			return Templates{}, err
+		}
+
+		for _, e := range entries {
+			name := strings.ToLower(e.Name)
+
+			switch {
+			case e.Type == "file" && name == "issue_template.md":
+				if f, ok, err := fetch(e.Path); err != nil {
+					return Templates{}, err
+				} else if ok {
+					templates.IssueTemplates = append(templates.IssueTemplates, f)
+				}
+
+			case e.Type == "file" && name == "pull_request_template.md":
+				if f, ok, err := fetch(e.Path); err != nil {
+					return Templates{}, err
+				} else if ok {
+					templates.PullRequestTemplates = append(templates.PullRequestTemplates, f)
+				}
+
+			case e.Type == "dir" && name == "issue_template":
+				files, err := listTemplateEntries(bctx, client, owner, repo, e.Path)
+				if err != nil {
+					return Templates{}, err
+				}
+
+				for _, file := range files {
+					ext := strings.ToLower(path.Ext(file.Name))
+					if file.Type != "file" || (ext != ".md" && ext != ".yml" && ext != ".yaml") {
+						continue
+					}
+
+					f, ok, err := fetch(file.Path)
+					if err != nil {
+						return Templates{}, err
+					} else if !ok {
+						continue
+					}
+
+					if name := strings.ToLower(file.Name); name == "config.yml" || name == "config.yaml" {
+						if templates.IssueConfig == nil {
+							templates.IssueConfig = &f
+						}
+
+						continue
+					}
+
+					templates.IssueTemplates = append(templates.IssueTemplates, f)
+				}
+
+			case e.Type == "dir" && name == "pull_request_template":
+				files, err := listTemplateEntries(bctx, client, owner, repo, e.Path)
+				if err != nil {
+					return Templates{}, err
+				}
+
+				for _, file := range files {
+					if file.Type != "file" || strings.ToLower(path.Ext(file.Name)) != ".md" {
+						continue
+					}
+
+					if f, ok, err := fetch(file.Path); err != nil {
+						return Templates{}, err
+					} else if ok {
+						templates.PullRequestTemplates = append(templates.PullRequestTemplates, f)
+					}
+				}
+			}
+		}
+	}
+
+	return templates, nil
+}
+
+// listTemplateEntries lists the entries of a directory of a
+// repository ordered by path, or nothing if it doesn't exist.
+func listTemplateEntries(bctx rego.BuiltinContext, client *http.Client, owner, repo, dir string) ([]templateEntry, error) {
+	var entries []templateEntry
+
+	status, err := githubGet(bctx.Context, client, repoPath(owner, repo, "contents", dir), &entries)
+	if err != nil {
+		return nil, err
+	} else if status == http.StatusNotFound {
+		return nil, nil
+	} else if status != http.StatusOK {
+		return nil, fmt.Errorf("list %s/ contents: unexpected status %d", dir, status)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Path < entries[j].Path
+	})
+
+	return entries, nil
+}
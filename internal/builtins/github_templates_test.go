@@ -0,0 +1,97 @@
+package builtins_test
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/reposaur/reposaur/internal/builtins"
+)
+
+const testPullRequestTemplate = `## Description
+
+## Checklist
+
+- [ ] Tests were added
+`
+
+func TestGitHubTemplates(t *testing.T) {
+	client := newStubClient(t, contentsHandler(map[string]string{
+		".github/ISSUE_TEMPLATE/bug_report.yml": "name: Bug report\n",
+		".github/ISSUE_TEMPLATE/feature.md":     "---\nname: Feature\n---\n",
+		".github/ISSUE_TEMPLATE/config.yml":     "blank_issues_enabled: false\n",
+		".github/ISSUE_TEMPLATE/README.txt":     "Not a template",
+		".github/pull_request_template.md":      testPullRequestTemplate,
+		".github/workflows/ci.yml":              "on: push\n",
+		"docs/PULL_REQUEST_TEMPLATE/release.md": "## Release notes\n",
+		"README.md":                             "# Test",
+	}))
+
+	impl := builtins.GitHubTemplatesBuiltinImpl(client)
+
+	term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm("test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var templates builtins.Templates
+	if err := ast.As(term.Value, &templates); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := builtins.Templates{
+		IssueTemplates: []builtins.TemplateFile{
+			{Path: ".github/ISSUE_TEMPLATE/bug_report.yml", Name: "bug_report.yml", Content: "name: Bug report\n"},
+			{Path: ".github/ISSUE_TEMPLATE/feature.md", Name: "feature.md", Content: "---\nname: Feature\n---\n"},
+		},
+		IssueConfig: &builtins.TemplateFile{
+			Path:    ".github/ISSUE_TEMPLATE/config.yml",
+			Name:    "config.yml",
+			Content: "blank_issues_enabled: false\n",
+		},
+		PullRequestTemplates: []builtins.TemplateFile{
+			{Path: ".github/pull_request_template.md", Name: "pull_request_template.md", Content: testPullRequestTemplate},
+			{Path: "docs/PULL_REQUEST_TEMPLATE/release.md", Name: "release.md", Content: "## Release notes\n"},
+		},
+	}
+
+	if !reflect.DeepEqual(templates, expected) {
+		t.Errorf("expected %+v, got %+v", expected, templates)
+	}
+}
+
+func TestGitHubTemplatesNone(t *testing.T) {
+	client := newStubClient(t, contentsHandler(map[string]string{
+		"README.md": "# Test",
+	}))
+
+	impl := builtins.GitHubTemplatesBuiltinImpl(client)
+
+	term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm("test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := objectTerm(t, map[string]interface{}{
+		"issue_templates":        []interface{}{},
+		"pull_request_templates": []interface{}{},
+	})
+
+	if !term.Equal(expected) {
+		t.Errorf("expected %v, got %v", expected, term)
+	}
+}
+
+func TestGitHubTemplatesError(t *testing.T) {
+	client := newStubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+
+	impl := builtins.GitHubTemplatesBuiltinImpl(client)
+
+	if _, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm("test")); err == nil {
+		t.Error("expected an unexpected status to fail")
+	}
+}
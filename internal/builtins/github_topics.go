@@ -0,0 +1,127 @@
+package builtins
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/types"
+)
+
+var GitHubTopicsBuiltin = rego.Function{
+	Name: "github.topics",
+	Decl: types.NewFunction(
+		types.Args(types.S, types.S),
+		types.NewArray(nil, types.S),
+	),
+	Memoize: true,
+}
+
+var GitHubRepoMetadataBuiltin = rego.Function{
+	Name: "github.repo_metadata",
+	Decl: types.NewFunction(
+		types.Args(types.S, types.S),
+		types.NewObject(nil, types.NewDynamicProperty(types.S, types.A)),
+	),
+	Memoize: true,
+}
+
+// RepoMetadata is the descriptive metadata of a repository.
+// Description and Homepage are empty if they aren't set.
+type RepoMetadata struct {
+	Description string   `json:"description"`
+	Homepage    string   `json:"homepage"`
+	Topics      []string `json:"topics"`
+}
+
+// GitHubTopicsBuiltinImpl fetches the topics of a repository from
+// the topics API, which includes them regardless of the media type
+// the repository was fetched with. Repositories without topics have
+// an empty list. Returns undefined if the repository doesn't exist.
+func GitHubTopicsBuiltinImpl(client *http.Client) func(bctx rego.BuiltinContext, op1, op2 *ast.Term) (*ast.Term, error) {
+	return func(bctx rego.BuiltinContext, op1, op2 *ast.Term) (*ast.Term, error) {
+		var owner, repo string
+
+		if err := ast.As(op1.Value, &owner); err != nil {
+			return nil, err
+		} else if err := ast.As(op2.Value, &repo); err != nil {
+			return nil, err
+		}
+
+		var resp struct {
+			Names []string `json:"names"`
+		}
+
+		status, err := githubGet(bctx.Context, client, repoPath(owner, repo, "topics"), &resp)
+		if err != nil {
+			return nil, err
+		} else if status == http.StatusNotFound {
+			return nil, nil
+		} else if status != http.StatusOK {
+			return nil, fmt.Errorf("get topics: unexpected status %d", status)
+		}
+
+		if resp.Names == nil {
+			resp.Names = []string{}
+		}
+
+		val, err := ast.InterfaceToValue(resp.Names)
+		if err != nil {
+			return nil, err
+		}
+
+		return ast.NewTerm(val), nil
+	}
+}
+
+// GitHubRepoMetadataBuiltinImpl fetches the description, homepage and
+// topics of a repository. Returns undefined if the repository doesn't
+// exist.
+func GitHubRepoMetadataBuiltinImpl(client *http.Client) func(bctx rego.BuiltinContext, op1, op2 *ast.Term) (*ast.Term, error) {
+	return func(bctx rego.BuiltinContext, op1, op2 *ast.Term) (*ast.Term, error) {
+		var owner, repo string
+
+		if err := ast.As(op1.Value, &owner); err != nil {
+			return nil, err
+		} else if err := ast.As(op2.Value, &repo); err != nil {
+			return nil, err
+		}
+
+		var resp struct {
+			Description *string  `json:"description"`
+			Homepage    *string  `json:"homepage"`
+			Topics      []string `json:"topics"`
+		}
+
+		status, err := githubGet(bctx.Context, client, repoPath(owner, repo), &resp)
+		if err != nil {
+			return nil, err
+		} else if status == http.StatusNotFound {
+			return nil, nil
+		} else if status != http.StatusOK {
+			return nil, fmt.Errorf("get repository: unexpected status %d", status)
+		}
+
+		meta := RepoMetadata{Topics: resp.Topics}
+
+		if resp.Description != nil {
+			meta.Description = *resp.Description
+		}
+
+		if resp.Homepage != nil {
+			meta.Homepage = *resp.Homepage
+		}
+
+		if meta.Topics == nil {
+			meta.Topics = []string{}
+		}
+
+		val, err := ast.InterfaceToValue(meta)
+		if err != nil {
+			return nil, err
+		}
+
+		return ast.NewTerm(val), nil
+	}
+}
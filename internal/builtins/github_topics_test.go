@@ -0,0 +1,98 @@
+package builtins_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/reposaur/reposaur/internal/builtins"
+)
+
+func newTopicsStubClient(t *testing.T) *http.Client {
+	return newStubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/reposaur/reposaur/topics":
+			_, _ = w.Write([]byte(`{"names": ["policy", "team-platform"]}`))
+
+		case "/repos/reposaur/reposaur":
+			_, _ = w.Write([]byte(`{"description": "Audit your GitHub data", "homepage": "https://reposaur.com", "topics": ["policy"]}`))
+
+		case "/repos/reposaur/empty/topics":
+			_, _ = w.Write([]byte(`{"names": []}`))
+
+		case "/repos/reposaur/empty":
+			_, _ = w.Write([]byte(`{"description": null, "homepage": null}`))
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestGitHubTopics(t *testing.T) {
+	impl := builtins.GitHubTopicsBuiltinImpl(newTopicsStubClient(t))
+
+	cases := []struct {
+		repo     string
+		expected *ast.Term
+	}{
+		{"reposaur", ast.ArrayTerm(ast.StringTerm("policy"), ast.StringTerm("team-platform"))},
+		{"empty", ast.ArrayTerm()},
+		{"missing", nil},
+	}
+
+	for _, c := range cases {
+		term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm(c.repo))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if c.expected == nil {
+			if term != nil {
+				t.Errorf("%s: expected undefined, got %v", c.repo, term)
+			}
+
+			continue
+		}
+
+		if term == nil || !term.Equal(c.expected) {
+			t.Errorf("%s: expected %v, got %v", c.repo, c.expected, term)
+		}
+	}
+}
+
+func TestGitHubRepoMetadata(t *testing.T) {
+	impl := builtins.GitHubRepoMetadataBuiltinImpl(newTopicsStubClient(t))
+
+	cases := []struct {
+		repo     string
+		expected builtins.RepoMetadata
+	}{
+		{"reposaur", builtins.RepoMetadata{Description: "Audit your GitHub data", Homepage: "https://reposaur.com", Topics: []string{"policy"}}},
+		{"empty", builtins.RepoMetadata{Topics: []string{}}},
+	}
+
+	for _, c := range cases {
+		term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm(c.repo))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var meta builtins.RepoMetadata
+		if err := ast.As(term.Value, &meta); err != nil {
+			t.Fatal(err)
+		}
+
+		if meta.Description != c.expected.Description || meta.Homepage != c.expected.Homepage || len(meta.Topics) != len(c.expected.Topics) {
+			t.Errorf("%s: expected %+v, got %+v", c.repo, c.expected, meta)
+		}
+	}
+
+	term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm("missing"))
+	if err != nil {
+		t.Fatal(err)
+	} else if term != nil {
+		t.Errorf("expected undefined, got %v", term)
+	}
+}
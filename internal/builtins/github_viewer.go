@@ -0,0 +1,154 @@
+package builtins
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/types"
+)
+
+const (
+	// ViewerUser is the type of viewers authenticated
+	// as a user, e.g. with a personal access token.
+	ViewerUser = "user"
+
+	// ViewerApp is the type of viewers authenticated
+	// as a GitHub App installation.
+	ViewerApp = "app"
+
+	// ViewerAnonymous is the type of
+	// unauthenticated viewers.
+	ViewerAnonymous = "anonymous"
+)
+
+var GitHubViewerBuiltin = rego.Function{
+	Name: "github.viewer",
+	Decl: types.NewFunction(
+		types.Args(),
+		types.NewObject(nil, types.NewDynamicProperty(types.S, types.A)),
+	),
+	Memoize: true,
+}
+
+// Viewer is the identity the GitHub API
+// requests are authenticated as.
+type Viewer struct {
+	Type   string   `json:"type"`
+	Login  string   `json:"login,omitempty"`
+	ID     int64    `json:"id,omitempty"`
+	Scopes []string `json:"scopes"`
+
+	// RepositorySelection is set for app installations,
+	// either "all" or "selected".
+	RepositorySelection string `json:"repository_selection,omitempty"`
+}
+
+// GitHubViewerBuiltinImpl returns the identity the client is
+// authenticated as, with the token's OAuth scopes. Installations
+// of GitHub Apps can't fetch /user, so they're detected by listing
+// their repositories instead. The identity of each client is
+// fetched once and reused by every evaluation.
+func GitHubViewerBuiltinImpl(client *http.Client) func(bctx rego.BuiltinContext, ops []*ast.Term) (*ast.Term, error) {
+	var (
+		mu      sync.Mutex
+		viewers = map[string]*ast.Term{}
+	)
+
+	return func(bctx rego.BuiltinContext, _ []*ast.Term) (*ast.Term, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		key := clientID(resolveClient(bctx.Context, client))
+
+		if viewer, ok := viewers[key]; ok {
+			return viewer, nil
+		}
+
+		v, err := fetchViewer(bctx, client)
+		if err != nil {
+			return nil, err
+		}
+
+		val, err := ast.InterfaceToValue(v)
+		if err != nil {
+			return nil, err
+		}
+
+		viewers[key] = ast.NewTerm(val)
+
+		return viewers[key], nil
+	}
+}
+
+func fetchViewer(bctx rego.BuiltinContext, client *http.Client) (Viewer, error) {
+	resp, err := githubDo(bctx.Context, client, "/user")
+	if err != nil {
+		return Viewer{}, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		var user struct {
+			Login string `json:"login"`
+			ID    int64  `json:"id"`
+		}
+
+		if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
+			return Viewer{}, err
+		}
+
+		return Viewer{
+			Type:   ViewerUser,
+			Login:  user.Login,
+			ID:     user.ID,
+			Scopes: parseScopes(resp.Header.Get("X-OAuth-Scopes")),
+		}, nil
+
+	case http.StatusUnauthorized:
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return Viewer{Type: ViewerAnonymous, Scopes: []string{}}, nil
+
+	case http.StatusForbidden:
+		_, _ = io.Copy(io.Discard, resp.Body)
+
+		var installation struct {
+			RepositorySelection string `json:"repository_selection"`
+		}
+
+		status, err := githubGet(bctx.Context, client, "/installation/repositories?per_page=1", &installation)
+		if err != nil {
+			return Viewer{}, err
+		} else if status != http.StatusOK {
+			return Viewer{}, fmt.Errorf("get viewer: unexpected status %d", status)
+		}
+
+		return Viewer{
+			Type:                ViewerApp,
+			Scopes:              []string{},
+			RepositorySelection: installation.RepositorySelection,
+		}, nil
+	}
+
+	return Viewer{}, fmt.Errorf("get viewer: unexpected status %d", resp.StatusCode)
+}
+
+// parseScopes parses the comma-separated scopes of the
+// X-OAuth-Scopes header. Fine-grained tokens don't have scopes.
+func parseScopes(header string) []string {
+	scopes := []string{}
+
+	for _, s := range strings.Split(header, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			scopes = append(scopes, s)
+		}
+	}
+
+	return scopes
+}
@@ -0,0 +1,124 @@
+package builtins_test
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/reposaur/reposaur/internal/builtins"
+	"github.com/reposaur/reposaur/pkg/util"
+)
+
+func TestGitHubViewerUser(t *testing.T) {
+	var calls int
+
+	client := newStubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+
+		if r.URL.Path != "/user" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("X-OAuth-Scopes", "repo, read:org")
+		_, _ = w.Write([]byte(`{"login": "octocat", "id": 1}`))
+	}))
+
+	impl := builtins.GitHubViewerBuiltinImpl(client)
+
+	for i := 0; i < 2; i++ {
+		term, err := impl(rego.BuiltinContext{}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var viewer builtins.Viewer
+		if err := ast.As(term.Value, &viewer); err != nil {
+			t.Fatal(err)
+		}
+
+		expected := builtins.Viewer{
+			Type:   builtins.ViewerUser,
+			Login:  "octocat",
+			ID:     1,
+			Scopes: []string{"repo", "read:org"},
+		}
+
+		if !reflect.DeepEqual(viewer, expected) {
+			t.Errorf("expected %+v, got %+v", expected, viewer)
+		}
+	}
+
+	if calls != 1 {
+		t.Errorf("expected viewer to be fetched once, got %d requests", calls)
+	}
+}
+
+func TestGitHubViewerPerClient(t *testing.T) {
+	newUserClient := func(login string) *http.Client {
+		return newStubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"login": "` + login + `", "id": 1}`))
+		}))
+	}
+
+	impl := builtins.GitHubViewerBuiltinImpl(newUserClient("octocat"))
+
+	for _, tc := range []struct {
+		bctx  rego.BuiltinContext
+		login string
+	}{
+		{rego.BuiltinContext{}, "octocat"},
+		{rego.BuiltinContext{Context: util.NewClientContext(context.Background(), newUserClient("hubot"))}, "hubot"},
+	} {
+		term, err := impl(tc.bctx, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var viewer builtins.Viewer
+		if err := ast.As(term.Value, &viewer); err != nil {
+			t.Fatal(err)
+		}
+
+		if viewer.Login != tc.login {
+			t.Errorf("expected viewer %s, got %s", tc.login, viewer.Login)
+		}
+	}
+}
+
+func TestGitHubViewerApp(t *testing.T) {
+	client := newStubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/user":
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"message": "Resource not accessible by integration"}`))
+		case "/installation/repositories":
+			_, _ = w.Write([]byte(`{"total_count": 1, "repository_selection": "selected", "repositories": []}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	term, err := builtins.GitHubViewerBuiltinImpl(client)(rego.BuiltinContext{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var viewer builtins.Viewer
+	if err := ast.As(term.Value, &viewer); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := builtins.Viewer{
+		Type:                builtins.ViewerApp,
+		Scopes:              []string{},
+		RepositorySelection: "selected",
+	}
+
+	if !reflect.DeepEqual(viewer, expected) {
+		t.Errorf("expected %+v, got %+v", expected, viewer)
+	}
+}
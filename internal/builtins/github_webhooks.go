@@ -0,0 +1,176 @@
+package builtins
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/types"
+)
+
+var GitHubWebhooksBuiltin = rego.Function{
+	Name: "github.webhooks",
+	Decl: types.NewFunction(
+		types.Args(types.S, types.S),
+		types.NewArray(nil, types.NewObject(nil, types.NewDynamicProperty(types.S, types.A))),
+	),
+	Memoize: true,
+}
+
+var GitHubOrgWebhooksBuiltin = rego.Function{
+	Name: "github.org_webhooks",
+	Decl: types.NewFunction(
+		types.Args(types.S),
+		types.NewArray(nil, types.NewObject(nil, types.NewDynamicProperty(types.S, types.A))),
+	),
+	Memoize: true,
+}
+
+// Webhook is a normalized view of a repository or organization
+// webhook. Its secret is never included, only whether it has one,
+// and credentials in its URL are redacted.
+type Webhook struct {
+	ID     int      `json:"id"`
+	Active bool     `json:"active"`
+	Events []string `json:"events"`
+
+	URL         string `json:"url"`
+	Host        string `json:"host"`
+	ContentType string `json:"content_type"`
+
+	// InsecureSSL is true if the SSL certificate
+	// of the URL's host isn't verified.
+	InsecureSSL bool `json:"insecure_ssl"`
+	HasSecret   bool `json:"has_secret"`
+}
+
+type webhookResponse struct {
+	ID     int      `json:"id"`
+	Active bool     `json:"active"`
+	Events []string `json:"events"`
+	Config struct {
+		URL         string      `json:"url"`
+		ContentType string      `json:"content_type"`
+		InsecureSSL interface{} `json:"insecure_ssl"`
+		Secret      string      `json:"secret"`
+	} `json:"config"`
+}
+
+// GitHubWebhooksBuiltinImpl fetches the webhooks of a repository and
+// returns them normalized, without their secrets. Repositories without
+// webhooks have an empty list. Listing them requires admin access to
+// the repository, so the result is undefined when the request is
+// forbidden or the repository doesn't exist.
+func GitHubWebhooksBuiltinImpl(client *http.Client) func(bctx rego.BuiltinContext, op1, op2 *ast.Term) (*ast.Term, error) {
+	return func(bctx rego.BuiltinContext, op1, op2 *ast.Term) (*ast.Term, error) {
+		var owner, repo string
+
+		if err := ast.As(op1.Value, &owner); err != nil {
+			return nil, err
+		} else if err := ast.As(op2.Value, &repo); err != nil {
+			return nil, err
+		}
+
+		return fetchWebhooks(bctx, client, repoPath(owner, repo, "hooks"))
+	}
+}
+
+// GitHubOrgWebhooksBuiltinImpl fetches the webhooks of an organization
+// like github.webhooks. Listing them requires owner credentials, so the
+// result is undefined when the request is forbidden or the organization
+// doesn't exist.
+func GitHubOrgWebhooksBuiltinImpl(client *http.Client) func(bctx rego.BuiltinContext, op1 *ast.Term) (*ast.Term, error) {
+	return func(bctx rego.BuiltinContext, op1 *ast.Term) (*ast.Term, error) {
+		var org string
+
+		if err := ast.As(op1.Value, &org); err != nil {
+			return nil, err
+		}
+
+		return fetchWebhooks(bctx, client, "/orgs/"+url.PathEscape(org)+"/hooks")
+	}
+}
+
+func fetchWebhooks(bctx rego.BuiltinContext, client *http.Client, path string) (*ast.Term, error) {
+	items, status, err := githubGetPages(bctx.Context, client, path, "", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	switch status {
+	case http.StatusOK:
+	case http.StatusForbidden, http.StatusNotFound:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("get webhooks: unexpected status %d", status)
+	}
+
+	var resp []webhookResponse
+
+	if err := decodeItems(items, &resp); err != nil {
+		return nil, err
+	}
+
+	hooks := make([]Webhook, 0, len(resp))
+	for _, r := range resp {
+		hooks = append(hooks, normalizeWebhook(r))
+	}
+
+	val, err := ast.InterfaceToValue(hooks)
+	if err != nil {
+		return nil, err
+	}
+
+	return ast.NewTerm(val), nil
+}
+
+func normalizeWebhook(resp webhookResponse) Webhook {
+	hook := Webhook{
+		ID:          resp.ID,
+		Active:      resp.Active,
+		Events:      resp.Events,
+		URL:         resp.Config.URL,
+		ContentType: resp.Config.ContentType,
+		HasSecret:   resp.Config.Secret != "",
+	}
+
+	if hook.Events == nil {
+		hook.Events = []string{}
+	}
+
+	if u, err := url.Parse(hook.URL); err == nil {
+		hook.Host = u.Hostname()
+		hook.URL = redactURL(u)
+	}
+
+	// insecure_ssl is either a string or a number,
+	// "0" (verified) or "1" (not verified)
+	hook.InsecureSSL = fmt.Sprint(resp.Config.InsecureSSL) == "1"
+
+	return hook
+}
+
+// redactedValue replaces credentials in webhook URLs.
+const redactedValue = "REDACTED"
+
+// redactURL returns u with its password and
+// the values of its query parameters redacted,
+// as they're often used to pass credentials.
+func redactURL(u *url.URL) string {
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), redactedValue)
+	}
+
+	if u.RawQuery != "" {
+		query := u.Query()
+		for k := range query {
+			query[k] = []string{redactedValue}
+		}
+
+		u.RawQuery = query.Encode()
+	}
+
+	return u.String()
+}
@@ -0,0 +1,310 @@
+package builtins
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/types"
+)
+
+// Access levels of the GITHUB_TOKEN scopes.
+const (
+	TokenAccessNone  = "none"
+	TokenAccessRead  = "read"
+	TokenAccessWrite = "write"
+)
+
+// effectivePermissionsKey is the key added to parsed
+// workflows and their jobs with the normalized permissions.
+const effectivePermissionsKey = "effective_permissions"
+
+// workflowTokenScopes are the scopes of the GITHUB_TOKEN
+// that can be set in the permissions of a workflow.
+var workflowTokenScopes = []string{
+	"actions", "checks", "contents", "deployments", "discussions", "id-token", "issues",
+	"packages", "pages", "pull-requests", "repository-projects", "security-events", "statuses",
+}
+
+// restrictedTokenScopes are the scopes of the GITHUB_TOKEN when
+// the repository's default workflow permissions are read-only.
+var restrictedTokenScopes = map[string]string{
+	"contents": TokenAccessRead,
+	"packages": TokenAccessRead,
+}
+
+var tokenAccessRanks = map[string]int{
+	TokenAccessNone:  0,
+	TokenAccessRead:  1,
+	TokenAccessWrite: 2,
+}
+
+var GitHubWorkflowPermissionsBuiltin = rego.Function{
+	Name: "github.workflow_permissions",
+	Decl: types.NewFunction(
+		types.Args(types.S, types.S),
+		types.NewObject(nil, types.NewDynamicProperty(types.S, types.A)),
+	),
+	Memoize: true,
+}
+
+// WorkflowPermissions are the normalized permissions of the
+// GITHUB_TOKEN of a workflow or job: the access level of every
+// scope and whether they were explicitly declared.
+type WorkflowPermissions struct {
+	Explicit bool              `json:"explicit"`
+	Scopes   map[string]string `json:"scopes"`
+}
+
+// WorkflowPermissionsSummary summarizes the permissions of
+// the workflows of a repository.
+type WorkflowPermissionsSummary struct {
+	// Default is the repository's default workflow permissions
+	// (read or write), empty if it couldn't be read.
+	Default string `json:"default"`
+
+	Workflows map[string]WorkflowPermissionsEntry `json:"workflows"`
+
+	// Max is the highest access level
+	// of each scope in any job.
+	Max map[string]string `json:"max"`
+}
+
+// WorkflowPermissionsEntry are the permissions
+// of a workflow and each of its jobs.
+type WorkflowPermissionsEntry struct {
+	Permissions WorkflowPermissions            `json:"permissions"`
+	Jobs        map[string]WorkflowPermissions `json:"jobs"`
+}
+
+// GitHubWorkflowPermissionsBuiltinImpl summarizes the GITHUB_TOKEN
+// permissions of the workflows in the default branch of a repository,
+// see WorkflowPermissionsSummary.
+func GitHubWorkflowPermissionsBuiltinImpl(client *http.Client) func(bctx rego.BuiltinContext, op1, op2 *ast.Term) (*ast.Term, error) {
+	return func(bctx rego.BuiltinContext, op1, op2 *ast.Term) (*ast.Term, error) {
+		var owner, repo string
+
+		if err := ast.As(op1.Value, &owner); err != nil {
+			return nil, err
+		} else if err := ast.As(op2.Value, &repo); err != nil {
+			return nil, err
+		}
+
+		def, err := fetchDefaultWorkflowPermissions(bctx, client, owner, repo)
+		if err != nil {
+			return nil, err
+		}
+
+		workflows, err := fetchWorkflows(bctx, client, owner, repo, "")
+		if err != nil {
+			return nil, err
+		}
+
+		summary := WorkflowPermissionsSummary{
+			Default:   def,
+			Workflows: map[string]WorkflowPermissionsEntry{},
+			Max:       map[string]string{},
+		}
+
+		for _, scope := range workflowTokenScopes {
+			summary.Max[scope] = TokenAccessNone
+		}
+
+		for path, w := range workflows {
+			workflow, _ := w.(map[string]interface{})
+
+			entry, err := workflowPermissionsEntry(workflow, def)
+			if err != nil {
+				return nil, fmt.Errorf("workflow %s: %w", path, err)
+			}
+
+			for _, job := range entry.Jobs {
+				for scope, access := range job.Scopes {
+					if tokenAccessRanks[access] > tokenAccessRanks[summary.Max[scope]] {
+						summary.Max[scope] = access
+					}
+				}
+			}
+
+			summary.Workflows[path] = entry
+		}
+
+		val, err := ast.InterfaceToValue(summary)
+		if err != nil {
+			return nil, err
+		}
+
+		return ast.NewTerm(val), nil
+	}
+}
+
+// fetchDefaultWorkflowPermissions returns the default workflow
+// permissions of a repository, read or write, or empty if they
+// can't be read, e.g. without administration access.
+func fetchDefaultWorkflowPermissions(bctx rego.BuiltinContext, client *http.Client, owner, repo string) (string, error) {
+	var resp struct {
+		DefaultWorkflowPermissions string `json:"default_workflow_permissions"`
+	}
+
+	status, err := githubGet(bctx.Context, client, repoPath(owner, repo, "actions", "permissions", "workflow"), &resp)
+	if err != nil {
+		return "", err
+	} else if status != http.StatusOK {
+		return "", nil
+	}
+
+	return resp.DefaultWorkflowPermissions, nil
+}
+
+// workflowPermissionsEntry normalizes the permissions of
+// a parsed workflow and its jobs, see addWorkflowPermissions.
+func workflowPermissionsEntry(workflow map[string]interface{}, def string) (WorkflowPermissionsEntry, error) {
+	entry := WorkflowPermissionsEntry{Jobs: map[string]WorkflowPermissions{}}
+
+	perms, ok := workflow["permissions"]
+	if ok {
+		scopes, err := parseWorkflowPermissions(perms)
+		if err != nil {
+			return entry, err
+		}
+
+		entry.Permissions = WorkflowPermissions{Explicit: true, Scopes: scopes}
+	} else {
+		entry.Permissions = WorkflowPermissions{Scopes: defaultTokenScopes(def)}
+	}
+
+	jobs, _ := workflow["jobs"].(map[string]interface{})
+
+	for id, j := range jobs {
+		job, _ := j.(map[string]interface{})
+
+		perms, ok := job["permissions"]
+		if !ok {
+			entry.Jobs[id] = entry.Permissions
+			continue
+		}
+
+		scopes, err := parseWorkflowPermissions(perms)
+		if err != nil {
+			return entry, fmt.Errorf("job %s: %w", id, err)
+		}
+
+		entry.Jobs[id] = WorkflowPermissions{Explicit: true, Scopes: scopes}
+	}
+
+	return entry, nil
+}
+
+// addWorkflowPermissions adds the normalized permissions to a parsed
+// workflow and each of its jobs, under the effective_permissions key.
+// Workflows and jobs that don't declare permissions get the default
+// ones (see defaultTokenScopes) and inherit the workflow's, respectively.
+func addWorkflowPermissions(workflow map[string]interface{}, def string) error {
+	entry, err := workflowPermissionsEntry(workflow, def)
+	if err != nil {
+		return err
+	}
+
+	workflow[effectivePermissionsKey] = workflowPermissionsValue(entry.Permissions)
+
+	jobs, _ := workflow["jobs"].(map[string]interface{})
+
+	for id, j := range jobs {
+		if job, ok := j.(map[string]interface{}); ok {
+			job[effectivePermissionsKey] = workflowPermissionsValue(entry.Jobs[id])
+		}
+	}
+
+	return nil
+}
+
+func workflowPermissionsValue(p WorkflowPermissions) map[string]interface{} {
+	scopes := make(map[string]interface{}, len(p.Scopes))
+	for k, v := range p.Scopes {
+		scopes[k] = v
+	}
+
+	return map[string]interface{}{
+		"explicit": p.Explicit,
+		"scopes":   scopes,
+	}
+}
+
+// parseWorkflowPermissions parses the permissions of a workflow or
+// job: read-all, write-all or an object with the access level of some
+// scopes, in which case the others have none.
+func parseWorkflowPermissions(v interface{}) (map[string]string, error) {
+	scopes := map[string]string{}
+
+	switch tv := v.(type) {
+	case string:
+		var access string
+
+		switch tv {
+		case "read-all":
+			access = TokenAccessRead
+		case "write-all":
+			access = TokenAccessWrite
+		default:
+			return nil, fmt.Errorf("invalid permissions '%s'", tv)
+		}
+
+		for _, scope := range workflowTokenScopes {
+			scopes[scope] = access
+		}
+
+		return scopes, nil
+
+	case map[string]interface{}, nil:
+		obj, _ := tv.(map[string]interface{})
+
+		for _, scope := range workflowTokenScopes {
+			scopes[scope] = TokenAccessNone
+		}
+
+		keys := make([]string, 0, len(obj))
+		for k := range obj {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, scope := range keys {
+			access, ok := obj[scope].(string)
+			if _, known := tokenAccessRanks[access]; !ok || !known {
+				return nil, fmt.Errorf("invalid access '%v' for %s", obj[scope], scope)
+			}
+
+			scopes[scope] = access
+		}
+
+		return scopes, nil
+	}
+
+	return nil, fmt.Errorf("invalid permissions '%v'", v)
+}
+
+// defaultTokenScopes returns the scopes of the GITHUB_TOKEN when
+// permissions aren't declared, depending on the repository's default
+// workflow permissions. If the default is unknown, the permissive
+// default (write) is assumed.
+func defaultTokenScopes(def string) map[string]string {
+	scopes := map[string]string{}
+
+	for _, scope := range workflowTokenScopes {
+		switch {
+		case def == TokenAccessRead:
+			scopes[scope] = TokenAccessNone
+			if access, ok := restrictedTokenScopes[scope]; ok {
+				scopes[scope] = access
+			}
+		case scope == "id-token":
+			scopes[scope] = TokenAccessNone
+		default:
+			scopes[scope] = TokenAccessWrite
+		}
+	}
+
+	return scopes
+}
@@ -0,0 +1,151 @@
+package builtins_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/reposaur/reposaur/internal/builtins"
+)
+
+const testExplicitPermissionsWorkflow = `
+on: push
+
+permissions:
+  contents: read
+
+jobs:
+  build:
+    runs-on: ubuntu-latest
+  release:
+    runs-on: ubuntu-latest
+    permissions:
+      contents: write
+      id-token: write
+  lint:
+    runs-on: ubuntu-latest
+    permissions: read-all
+`
+
+const testImplicitPermissionsWorkflow = `
+on: push
+
+jobs:
+  test:
+    runs-on: ubuntu-latest
+`
+
+// newWorkflowPermissionsStubClient serves the workflows and, if def
+// isn't empty, the repository's default workflow permissions.
+func newWorkflowPermissionsStubClient(t *testing.T, def string) *http.Client {
+	contents := contentsHandler(map[string]string{
+		".github/workflows/explicit.yml": testExplicitPermissionsWorkflow,
+		".github/workflows/implicit.yml": testImplicitPermissionsWorkflow,
+	})
+
+	return newStubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/reposaur/test/actions/permissions/workflow" {
+			contents.ServeHTTP(w, r)
+			return
+		}
+
+		if def == "" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"default_workflow_permissions": "` + def + `"}`))
+	}))
+}
+
+func TestGitHubWorkflowPermissions(t *testing.T) {
+	impl := builtins.GitHubWorkflowPermissionsBuiltinImpl(newWorkflowPermissionsStubClient(t, "read"))
+
+	term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm("test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var summary builtins.WorkflowPermissionsSummary
+	if err := ast.As(term.Value, &summary); err != nil {
+		t.Fatal(err)
+	}
+
+	if summary.Default != "read" {
+		t.Errorf("expected default to be read, got '%s'", summary.Default)
+	}
+
+	explicit := summary.Workflows[".github/workflows/explicit.yml"]
+
+	cases := []struct {
+		name        string
+		permissions builtins.WorkflowPermissions
+		explicit    bool
+		scopes      map[string]string
+	}{
+		{"explicit workflow", explicit.Permissions, true, map[string]string{"contents": "read", "issues": "none"}},
+		{"inheriting job", explicit.Jobs["build"], true, map[string]string{"contents": "read", "id-token": "none"}},
+		{"explicit job", explicit.Jobs["release"], true, map[string]string{"contents": "write", "id-token": "write", "packages": "none"}},
+		{"read-all job", explicit.Jobs["lint"], true, map[string]string{"contents": "read", "issues": "read"}},
+		{"implicit job", summary.Workflows[".github/workflows/implicit.yml"].Jobs["test"], false, map[string]string{"contents": "read", "packages": "read", "issues": "none"}},
+	}
+
+	for _, c := range cases {
+		if c.permissions.Explicit != c.explicit {
+			t.Errorf("%s: expected explicit to be %v", c.name, c.explicit)
+		}
+
+		for scope, access := range c.scopes {
+			if got := c.permissions.Scopes[scope]; got != access {
+				t.Errorf("%s: expected %s to be %s, got '%s'", c.name, scope, access, got)
+			}
+		}
+	}
+
+	for scope, access := range map[string]string{"contents": "write", "id-token": "write", "issues": "read", "pages": "read"} {
+		if got := summary.Max[scope]; got != access {
+			t.Errorf("expected the max of %s to be %s, got '%s'", scope, access, got)
+		}
+	}
+}
+
+func TestGitHubWorkflowPermissionsUnknownDefault(t *testing.T) {
+	impl := builtins.GitHubWorkflowPermissionsBuiltinImpl(newWorkflowPermissionsStubClient(t, ""))
+
+	term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm("test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var summary builtins.WorkflowPermissionsSummary
+	if err := ast.As(term.Value, &summary); err != nil {
+		t.Fatal(err)
+	}
+
+	implicit := summary.Workflows[".github/workflows/implicit.yml"].Permissions
+
+	if implicit.Explicit || implicit.Scopes["contents"] != "write" || implicit.Scopes["id-token"] != "none" {
+		t.Errorf("expected the permissive defaults to be assumed, got %+v", implicit)
+	}
+}
+
+func TestGitHubWorkflowsEffectivePermissions(t *testing.T) {
+	impl := builtins.GitHubWorkflowsBuiltinImpl(newWorkflowPermissionsStubClient(t, "read"))
+
+	term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm("test"), ast.StringTerm(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ref := ast.MustParseRef(`x[".github/workflows/explicit.yml"].jobs.release.effective_permissions.scopes.contents`)
+
+	got, err := term.Value.Find(ref[1:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Compare(ast.String("write")) != 0 {
+		t.Errorf("expected the release job to have contents write, got %v", got)
+	}
+}
@@ -0,0 +1,110 @@
+package builtins
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/types"
+)
+
+const workflowsDir = ".github/workflows"
+
+var GitHubWorkflowsBuiltin = rego.Function{
+	Name: "github.workflows",
+	Decl: types.NewFunction(
+		types.Args(types.S, types.S, types.S),
+		types.NewObject(nil, types.NewDynamicProperty(types.S, types.A)),
+	),
+	Memoize: true,
+}
+
+// GitHubWorkflowsBuiltinImpl fetches every workflow file in a
+// repository at ref (the default branch if empty) and returns them
+// parsed, keyed by path. Workflows and their jobs include their
+// normalized effective_permissions, unless they're invalid. Returns
+// an empty object if the repository has no workflows.
+func GitHubWorkflowsBuiltinImpl(client *http.Client) func(bctx rego.BuiltinContext, op1, op2, op3 *ast.Term) (*ast.Term, error) {
+	return func(bctx rego.BuiltinContext, op1, op2, op3 *ast.Term) (*ast.Term, error) {
+		var owner, repo, ref string
+
+		if err := ast.As(op1.Value, &owner); err != nil {
+			return nil, err
+		} else if err := ast.As(op2.Value, &repo); err != nil {
+			return nil, err
+		} else if err := ast.As(op3.Value, &ref); err != nil {
+			return nil, err
+		}
+
+		workflows, err := fetchWorkflows(bctx, client, owner, repo, ref)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(workflows) > 0 {
+			def, err := fetchDefaultWorkflowPermissions(bctx, client, owner, repo)
+			if err != nil {
+				return nil, err
+			}
+
+			// workflows with invalid permissions are returned
+			// as they are, github.workflow_permissions fails
+			for _, w := range workflows {
+				if workflow, ok := w.(map[string]interface{}); ok {
+					_ = addWorkflowPermissions(workflow, def)
+				}
+			}
+		}
+
+		val, err := ast.InterfaceToValue(workflows)
+		if err != nil {
+			return nil, err
+		}
+
+		return ast.NewTerm(val), nil
+	}
+}
+
+func fetchWorkflows(bctx rego.BuiltinContext, client *http.Client, owner, repo, ref string) (map[string]interface{}, error) {
+	var entries []struct {
+		Path string `json:"path"`
+		Type string `json:"type"`
+	}
+
+	listPath := withQuery(repoPath(owner, repo, "contents", workflowsDir), refQuery(ref))
+
+	status, err := githubGet(bctx.Context, client, listPath, &entries)
+	if err != nil {
+		return nil, err
+	} else if status == http.StatusNotFound {
+		return map[string]interface{}{}, nil
+	} else if status != http.StatusOK {
+		return nil, fmt.Errorf("list workflows: unexpected status %d", status)
+	}
+
+	workflows := map[string]interface{}{}
+
+	for _, e := range entries {
+		if ext := path.Ext(e.Path); e.Type != "file" || (ext != ".yml" && ext != ".yaml") {
+			continue
+		}
+
+		content, ok, err := githubContent(bctx.Context, client, owner, repo, e.Path, ref)
+		if err != nil {
+			return nil, err
+		} else if !ok {
+			continue
+		}
+
+		workflow, err := parseYAML(content)
+		if err != nil {
+			return nil, fmt.Errorf("parse workflow %s: %w", e.Path, err)
+		}
+
+		workflows[e.Path] = workflow
+	}
+
+	return workflows, nil
+}
@@ -0,0 +1,91 @@
+package builtins_test
+
+import (
+	"testing"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/reposaur/reposaur/internal/builtins"
+)
+
+const testWorkflow = `
+name: CI
+
+on:
+  push:
+    branches: [main]
+  schedule:
+    - cron: "0 * * * *"
+
+jobs:
+  test:
+    runs-on: self-hosted
+    steps:
+      - uses: actions/checkout@v3
+      - run: go test ./...
+`
+
+func TestGitHubWorkflows(t *testing.T) {
+	client := newStubClient(t, contentsHandler(map[string]string{
+		".github/workflows/ci.yml":    testWorkflow,
+		".github/workflows/README.md": "# Workflows",
+		".github/workflows/lint.yaml": "on: pull_request\njobs: {}\n",
+		".github/dependabot.yml":      "version: 2\n",
+	}))
+
+	impl := builtins.GitHubWorkflowsBuiltinImpl(client)
+
+	term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm("test"), ast.StringTerm("main"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var workflows map[string]map[string]interface{}
+	if err := ast.As(term.Value, &workflows); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(workflows) != 2 {
+		t.Fatalf("expected 2 workflows, got %d", len(workflows))
+	}
+
+	ci, ok := workflows[".github/workflows/ci.yml"]
+	if !ok {
+		t.Fatal("expected ci.yml workflow")
+	}
+
+	on, ok := ci["on"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected 'on' key to be an object, got %v", ci["on"])
+	}
+
+	if _, ok := on["schedule"]; !ok {
+		t.Error("expected schedule trigger")
+	}
+
+	runsOn := ci["jobs"].(map[string]interface{})["test"].(map[string]interface{})["runs-on"]
+	if runsOn != "self-hosted" {
+		t.Errorf("expected runs-on to be self-hosted, got %v", runsOn)
+	}
+
+	if lint := workflows[".github/workflows/lint.yaml"]; lint["on"] != "pull_request" {
+		t.Errorf("expected lint.yaml to be triggered on pull_request, got %v", lint["on"])
+	}
+}
+
+func TestGitHubWorkflowsNone(t *testing.T) {
+	client := newStubClient(t, contentsHandler(map[string]string{
+		"README.md": "# Test",
+	}))
+
+	impl := builtins.GitHubWorkflowsBuiltinImpl(client)
+
+	term, err := impl(rego.BuiltinContext{}, ast.StringTerm("reposaur"), ast.StringTerm("test"), ast.StringTerm(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if term.Value.Compare(ast.NewObject()) != 0 {
+		t.Errorf("expected empty object, got %v", term.Value)
+	}
+}
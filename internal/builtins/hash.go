@@ -0,0 +1,62 @@
+package builtins
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"strings"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/types"
+)
+
+// hashAlgorithms are the algorithms supported by hash.verify.
+var hashAlgorithms = map[string]func() hash.Hash{
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+var HashVerifyBuiltin = rego.Function{
+	Name: "hash.verify",
+	Decl: types.NewFunction(
+		types.Args(types.S, types.S, types.S),
+		types.B,
+	),
+	Memoize: true,
+}
+
+// HashVerifyBuiltinImpl reports whether the hash of content, computed
+// with the algorithm (sha256 or sha512), is the expected hex-encoded
+// digest. Digests are compared case-insensitively and may be prefixed
+// with the algorithm, e.g. "sha256:...". Unknown algorithms halt the
+// evaluation with an error.
+func HashVerifyBuiltinImpl(_ rego.BuiltinContext, op1, op2, op3 *ast.Term) (*ast.Term, error) {
+	var content, algorithm, expected string
+
+	if err := ast.As(op1.Value, &content); err != nil {
+		return nil, err
+	} else if err := ast.As(op2.Value, &algorithm); err != nil {
+		return nil, err
+	} else if err := ast.As(op3.Value, &expected); err != nil {
+		return nil, err
+	}
+
+	algorithm = strings.ToLower(algorithm)
+
+	newHash, ok := hashAlgorithms[algorithm]
+	if !ok {
+		return nil, fmt.Errorf("unsupported hash algorithm %q, must be one of sha256 and sha512", algorithm)
+	}
+
+	h := newHash()
+	_, _ = h.Write([]byte(content))
+
+	actual := hex.EncodeToString(h.Sum(nil))
+	expected = strings.ToLower(strings.TrimPrefix(expected, algorithm+":"))
+
+	return ast.BooleanTerm(subtle.ConstantTimeCompare([]byte(actual), []byte(expected)) == 1), nil
+}
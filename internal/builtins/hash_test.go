@@ -0,0 +1,46 @@
+package builtins_test
+
+import (
+	"testing"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/reposaur/reposaur/internal/builtins"
+)
+
+func TestHashVerify(t *testing.T) {
+	const (
+		sha256Hello = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+		sha512Hello = "9b71d224bd62f3785d96d46ad3ea3d73319bfbc2890caadae2dff72519673ca72323c3d99ba5c11d7c7acc6e14b8c5da0c4663475c2e5c3adef46f73bcdec043"
+	)
+
+	cases := []struct {
+		algorithm string
+		expected  string
+		valid     bool
+	}{
+		{"sha256", sha256Hello, true},
+		{"SHA256", "sha256:" + sha256Hello, true},
+		{"sha512", sha512Hello, true},
+		{"sha256", sha512Hello, false},
+		{"sha512", "0000", false},
+	}
+
+	for _, c := range cases {
+		term, err := builtins.HashVerifyBuiltinImpl(rego.BuiltinContext{}, ast.StringTerm("hello"), ast.StringTerm(c.algorithm), ast.StringTerm(c.expected))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := term.Value.Compare(ast.Boolean(c.valid)) == 0; !got {
+			t.Errorf("expected hash.verify with %s and %s to be %v", c.algorithm, c.expected, c.valid)
+		}
+	}
+}
+
+func TestHashVerifyUnknownAlgorithm(t *testing.T) {
+	_, err := builtins.HashVerifyBuiltinImpl(rego.BuiltinContext{}, ast.StringTerm("hello"), ast.StringTerm("md5"), ast.StringTerm(""))
+	if err == nil {
+		t.Error("expected an error for an unknown algorithm")
+	}
+}
@@ -0,0 +1,144 @@
+package builtins
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/types"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// schemaURL is the URL the schema is compiled as, so
+// references to it (e.g. "#/definitions/name") resolve.
+const schemaURL = "schema.json"
+
+// ErrInvalidSchema is returned by json.validate_schema
+// when the schema itself isn't valid.
+var ErrInvalidSchema = errors.New("invalid schema")
+
+var JSONValidateSchemaBuiltin = rego.Function{
+	Name: "json.validate_schema",
+	Decl: types.NewFunction(
+		types.Args(types.A, types.A),
+		types.NewArray(nil, types.NewObject(nil, types.NewDynamicProperty(types.S, types.S))),
+	),
+	Memoize: true,
+}
+
+// SchemaError is a violation of a
+// JSON Schema by a document.
+type SchemaError struct {
+	// Path is a JSON pointer (RFC 6901) to
+	// the invalid part of the document.
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// JSONValidateSchemaBuiltinImpl validates a document against a JSON
+// Schema and returns the validation errors, empty if it's valid. Both
+// the document and the schema can be values or JSON strings.
+//
+// Schemas are draft-07 unless they declare another draft with
+// "$schema", with references limited to the schema itself
+// (e.g. "#/definitions/name").
+func JSONValidateSchemaBuiltinImpl(_ rego.BuiltinContext, op1, op2 *ast.Term) (*ast.Term, error) {
+	doc, err := schemaOperand(op1)
+	if err != nil {
+		return nil, fmt.Errorf("invalid document: %w", err)
+	}
+
+	schema, err := schemaOperand(op2)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSchema, err)
+	}
+
+	compiled, err := compileSchema(schema)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSchema, err)
+	}
+
+	errs := []SchemaError{}
+
+	var verr *jsonschema.ValidationError
+
+	if err := compiled.Validate(doc); errors.As(err, &verr) {
+		errs = schemaErrors(verr, errs)
+	} else if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSchema, err)
+	}
+
+	sort.SliceStable(errs, func(i, j int) bool {
+		return errs[i].Path < errs[j].Path
+	})
+
+	val, err := ast.InterfaceToValue(errs)
+	if err != nil {
+		return nil, err
+	}
+
+	return ast.NewTerm(val), nil
+}
+
+// compileSchema compiles schema as a draft-07 schema, unless it
+// declares another draft. Remote references aren't loaded.
+func compileSchema(schema interface{}) (*jsonschema.Schema, error) {
+	b, err := json.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	c := jsonschema.NewCompiler()
+	c.Draft = jsonschema.Draft7
+	c.LoadURL = func(s string) (io.ReadCloser, error) {
+		return nil, fmt.Errorf("unsupported reference %s", s)
+	}
+
+	if err := c.AddResource(schemaURL, bytes.NewReader(b)); err != nil {
+		return nil, err
+	}
+
+	return c.Compile(schemaURL)
+}
+
+// schemaErrors appends the leaves of the tree of validation
+// errors to errs, the ones describing actual violations.
+func schemaErrors(verr *jsonschema.ValidationError, errs []SchemaError) []SchemaError {
+	if len(verr.Causes) == 0 {
+		return append(errs, SchemaError{
+			Path:    verr.InstanceLocation,
+			Message: verr.Message,
+		})
+	}
+
+	for _, cause := range verr.Causes {
+		errs = schemaErrors(cause, errs)
+	}
+
+	return errs
+}
+
+// schemaOperand returns the value of op, parsing
+// it if it's a JSON string.
+func schemaOperand(op *ast.Term) (interface{}, error) {
+	if s, ok := op.Value.(ast.String); ok {
+		var v interface{}
+
+		dec := json.NewDecoder(strings.NewReader(string(s)))
+		dec.UseNumber()
+
+		if err := dec.Decode(&v); err != nil {
+			return nil, err
+		}
+
+		return v, nil
+	}
+
+	return ast.JSON(op.Value)
+}
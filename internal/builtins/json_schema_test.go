@@ -0,0 +1,122 @@
+package builtins_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/reposaur/reposaur/internal/builtins"
+)
+
+const testRenovateSchema = `{
+	"type": "object",
+	"required": ["extends"],
+	"properties": {
+		"extends": {"type": "array", "items": {"type": "string"}, "minItems": 1},
+		"schedule": {"$ref": "#/definitions/schedule"},
+		"prConcurrentLimit": {"type": "integer", "minimum": 0},
+		"automerge": {"type": "boolean"}
+	},
+	"additionalProperties": false,
+	"definitions": {
+		"schedule": {
+			"oneOf": [
+				{"type": "string"},
+				{"type": "array", "items": {"type": "string"}}
+			]
+		}
+	}
+}`
+
+func validateSchema(t *testing.T, doc, schema *ast.Term) ([]builtins.SchemaError, error) {
+	t.Helper()
+
+	term, err := builtins.JSONValidateSchemaBuiltinImpl(rego.BuiltinContext{}, doc, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []builtins.SchemaError
+	if err := ast.As(term.Value, &errs); err != nil {
+		t.Fatal(err)
+	}
+
+	return errs, nil
+}
+
+func TestJSONValidateSchema(t *testing.T) {
+	cases := []struct {
+		name     string
+		doc      *ast.Term
+		expected []builtins.SchemaError
+	}{
+		{
+			name:     "valid string",
+			doc:      ast.StringTerm(`{"extends": ["config:base"], "schedule": ["before 5am"], "prConcurrentLimit": 5}`),
+			expected: []builtins.SchemaError{},
+		},
+		{
+			name: "valid value",
+			doc: objectTerm(t, map[string]interface{}{
+				"extends":   []interface{}{"config:base"},
+				"schedule":  "weekly",
+				"automerge": true,
+			}),
+			expected: []builtins.SchemaError{},
+		},
+		{
+			name: "invalid",
+			doc:  ast.StringTerm(`{"extends": [], "schedule": 1, "prConcurrentLimit": 1.5, "unknown": true}`),
+			expected: []builtins.SchemaError{
+				{Path: "", Message: "additionalProperties 'unknown' not allowed"},
+				{Path: "/extends", Message: "minimum 1 items required, but found 0 items"},
+				{Path: "/prConcurrentLimit", Message: "expected integer, but got number"},
+				{Path: "/schedule", Message: "expected string, but got number"},
+				{Path: "/schedule", Message: "expected array, but got number"},
+			},
+		},
+		{
+			name: "missing required",
+			doc:  ast.StringTerm(`{"automerge": "yes"}`),
+			expected: []builtins.SchemaError{
+				{Path: "", Message: "missing properties: 'extends'"},
+				{Path: "/automerge", Message: "expected boolean, but got string"},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			errs, err := validateSchema(t, c.doc, ast.StringTerm(testRenovateSchema))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(errs, c.expected) {
+				t.Errorf("expected %v, got %v", c.expected, errs)
+			}
+		})
+	}
+}
+
+func TestJSONValidateSchemaInvalidSchema(t *testing.T) {
+	schemas := []string{
+		`{"type": "text"}`,
+		`{"properties": {"name": {"minLength": -1}}}`,
+		`{"pattern": "("}`,
+		`{"$ref": "#/definitions/missing"}`,
+		`{"$ref": "#"}`,
+		`{"anyOf": []}`,
+		`{"$ref": "https://example.com/schema.json"}`,
+		`not json`,
+	}
+
+	for _, schema := range schemas {
+		_, err := validateSchema(t, ast.StringTerm(`{}`), ast.StringTerm(schema))
+		if !errors.Is(err, builtins.ErrInvalidSchema) {
+			t.Errorf("expected %s to be invalid, got %v", schema, err)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package builtins
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/types"
+)
+
+var JWTDecodeBuiltin = rego.Function{
+	Name: "jwt.decode",
+	Decl: types.NewFunction(
+		types.Args(types.S),
+		types.NewObject(nil, types.NewDynamicProperty(types.S, types.A)),
+	),
+	Memoize: true,
+}
+
+var JWTVerifyRS256Builtin = rego.Function{
+	Name: "jwt.verify_rs256",
+	Decl: types.NewFunction(
+		types.Args(types.S, types.S),
+		types.B,
+	),
+	Memoize: true,
+}
+
+// JWT is a decoded JSON Web Token.
+type JWT struct {
+	Header  map[string]interface{} `json:"header"`
+	Payload map[string]interface{} `json:"payload"`
+
+	// Signature is the hex-encoded signature.
+	Signature string `json:"signature"`
+}
+
+// JWTDecodeBuiltinImpl decodes the header and the payload of a
+// JWT in compact serialization, without verifying its signature
+// (see jwt.verify_rs256). Malformed tokens halt the evaluation
+// with an error.
+func JWTDecodeBuiltinImpl(_ rego.BuiltinContext, op1 *ast.Term) (*ast.Term, error) {
+	var token string
+
+	if err := ast.As(op1.Value, &token); err != nil {
+		return nil, err
+	}
+
+	jwt, _, err := decodeJWT(token)
+	if err != nil {
+		return nil, err
+	}
+
+	val, err := ast.InterfaceToValue(jwt)
+	if err != nil {
+		return nil, err
+	}
+
+	return ast.NewTerm(val), nil
+}
+
+// JWTVerifyRS256BuiltinImpl reports whether the JWT is signed with
+// RS256 by the key of the PEM-encoded certificate or public key.
+// Tokens signed with other algorithms aren't valid. Malformed tokens
+// or keys halt the evaluation with an error.
+func JWTVerifyRS256BuiltinImpl(_ rego.BuiltinContext, op1, op2 *ast.Term) (*ast.Term, error) {
+	var token, cert string
+
+	if err := ast.As(op1.Value, &token); err != nil {
+		return nil, err
+	} else if err := ast.As(op2.Value, &cert); err != nil {
+		return nil, err
+	}
+
+	jwt, sig, err := decodeJWT(token)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := parseRSAPublicKey(cert)
+	if err != nil {
+		return nil, err
+	}
+
+	if alg, _ := jwt.Header["alg"].(string); alg != "RS256" {
+		return ast.BooleanTerm(false), nil
+	}
+
+	signed := token[:strings.LastIndex(token, ".")]
+	digest := sha256.Sum256([]byte(signed))
+
+	err = rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], sig)
+
+	return ast.BooleanTerm(err == nil), nil
+}
+
+// decodeJWT decodes a JWT in compact serialization,
+// returning it and its raw signature.
+func decodeJWT(token string) (JWT, []byte, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return JWT{}, nil, fmt.Errorf("malformed jwt: expected 3 parts, got %d", len(parts))
+	}
+
+	var jwt JWT
+
+	if err := decodeJWTPart(parts[0], &jwt.Header); err != nil {
+		return JWT{}, nil, fmt.Errorf("malformed jwt header: %w", err)
+	}
+
+	if err := decodeJWTPart(parts[1], &jwt.Payload); err != nil {
+		return JWT{}, nil, fmt.Errorf("malformed jwt payload: %w", err)
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return JWT{}, nil, fmt.Errorf("malformed jwt signature: %w", err)
+	}
+
+	jwt.Signature = hex.EncodeToString(sig)
+
+	return jwt, sig, nil
+}
+
+func decodeJWTPart(part string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(part, "="))
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, v)
+}
+
+// parseRSAPublicKey parses a PEM-encoded certificate,
+// PKIX public key or PKCS #1 public key.
+func parseRSAPublicKey(s string) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode([]byte(s))
+	if block == nil {
+		return nil, errors.New("malformed key: expected a PEM block")
+	}
+
+	var (
+		pub interface{}
+		err error
+	)
+
+	switch block.Type {
+	case "CERTIFICATE":
+		var cert *x509.Certificate
+		if cert, err = x509.ParseCertificate(block.Bytes); err == nil {
+			pub = cert.PublicKey
+		}
+	case "PUBLIC KEY":
+		pub, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		pub, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("malformed key: unsupported PEM block %q", block.Type)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("malformed key: %w", err)
+	}
+
+	key, ok := pub.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("malformed key: not an RSA key")
+	}
+
+	return key, nil
+}
@@ -0,0 +1,195 @@
+package builtins
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/types"
+)
+
+// DefaultDNSTimeout is a sensible maximum duration of the
+// DNS lookups of `net.is_private_ip`, see WithDNSResolution.
+const DefaultDNSTimeout = 2 * time.Second
+
+// sharedAddressSpace is the IPv4 range used by carrier-grade
+// NAT (RFC 6598), which isn't publicly routable either.
+var sharedAddressSpace = &net.IPNet{
+	IP:   net.IPv4(100, 64, 0, 0),
+	Mask: net.CIDRMask(10, 32),
+}
+
+var NetHostAllowedBuiltin = rego.Function{
+	Name: "net.host_allowed",
+	Decl: types.NewFunction(
+		types.Args(types.S, types.NewArray(nil, types.S)),
+		types.B,
+	),
+}
+
+var NetIsPrivateIPBuiltin = rego.Function{
+	Name: "net.is_private_ip",
+	Decl: types.NewFunction(
+		types.Args(types.S),
+		types.B,
+	),
+	Memoize: true,
+}
+
+// NetHostAllowedBuiltinImpl returns whether the host of a URL (or a
+// host, optionally with a port) is in an allowlist. Entries are host
+// names, matched case-insensitively, wildcards such as *.example.com
+// matching any subdomain (but not example.com itself), IP addresses or
+// CIDR ranges, which only match hosts that are IP addresses: host names
+// aren't resolved. URLs without a host aren't allowed, and invalid
+// entries halt the evaluation with an error.
+func NetHostAllowedBuiltinImpl(bctx rego.BuiltinContext, op1, op2 *ast.Term) (*ast.Term, error) {
+	var (
+		rawURL    string
+		allowlist []string
+	)
+
+	if err := ast.As(op1.Value, &rawURL); err != nil {
+		return nil, err
+	} else if err := ast.As(op2.Value, &allowlist); err != nil {
+		return nil, err
+	}
+
+	host := parseHost(rawURL)
+	ip := net.ParseIP(host)
+
+	for _, entry := range allowlist {
+		ok, err := hostMatches(host, ip, entry)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			return ast.BooleanTerm(true), nil
+		}
+	}
+
+	return ast.BooleanTerm(false), nil
+}
+
+// NetIsPrivateIPBuiltinImpl returns whether a host (or the host of a URL)
+// is an IP address that isn't publicly routable: private (RFC 1918 and
+// RFC 4193), loopback, link-local, unspecified or in the shared address
+// space (RFC 6598). Host names are only resolved if enabled with
+// WithDNSResolution, and are private if any of their addresses is. The
+// result is undefined for host names if resolution is disabled, and
+// failed lookups halt the evaluation with an error, so rules relying on
+// it don't pass by mistake.
+func NetIsPrivateIPBuiltinImpl(bctx rego.BuiltinContext, op1 *ast.Term) (*ast.Term, error) {
+	var rawHost string
+
+	if err := ast.As(op1.Value, &rawHost); err != nil {
+		return nil, err
+	}
+
+	host := parseHost(rawHost)
+	if host == "" {
+		return nil, nil
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		return ast.BooleanTerm(isPrivateIP(ip)), nil
+	}
+
+	// host names aren't resolved unless the lookups
+	// have a timeout, see WithDNSResolution
+	timeout := contextOptions(bctx.Context).dnsTimeout
+	if timeout <= 0 {
+		return nil, nil
+	}
+
+	ctx := bctx.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, fmt.Errorf("net.is_private_ip: %w", err)
+	} else if len(addrs) == 0 {
+		return nil, fmt.Errorf("net.is_private_ip: no addresses for %s", host)
+	}
+
+	for _, addr := range addrs {
+		if isPrivateIP(addr.IP) {
+			return ast.BooleanTerm(true), nil
+		}
+	}
+
+	return ast.BooleanTerm(false), nil
+}
+
+// parseHost returns the lowercase host of a URL or a host,
+// optionally with a port, without brackets and trailing
+// dots. Returns empty if there isn't one.
+func parseHost(s string) string {
+	s = strings.TrimSpace(s)
+
+	if strings.Contains(s, "://") {
+		u, err := url.Parse(s)
+		if err != nil {
+			return ""
+		}
+
+		s = u.Hostname()
+	} else if host, _, err := net.SplitHostPort(s); err == nil {
+		s = host
+	}
+
+	s = strings.TrimSuffix(strings.Trim(s, "[]"), ".")
+
+	return strings.ToLower(s)
+}
+
+// hostMatches returns whether host, which is ip if it's an IP
+// address, matches an allowlist entry.
+func hostMatches(host string, ip net.IP, entry string) (bool, error) {
+	entry = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(entry), "."))
+
+	switch {
+	case entry == "" || entry == "*" || strings.Contains(entry, "://"):
+		return false, fmt.Errorf("invalid allowlist entry %q", entry)
+
+	case strings.Contains(entry, "/"):
+		_, ipNet, err := net.ParseCIDR(entry)
+		if err != nil {
+			return false, fmt.Errorf("invalid allowlist entry %q: %w", entry, err)
+		}
+
+		return ip != nil && ipNet.Contains(ip), nil
+
+	case net.ParseIP(entry) != nil:
+		return ip != nil && ip.Equal(net.ParseIP(entry)), nil
+
+	case strings.HasPrefix(entry, "*."):
+		return ip == nil && strings.HasSuffix(host, entry[1:]), nil
+
+	case strings.Contains(entry, "*"):
+		return false, fmt.Errorf("invalid allowlist entry %q: wildcards must be the first label", entry)
+	}
+
+	return host != "" && host == entry, nil
+}
+
+// isPrivateIP returns whether ip isn't publicly routable.
+func isPrivateIP(ip net.IP) bool {
+	return ip.IsPrivate() ||
+		ip.IsLoopback() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsUnspecified() ||
+		sharedAddressSpace.Contains(ip)
+}
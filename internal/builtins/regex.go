@@ -0,0 +1,127 @@
+package builtins
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"sync"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/types"
+)
+
+const (
+	// regexMaxPatternLength is the maximum length of
+	// patterns accepted by regex.match_safe.
+	regexMaxPatternLength = 1024
+
+	// regexMaxProgramSize is the maximum number of instructions
+	// of a compiled pattern accepted by regex.match_safe. Large
+	// counted repetitions (e.g. `(a{100}){100}`) blow up quickly.
+	regexMaxProgramSize = 10000
+
+	// regexMaxValueLength is the maximum length of
+	// values matched by regex.match_safe.
+	regexMaxValueLength = 1 << 20
+
+	// regexCacheSize is the maximum number of compiled
+	// patterns kept by regex.match_safe.
+	regexCacheSize = 256
+)
+
+// ErrRegexTooComplex is returned by regex.match_safe
+// when a pattern or value exceeds its limits.
+var ErrRegexTooComplex = errors.New("regex too complex")
+
+var RegexMatchSafeBuiltin = rego.Function{
+	Name: "regex.match_safe",
+	Decl: types.NewFunction(
+		types.Args(types.S, types.S),
+		types.B,
+	),
+	Memoize: true,
+}
+
+// RegexMatchSafeBuiltinImpl reports whether value matches pattern.
+// Unlike regex.match, patterns exceeding the complexity limits and
+// values exceeding the size limit are rejected, so patterns read
+// from repository contents can't slow down the evaluation.
+func RegexMatchSafeBuiltinImpl(_ rego.BuiltinContext, op1, op2 *ast.Term) (*ast.Term, error) {
+	var pattern, value string
+
+	if err := ast.As(op1.Value, &pattern); err != nil {
+		return nil, err
+	}
+
+	if err := ast.As(op2.Value, &value); err != nil {
+		return nil, err
+	}
+
+	if len(value) > regexMaxValueLength {
+		return nil, fmt.Errorf("%w: value exceeds %d bytes", ErrRegexTooComplex, regexMaxValueLength)
+	}
+
+	re, err := compileSafeRegex(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	return ast.BooleanTerm(re.MatchString(value)), nil
+}
+
+var regexCache = struct {
+	sync.Mutex
+	entries map[string]*regexp.Regexp
+}{
+	entries: map[string]*regexp.Regexp{},
+}
+
+// compileSafeRegex compiles pattern, checking it's within the
+// complexity limits. Compiled patterns are cached.
+func compileSafeRegex(pattern string) (*regexp.Regexp, error) {
+	regexCache.Lock()
+	re, ok := regexCache.entries[pattern]
+	regexCache.Unlock()
+
+	if ok {
+		return re, nil
+	}
+
+	if len(pattern) > regexMaxPatternLength {
+		return nil, fmt.Errorf("%w: pattern exceeds %d characters", ErrRegexTooComplex, regexMaxPatternLength)
+	}
+
+	parsed, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+
+	prog, err := syntax.Compile(parsed.Simplify())
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+
+	if len(prog.Inst) > regexMaxProgramSize {
+		return nil, fmt.Errorf("%w: pattern compiles to %d instructions, limit is %d", ErrRegexTooComplex, len(prog.Inst), regexMaxProgramSize)
+	}
+
+	re, err = regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+
+	regexCache.Lock()
+	defer regexCache.Unlock()
+
+	// the cache is small and patterns rarely vary,
+	// so it's simply reset once it's full
+	if len(regexCache.entries) >= regexCacheSize {
+		regexCache.entries = map[string]*regexp.Regexp{}
+	}
+
+	regexCache.entries[pattern] = re
+
+	return re, nil
+}
@@ -0,0 +1,66 @@
+package builtins_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/reposaur/reposaur/internal/builtins"
+)
+
+func TestRegexMatchSafe(t *testing.T) {
+	cases := []struct {
+		pattern  string
+		value    string
+		expected bool
+	}{
+		{`^v\d+\.\d+\.\d+$`, "v1.2.3", true},
+		{`^v\d+\.\d+\.\d+$`, "1.2.3", false},
+		{`(a+)+$`, strings.Repeat("a", 50) + "!", false},
+		{`^release/.*`, "release/2022-05", true},
+	}
+
+	for _, c := range cases {
+		term, err := builtins.RegexMatchSafeBuiltinImpl(rego.BuiltinContext{}, ast.StringTerm(c.pattern), ast.StringTerm(c.value))
+		if err != nil {
+			t.Fatalf("%s: %v", c.pattern, err)
+		}
+
+		if got := term.Value.Compare(ast.Boolean(c.expected)) == 0; !got {
+			t.Errorf("expected regex.match_safe(%q, %q) to be %v", c.pattern, c.value, c.expected)
+		}
+	}
+}
+
+func TestRegexMatchSafeInvalidPattern(t *testing.T) {
+	cases := map[string]bool{
+		`(unclosed`:                    false,
+		`a{2,1}`:                       false,
+		`(a{1000}){1000}`:              false,
+		strings.Repeat(`\w{1000}`, 12): true,
+		strings.Repeat("a", 2000):      true,
+	}
+
+	for pattern, tooComplex := range cases {
+		_, err := builtins.RegexMatchSafeBuiltinImpl(rego.BuiltinContext{}, ast.StringTerm(pattern), ast.StringTerm("a"))
+		if err == nil {
+			t.Errorf("expected %q to be rejected", pattern)
+			continue
+		}
+
+		if got := errors.Is(err, builtins.ErrRegexTooComplex); got != tooComplex {
+			t.Errorf("expected %q too complex to be %v, got %v", pattern, tooComplex, err)
+		}
+	}
+}
+
+func TestRegexMatchSafeLargeValue(t *testing.T) {
+	value := strings.Repeat("a", 2<<20)
+
+	_, err := builtins.RegexMatchSafeBuiltinImpl(rego.BuiltinContext{}, ast.StringTerm("a"), ast.StringTerm(value))
+	if !errors.Is(err, builtins.ErrRegexTooComplex) {
+		t.Errorf("expected large value to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,153 @@
+package builtins
+
+import (
+	"strings"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/types"
+)
+
+// spdxRule describes the licenses a project including
+// a work under a particular license can have.
+type spdxRule struct {
+	// only lists the licenses the project can have,
+	// if empty the project can have any known license.
+	only []string
+
+	// except lists the licenses the project can't have.
+	except []string
+}
+
+// spdxRules are the compatibility rules of the
+// known licenses, keyed by their SPDX ID.
+var spdxRules = map[string]spdxRule{
+	"0BSD":         {},
+	"BSD-2-Clause": {},
+	"BSD-3-Clause": {},
+	"BSL-1.0":      {},
+	"CC0-1.0":      {},
+	"ISC":          {},
+	"MIT":          {},
+	"Unlicense":    {},
+	"Zlib":         {},
+
+	"Apache-2.0": {except: []string{"GPL-2.0-only", "LGPL-2.1-only"}},
+	"MPL-2.0":    {},
+
+	"LGPL-2.1-only":     {},
+	"LGPL-2.1-or-later": {},
+	"LGPL-3.0-only":     {except: []string{"GPL-2.0-only", "LGPL-2.1-only"}},
+	"LGPL-3.0-or-later": {except: []string{"GPL-2.0-only", "LGPL-2.1-only"}},
+
+	"GPL-2.0-only": {only: []string{"GPL-2.0-only"}},
+	"GPL-2.0-or-later": {only: []string{
+		"GPL-2.0-only", "GPL-2.0-or-later",
+		"GPL-3.0-only", "GPL-3.0-or-later",
+		"AGPL-3.0-only", "AGPL-3.0-or-later",
+	}},
+	"GPL-3.0-only": {only: []string{
+		"GPL-3.0-only", "GPL-3.0-or-later",
+		"AGPL-3.0-only", "AGPL-3.0-or-later",
+	}},
+	"GPL-3.0-or-later": {only: []string{
+		"GPL-3.0-only", "GPL-3.0-or-later",
+		"AGPL-3.0-only", "AGPL-3.0-or-later",
+	}},
+	"AGPL-3.0-only":     {only: []string{"AGPL-3.0-only", "AGPL-3.0-or-later"}},
+	"AGPL-3.0-or-later": {only: []string{"AGPL-3.0-only", "AGPL-3.0-or-later"}},
+}
+
+// spdxAliases maps deprecated SPDX IDs, which GitHub
+// still returns, to their current ID.
+var spdxAliases = map[string]string{
+	"GPL-2.0":   "GPL-2.0-only",
+	"GPL-2.0+":  "GPL-2.0-or-later",
+	"GPL-3.0":   "GPL-3.0-only",
+	"GPL-3.0+":  "GPL-3.0-or-later",
+	"LGPL-2.1":  "LGPL-2.1-only",
+	"LGPL-2.1+": "LGPL-2.1-or-later",
+	"LGPL-3.0":  "LGPL-3.0-only",
+	"LGPL-3.0+": "LGPL-3.0-or-later",
+	"AGPL-3.0":  "AGPL-3.0-only",
+}
+
+var SPDXCompatibleBuiltin = rego.Function{
+	Name: "spdx.compatible",
+	Decl: types.NewFunction(
+		types.Args(types.S, types.S),
+		types.B,
+	),
+	Memoize: true,
+}
+
+// SPDXCompatibleBuiltinImpl reports whether a work licensed under the
+// first SPDX ID can be included in a project licensed under the second
+// one, e.g. a dependency in a repository. IDs are matched case-insensitively
+// and deprecated IDs (e.g. "GPL-3.0") are accepted. This is a basic check
+// of the most common licenses: a license is only compatible with unknown
+// licenses if they're the same.
+func SPDXCompatibleBuiltinImpl(_ rego.BuiltinContext, op1, op2 *ast.Term) (*ast.Term, error) {
+	var work, project string
+
+	if err := ast.As(op1.Value, &work); err != nil {
+		return nil, err
+	} else if err := ast.As(op2.Value, &project); err != nil {
+		return nil, err
+	}
+
+	return ast.BooleanTerm(spdxCompatible(canonicalSPDX(work), canonicalSPDX(project))), nil
+}
+
+func spdxCompatible(work, project string) bool {
+	if work == project {
+		return true
+	}
+
+	rule, ok := spdxRules[work]
+	if !ok {
+		return false
+	}
+
+	if _, ok := spdxRules[project]; !ok {
+		return false
+	}
+
+	for _, id := range rule.except {
+		if id == project {
+			return false
+		}
+	}
+
+	if len(rule.only) == 0 {
+		return true
+	}
+
+	for _, id := range rule.only {
+		if id == project {
+			return true
+		}
+	}
+
+	return false
+}
+
+// canonicalSPDX returns the current ID of a known license matching
+// id case-insensitively, or id itself if it's unknown.
+func canonicalSPDX(id string) string {
+	id = strings.TrimSpace(id)
+
+	for alias, canonical := range spdxAliases {
+		if strings.EqualFold(alias, id) {
+			return canonical
+		}
+	}
+
+	for known := range spdxRules {
+		if strings.EqualFold(known, id) {
+			return known
+		}
+	}
+
+	return id
+}
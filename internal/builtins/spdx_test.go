@@ -0,0 +1,42 @@
+package builtins_test
+
+import (
+	"testing"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/reposaur/reposaur/internal/builtins"
+)
+
+func TestSPDXCompatible(t *testing.T) {
+	cases := []struct {
+		work, project string
+		compatible    bool
+	}{
+		{"MIT", "Apache-2.0", true},
+		{"mit", "GPL-3.0-only", true},
+		{"Apache-2.0", "MIT", true},
+		{"Apache-2.0", "GPL-3.0", true},
+		{"Apache-2.0", "GPL-2.0", false},
+		{"GPL-3.0", "MIT", false},
+		{"GPL-2.0-or-later", "GPL-3.0-only", true},
+		{"GPL-2.0-only", "GPL-3.0-only", false},
+		{"GPL-3.0-only", "AGPL-3.0", true},
+		{"AGPL-3.0-only", "GPL-3.0-only", false},
+		{"LGPL-3.0", "GPL-2.0-only", false},
+		{"Proprietary", "Proprietary", true},
+		{"Proprietary", "MIT", false},
+		{"MIT", "Proprietary", false},
+	}
+
+	for _, c := range cases {
+		term, err := builtins.SPDXCompatibleBuiltinImpl(rego.BuiltinContext{}, ast.StringTerm(c.work), ast.StringTerm(c.project))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := term.Value.Compare(ast.Boolean(c.compatible)) == 0; !got {
+			t.Errorf("expected %s in a %s project to be compatible: %v", c.work, c.project, c.compatible)
+		}
+	}
+}
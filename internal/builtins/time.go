@@ -0,0 +1,117 @@
+package builtins
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/types"
+)
+
+var TimeDaysSinceBuiltin = rego.Function{
+	Name: "time.days_since",
+	Decl: types.NewFunction(
+		types.Args(types.S),
+		types.N,
+	),
+}
+
+var TimeIsOlderThanBuiltin = rego.Function{
+	Name: "time.is_older_than",
+	Decl: types.NewFunction(
+		types.Args(types.S, types.S),
+		types.B,
+	),
+}
+
+// timestampLayouts are the layouts accepted by the
+// time built-in functions, in order of preference.
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// TimeDaysSinceBuiltinImpl returns the number of
+// full days elapsed since a timestamp.
+func TimeDaysSinceBuiltinImpl(bctx rego.BuiltinContext, op1 *ast.Term) (*ast.Term, error) {
+	var tsStr string
+
+	if err := ast.As(op1.Value, &tsStr); err != nil {
+		return nil, err
+	}
+
+	ts, err := parseTimestamp(tsStr)
+	if err != nil {
+		return nil, err
+	}
+
+	days := int(builtinNow(bctx).Sub(ts).Hours() / 24)
+
+	return ast.IntNumberTerm(days), nil
+}
+
+// TimeIsOlderThanBuiltinImpl returns whether a timestamp is
+// older than a duration, e.g. "180d", "2w" or "36h".
+func TimeIsOlderThanBuiltinImpl(bctx rego.BuiltinContext, op1, op2 *ast.Term) (*ast.Term, error) {
+	var tsStr, durStr string
+
+	if err := ast.As(op1.Value, &tsStr); err != nil {
+		return nil, err
+	} else if err := ast.As(op2.Value, &durStr); err != nil {
+		return nil, err
+	}
+
+	ts, err := parseTimestamp(tsStr)
+	if err != nil {
+		return nil, err
+	}
+
+	dur, err := parseDuration(durStr)
+	if err != nil {
+		return nil, err
+	}
+
+	return ast.BooleanTerm(builtinNow(bctx).Sub(ts) > dur), nil
+}
+
+func parseTimestamp(s string) (time.Time, error) {
+	for _, layout := range timestampLayouts {
+		if ts, err := time.Parse(layout, s); err == nil {
+			return ts, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("parse error: can't parse '%s' as a timestamp", s)
+}
+
+// parseDuration extends time.ParseDuration with
+// support for days (d) and weeks (w).
+func parseDuration(s string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{
+		"d": 24 * time.Hour,
+		"w": 7 * 24 * time.Hour,
+	} {
+		if !strings.HasSuffix(s, suffix) {
+			continue
+		}
+
+		n, err := strconv.ParseFloat(strings.TrimSuffix(s, suffix), 64)
+		if err != nil {
+			return 0, fmt.Errorf("parse error: can't parse '%s' as a duration", s)
+		}
+
+		return time.Duration(n * float64(unit)), nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("parse error: can't parse '%s' as a duration", s)
+	}
+
+	return d, nil
+}
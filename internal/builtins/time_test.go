@@ -0,0 +1,76 @@
+package builtins_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/reposaur/reposaur/internal/builtins"
+)
+
+var timeTestNow = rego.BuiltinContext{
+	Time: ast.IntNumberTerm(int(time.Date(2022, time.May, 10, 12, 0, 0, 0, time.UTC).UnixNano())),
+}
+
+func TestTimeDaysSince(t *testing.T) {
+	cases := map[string]int{
+		"2022-05-10T11:00:00Z":           0,
+		"2022-05-09T12:00:00Z":           1,
+		"2022-05-09T13:00:00+02:00":      1,
+		"2021-11-11T11:59:59.123456789Z": 180,
+		"2022-05-01T12:00:00":            9,
+		"2022-05-01":                     9,
+	}
+
+	for ts, expected := range cases {
+		term, err := builtins.TimeDaysSinceBuiltinImpl(timeTestNow, ast.StringTerm(ts))
+		if err != nil {
+			t.Fatalf("%s: %s", ts, err)
+		}
+
+		if term.Value.Compare(ast.IntNumberTerm(expected).Value) != 0 {
+			t.Errorf("expected days since %s to be %d, got %v", ts, expected, term.Value)
+		}
+	}
+}
+
+func TestTimeIsOlderThan(t *testing.T) {
+	cases := []struct {
+		ts       string
+		duration string
+		expected bool
+	}{
+		{"2021-11-11T12:00:00Z", "179d", true},
+		{"2021-11-11T12:00:00Z", "181d", false},
+		{"2022-05-01T12:00:00Z", "1w", true},
+		{"2022-05-01T12:00:00Z", "2w", false},
+		{"2022-05-10T10:00:00Z", "1h30m", true},
+		{"2022-05-10T10:00:00Z", "3h", false},
+	}
+
+	for _, c := range cases {
+		term, err := builtins.TimeIsOlderThanBuiltinImpl(timeTestNow, ast.StringTerm(c.ts), ast.StringTerm(c.duration))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if term.Value.Compare(ast.Boolean(c.expected)) != 0 {
+			t.Errorf("expected time.is_older_than(%s, %s) to be %v", c.ts, c.duration, c.expected)
+		}
+	}
+}
+
+func TestTimeInvalidValues(t *testing.T) {
+	if _, err := builtins.TimeDaysSinceBuiltinImpl(timeTestNow, ast.StringTerm("yesterday")); err == nil {
+		t.Error("expected error parsing invalid timestamp")
+	}
+
+	if _, err := builtins.TimeIsOlderThanBuiltinImpl(timeTestNow, ast.StringTerm("yesterday"), ast.StringTerm("1d")); err == nil {
+		t.Error("expected error parsing invalid timestamp")
+	}
+
+	if _, err := builtins.TimeIsOlderThanBuiltinImpl(timeTestNow, ast.StringTerm("2022-05-01"), ast.StringTerm("a while")); err == nil {
+		t.Error("expected error parsing invalid duration")
+	}
+}
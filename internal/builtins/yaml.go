@@ -0,0 +1,113 @@
+package builtins
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/types"
+	"gopkg.in/yaml.v3"
+)
+
+// YAMLUnmarshalAllBuiltin complements OPA's yaml.unmarshal, which
+// only supports a single document and follows YAML 1.1 (e.g. the
+// `on` key of workflows becomes `true`).
+var YAMLUnmarshalAllBuiltin = rego.Function{
+	Name: "yaml.unmarshal_all",
+	Decl: types.NewFunction(
+		types.Args(types.S),
+		types.NewArray(nil, types.A),
+	),
+}
+
+// YAMLUnmarshalAllBuiltinImpl parses every document in a YAML string
+// and returns them as an array. Returns undefined if the string
+// isn't valid YAML.
+func YAMLUnmarshalAllBuiltinImpl(bctx rego.BuiltinContext, op1 *ast.Term) (*ast.Term, error) {
+	var str string
+
+	if err := ast.As(op1.Value, &str); err != nil {
+		return nil, err
+	}
+
+	docs, err := parseYAMLDocuments([]byte(str))
+	if err != nil {
+		return nil, nil
+	}
+
+	val, err := ast.InterfaceToValue(docs)
+	if err != nil {
+		return nil, err
+	}
+
+	return ast.NewTerm(val), nil
+}
+
+// parseYAML parses a YAML document into values that can be
+// converted to Rego values. Keys like `on` are kept as strings,
+// unlike YAML 1.1 parsers that convert them to booleans.
+func parseYAML(data []byte) (interface{}, error) {
+	var v interface{}
+
+	if err := yaml.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+
+	return normalizeYAML(v), nil
+}
+
+// parseYAMLDocuments parses every document in data, like
+// parseYAML does for a single document.
+func parseYAMLDocuments(data []byte) ([]interface{}, error) {
+	docs := []interface{}{}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+
+	for {
+		var v interface{}
+
+		err := dec.Decode(&v)
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		docs = append(docs, normalizeYAML(v))
+	}
+
+	return docs, nil
+}
+
+// normalizeYAML converts maps with non-string keys, which
+// can't be represented in JSON, to maps with string keys.
+func normalizeYAML(v interface{}) interface{} {
+	switch tv := v.(type) {
+	case map[string]interface{}:
+		for k, e := range tv {
+			tv[k] = normalizeYAML(e)
+		}
+
+		return tv
+
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(tv))
+
+		for k, e := range tv {
+			m[fmt.Sprint(k)] = normalizeYAML(e)
+		}
+
+		return m
+
+	case []interface{}:
+		for i, e := range tv {
+			tv[i] = normalizeYAML(e)
+		}
+
+		return tv
+	}
+
+	return v
+}
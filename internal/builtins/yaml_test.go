@@ -0,0 +1,94 @@
+package builtins_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/reposaur/reposaur/internal/builtins"
+)
+
+func unmarshalAll(t *testing.T, s string) ([]interface{}, bool) {
+	t.Helper()
+
+	term, err := builtins.YAMLUnmarshalAllBuiltinImpl(rego.BuiltinContext{}, ast.StringTerm(s))
+	if err != nil {
+		t.Fatal(err)
+	} else if term == nil {
+		return nil, false
+	}
+
+	var docs []interface{}
+	if err := ast.As(term.Value, &docs); err != nil {
+		t.Fatal(err)
+	}
+
+	return docs, true
+}
+
+func TestYAMLUnmarshalAllSingleDocument(t *testing.T) {
+	docs, ok := unmarshalAll(t, `
+repository:
+  name: test
+  private: true
+on: push
+labels:
+  - name: bug
+    color: d73a4a
+`)
+	if !ok {
+		t.Fatal("expected document to be parsed")
+	}
+
+	expected := []interface{}{
+		map[string]interface{}{
+			"repository": map[string]interface{}{
+				"name":    "test",
+				"private": true,
+			},
+			"on": "push",
+			"labels": []interface{}{
+				map[string]interface{}{"name": "bug", "color": "d73a4a"},
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(expected, docs) {
+		t.Errorf("expected %v, got %v", expected, docs)
+	}
+}
+
+func TestYAMLUnmarshalAllMultipleDocuments(t *testing.T) {
+	docs, ok := unmarshalAll(t, "a: 1\n---\nb: 2\n---\n- c\n")
+	if !ok {
+		t.Fatal("expected documents to be parsed")
+	}
+
+	if len(docs) != 3 {
+		t.Fatalf("expected 3 documents, got %d", len(docs))
+	}
+
+	if _, ok := docs[2].([]interface{}); !ok {
+		t.Errorf("expected third document to be an array, got %v", docs[2])
+	}
+}
+
+func TestYAMLUnmarshalAllNonStringKeys(t *testing.T) {
+	docs, ok := unmarshalAll(t, "1: one\ntrue: yes\n")
+	if !ok {
+		t.Fatal("expected document to be parsed")
+	}
+
+	expected := map[string]interface{}{"1": "one", "true": "yes"}
+
+	if !reflect.DeepEqual(expected, docs[0]) {
+		t.Errorf("expected %v, got %v", expected, docs[0])
+	}
+}
+
+func TestYAMLUnmarshalAllInvalid(t *testing.T) {
+	if _, ok := unmarshalAll(t, "a: [1, 2\nb: {"); ok {
+		t.Error("expected invalid YAML to be undefined")
+	}
+}
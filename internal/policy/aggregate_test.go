@@ -0,0 +1,79 @@
+package policy_test
+
+import (
+	"context"
+	"testing"
+)
+
+const aggregatePolicy = `
+package org
+
+violation_no_security_contact {
+	count([r | r := input[_]; r.security_contact]) == 0
+}
+
+warn_too_many_public {
+	count([r | r := input[_]; r.visibility == "public"]) > 1
+}
+`
+
+func TestCheckAggregate(t *testing.T) {
+	engine := loadTestEngine(t, []string{aggregatePolicy})
+
+	cases := []struct {
+		name     string
+		inputs   []interface{}
+		expected map[string]bool
+	}{
+		{
+			name: "one repo with security contact",
+			inputs: []interface{}{
+				map[string]interface{}{"name": "a", "visibility": "public"},
+				map[string]interface{}{"name": "b", "visibility": "private", "security_contact": "sec@example.com"},
+			},
+			expected: map[string]bool{
+				"org/violation/no_security_contact": true,
+				"org/warn/too_many_public":          true,
+			},
+		},
+		{
+			name: "no repo with security contact",
+			inputs: []interface{}{
+				map[string]interface{}{"name": "a", "visibility": "public"},
+				map[string]interface{}{"name": "b", "visibility": "public"},
+			},
+			expected: map[string]bool{
+				"org/violation/no_security_contact": false,
+				"org/warn/too_many_public":          false,
+			},
+		},
+		{
+			name:   "no repos",
+			inputs: nil,
+			expected: map[string]bool{
+				"org/violation/no_security_contact": false,
+				"org/warn/too_many_public":          true,
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			report, err := engine.CheckAggregate(context.Background(), "org", c.inputs)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for uid, passed := range c.expected {
+				result, ok := report.Results[uid]
+				if !ok {
+					t.Fatalf("expected result for %s", uid)
+				}
+
+				if result.Passed != passed {
+					t.Errorf("expected %s passed to be %v, got %v", uid, passed, result.Passed)
+				}
+			}
+		})
+	}
+}
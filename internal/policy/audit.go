@@ -0,0 +1,53 @@
+package policy
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/reposaur/reposaur/pkg/output"
+)
+
+// WithAuditTrail records every decision of the engine's checks in
+// trail: the rules evaluated, a hash of their input, the requests
+// done by the built-in functions and the outcome.
+func WithAuditTrail(trail *output.AuditTrail) Option {
+	return func(e *Engine) {
+		e.auditTrail = trail
+	}
+}
+
+// requestLog records the requests done
+// by the built-in functions in a decision.
+type requestLog struct {
+	mu       sync.Mutex
+	requests []output.AuditRequest
+}
+
+func (l *requestLog) RecordRequest(req *http.Request, resp *http.Response, err error, started time.Time) {
+	r := output.AuditRequest{
+		Method:     req.Method,
+		URL:        req.URL.String(),
+		Timestamp:  started.UTC(),
+		DurationMS: time.Since(started).Milliseconds(),
+	}
+
+	if err != nil {
+		r.Error = err.Error()
+	} else {
+		r.StatusCode = resp.StatusCode
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.requests = append(l.requests, r)
+}
+
+// entries returns the recorded requests.
+func (l *requestLog) entries() []output.AuditRequest {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return append([]output.AuditRequest{}, l.requests...)
+}
@@ -0,0 +1,140 @@
+package policy_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/reposaur/reposaur/internal/policy"
+	"github.com/reposaur/reposaur/pkg/cache"
+	"github.com/reposaur/reposaur/pkg/output"
+)
+
+const auditPolicy = `
+package repository
+
+violation_not_internal {
+	input.visibility != "internal"
+}
+
+warn_no_description {
+	not input.description
+}
+`
+
+const auditOrganizationPolicy = `
+package organization
+
+violation_no_two_factor {
+	not input.two_factor_requirement_enabled
+}
+`
+
+func TestWithAuditTrail(t *testing.T) {
+	trail := output.NewAuditTrail()
+	engine := loadTestEngine(t, []string{auditPolicy}, policy.WithAuditTrail(trail))
+
+	input := map[string]interface{}{"visibility": "public", "description": "Reposaur"}
+
+	report, err := engine.Check(context.Background(), "repository", input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decisions := trail.Decisions()
+	if len(decisions) != 1 {
+		t.Fatalf("expected 1 decision, got %d", len(decisions))
+	}
+
+	d := decisions[0]
+
+	b, _ := json.Marshal(input)
+	sum := sha256.Sum256(b)
+
+	if d.DecisionID != report.DecisionID || d.Namespace != "repository" || d.InputHash != hex.EncodeToString(sum[:]) || d.Cached {
+		t.Errorf("unexpected decision %+v", d)
+	}
+
+	// ordered like the report's results
+	expected := []output.AuditRule{
+		{UID: "repository/warn/no_description", Status: output.StatusPassed},
+		{UID: "repository/violation/not_internal", Status: output.StatusFailed},
+	}
+
+	if len(d.Rules) != len(expected) {
+		t.Fatalf("expected %d rules, got %+v", len(expected), d.Rules)
+	}
+
+	for i := range expected {
+		if d.Rules[i].UID != expected[i].UID || d.Rules[i].Status != expected[i].Status {
+			t.Errorf("expected %+v, got %+v", expected[i], d.Rules[i])
+		}
+	}
+}
+
+func TestWithAuditTrailCheckAll(t *testing.T) {
+	trail := output.NewAuditTrail()
+	engine := loadTestEngine(t, []string{auditPolicy, auditOrganizationPolicy}, policy.WithAuditTrail(trail))
+
+	if _, err := engine.CheckAll(context.Background(), map[string]interface{}{}); err != nil {
+		t.Fatal(err)
+	}
+
+	namespaces := map[string]int{}
+	for _, d := range trail.Decisions() {
+		namespaces[d.Namespace] = len(d.Rules)
+	}
+
+	if len(namespaces) != 2 || namespaces["repository"] != 2 || namespaces["organization"] != 1 {
+		t.Errorf("expected a decision per namespace, got %v", namespaces)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := trail.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded struct {
+		Decisions []output.AuditDecision `json:"decisions"`
+	}
+
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(decoded.Decisions) != 2 {
+		t.Errorf("expected 2 serialized decisions, got %d", len(decoded.Decisions))
+	}
+}
+
+func TestWithAuditTrailCached(t *testing.T) {
+	trail := output.NewAuditTrail()
+	engine := loadTestEngine(t, []string{auditPolicy},
+		policy.WithAuditTrail(trail),
+		policy.WithEvalCache(cache.NewMemory(0)),
+	)
+
+	input := map[string]interface{}{"visibility": "internal"}
+
+	for i := 0; i < 2; i++ {
+		if _, err := engine.Check(context.Background(), "repository", input); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	decisions := trail.Decisions()
+	if len(decisions) != 2 {
+		t.Fatalf("expected 2 decisions, got %d", len(decisions))
+	}
+
+	if decisions[0].Cached || !decisions[1].Cached {
+		t.Errorf("expected only the second decision to be cached, got %v and %v", decisions[0].Cached, decisions[1].Cached)
+	}
+
+	if decisions[0].InputHash != decisions[1].InputHash {
+		t.Error("expected the same input hash")
+	}
+}
@@ -0,0 +1,106 @@
+package policy
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/bundle"
+	"github.com/open-policy-agent/opa/format"
+)
+
+// bundleExt is the extension of bundle
+// tarballs loaded like directories.
+const bundleExt = ".tar.gz"
+
+// Bundle returns the engine's policies as an OPA bundle tarball. See
+// WriteBundle.
+func (e *Engine) Bundle() ([]byte, error) {
+	buf := &bytes.Buffer{}
+
+	if err := e.WriteBundle(buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// WriteBundle writes the engine's policies to w as an OPA bundle
+// tarball, which can be loaded like loose files, e.g. to validate
+// policies during development and ship them as a bundle. Modules
+// are formatted with their comments, preserving their annotations.
+// The policies of policy sets aren't included.
+func (e *Engine) WriteBundle(w io.Writer) error {
+	names := make([]string, 0, len(e.modules))
+	for name := range e.modules {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	b := bundle.Bundle{
+		Manifest: bundle.Manifest{Roots: &[]string{""}},
+		Data:     map[string]interface{}{},
+	}
+
+	for i, name := range names {
+		raw, err := format.Ast(e.modules[name])
+		if err != nil {
+			return fmt.Errorf("write bundle: format %s: %w", name, err)
+		}
+
+		b.Modules = append(b.Modules, bundle.ModuleFile{
+			URL:  bundleModulePath(i, name),
+			Path: bundleModulePath(i, name),
+			Raw:  raw,
+		})
+	}
+
+	if err := bundle.NewWriter(w).Write(b); err != nil {
+		return fmt.Errorf("write bundle: %w", err)
+	}
+
+	return nil
+}
+
+// bundleModulePath returns the path in a bundle of the module with
+// name, which is a file path optionally suffixed with #N for files
+// split in several modules (see splitModules). Paths are prefixed
+// with the module's index, so they're unique.
+func bundleModulePath(i int, name string) string {
+	base := path.Base(strings.ReplaceAll(name, "\\", "/"))
+	base = strings.TrimSuffix(strings.ReplaceAll(base, "#", "_"), bundle.RegoExt)
+
+	return fmt.Sprintf("/%03d_%s%s", i, base, bundle.RegoExt)
+}
+
+// loadBundleModules reads the bundle tarball at path and
+// returns its modules, named after the tarball and their
+// path in it.
+func loadBundleModules(path string) (map[string]*ast.Module, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	b, err := bundle.NewCustomReader(bundle.NewTarballLoaderWithBaseURL(f, path)).
+		WithProcessAnnotations(true).
+		Read()
+	if err != nil {
+		return nil, fmt.Errorf("read bundle %s: %w", path, err)
+	}
+
+	modules := map[string]*ast.Module{}
+
+	for _, mf := range b.Modules {
+		modules[path+mf.Path] = mf.Parsed
+	}
+
+	return modules, nil
+}
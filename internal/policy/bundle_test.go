@@ -0,0 +1,80 @@
+package policy_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/reposaur/reposaur/internal/policy"
+)
+
+func TestBundleRoundTrip(t *testing.T) {
+	const annotated = `
+# METADATA
+# title: Repository is public
+# custom:
+#   tags: [security]
+#   security-severity: 9
+violation_public {
+	input.visibility == "public"
+}
+`
+
+	const multiple = `
+package organization
+
+warn_no_description {
+	not input.description
+}
+
+# METADATA
+# title: Pull request has no reviewers
+package pull_request
+
+note_no_reviewers {
+	count(input.requested_reviewers) == 0
+}
+`
+
+	engine := loadTestEngine(t, []string{testPolicy + annotated, multiple})
+
+	b, err := engine.Bundle()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "bundle.tar.gz")
+	if err := os.WriteFile(path, b, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := policy.Load(context.Background(), []string{path})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	namespaces, reloadedNamespaces := engine.Namespaces(), reloaded.Namespaces()
+	sort.Strings(namespaces)
+	sort.Strings(reloadedNamespaces)
+
+	if !reflect.DeepEqual(namespaces, reloadedNamespaces) {
+		t.Errorf("expected namespaces %v, got %v", namespaces, reloadedNamespaces)
+	}
+
+	if catalog, reloadedCatalog := engine.Catalog(), reloaded.Catalog(); !reflect.DeepEqual(catalog, reloadedCatalog) {
+		t.Errorf("expected catalog %v, got %v", catalog, reloadedCatalog)
+	}
+
+	report, err := reloaded.Check(context.Background(), "repository", map[string]interface{}{"visibility": "public"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, ok := report.Results["repository/violation/public"]
+	if !ok || result.Passed || result.Rule.Title != "Repository is public" || result.Rule.SecuritySeverity != "9" {
+		t.Errorf("expected the annotated rule to be evaluated from the bundle, got %+v", result)
+	}
+}
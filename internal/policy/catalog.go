@@ -0,0 +1,32 @@
+package policy
+
+import (
+	"github.com/reposaur/reposaur/pkg/output"
+)
+
+// Catalog describes the rules of the loaded
+// policies, grouped by namespace.
+type Catalog map[string][]*output.Rule
+
+// Catalog describes the rules of the engine and its policy sets,
+// without evaluating them, sorted by output.SortRules.
+func (e *Engine) Catalog() Catalog {
+	catalog := Catalog{}
+
+	for _, mod := range e.Modules() {
+		namespace := moduleNamespace(mod)
+		catalog[namespace] = append(catalog[namespace], e.moduleRules(namespace, mod)...)
+	}
+
+	for _, set := range e.sets {
+		for namespace, rules := range set.engine.Catalog() {
+			catalog[namespace] = append(catalog[namespace], rules...)
+		}
+	}
+
+	for _, rules := range catalog {
+		output.SortRules(rules)
+	}
+
+	return catalog
+}
@@ -0,0 +1,75 @@
+package policy_test
+
+import (
+	"reflect"
+	"testing"
+)
+
+const catalogRepositoryPolicy = `
+package repository
+
+# METADATA
+# title: Repository is not internal
+# description: Repositories must be internal
+# custom:
+#   tags: [security]
+violation_not_internal {
+	input.visibility != "internal"
+}
+
+warn_no_description {
+	not input.description
+}
+
+# not a rule kind, must be ignored
+helper {
+	true
+}
+`
+
+const catalogOrganizationPolicy = `
+package organization
+
+# METADATA
+# title: 2FA is disabled
+note_two_factor_disabled {
+	input.two_factor_requirement_enabled == false
+}
+`
+
+func TestCatalog(t *testing.T) {
+	engine := loadTestEngine(t, []string{catalogRepositoryPolicy, catalogOrganizationPolicy})
+	catalog := engine.Catalog()
+
+	if len(catalog) != 2 {
+		t.Fatalf("expected 2 namespaces, got %d", len(catalog))
+	}
+
+	repoRules := catalog["repository"]
+	if len(repoRules) != 2 {
+		t.Fatalf("expected 2 repository rules, got %d", len(repoRules))
+	}
+
+	notInternal := repoRules[1]
+
+	if notInternal.ID != "not_internal" || notInternal.Kind != "violation" || notInternal.Severity != "error" {
+		t.Errorf("unexpected rule %+v", notInternal)
+	}
+
+	if notInternal.Title != "Repository is not internal" || notInternal.Description != "Repositories must be internal" {
+		t.Errorf("expected rule annotations, got %+v", notInternal)
+	}
+
+	if !reflect.DeepEqual(notInternal.Tags, []string{"security"}) {
+		t.Errorf("expected tags to be [security], got %v", notInternal.Tags)
+	}
+
+	if noDescription := repoRules[0]; noDescription.ID != "no_description" || noDescription.Title != "no_description" {
+		t.Errorf("unexpected rule %+v", noDescription)
+	}
+
+	orgRules := catalog["organization"]
+	if len(orgRules) != 1 || orgRules[0].Severity != "note" {
+		t.Errorf("unexpected organization rules %+v", orgRules)
+	}
+}
@@ -0,0 +1,95 @@
+package policy_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/types"
+)
+
+func init() {
+	rego.RegisterBuiltin1(
+		&rego.Function{
+			Name: "test.sleep",
+			Decl: types.NewFunction(types.Args(types.N), types.B),
+		},
+		func(_ rego.BuiltinContext, op *ast.Term) (*ast.Term, error) {
+			var ms int
+			if err := ast.As(op.Value, &ms); err != nil {
+				return nil, err
+			}
+
+			time.Sleep(time.Duration(ms) * time.Millisecond)
+
+			return ast.BooleanTerm(true), nil
+		},
+	)
+}
+
+const slowPolicy = `
+package repository
+
+violation_a {
+	test.sleep(50)
+	input.visibility != "internal"
+}
+
+violation_b {
+	test.sleep(50)
+}
+
+violation_c {
+	test.sleep(50)
+}
+`
+
+func TestCheckDeadline(t *testing.T) {
+	engine := loadTestEngine(t, []string{slowPolicy})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 75*time.Millisecond)
+	defer cancel()
+
+	report, err := engine.Check(ctx, "repository", map[string]interface{}{"visibility": "public"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(report.Results) != 3 {
+		t.Fatalf("expected a result for every rule, got %d", len(report.Results))
+	}
+
+	a := report.Results["repository/violation/a"]
+	if a.TimedOut || a.Passed {
+		t.Errorf("expected a to be evaluated and fail, got %+v", a)
+	}
+
+	c := report.Results["repository/violation/c"]
+	if !c.TimedOut {
+		t.Errorf("expected c to time out, got %+v", c)
+	}
+
+	if c.Failed() {
+		t.Error("expected timed out result not to be failed")
+	}
+}
+
+func TestCheckDeadlineExceeded(t *testing.T) {
+	engine := loadTestEngine(t, []string{testPolicy})
+
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	report, err := engine.Check(ctx, "repository", map[string]interface{}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for uid, result := range report.Results {
+		if !result.TimedOut {
+			t.Errorf("expected %s to time out", uid)
+		}
+	}
+}
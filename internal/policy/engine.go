@@ -2,33 +2,129 @@ package policy
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/open-policy-agent/opa/ast"
 	"github.com/open-policy-agent/opa/bundle"
-	"github.com/open-policy-agent/opa/loader"
 	"github.com/open-policy-agent/opa/rego"
 	"github.com/open-policy-agent/opa/topdown"
+	"github.com/reposaur/reposaur/internal/builtins"
+	"github.com/reposaur/reposaur/pkg/cache"
 	"github.com/reposaur/reposaur/pkg/output"
+	"github.com/reposaur/reposaur/pkg/util"
 )
 
+// ErrNamespaceNotFound is returned when checking a namespace
+// that none of the loaded policies provide.
+var ErrNamespaceNotFound = errors.New("namespace not found")
+
+// ErrEmptyNamespace is returned by Load in strict mode when
+// a namespace doesn't have any rules with a known kind.
+var ErrEmptyNamespace = errors.New("namespace without rules")
+
+// Option represents an Engine option that can change a
+// particular behavior.
+type Option func(*Engine)
+
+// DeprecatedNotice is the notice added to the
+// results of deprecated rules.
+const DeprecatedNotice = "This rule is deprecated and may be removed in the future"
+
 type Engine struct {
 	modules  map[string]*ast.Module
 	compiler *ast.Compiler
+	since    time.Time
+
+	excludeExperimental bool
+	excludeDeprecated   bool
+	strict              bool
+	rawValues           bool
+
+	namespaceSeverities map[string]string
+
+	cache cache.Cache
+
+	evalCache  cache.Cache
+	policyHash string
+
+	ociClient   *http.Client
+	ociCacheDir string
+
+	trustedKeys map[string]*bundle.KeyConfig
+
+	setName  string
+	setPaths []policySetPaths
+	sets     []*policySet
+
+	client         *http.Client
+	concurrency    int
+	timeout        time.Duration
+	printWriter    io.Writer
+	capabilities   *ast.Capabilities
+	customBuiltins []Builtin
+	uidFunc        output.UIDFunc
+	auditTrail     *output.AuditTrail
+	allowedMethods []string
+	builtinOpts    []builtins.Option
 }
 
-func Load(ctx context.Context, policyPaths []string) (*Engine, error) {
-	policies, err := allRegos(policyPaths)
+// Load loads the policies in policyPaths, which are files or
+// directories. Paths starting with oci:// reference a bundle in an
+// OCI registry, which is pulled before loading (see WithOCICacheDir).
+// If there are trusted keys, every path must be a signed bundle
+// (see WithTrustedKey).
+func Load(ctx context.Context, policyPaths []string, opts ...Option) (*Engine, error) {
+	engine := Engine{
+		ociClient:   http.DefaultClient,
+		printWriter: os.Stderr,
+	}
+
+	for _, opt := range opts {
+		opt(&engine)
+	}
+
+	localPaths, err := engine.pullOCIPaths(ctx, policyPaths)
 	if err != nil {
 		return nil, fmt.Errorf("load: %w", err)
-	} else if len(policies.Modules) == 0 {
+	}
+
+	if err := engine.loadPolicySets(ctx, opts); err != nil {
+		return nil, fmt.Errorf("load: %w", err)
+	}
+
+	var modules map[string]*ast.Module
+
+	if len(policyPaths) == 0 && len(engine.sets) > 0 {
+		modules = map[string]*ast.Module{}
+	} else if len(engine.trustedKeys) > 0 {
+		modules, err = engine.loadSignedBundles(localPaths)
+	} else {
+		modules, err = loadModules(localPaths)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("load: %w", err)
+	} else if len(modules) == 0 && len(engine.sets) == 0 {
 		return nil, fmt.Errorf("no policies found in %v", policyPaths)
 	}
 
-	modules := policies.ParsedModules()
-	compiler := ast.NewCompiler().WithEnablePrintStatements(true)
+	decls, err := engine.customBuiltinDecls()
+	if err != nil {
+		return nil, fmt.Errorf("load: %w", err)
+	}
+
+	compiler := ast.NewCompiler().
+		WithEnablePrintStatements(true).
+		WithCapabilities(engine.capabilities).
+		WithBuiltins(decls)
 
 	compiler.Compile(modules)
 
@@ -36,43 +132,165 @@ func Load(ctx context.Context, policyPaths []string) (*Engine, error) {
 		return nil, fmt.Errorf("compiler: %w", compiler.Errors)
 	}
 
-	engine := Engine{
-		modules:  modules,
-		compiler: compiler,
+	engine.modules = modules
+	engine.compiler = compiler
+
+	if engine.evalCache != nil {
+		engine.policyHash = engine.hashPolicies()
+	}
+
+	if engine.strict {
+		if err := engine.validateNamespaces(); err != nil {
+			return nil, fmt.Errorf("load: %w", err)
+		}
 	}
 
 	return &engine, nil
 }
 
-// Namespaces returns all of the namespaces in the engine.
+// WithStrict makes Load fail if any namespace doesn't have rules
+// with a known kind, which is likely a mistake. It's opt-in as
+// packages with only helper rules (libraries) are valid.
+func WithStrict() Option {
+	return func(e *Engine) {
+		e.strict = true
+	}
+}
+
+// WithRawValues makes the results carry the value of their
+// rule (see output.Result.Value), e.g. for tooling to post-process
+// it. It's opt-in as values can be large.
+func WithRawValues() Option {
+	return func(e *Engine) {
+		e.rawValues = true
+	}
+}
+
+// WithSince makes the engine skip inputs that weren't
+// pushed or updated after t. Reports for those inputs
+// are marked as stale and have every rule skipped.
+func WithSince(t time.Time) Option {
+	return func(e *Engine) {
+		e.since = t
+	}
+}
+
+// WithoutExperimental excludes rules marked as
+// experimental from the engine's checks.
+func WithoutExperimental() Option {
+	return func(e *Engine) {
+		e.excludeExperimental = true
+	}
+}
+
+// WithoutDeprecated excludes rules marked as
+// deprecated from the engine's checks.
+func WithoutDeprecated() Option {
+	return func(e *Engine) {
+		e.excludeDeprecated = true
+	}
+}
+
+// WithCache sets a cache shared by every check of the engine.
+// Built-in functions, like `github.request`, consult it before
+// doing requests, so their responses are reused across checks.
+func WithCache(c cache.Cache) Option {
+	return func(e *Engine) {
+		e.cache = c
+	}
+}
+
+// Namespaces returns all of the namespaces in the engine,
+// including the ones of its policy sets.
 func (e *Engine) Namespaces() []string {
-	var namespaces []string
+	var (
+		namespaces []string
+		seen       = map[string]bool{}
+	)
+
 	for _, module := range e.Modules() {
-		namespace := strings.Replace(module.Package.Path.String(), "data.", "", 1)
-		for _, ns := range namespaces {
-			if ns == namespace {
-				continue
-			}
+		namespace := moduleNamespace(module)
+		if seen[namespace] {
+			continue
 		}
 
+		seen[namespace] = true
 		namespaces = append(namespaces, namespace)
 	}
 
+	for _, set := range e.sets {
+		for _, namespace := range set.engine.Namespaces() {
+			if !seen[namespace] {
+				seen[namespace] = true
+				namespaces = append(namespaces, namespace)
+			}
+		}
+	}
+
 	return namespaces
 }
 
+// validateNamespaces returns ErrEmptyNamespace if any namespace
+// doesn't have rules with a known kind.
+func (e *Engine) validateNamespaces() error {
+	var empty []string
+
+	for ns, rules := range e.Catalog() {
+		if len(rules) == 0 {
+			empty = append(empty, ns)
+		}
+	}
+
+	if len(empty) == 0 {
+		return nil
+	}
+
+	sort.Strings(empty)
+
+	return fmt.Errorf("%w: %s", ErrEmptyNamespace, strings.Join(empty, ", "))
+}
+
+// hasNamespace reports whether any of the loaded
+// policies provide namespace.
+func (e *Engine) hasNamespace(namespace string) bool {
+	for _, ns := range e.Namespaces() {
+		if ns == namespace {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasOwnNamespace reports whether any of the engine's own
+// policies, i.e. not the ones of its policy sets, provide
+// namespace.
+func (e *Engine) hasOwnNamespace(namespace string) bool {
+	for _, mod := range e.Modules() {
+		if moduleNamespace(mod) == namespace {
+			return true
+		}
+	}
+
+	return false
+}
+
 // Compiler returns the compiler from the loaded policies.
 func (e *Engine) Compiler() *ast.Compiler {
 	return e.compiler
 }
 
-// Modules returns the modules from the loaded policies.
+// Modules returns the modules from the loaded policies,
+// not including the ones of its policy sets.
 func (e *Engine) Modules() map[string]*ast.Module {
 	return e.modules
 }
 
+// Check executes the rules of namespace against input. If the
+// deadline of ctx is exceeded, the rules not evaluated yet are
+// marked as timed out and the partial report is returned.
 func (e *Engine) Check(ctx context.Context, namespace string, input interface{}) (output.Report, error) {
-	report, err := e.check(ctx, namespace, input)
+	report, err := e.check(ctx, namespace, input, nil)
 	if err != nil {
 		return output.Report{}, fmt.Errorf("check: %w", err)
 	}
@@ -80,77 +298,413 @@ func (e *Engine) Check(ctx context.Context, namespace string, input interface{})
 	return report, nil
 }
 
-func (e *Engine) check(ctx context.Context, namespace string, input interface{}) (output.Report, error) {
-	report := output.Report{
-		Rules:   map[string]*output.Rule{},
-		Results: map[string]*output.Result{},
+// CheckAggregate executes the policies in namespace once against
+// the whole collection of inputs, which policies access as an array
+// through `input`. This allows policies that reason over every
+// subject at once, e.g. every repository in an organization.
+func (e *Engine) CheckAggregate(ctx context.Context, namespace string, inputs []interface{}) (output.Report, error) {
+	if inputs == nil {
+		inputs = []interface{}{}
 	}
 
-	for _, mod := range e.Modules() {
-		currNamespace := strings.TrimLeft(mod.Package.Path.String(), "data.")
-		if currNamespace != namespace {
+	report, err := e.check(ctx, namespace, inputs, nil)
+	if err != nil {
+		return output.Report{}, fmt.Errorf("check aggregate: %w", err)
+	}
+
+	return report, nil
+}
+
+// defaultCacheEntries is the maximum number of responses
+// held by the cache CheckAll creates when there's none.
+const defaultCacheEntries = 1024
+
+// CheckAll executes the rules of every namespace against input,
+// checking the namespaces concurrently, and returns the reports by
+// namespace. The checks share a cache for built-in functions, the
+// engine's (see WithCache) or one for this invocation, so endpoints
+// requested by the policies of several namespaces are fetched once.
+func (e *Engine) CheckAll(ctx context.Context, input interface{}) (map[string]output.Report, error) {
+	if e.cache == nil {
+		if _, ok := cache.FromContext(ctx); !ok {
+			ctx = cache.NewContext(ctx, cache.NewLRU(0, defaultCacheEntries))
+		}
+	}
+
+	var (
+		namespaces = e.Namespaces()
+		wg         = sync.WaitGroup{}
+		reports    = make([]output.Report, len(namespaces))
+		errs       = make([]error, len(namespaces))
+	)
+
+	// limits the namespaces checked
+	// concurrently, see WithConcurrency
+	var sem chan struct{}
+	if e.concurrency > 0 {
+		sem = make(chan struct{}, e.concurrency)
+	}
+
+	for i, ns := range namespaces {
+		wg.Add(1)
+
+		go func(i int, ns string) {
+			defer wg.Done()
+
+			if sem != nil {
+				sem <- struct{}{}
+				defer func() { <-sem }()
+			}
+
+			reports[i], errs[i] = e.check(ctx, ns, input, nil)
+		}(i, ns)
+	}
+
+	wg.Wait()
+
+	byNamespace := make(map[string]output.Report, len(namespaces))
+
+	for i, ns := range namespaces {
+		if errs[i] != nil {
+			return nil, fmt.Errorf("check all: %s: %w", ns, errs[i])
+		}
+
+		byNamespace[ns] = reports[i]
+	}
+
+	return byNamespace, nil
+}
+
+// check executes the rules in namespace against input, including
+// the ones of the policy sets. If include is set, only the rules it
+// returns true for are executed.
+func (e *Engine) check(ctx context.Context, namespace string, input interface{}, include func(*output.Rule) bool) (output.Report, error) {
+	if !e.hasNamespace(namespace) {
+		return output.Report{}, fmt.Errorf("%w: %s", ErrNamespaceNotFound, namespace)
+	}
+
+	ctx, cancel := e.checkContext(ctx)
+	defer cancel()
+
+	report, err := e.checkOwn(ctx, namespace, input, include)
+	if err != nil {
+		return output.Report{}, err
+	}
+
+	for _, set := range e.sets {
+		if !set.engine.hasOwnNamespace(namespace) {
 			continue
 		}
 
-		for _, r := range mod.Rules {
-			var annotations *ast.Annotations
-			for _, a := range mod.Annotations {
-				if a.Scope == "rule" && a.GetTargetPath().String() == r.Path().String() {
-					annotations = a
-				}
+		setReport, err := set.engine.checkOwn(ctx, namespace, input, include)
+		if err != nil {
+			return output.Report{}, fmt.Errorf("policy set %s: %w", set.name, err)
+		}
+
+		report.Stale = report.Stale || setReport.Stale
+
+		// rules of a set with the same UID as one of the
+		// engine's or of a previous set aren't included
+		shadowed := map[string]bool{}
+
+		for _, rule := range setReport.SortedRules() {
+			if _, ok := report.Rules[rule.UID()]; ok {
+				shadowed[rule.UID()] = true
+				continue
+			}
+
+			report.AddRule(rule)
+		}
+
+		for _, result := range setReport.SortedResults() {
+			if !shadowed[result.Rule.UID()] {
+				report.AddResult(result)
 			}
+		}
+	}
+
+	return report, nil
+}
+
+// checkOwn executes the engine's own rules in namespace against
+// input, not including the ones of the policy sets. If include is
+// set, only the rules it returns true for are executed. The decision
+// is recorded in the engine's audit trail, if any.
+func (e *Engine) checkOwn(ctx context.Context, namespace string, input interface{}, include func(*output.Rule) bool) (output.Report, error) {
+	if e.auditTrail == nil {
+		report, _, err := e.evalOwn(ctx, namespace, input, include)
+		return report, err
+	}
+
+	var (
+		started  = time.Now()
+		requests = &requestLog{}
+	)
+
+	report, cached, err := e.evalOwn(util.NewRecorderContext(ctx, requests), namespace, input, include)
+	if err != nil || len(report.Rules) == 0 {
+		return report, err
+	}
+
+	hash, err := hashInput(input)
+	if err != nil {
+		return output.Report{}, err
+	}
+
+	decision := output.NewAuditDecision(report, namespace, hash, requests.entries())
+	decision.Set = e.setName
+	decision.Cached = cached
+	decision.DurationMS = time.Since(started).Milliseconds()
+
+	e.auditTrail.Add(decision)
+
+	return report, nil
+}
+
+// evalOwn is checkOwn without recording the decision, also
+// returning whether the report was taken from the eval cache.
+func (e *Engine) evalOwn(ctx context.Context, namespace string, input interface{}, include func(*output.Rule) bool) (output.Report, bool, error) {
+	decisionID, err := newDecisionID()
+	if err != nil {
+		return output.Report{}, false, fmt.Errorf("decision id: %w", err)
+	}
+
+	var cacheKey string
 
-			rule, err := output.NewRule(namespace, r, annotations)
-			if err != nil {
+	if include == nil && e.EvalCacheable() {
+		if cacheKey, err = e.evalCacheKey(namespace, input); err != nil {
+			return output.Report{}, false, err
+		}
+
+		report, ok, err := e.cachedReport(ctx, cacheKey)
+		if err != nil {
+			return output.Report{}, false, fmt.Errorf("eval cache get: %w", err)
+		}
+
+		if ok {
+			report.DecisionID = decisionID
+			report.Timestamp = time.Now().UTC()
+			report.Input = input
+
+			return report, true, nil
+		}
+	}
+
+	report := output.Report{
+		Rules:      map[string]*output.Rule{},
+		Results:    map[string]*output.Result{},
+		DecisionID: decisionID,
+		Timestamp:  time.Now().UTC(),
+		Input:      input,
+	}
+
+	var rules []*output.Rule
+
+	for _, mod := range e.Modules() {
+		if moduleNamespace(mod) != namespace {
+			continue
+		}
+
+		for _, rule := range e.moduleRules(namespace, mod) {
+			if e.isExcluded(rule) || (include != nil && !include(rule)) {
 				continue
 			}
 
+			rules = append(rules, rule)
+		}
+	}
+
+	// rules with the same UID are the same rule,
+	// the first one in order is the one checked
+	output.SortRules(rules)
+
+	for _, rule := range rules {
+		if _, ok := report.Rules[rule.UID()]; !ok {
 			report.AddRule(rule)
 		}
 	}
 
-	for _, rule := range report.Rules {
-		var result *output.Result
+	if len(report.Rules) == 0 {
+		return report, false, nil
+	}
+
+	if e.isStale(input) {
+		report.Stale = true
 
-		result, err := e.querySkip(ctx, rule, input)
+		for _, rule := range report.SortedRules() {
+			report.AddResult(&output.Result{
+				Rule:    rule,
+				Skipped: true,
+			})
+		}
+
+		return report, false, nil
+	}
+
+	if e.cache != nil {
+		ctx = cache.NewContext(ctx, e.cache)
+	}
+
+	// rules that aren't evaluated before the deadline are
+	// marked as timed out, returning a partial report
+	with, err := e.queryFixtures(ctx, namespace, input)
+	if err != nil && !deadlineExceeded(ctx) {
+		return output.Report{}, false, fmt.Errorf("query fixtures: %s: %w", namespace, err)
+	}
+
+	for _, rule := range report.SortedRules() {
+		if deadlineExceeded(ctx) {
+			report.AddResult(&output.Result{Rule: rule, TimedOut: true})
+			continue
+		}
+
+		results, err := e.evalRule(ctx, rule, input, with)
 		if err != nil {
-			return output.Report{}, fmt.Errorf("query skip rule: %s: %w", rule.UID(), err)
+			if deadlineExceeded(ctx) {
+				report.AddResult(&output.Result{Rule: rule, TimedOut: true})
+				continue
+			}
+
+			return output.Report{}, false, err
 		}
 
-		if !result.Skipped {
-			result, err = e.queryRule(ctx, rule, input)
-			if err != nil {
-				return output.Report{}, fmt.Errorf("query rule: %s: %w", rule.UID(), err)
+		for _, result := range results {
+			if rule.Deprecated {
+				result.Notice = DeprecatedNotice
 			}
+
+			report.AddResult(result)
 		}
+	}
 
-		report.AddResult(result)
+	if cacheKey != "" {
+		if err := e.cacheReport(ctx, cacheKey, report); err != nil {
+			return output.Report{}, false, fmt.Errorf("eval cache set: %w", err)
+		}
 	}
 
-	return report, nil
+	return report, false, nil
 }
 
-func (e Engine) queryRule(ctx context.Context, rule *output.Rule, input interface{}) (*output.Result, error) {
+// evalRule evaluates the skip rules and, if not
+// skipped, the rule against input, returning a
+// result for each of the rule's findings.
+func (e Engine) evalRule(ctx context.Context, rule *output.Rule, input interface{}, with []*ast.With) ([]*output.Result, error) {
+	result, err := e.querySkip(ctx, rule, input, with)
+	if err != nil {
+		return nil, fmt.Errorf("query skip rule: %s: %w", rule.UID(), err)
+	}
+
+	if result.Skipped {
+		return []*output.Result{result}, nil
+	}
+
+	ruleInput, err := selectInput(input, rule.Input)
+	if err != nil {
+		return nil, fmt.Errorf("select input: %s: %w", rule.UID(), err)
+	}
+
+	results, err := e.queryRule(ctx, rule, ruleInput, with)
+	if err != nil {
+		return nil, fmt.Errorf("query rule: %s: %w", rule.UID(), err)
+	}
+
+	return results, nil
+}
+
+// deadlineExceeded returns true if the
+// deadline of ctx has been exceeded.
+func deadlineExceeded(ctx context.Context) bool {
+	return errors.Is(ctx.Err(), context.DeadlineExceeded)
+}
+
+// isExcluded returns true if rule shouldn't be checked
+// because of its experimental or deprecated status.
+func (e *Engine) isExcluded(rule *output.Rule) bool {
+	return (e.excludeExperimental && rule.Experimental) ||
+		(e.excludeDeprecated && rule.Deprecated)
+}
+
+// queryRule evaluates the rule against input. Rules with a set
+// value, e.g. partial set rules, fail with a result for each of
+// its elements and are undefined if the set is empty.
+func (e Engine) queryRule(ctx context.Context, rule *output.Rule, input interface{}, with []*ast.With) ([]*output.Result, error) {
 	query := fmt.Sprintf("data.%s.%s_%s", rule.Namespace, rule.Kind, rule.ID)
-	regoInstance := e.buildRegoInstance(query, input)
+
+	regoInstance, err := e.buildRegoInstance(query, input, with)
+	if err != nil {
+		return nil, err
+	}
 
 	resultSet, err := regoInstance.Eval(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("query eval: %w", err)
 	}
 
-	result := output.Result{
-		Rule:   rule,
-		Query:  query,
-		Passed: len(resultSet) == 0,
+	var (
+		value   interface{}
+		defined = len(resultSet) > 0
+	)
+
+	if defined && len(resultSet[0].Expressions) > 0 {
+		value = resultSet[0].Expressions[0].Value
 	}
 
-	return &result, nil
+	findings, isSet := value.([]interface{})
+	if isSet && len(findings) == 0 {
+		defined = false
+	}
+
+	// Rules fail when they're defined,
+	// unless they're affirmative.
+	passed := defined == rule.Affirmative()
+
+	if passed || !defined || !isSet {
+		result := output.Result{
+			Rule:   rule,
+			Query:  query,
+			Passed: passed,
+		}
+
+		if defined {
+			result.Message, result.Location = resultDetails(value)
+
+			if e.rawValues {
+				result.Value = value
+			}
+		}
+
+		return []*output.Result{&result}, nil
+	}
+
+	results := make([]*output.Result, 0, len(findings))
+
+	for i, finding := range findings {
+		result := output.Result{
+			Rule:    rule,
+			Query:   query,
+			Finding: i,
+		}
+
+		result.Message, result.Location = resultDetails(finding)
+
+		if e.rawValues {
+			result.Value = finding
+		}
+
+		results = append(results, &result)
+	}
+
+	return results, nil
 }
 
-func (e Engine) querySkip(ctx context.Context, rule *output.Rule, input interface{}) (*output.Result, error) {
+func (e Engine) querySkip(ctx context.Context, rule *output.Rule, input interface{}, with []*ast.With) (*output.Result, error) {
 	query := fmt.Sprintf("data.%s.skip[_][_] == %q", rule.Namespace, rule.ID)
-	regoInstance := e.buildRegoInstance(query, input)
+
+	regoInstance, err := e.buildRegoInstance(query, input, with)
+	if err != nil {
+		return nil, err
+	}
 
 	resultSet, err := regoInstance.Eval(ctx)
 	if err != nil {
@@ -166,20 +720,72 @@ func (e Engine) querySkip(ctx context.Context, rule *output.Rule, input interfac
 	return &result, nil
 }
 
-func (e Engine) buildRegoInstance(query string, input interface{}) *rego.Rego {
-	return rego.New(
-		rego.Query(query),
-		rego.Input(input),
+// buildRegoInstance creates a Rego instance for the query. The
+// with modifiers are applied to every expression in the query.
+// The input document is left undefined if input is undefinedInput.
+// Additional Rego options, e.g. a tracer, can be set with extra.
+func (e Engine) buildRegoInstance(query string, input interface{}, with []*ast.With, extra ...func(*rego.Rego)) (*rego.Rego, error) {
+	body, err := ast.ParseBody(query)
+	if err != nil {
+		return nil, fmt.Errorf("parse query: %w", err)
+	}
+
+	for _, expr := range body {
+		expr.With = append(expr.With, with...)
+	}
+
+	opts := []func(*rego.Rego){
+		rego.ParsedQuery(body),
 		rego.Compiler(e.compiler),
 		rego.StrictBuiltinErrors(true),
-		rego.PrintHook(topdown.NewPrintHook(os.Stderr)),
-	)
+		rego.PrintHook(topdown.NewPrintHook(e.printWriter)),
+	}
+
+	opts = append(opts, e.customBuiltinFuncs()...)
+
+	if _, ok := input.(undefinedInput); !ok {
+		opts = append(opts, rego.Input(input))
+	}
+
+	return rego.New(append(opts, extra...)...), nil
 }
 
-func allRegos(paths []string) (*loader.Result, error) {
-	return loader.NewFileLoader().
-		WithProcessAnnotation(true).
-		Filtered(paths, func(_ string, info os.FileInfo, depth int) bool {
-			return !info.IsDir() && !strings.HasSuffix(info.Name(), bundle.RegoExt)
-		})
+// moduleNamespace returns the namespace of mod,
+// i.e. its package path without the data prefix.
+func moduleNamespace(mod *ast.Module) string {
+	return strings.TrimPrefix(mod.Package.Path.String(), "data.")
+}
+
+// moduleRules returns the rules in mod that have a valid kind,
+// with the information from their annotations, attributed to
+// the engine's policy set.
+func (e *Engine) moduleRules(namespace string, mod *ast.Module) []*output.Rule {
+	var (
+		rules []*output.Rule
+		opts  = []output.RuleOption{output.WithDefaultSecuritySeverity(e.namespaceSecuritySeverity(namespace))}
+	)
+
+	for _, r := range mod.Rules {
+		var annotations *ast.Annotations
+		for _, a := range mod.Annotations {
+			if a.Scope == "rule" && a.GetTargetPath().String() == r.Path().String() {
+				annotations = a
+			}
+		}
+
+		rule, err := output.NewRule(namespace, r, annotations, opts...)
+		if err != nil {
+			continue
+		}
+
+		rule.Set = e.setName
+
+		if e.uidFunc != nil {
+			rule.CustomUID = e.uidFunc(*rule)
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules
 }
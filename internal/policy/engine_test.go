@@ -0,0 +1,127 @@
+package policy_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/reposaur/reposaur/internal/policy"
+)
+
+const testPolicy = `
+package repository
+
+violation_not_internal {
+	input.visibility != "internal"
+}
+
+warn_no_description {
+	not input.description
+}
+`
+
+// loadTestEngine writes each of the policies to a temporary
+// directory and loads an engine from it.
+func loadTestEngine(t *testing.T, policies []string, opts ...policy.Option) *policy.Engine {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	for i, p := range policies {
+		path := filepath.Join(dir, "policy"+string(rune('a'+i))+".rego")
+		if err := os.WriteFile(path, []byte(p), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	engine, err := policy.Load(context.Background(), []string{dir}, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return engine
+}
+
+func TestCheckSince(t *testing.T) {
+	since := time.Date(2022, time.May, 1, 0, 0, 0, 0, time.UTC)
+	engine := loadTestEngine(t, []string{testPolicy}, policy.WithSince(since))
+
+	cases := []struct {
+		name  string
+		input map[string]interface{}
+		stale bool
+	}{
+		{
+			name:  "pushed before cutoff",
+			input: map[string]interface{}{"pushed_at": "2022-04-30T23:59:59Z"},
+			stale: true,
+		},
+		{
+			name:  "pushed after cutoff",
+			input: map[string]interface{}{"pushed_at": "2022-05-01T00:00:01Z"},
+			stale: false,
+		},
+		{
+			name:  "updated before cutoff",
+			input: map[string]interface{}{"updated_at": "2021-01-01T00:00:00Z"},
+			stale: true,
+		},
+		{
+			name:  "no timestamp",
+			input: map[string]interface{}{},
+			stale: false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			report, err := engine.Check(context.Background(), "repository", c.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if report.Stale != c.stale {
+				t.Errorf("expected stale to be %v, got %v", c.stale, report.Stale)
+			}
+
+			for uid, result := range report.Results {
+				if c.stale && !result.Skipped {
+					t.Errorf("expected %s to be skipped", uid)
+				}
+
+				if !c.stale && result.Skipped {
+					t.Errorf("expected %s to be evaluated", uid)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckDecisionID(t *testing.T) {
+	engine := loadTestEngine(t, []string{testPolicy})
+	input := map[string]interface{}{"visibility": "public"}
+
+	first, err := engine.Check(context.Background(), "repository", input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := engine.Check(context.Background(), "repository", input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(first.DecisionID) != 36 {
+		t.Errorf("expected decision ID to be a UUID, got %s", first.DecisionID)
+	}
+
+	if first.DecisionID == second.DecisionID {
+		t.Errorf("expected each check to have a different decision ID, got %s", first.DecisionID)
+	}
+
+	if first.Timestamp.IsZero() {
+		t.Error("expected timestamp to be set")
+	}
+}
@@ -0,0 +1,210 @@
+package policy
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/reposaur/reposaur/internal/builtins"
+	"github.com/reposaur/reposaur/pkg/cache"
+	"github.com/reposaur/reposaur/pkg/output"
+)
+
+const evalCacheKeyPrefix = "policy.eval:"
+
+// nondeterministicBuiltins are the built-in functions whose result
+// may change between evaluations of the same input, e.g. because they
+// do requests or depend on the current time. Names ending with a dot
+// match every function in that namespace.
+var nondeterministicBuiltins = []string{
+	"cron.parse",
+	"github.",
+	"http.send",
+	"io.jwt.decode_verify",
+	"net.lookup_ip_addr",
+	"opa.runtime",
+	"rand.",
+	"time.now_ns",
+	"time.days_since",
+	"time.is_older_than",
+	"uuid.rfc4122",
+}
+
+// WithEvalCache memoizes reports in c, keyed by a hash of the
+// policies plus a hash of the namespace and input, so checking an
+// unchanged input against unchanged policies doesn't evaluate them
+// again. Changing the policies changes their hash, invalidating
+// every previous entry.
+//
+// Only deterministic policies are cached: if any of them calls a
+// built-in function that does requests, resolves host names or
+// depends on the current time (e.g. `github.request`), or a custom
+// built-in that isn't declared deterministic, the cache is never
+// consulted.
+func WithEvalCache(c cache.Cache) Option {
+	return func(e *Engine) {
+		e.evalCache = c
+	}
+}
+
+// EvalCacheable reports whether the reports of the
+// engine are memoized (see WithEvalCache).
+func (e *Engine) EvalCacheable() bool {
+	return e.evalCache != nil && e.policyHash != ""
+}
+
+// hashPolicies returns a hash of the modules and the options that
+// change their results, including the rules' UIDs and the custom
+// built-ins, or an empty string if any of the modules is
+// nondeterministic and their results can't be cached.
+func (e *Engine) hashPolicies() string {
+	paths := make([]string, 0, len(e.modules))
+
+	for path, mod := range e.modules {
+		if e.isNondeterministic(mod) {
+			return ""
+		}
+
+		paths = append(paths, path)
+	}
+
+	sort.Strings(paths)
+
+	h := sha256.New()
+
+	fmt.Fprintf(h, "set=%s since=%s experimental=%v deprecated=%v raw=%v severities=%v\n",
+		e.setName, e.since.Format(time.RFC3339Nano), e.excludeExperimental, e.excludeDeprecated, e.rawValues, e.namespaceSeverities)
+
+	for _, b := range e.customBuiltins {
+		fmt.Fprintf(h, "builtin=%s %s\n", b.Decl.Name, b.Decl.Decl)
+	}
+
+	for _, path := range paths {
+		mod := e.modules[path]
+
+		fmt.Fprintf(h, "%s\n%s\n", path, mod)
+
+		for _, rule := range e.moduleRules(moduleNamespace(mod), mod) {
+			fmt.Fprintf(h, "uid=%s\n", rule.UID())
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// isNondeterministic reports whether mod calls any of the
+// nondeterministic built-in functions, or a custom built-in that
+// isn't deterministic, either as an expression or nested in one
+// (e.g. `time.now_ns() > x`).
+func (e *Engine) isNondeterministic(mod *ast.Module) bool {
+	found := false
+
+	ast.NewGenericVisitor(func(x interface{}) bool {
+		if found {
+			return true
+		}
+
+		switch x := x.(type) {
+		case *ast.Expr:
+			found = x.IsCall() && e.isNondeterministicBuiltin(x.Operator().String())
+		case ast.Call:
+			found = len(x) > 0 && e.isNondeterministicBuiltin(x[0].String())
+		}
+
+		return found
+	}).Walk(mod)
+
+	return found
+}
+
+func (e *Engine) isNondeterministicBuiltin(name string) bool {
+	for _, b := range e.customBuiltins {
+		if b.Decl.Name == name {
+			return !b.Deterministic
+		}
+	}
+
+	// host names are resolved depending on the options, which
+	// are unknown if the engine doesn't set them
+	if name == builtins.NetIsPrivateIPBuiltin.Name {
+		return e.builtinOpts == nil || builtins.ResolvesDNS(e.builtinOpts...)
+	}
+
+	for _, b := range nondeterministicBuiltins {
+		if name == b || (strings.HasSuffix(b, ".") && strings.HasPrefix(name, b)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// evalCacheKey returns the key of the report of
+// checking input against the rules in namespace.
+func (e *Engine) evalCacheKey(namespace string, input interface{}) (string, error) {
+	hash, err := hashInput(input)
+	if err != nil {
+		return "", err
+	}
+
+	return evalCacheKeyPrefix + e.policyHash + ":" + namespace + ":" + hash, nil
+}
+
+// hashInput returns the hex-encoded SHA-256
+// hash of the JSON encoding of input.
+func hashInput(input interface{}) (string, error) {
+	b, err := json.Marshal(input)
+	if err != nil {
+		return "", fmt.Errorf("hash input: %w", err)
+	}
+
+	sum := sha256.Sum256(b)
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// cachedReport returns the report stored with key,
+// if any. Its results point to the report's rules.
+func (e *Engine) cachedReport(ctx context.Context, key string) (output.Report, bool, error) {
+	b, ok, err := e.evalCache.Get(ctx, key)
+	if err != nil || !ok {
+		return output.Report{}, false, err
+	}
+
+	var report output.Report
+
+	if err := json.Unmarshal(b, &report); err != nil {
+		return output.Report{}, false, fmt.Errorf("decode cached report: %w", err)
+	}
+
+	for _, result := range report.Results {
+		if rule, ok := report.Rules[result.Rule.UID()]; ok {
+			result.Rule = rule
+		}
+	}
+
+	return report, true, nil
+}
+
+// cacheReport stores report with key. Reports with
+// timed out results are partial and aren't stored.
+func (e *Engine) cacheReport(ctx context.Context, key string, report output.Report) error {
+	for _, result := range report.Results {
+		if result.TimedOut {
+			return nil
+		}
+	}
+
+	b, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("encode report: %w", err)
+	}
+
+	return e.evalCache.Set(ctx, key, b)
+}
@@ -0,0 +1,291 @@
+package policy_test
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/reposaur/reposaur/internal/builtins"
+	"github.com/reposaur/reposaur/internal/policy"
+	"github.com/reposaur/reposaur/pkg/cache"
+	"github.com/reposaur/reposaur/pkg/output"
+)
+
+// countingCache is a cache.Memory that counts
+// the hits and the values set.
+type countingCache struct {
+	*cache.Memory
+
+	mu   sync.Mutex
+	hits int
+	sets int
+}
+
+func (c *countingCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	v, ok, err := c.Memory.Get(ctx, key)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if ok {
+		c.hits++
+	}
+
+	return v, ok, err
+}
+
+func (c *countingCache) Set(ctx context.Context, key string, value []byte) error {
+	c.mu.Lock()
+	c.sets++
+	c.mu.Unlock()
+
+	return c.Memory.Set(ctx, key, value)
+}
+
+func TestCheckWithEvalCache(t *testing.T) {
+	c := &countingCache{Memory: cache.NewMemory(0)}
+	engine := loadTestEngine(t, []string{testPolicy}, policy.WithEvalCache(c))
+	ctx := context.Background()
+
+	if !engine.EvalCacheable() {
+		t.Fatal("expected the engine to be cacheable")
+	}
+
+	input := map[string]interface{}{"visibility": "public"}
+
+	first, err := engine.Check(ctx, "repository", input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := engine.Check(ctx, "repository", input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.hits != 1 || c.sets != 1 {
+		t.Errorf("expected 1 hit and 1 set, got %d and %d", c.hits, c.sets)
+	}
+
+	if first.DecisionID == second.DecisionID {
+		t.Error("expected a cached report to have a new decision ID")
+	}
+
+	for uid, result := range first.Results {
+		cached, ok := second.Results[uid]
+		if !ok || cached.Passed != result.Passed {
+			t.Errorf("expected cached result of %s to match, got %v", uid, cached)
+			continue
+		}
+
+		if cached.Rule != second.Rules[cached.Rule.UID()] {
+			t.Errorf("expected cached result of %s to point to the report's rule", uid)
+		}
+	}
+
+	if _, err := engine.Check(ctx, "repository", map[string]interface{}{"visibility": "internal"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if c.hits != 1 || c.sets != 2 {
+		t.Errorf("expected a different input to miss, got %d hits and %d sets", c.hits, c.sets)
+	}
+}
+
+func TestCheckWithEvalCacheInvalidation(t *testing.T) {
+	c := &countingCache{Memory: cache.NewMemory(0)}
+	ctx := context.Background()
+	input := map[string]interface{}{"visibility": "public"}
+
+	engine := loadTestEngine(t, []string{testPolicy}, policy.WithEvalCache(c))
+	if _, err := engine.Check(ctx, "repository", input); err != nil {
+		t.Fatal(err)
+	}
+
+	changed := testPolicy + `
+violation_public {
+	input.visibility == "public"
+}
+`
+
+	engine = loadTestEngine(t, []string{changed}, policy.WithEvalCache(c))
+
+	report, err := engine.Check(ctx, "repository", input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.hits != 0 {
+		t.Errorf("expected changed policies to miss, got %d hits", c.hits)
+	}
+
+	if result, ok := report.Results["repository/violation/public"]; !ok || result.Passed {
+		t.Errorf("expected the new rule to be evaluated and fail, got %v", result)
+	}
+}
+
+func TestCheckWithEvalCacheNondeterministic(t *testing.T) {
+	c := &countingCache{Memory: cache.NewMemory(0)}
+	engine := loadTestEngine(t, []string{`
+package repository
+
+violation_old {
+	time.now_ns() > 0
+}
+`}, policy.WithEvalCache(c))
+
+	if engine.EvalCacheable() {
+		t.Fatal("expected the engine not to be cacheable")
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := engine.Check(context.Background(), "repository", map[string]interface{}{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if c.hits != 0 || c.sets != 0 {
+		t.Errorf("expected the cache not to be used, got %d hits and %d sets", c.hits, c.sets)
+	}
+}
+
+func TestCheckWithEvalCacheTimeDependentBuiltins(t *testing.T) {
+	builtins.RegisterBuiltins(http.DefaultClient)
+
+	cases := map[string]string{
+		"cron.parse": `
+package repository
+
+violation_schedule {
+	cron.parse(input.schedule).interval < 300
+}
+`,
+		"io.jwt.decode_verify": `
+package repository
+
+violation_token {
+	[valid, _, _] := io.jwt.decode_verify(input.token, {"secret": "secret"})
+	not valid
+}
+`,
+	}
+
+	for name, src := range cases {
+		t.Run(name, func(t *testing.T) {
+			engine := loadTestEngine(t, []string{src}, policy.WithEvalCache(cache.NewMemory(0)))
+
+			if engine.EvalCacheable() {
+				t.Error("expected the engine not to be cacheable")
+			}
+		})
+	}
+}
+
+func TestCheckWithEvalCacheCustomBuiltins(t *testing.T) {
+	src := `
+package repository
+
+violation_double {
+	custom.double(input.n) > 4
+}
+`
+
+	deterministic := doubleBuiltin
+	deterministic.Deterministic = true
+
+	cases := []struct {
+		name      string
+		builtin   policy.Builtin
+		cacheable bool
+	}{
+		{"undeclared", doubleBuiltin, false},
+		{"deterministic", deterministic, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			engine := loadTestEngine(t, []string{src}, policy.WithCustomBuiltins(c.builtin), policy.WithEvalCache(cache.NewMemory(0)))
+
+			if engine.EvalCacheable() != c.cacheable {
+				t.Errorf("expected the engine to be cacheable: %t", c.cacheable)
+			}
+		})
+	}
+}
+
+func TestCheckWithEvalCacheDNSResolution(t *testing.T) {
+	builtins.RegisterBuiltins(http.DefaultClient)
+
+	src := `
+package repository
+
+violation_private_homepage {
+	net.is_private_ip(input.homepage)
+}
+`
+
+	cases := []struct {
+		name      string
+		opts      []policy.Option
+		cacheable bool
+	}{
+		{"unknown options", nil, false},
+		{"without resolution", []policy.Option{policy.WithBuiltinOptions()}, true},
+		{"with resolution", []policy.Option{policy.WithBuiltinOptions(builtins.WithDNSResolution(builtins.DefaultDNSTimeout))}, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			opts := append([]policy.Option{policy.WithEvalCache(cache.NewMemory(0))}, c.opts...)
+			engine := loadTestEngine(t, []string{src}, opts...)
+
+			if engine.EvalCacheable() != c.cacheable {
+				t.Errorf("expected the engine to be cacheable: %t", c.cacheable)
+			}
+		})
+	}
+}
+
+func TestCheckWithEvalCacheUIDFunc(t *testing.T) {
+	c := &countingCache{Memory: cache.NewMemory(0)}
+	ctx := context.Background()
+	input := map[string]interface{}{"visibility": "public"}
+
+	dir := writeTestPolicy(t, testPolicy)
+
+	for i := 0; i < 2; i++ {
+		engine, err := policy.Load(ctx, []string{dir}, policy.WithEvalCache(c))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := engine.Check(ctx, "repository", input); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if c.hits != 1 {
+		t.Fatalf("expected the same policies to hit, got %d hits", c.hits)
+	}
+
+	engine, err := policy.Load(ctx, []string{dir}, policy.WithEvalCache(c), policy.WithUIDFunc(func(r output.Rule) string {
+		return "custom/" + r.ID
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := engine.Check(ctx, "repository", input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.hits != 1 {
+		t.Errorf("expected a different UID func to miss, got %d hits", c.hits)
+	}
+
+	if _, ok := report.Results["custom/not_internal"]; !ok {
+		t.Errorf("expected results keyed by the custom UIDs, got %v", report.Results)
+	}
+}
@@ -0,0 +1,102 @@
+package policy_test
+
+import (
+	"context"
+	"testing"
+)
+
+const findingsPolicy = `
+package repository
+
+violation_unprotected_branch[msg] {
+	branch := input.branches[_]
+	not branch.protected
+	msg := sprintf("Branch %s isn't protected", [branch.name])
+}
+
+violation_unpinned_action[{"msg": msg, "path": path, "line": step.line}] {
+	path := input.workflow.path
+	step := input.workflow.steps[_]
+	not contains(step.uses, "@")
+	msg := sprintf("Action %s isn't pinned", [step.uses])
+}
+`
+
+func TestCheckMultipleFindings(t *testing.T) {
+	engine := loadTestEngine(t, []string{findingsPolicy})
+
+	report, err := engine.Check(context.Background(), "repository", map[string]interface{}{
+		"branches": []interface{}{
+			map[string]interface{}{"name": "main", "protected": true},
+			map[string]interface{}{"name": "develop"},
+			map[string]interface{}{"name": "release"},
+		},
+		"workflow": map[string]interface{}{
+			"path": ".github/workflows/ci.yml",
+			"steps": []interface{}{
+				map[string]interface{}{"uses": "actions/checkout", "line": 10},
+				map[string]interface{}{"uses": "actions/setup-go", "line": 12},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var branches, actions []string
+
+	for _, result := range report.SortedResults() {
+		if !result.Failed() {
+			t.Errorf("expected %s to fail", result.Key())
+		}
+
+		switch result.Rule.ID {
+		case "unprotected_branch":
+			branches = append(branches, result.Message)
+
+		case "unpinned_action":
+			actions = append(actions, result.Message)
+
+			if result.Location == nil || result.Location.Path != ".github/workflows/ci.yml" || result.Location.Line == 0 {
+				t.Errorf("expected %s to have a location, got %v", result.Key(), result.Location)
+			}
+		}
+	}
+
+	if len(branches) != 2 || branches[0] != "Branch develop isn't protected" || branches[1] != "Branch release isn't protected" {
+		t.Errorf("expected a finding for each unprotected branch, got %q", branches)
+	}
+
+	if len(actions) != 2 {
+		t.Errorf("expected a finding for each unpinned action, got %q", actions)
+	}
+
+	for _, key := range []string{"repository/violation/unprotected_branch", "repository/violation/unprotected_branch#1"} {
+		if _, ok := report.Results[key]; !ok {
+			t.Errorf("expected a result with key %s", key)
+		}
+	}
+}
+
+func TestCheckNoFindings(t *testing.T) {
+	engine := loadTestEngine(t, []string{findingsPolicy})
+
+	report, err := engine.Check(context.Background(), "repository", map[string]interface{}{
+		"branches": []interface{}{
+			map[string]interface{}{"name": "main", "protected": true},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(report.Results) != 2 {
+		t.Fatalf("expected a result for each rule, got %d", len(report.Results))
+	}
+
+	for key, result := range report.Results {
+		if !result.Passed {
+			t.Errorf("expected %s to pass", key)
+		}
+	}
+}
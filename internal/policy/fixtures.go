@@ -0,0 +1,66 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/open-policy-agent/opa/ast"
+)
+
+// fixturesRule is the name of the rule that namespaces can define
+// to compute values shared by their rules, e.g. a response from
+// the GitHub API that several rules assert on.
+const fixturesRule = "fixtures"
+
+// queryFixtures evaluates the fixtures rule of the namespace, if
+// defined, and returns a with modifier that replaces the rule by its
+// value. This way the fixtures are computed once per input instead
+// of once per rule.
+func (e Engine) queryFixtures(ctx context.Context, namespace string, input interface{}) ([]*ast.With, error) {
+	if !e.hasRule(namespace, fixturesRule) {
+		return nil, nil
+	}
+
+	ref := fmt.Sprintf("data.%s.%s", namespace, fixturesRule)
+
+	regoInstance, err := e.buildRegoInstance(ref, input, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resultSet, err := regoInstance.Eval(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("fixtures query eval: %w", err)
+	} else if len(resultSet) == 0 || len(resultSet[0].Expressions) == 0 {
+		return nil, nil
+	}
+
+	value, err := ast.InterfaceToValue(resultSet[0].Expressions[0].Value)
+	if err != nil {
+		return nil, err
+	}
+
+	with := &ast.With{
+		Target: ast.NewTerm(ast.MustParseRef(ref)),
+		Value:  ast.NewTerm(value),
+	}
+
+	return []*ast.With{with}, nil
+}
+
+// hasRule returns whether the namespace defines a rule with name.
+func (e Engine) hasRule(namespace, name string) bool {
+	for _, mod := range e.modules {
+		if mod.Package.Path.String() != "data."+namespace {
+			continue
+		}
+
+		for _, r := range mod.Rules {
+			if r.Head.Name.String() == name {
+				return true
+			}
+		}
+	}
+
+	return false
+}
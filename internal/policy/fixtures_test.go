@@ -0,0 +1,79 @@
+package policy_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/types"
+)
+
+var fixtureCalls int64
+
+func init() {
+	rego.RegisterBuiltin1(
+		&rego.Function{
+			Name: "test.fixture",
+			Decl: types.NewFunction(types.Args(types.A), types.A),
+		},
+		func(_ rego.BuiltinContext, op *ast.Term) (*ast.Term, error) {
+			atomic.AddInt64(&fixtureCalls, 1)
+			return op, nil
+		},
+	)
+}
+
+const fixturesPolicy = `
+package repository
+
+fixtures = {"name": name} {
+	name := test.fixture(input.name)
+}
+
+violation_a {
+	fixtures.name == "test"
+}
+
+violation_b {
+	fixtures.name == "other"
+}
+
+warn_c {
+	fixtures.name == "test"
+}
+`
+
+func TestCheckFixturesComputedOnce(t *testing.T) {
+	engine := loadTestEngine(t, []string{fixturesPolicy})
+	atomic.StoreInt64(&fixtureCalls, 0)
+
+	report, err := engine.Check(context.Background(), "repository", map[string]interface{}{
+		"name": "test",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if calls := atomic.LoadInt64(&fixtureCalls); calls != 1 {
+		t.Errorf("expected fixtures to be computed once, got %d calls", calls)
+	}
+
+	expected := map[string]bool{
+		"repository/violation/a": false,
+		"repository/violation/b": true,
+		"repository/warn/c":      false,
+	}
+
+	for uid, passed := range expected {
+		result, ok := report.Results[uid]
+		if !ok {
+			t.Fatalf("expected result for %s", uid)
+		}
+
+		if result.Passed != passed {
+			t.Errorf("expected %s passed to be %v, got %v", uid, passed, result.Passed)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package policy
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/open-policy-agent/opa/ast"
+)
+
+// Diagnostic describes a problem found in a rule
+// of the loaded policies.
+type Diagnostic struct {
+	Namespace string
+	Rule      string
+	Location  *ast.Location
+	Message   string
+}
+
+func (d Diagnostic) String() string {
+	if d.Location == nil {
+		return fmt.Sprintf("%s.%s: %s", d.Namespace, d.Rule, d.Message)
+	}
+
+	return fmt.Sprintf("%s:%d: %s.%s: %s", d.Location.File, d.Location.Row, d.Namespace, d.Rule, d.Message)
+}
+
+// Lint statically analyzes the loaded policies and returns
+// diagnostics for rules that can never be true, and therefore
+// never fail, because they:
+//
+//   - reference data that no rule or package provides, e.g. a
+//     misspelled package or rule name
+//   - have an expression that is always false, e.g. `false` or
+//     `1 == 2`
+//
+// Diagnostics are sorted by location.
+func (e *Engine) Lint() []Diagnostic {
+	var diagnostics []Diagnostic
+
+	for _, mod := range e.compiler.Modules {
+		namespace := moduleNamespace(mod)
+
+		for _, rule := range mod.Rules {
+			for _, msg := range e.lintRule(rule) {
+				diagnostics = append(diagnostics, Diagnostic{
+					Namespace: namespace,
+					Rule:      rule.Head.Name.String(),
+					Location:  rule.Location,
+					Message:   msg,
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		a, b := diagnostics[i].Location, diagnostics[j].Location
+		if a == nil || b == nil {
+			return b != nil
+		}
+
+		return a.Compare(b) < 0
+	})
+
+	return diagnostics
+}
+
+func (e *Engine) lintRule(rule *ast.Rule) []string {
+	var (
+		msgs []string
+		seen = map[string]bool{}
+	)
+
+	// negated expressions are true when the reference is
+	// undefined, so they're skipped
+	ast.NewGenericVisitor(func(x interface{}) bool {
+		if expr, ok := x.(*ast.Expr); ok {
+			return expr.Negated
+		}
+
+		ref, ok := x.(ast.Ref)
+		if !ok {
+			return false
+		}
+
+		if !ref.HasPrefix(ast.DefaultRootRef) || len(ref) < 2 {
+			return false
+		}
+
+		prefix := ref.ConstantPrefix()
+		if len(prefix) < 2 || seen[prefix.String()] {
+			return false
+		}
+
+		seen[prefix.String()] = true
+
+		if len(e.compiler.GetRules(prefix)) == 0 {
+			msgs = append(msgs, fmt.Sprintf("references undefined document %s", prefix))
+		}
+
+		return false
+	}).Walk(rule)
+
+	for _, expr := range rule.Body {
+		if alwaysFalse(expr) {
+			msgs = append(msgs, fmt.Sprintf("expression is always false: %s", expr))
+		}
+	}
+
+	return msgs
+}
+
+// comparisons are the operators that alwaysFalse
+// evaluates when their operands are ground scalars.
+var comparisons = map[string]func(int) bool{
+	ast.Equality.Name:      func(c int) bool { return c == 0 },
+	ast.Equal.Name:         func(c int) bool { return c == 0 },
+	ast.NotEqual.Name:      func(c int) bool { return c != 0 },
+	ast.LessThan.Name:      func(c int) bool { return c < 0 },
+	ast.LessThanEq.Name:    func(c int) bool { return c <= 0 },
+	ast.GreaterThan.Name:   func(c int) bool { return c > 0 },
+	ast.GreaterThanEq.Name: func(c int) bool { return c >= 0 },
+}
+
+// alwaysFalse returns true if expr is the `false` constant or a
+// comparison between constants that doesn't hold, taking negation
+// into account.
+func alwaysFalse(expr *ast.Expr) bool {
+	var holds bool
+
+	switch terms := expr.Terms.(type) {
+	case *ast.Term:
+		b, ok := terms.Value.(ast.Boolean)
+		if !ok {
+			return false
+		}
+
+		holds = bool(b)
+
+	case []*ast.Term:
+		cmp, ok := comparisons[expr.Operator().String()]
+		if !ok || len(terms) != 3 {
+			return false
+		}
+
+		a, b := terms[1].Value, terms[2].Value
+		if !isScalar(a) || !isScalar(b) {
+			return false
+		}
+
+		holds = cmp(ast.Compare(a, b))
+
+	default:
+		return false
+	}
+
+	if expr.Negated {
+		holds = !holds
+	}
+
+	return !holds
+}
+
+func isScalar(v ast.Value) bool {
+	switch v.(type) {
+	case ast.Null, ast.Boolean, ast.Number, ast.String:
+		return true
+	}
+
+	return false
+}
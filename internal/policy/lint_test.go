@@ -0,0 +1,90 @@
+package policy_test
+
+import (
+	"strings"
+	"testing"
+)
+
+const lintPolicy = `
+package repository
+
+is_public {
+	input.visibility == "public"
+}
+
+violation_public {
+	is_public
+}
+
+violation_typo {
+	data.repository.is_publci
+}
+
+violation_other_package {
+	data.organisation.members_can_fork
+}
+
+violation_disabled {
+	false
+}
+
+violation_never_equal {
+	"a" == "b"
+	input.name
+}
+
+violation_not_true {
+	not true
+}
+
+warn_negated {
+	not input.foo
+	not data.repository.is_private
+}
+
+warn_valid_comparison {
+	1 < 2
+	input.archived
+}
+`
+
+func TestLint(t *testing.T) {
+	engine := loadTestEngine(t, []string{lintPolicy})
+
+	diagnostics := engine.Lint()
+
+	expected := []struct {
+		rule    string
+		message string
+	}{
+		{"violation_typo", "data.repository.is_publci"},
+		{"violation_other_package", "data.organisation.members_can_fork"},
+		{"violation_disabled", "always false"},
+		{"violation_never_equal", "always false"},
+		{"violation_not_true", "always false"},
+	}
+
+	if len(diagnostics) != len(expected) {
+		t.Fatalf("expected %d diagnostics, got %d: %v", len(expected), len(diagnostics), diagnostics)
+	}
+
+	for i, e := range expected {
+		d := diagnostics[i]
+
+		if d.Rule != e.rule || !strings.Contains(d.Message, e.message) {
+			t.Errorf("expected diagnostic %d to be for %s containing '%s', got %s", i, e.rule, e.message, d)
+		}
+
+		if d.Namespace != "repository" || d.Location == nil {
+			t.Errorf("expected diagnostic %d to have a namespace and location, got %s", i, d)
+		}
+	}
+}
+
+func TestLintClean(t *testing.T) {
+	engine := loadTestEngine(t, []string{testPolicy})
+
+	if diagnostics := engine.Lint(); len(diagnostics) != 0 {
+		t.Errorf("expected no diagnostics, got %v", diagnostics)
+	}
+}
@@ -0,0 +1,56 @@
+package policy
+
+import (
+	"encoding/json"
+
+	"github.com/reposaur/reposaur/pkg/output"
+)
+
+// Keys of the object rules can be defined as to describe
+// their finding, e.g.:
+//
+//	violation_unpinned_action = {"msg": msg, "path": path, "line": line} {
+//		...
+//	}
+const (
+	messageKey = "msg"
+	pathKey    = "path"
+	lineKey    = "line"
+)
+
+// resultDetails extracts the message and location of the
+// value of a rule, if it's an object describing the finding.
+// String values are the message. Other values, e.g. true,
+// have none.
+func resultDetails(value interface{}) (string, *output.Location) {
+	if msg, ok := value.(string); ok {
+		return msg, nil
+	}
+
+	obj, ok := value.(map[string]interface{})
+	if !ok {
+		return "", nil
+	}
+
+	msg, _ := obj[messageKey].(string)
+
+	path, _ := obj[pathKey].(string)
+	if path == "" {
+		return msg, nil
+	}
+
+	loc := &output.Location{Path: path}
+
+	switch line := obj[lineKey].(type) {
+	case json.Number:
+		if n, err := line.Int64(); err == nil && n > 0 {
+			loc.Line = int(n)
+		}
+	case float64:
+		if line > 0 {
+			loc.Line = int(line)
+		}
+	}
+
+	return msg, loc
+}
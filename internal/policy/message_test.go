@@ -0,0 +1,86 @@
+package policy_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/reposaur/reposaur/pkg/output"
+)
+
+const locatedPolicy = `
+package repository
+
+violation_unpinned_action = {"msg": msg, "path": path, "line": line} {
+	step := input.workflow.steps[i]
+	not contains(step.uses, "@")
+	msg := sprintf("Action %s isn't pinned", [step.uses])
+	path := input.workflow.path
+	line := step.line
+}
+
+warn_no_description = {"msg": "Repository has no description"} {
+	not input.description
+}
+`
+
+func TestCheckLocatedResult(t *testing.T) {
+	engine := loadTestEngine(t, []string{locatedPolicy})
+
+	report, err := engine.Check(context.Background(), "repository", map[string]interface{}{
+		"workflow": map[string]interface{}{
+			"path": ".github/workflows/ci.yml",
+			"steps": []interface{}{
+				map[string]interface{}{"uses": "actions/checkout@v3", "line": 10},
+				map[string]interface{}{"uses": "actions/setup-go", "line": 12},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := report.Results["repository/violation/unpinned_action"]
+	if result.Passed {
+		t.Fatal("expected unpinned_action to fail")
+	}
+
+	if result.Message != "Action actions/setup-go isn't pinned" {
+		t.Errorf("unexpected message: %s", result.Message)
+	}
+
+	if result.Location == nil || result.Location.Path != ".github/workflows/ci.yml" || result.Location.Line != 12 {
+		t.Errorf("unexpected location: %+v", result.Location)
+	}
+
+	if r := report.Results["repository/warn/no_description"]; r.Message != "Repository has no description" || r.Location != nil {
+		t.Errorf("expected message without location, got %q %+v", r.Message, r.Location)
+	}
+
+	sr, err := output.NewSarifReport(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, r := range sr.Runs[0].Results {
+		if *r.RuleID != "repository/violation/unpinned_action" {
+			continue
+		}
+
+		if *r.Message.Text != result.Message {
+			t.Errorf("expected SARIF message %q, got %q", result.Message, *r.Message.Text)
+		}
+
+		pl := r.Locations[0].PhysicalLocation
+		if *pl.ArtifactLocation.URI != ".github/workflows/ci.yml" {
+			t.Errorf("unexpected SARIF uri: %s", *pl.ArtifactLocation.URI)
+		}
+
+		if pl.Region == nil || *pl.Region.StartLine != 12 {
+			t.Errorf("unexpected SARIF region: %+v", pl.Region)
+		}
+
+		return
+	}
+
+	t.Error("expected SARIF result for unpinned_action")
+}
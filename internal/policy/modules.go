@@ -0,0 +1,123 @@
+package policy
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/bundle"
+)
+
+// loadModules parses the Rego files in paths, which are files,
+// directories or bundle tarballs. Files with more than one package
+// statement are split into a module per package (see splitModules).
+func loadModules(paths []string) (map[string]*ast.Module, error) {
+	modules := map[string]*ast.Module{}
+
+	for _, path := range paths {
+		if strings.HasSuffix(path, bundleExt) {
+			bundleModules, err := loadBundleModules(path)
+			if err != nil {
+				return nil, err
+			}
+
+			for name, mod := range bundleModules {
+				modules[name] = mod
+			}
+
+			continue
+		}
+
+		err := filepath.WalkDir(path, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if d.IsDir() || !strings.HasSuffix(d.Name(), bundle.RegoExt) {
+				return nil
+			}
+
+			src, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+
+			sources, err := splitModules(path, string(src))
+			if err != nil {
+				return err
+			}
+
+			for name, src := range sources {
+				mod, err := ast.ParseModuleWithOpts(path, src, ast.ParserOptions{ProcessAnnotation: true})
+				if err != nil {
+					return err
+				}
+
+				modules[name] = mod
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return modules, nil
+}
+
+// splitModules splits the Rego source of the file at path in
+// a source per package statement, keyed by the module name. Each
+// package takes the comments right above it, e.g. its METADATA.
+// Sources are padded with empty lines, so locations still point
+// to the right line in the file.
+//
+// A file with a single package results in a single
+// source, named like the file.
+func splitModules(path, src string) (map[string]string, error) {
+	stmts, _, err := ast.ParseStatements(path, src)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []int
+
+	for _, stmt := range stmts {
+		if pkg, ok := stmt.(*ast.Package); ok {
+			rows = append(rows, pkg.Location.Row)
+		}
+	}
+
+	if len(rows) < 2 {
+		return map[string]string{path: src}, nil
+	}
+
+	var (
+		lines = strings.SplitAfter(src, "\n")
+		cuts  = []int{0}
+	)
+
+	for _, row := range rows[1:] {
+		cut := row - 1
+		for cut > 0 && strings.HasPrefix(strings.TrimSpace(lines[cut-1]), "#") {
+			cut--
+		}
+
+		cuts = append(cuts, cut)
+	}
+
+	cuts = append(cuts, len(lines))
+	sources := make(map[string]string, len(rows))
+
+	for i := 0; i < len(rows); i++ {
+		start, end := cuts[i], cuts[i+1]
+		name := fmt.Sprintf("%s#%d", path, i+1)
+
+		sources[name] = strings.Repeat("\n", start) + strings.Join(lines[start:end], "")
+	}
+
+	return sources, nil
+}
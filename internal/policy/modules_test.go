@@ -0,0 +1,103 @@
+package policy_test
+
+import (
+	"context"
+	"sort"
+	"testing"
+)
+
+const multiPackagePolicy = `package repository
+
+violation_not_internal {
+	input.visibility != "internal"
+}
+
+# METADATA
+# title: Organization policies
+package organization
+
+# METADATA
+# title: Members can't fork private repositories
+violation_members_can_fork {
+	input.members_can_fork_private_repositories
+}
+
+package pull_request
+
+warn_no_body {
+	not input.body
+}
+`
+
+func TestLoadMultiPackageFile(t *testing.T) {
+	engine := loadTestEngine(t, []string{multiPackagePolicy})
+
+	namespaces := engine.Namespaces()
+	sort.Strings(namespaces)
+
+	expected := []string{"organization", "pull_request", "repository"}
+
+	if len(namespaces) != len(expected) {
+		t.Fatalf("expected namespaces %v, got %v", expected, namespaces)
+	}
+
+	for i, ns := range expected {
+		if namespaces[i] != ns {
+			t.Errorf("expected namespace %d to be %s, got %s", i, ns, namespaces[i])
+		}
+	}
+
+	input := map[string]interface{}{"members_can_fork_private_repositories": true}
+
+	report, err := engine.Check(context.Background(), "organization", input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, ok := report.Results["organization/violation/members_can_fork"]
+	if !ok {
+		t.Fatalf("expected a result for members_can_fork, got %v", report.Results)
+	}
+
+	if !result.Failed() {
+		t.Error("expected members_can_fork to fail")
+	}
+
+	if result.Rule.Title != "Members can't fork private repositories" {
+		t.Errorf("expected the rule's metadata to be kept, got title '%s'", result.Rule.Title)
+	}
+
+	if len(report.Results) != 1 {
+		t.Errorf("expected only the organization rules to be checked, got %d results", len(report.Results))
+	}
+}
+
+func TestLoadMultiPackageFileLocations(t *testing.T) {
+	engine := loadTestEngine(t, []string{multiPackagePolicy})
+
+	rows := map[string]int{}
+
+	for _, mod := range engine.Modules() {
+		rows[mod.Package.Path.String()] = mod.Package.Location.Row
+
+		if mod.Package.Path.String() != "data.organization" {
+			continue
+		}
+
+		if len(mod.Annotations) != 2 || mod.Annotations[0].Scope != "package" {
+			t.Errorf("expected the package's metadata to be kept, got %v", mod.Annotations)
+		}
+	}
+
+	expected := map[string]int{
+		"data.repository":   1,
+		"data.organization": 9,
+		"data.pull_request": 17,
+	}
+
+	for pkg, row := range expected {
+		if rows[pkg] != row {
+			t.Errorf("expected %s to be at line %d, got %d", pkg, row, rows[pkg])
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package policy_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/reposaur/reposaur/internal/policy"
+)
+
+const otherNamespacePolicy = `
+package organization
+
+warn_no_email {
+	not input.email
+}
+`
+
+func TestCheckNamespaceNotFound(t *testing.T) {
+	engine := loadTestEngine(t, []string{testPolicy, otherNamespacePolicy})
+	ctx := context.Background()
+
+	_, err := engine.Check(ctx, "repositry", map[string]interface{}{})
+	if !errors.Is(err, policy.ErrNamespaceNotFound) {
+		t.Errorf("expected ErrNamespaceNotFound, got %v", err)
+	}
+
+	_, err = engine.CheckAggregate(ctx, "repositories", nil)
+	if !errors.Is(err, policy.ErrNamespaceNotFound) {
+		t.Errorf("expected ErrNamespaceNotFound from aggregate check, got %v", err)
+	}
+
+	for _, ns := range []string{"repository", "organization"} {
+		if _, err := engine.Check(ctx, ns, map[string]interface{}{}); err != nil {
+			t.Errorf("expected %s to be checked, got %v", ns, err)
+		}
+	}
+}
+
+func TestNamespacesUnique(t *testing.T) {
+	engine := loadTestEngine(t, []string{testPolicy, testPolicy, otherNamespacePolicy})
+
+	if namespaces := engine.Namespaces(); len(namespaces) != 2 {
+		t.Errorf("expected 2 namespaces, got %v", namespaces)
+	}
+}
@@ -0,0 +1,512 @@
+package policy
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const ociScheme = "oci://"
+
+// ociManifestMediaTypes are the manifest formats
+// accepted when pulling from a registry.
+var ociManifestMediaTypes = []string{
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+// ociBundleMediaTypes are the layer media types of policy
+// bundles, as pushed by OPA, Conftest and oras.
+var ociBundleMediaTypes = map[string]bool{
+	"application/vnd.oci.image.layer.v1.tar+gzip":            true,
+	"application/vnd.cncf.openpolicyagent.layer.v1.tar+gzip": true,
+}
+
+var ociAuthParamRegex = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// WithOCICacheDir sets the directory where bundles pulled from
+// OCI registries are cached. Defaults to reposaur/oci in the user's
+// cache directory.
+func WithOCICacheDir(dir string) Option {
+	return func(e *Engine) {
+		e.ociCacheDir = dir
+	}
+}
+
+// WithOCIClient sets the HTTP client used to pull
+// bundles from OCI registries.
+func WithOCIClient(client *http.Client) Option {
+	return func(e *Engine) {
+		e.ociClient = client
+	}
+}
+
+// ociReference is a parsed oci://registry/repository:tag
+// (or @digest) policy path.
+type ociReference struct {
+	registry   string
+	repository string
+	reference  string
+}
+
+func parseOCIReference(path string) (ociReference, error) {
+	ref := strings.TrimPrefix(path, ociScheme)
+
+	slash := strings.Index(ref, "/")
+	if slash <= 0 || slash == len(ref)-1 {
+		return ociReference{}, fmt.Errorf("invalid OCI reference %q", path)
+	}
+
+	r := ociReference{
+		registry:   ref[:slash],
+		repository: ref[slash+1:],
+		reference:  "latest",
+	}
+
+	if at := strings.Index(r.repository, "@"); at >= 0 {
+		r.repository, r.reference = r.repository[:at], r.repository[at+1:]
+	} else if colon := strings.LastIndex(r.repository, ":"); colon >= 0 {
+		r.repository, r.reference = r.repository[:colon], r.repository[colon+1:]
+	}
+
+	// the registry may be a bracketed IPv6 host, e.g. [::1]:5000
+	if u, err := url.Parse("//" + r.registry); err != nil || u.Host != r.registry {
+		return ociReference{}, fmt.Errorf("invalid OCI reference %q", path)
+	}
+
+	if r.repository == "" || r.reference == "" {
+		return ociReference{}, fmt.Errorf("invalid OCI reference %q", path)
+	}
+
+	return r, nil
+}
+
+// baseURL returns the registry's API URL. Registries on the
+// loopback interface are accessed through plain HTTP, like
+// Docker does.
+func (r ociReference) baseURL() string {
+	u, _ := url.Parse("//" + r.registry)
+	host := u.Hostname()
+
+	if host == "localhost" || host == "127.0.0.1" || host == "::1" {
+		return "http://" + r.registry
+	}
+
+	return "https://" + r.registry
+}
+
+// pullOCIPaths pulls the bundles referenced by OCI paths and
+// returns the paths with those replaced by the local directories
+// they were extracted to.
+func (e *Engine) pullOCIPaths(ctx context.Context, paths []string) ([]string, error) {
+	local := make([]string, 0, len(paths))
+
+	for _, p := range paths {
+		if !strings.HasPrefix(p, ociScheme) {
+			local = append(local, p)
+			continue
+		}
+
+		dir, err := e.pullOCI(ctx, p)
+		if err != nil {
+			return nil, fmt.Errorf("pull %s: %w", p, err)
+		}
+
+		local = append(local, dir)
+	}
+
+	return local, nil
+}
+
+// pullOCI pulls the bundle referenced by path and extracts it to
+// the cache, keyed by the manifest's digest. Bundles already in the
+// cache aren't downloaded again.
+func (e *Engine) pullOCI(ctx context.Context, path string) (string, error) {
+	ref, err := parseOCIReference(path)
+	if err != nil {
+		return "", err
+	}
+
+	cacheDir := e.ociCacheDir
+	if cacheDir == "" {
+		userCache, err := os.UserCacheDir()
+		if err != nil {
+			return "", err
+		}
+
+		cacheDir = filepath.Join(userCache, "reposaur", "oci")
+	}
+
+	p := ociPuller{client: e.ociClient, ref: ref}
+
+	var manifest struct {
+		Layers []struct {
+			MediaType string `json:"mediaType"`
+			Digest    string `json:"digest"`
+		} `json:"layers"`
+	}
+
+	body, err := p.get(ctx, "manifests/"+ref.reference, strings.Join(ociManifestMediaTypes, ", "))
+	if err != nil {
+		return "", fmt.Errorf("get manifest: %w", err)
+	}
+
+	raw, err := io.ReadAll(body)
+	body.Close()
+	if err != nil {
+		return "", err
+	}
+
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		return "", fmt.Errorf("decode manifest: %w", err)
+	}
+
+	sum := sha256.Sum256(raw)
+
+	if algo, expected, found := strings.Cut(ref.reference, ":"); found {
+		if algo != "sha256" {
+			return "", fmt.Errorf("unsupported digest algorithm %q", algo)
+		}
+
+		if actual := hex.EncodeToString(sum[:]); actual != expected {
+			return "", fmt.Errorf("manifest digest mismatch: got sha256:%s", actual)
+		}
+	}
+
+	dir := filepath.Join(cacheDir, "sha256", hex.EncodeToString(sum[:]))
+
+	if _, err := os.Stat(dir); err == nil {
+		return dir, nil
+	}
+
+	tmp, err := os.MkdirTemp(cacheDir, ".pull-")
+	if errors.Is(err, os.ErrNotExist) {
+		if err = os.MkdirAll(cacheDir, 0o700); err == nil {
+			tmp, err = os.MkdirTemp(cacheDir, ".pull-")
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmp)
+
+	var pulled int
+
+	for _, layer := range manifest.Layers {
+		if !ociBundleMediaTypes[layer.MediaType] {
+			continue
+		}
+
+		if err := p.extractBlob(ctx, layer.Digest, tmp); err != nil {
+			return "", fmt.Errorf("layer %s: %w", layer.Digest, err)
+		}
+
+		pulled++
+	}
+
+	if pulled == 0 {
+		return "", errors.New("manifest doesn't have any bundle layers")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dir), 0o700); err != nil {
+		return "", err
+	}
+
+	if err := os.Rename(tmp, dir); err != nil {
+		return "", err
+	}
+
+	return dir, nil
+}
+
+// ociPuller does requests against the registry API of
+// a repository, authenticating when challenged.
+type ociPuller struct {
+	client *http.Client
+	ref    ociReference
+	auth   string
+}
+
+func (p *ociPuller) get(ctx context.Context, path, accept string) (io.ReadCloser, error) {
+	u := fmt.Sprintf("%s/v2/%s/%s", p.ref.baseURL(), p.ref.repository, path)
+
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("User-Agent", "reposaur")
+
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+
+		if p.auth != "" {
+			req.Header.Set("Authorization", p.auth)
+		}
+
+		resp, err := p.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode == http.StatusOK {
+			return resp.Body, nil
+		}
+
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusUnauthorized || attempt > 0 {
+			return nil, fmt.Errorf("GET %s: unexpected status %d", u, resp.StatusCode)
+		}
+
+		if p.auth, err = p.authenticate(ctx, resp.Header.Get("WWW-Authenticate")); err != nil {
+			return nil, fmt.Errorf("authenticate: %w", err)
+		}
+	}
+}
+
+// authenticate answers an authentication challenge with the
+// registry's Docker credentials, returning the Authorization header
+// to use in subsequent requests.
+func (p *ociPuller) authenticate(ctx context.Context, challenge string) (string, error) {
+	scheme, rawParams, _ := strings.Cut(challenge, " ")
+
+	basic := dockerCredentials(p.ref.registry)
+
+	if strings.EqualFold(scheme, "basic") {
+		if basic == "" {
+			return "", fmt.Errorf("no credentials for %s", p.ref.registry)
+		}
+
+		return "Basic " + basic, nil
+	}
+
+	if !strings.EqualFold(scheme, "bearer") {
+		return "", fmt.Errorf("unsupported authentication scheme %q", scheme)
+	}
+
+	params := map[string]string{}
+	for _, m := range ociAuthParamRegex.FindAllStringSubmatch(rawParams, -1) {
+		params[m[1]] = m[2]
+	}
+
+	if params["realm"] == "" {
+		return "", errors.New("bearer challenge without realm")
+	}
+
+	query := url.Values{}
+	for _, k := range []string{"service", "scope"} {
+		if params[k] != "" {
+			query.Set(k, params[k])
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, params["realm"]+"?"+query.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+
+	if basic != "" {
+		req.Header.Set("Authorization", "Basic "+basic)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("get token: unexpected status %d", resp.StatusCode)
+	}
+
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", err
+	}
+
+	if token.Token == "" {
+		token.Token = token.AccessToken
+	}
+
+	return "Bearer " + token.Token, nil
+}
+
+// extractBlob downloads a gzipped tarball blob, verifies its
+// digest and extracts it to dir.
+func (p *ociPuller) extractBlob(ctx context.Context, digest, dir string) error {
+	algo, expected, _ := strings.Cut(digest, ":")
+	if algo != "sha256" {
+		return fmt.Errorf("unsupported digest algorithm %q", algo)
+	}
+
+	body, err := p.get(ctx, "blobs/"+digest, "")
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	hash := sha256.New()
+
+	gz, err := gzip.NewReader(io.TeeReader(body, hash))
+	if err != nil {
+		return err
+	}
+
+	if err := extractTar(tar.NewReader(gz), dir); err != nil {
+		return err
+	}
+
+	// Hash any trailing data the archive readers didn't consume.
+	if _, err := io.Copy(hash, body); err != nil {
+		return err
+	}
+
+	if actual := hex.EncodeToString(hash.Sum(nil)); actual != expected {
+		return fmt.Errorf("digest mismatch: got sha256:%s", actual)
+	}
+
+	return nil
+}
+
+// extractTar extracts the regular files of an archive to dir,
+// refusing entries that would be written outside of it.
+func extractTar(tr *tar.Reader, dir string) error {
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		target := filepath.Join(dir, filepath.FromSlash(hdr.Name))
+		if !strings.HasPrefix(target, filepath.Clean(dir)+string(os.PathSeparator)) {
+			return fmt.Errorf("invalid path in archive %q", hdr.Name)
+		}
+
+		if err := os.MkdirAll(filepath.Dir(target), 0o700); err != nil {
+			return err
+		}
+
+		f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+		if err != nil {
+			return err
+		}
+
+		_, err = io.Copy(f, tr)
+		f.Close()
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// dockerCredentials returns the Base64 encoded basic credentials
+// of registry from the Docker configuration, if any. Credentials
+// kept by a credential helper (credHelpers or credsStore) are
+// retrieved with it.
+func dockerCredentials(registry string) string {
+	configDir := os.Getenv("DOCKER_CONFIG")
+	if configDir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+
+		configDir = filepath.Join(home, ".docker")
+	}
+
+	data, err := os.ReadFile(filepath.Join(configDir, "config.json"))
+	if err != nil {
+		return ""
+	}
+
+	var config struct {
+		Auths map[string]struct {
+			Auth     string `json:"auth"`
+			Username string `json:"username"`
+			Password string `json:"password"`
+		} `json:"auths"`
+		CredHelpers map[string]string `json:"credHelpers"`
+		CredsStore  string            `json:"credsStore"`
+	}
+
+	if err := json.Unmarshal(data, &config); err != nil {
+		return ""
+	}
+
+	if helper, ok := config.CredHelpers[registry]; ok {
+		return helperCredentials(helper, registry)
+	}
+
+	for _, key := range []string{registry, "https://" + registry, "http://" + registry} {
+		auth, ok := config.Auths[key]
+		if !ok {
+			continue
+		}
+
+		if auth.Auth != "" {
+			return auth.Auth
+		}
+
+		if auth.Username != "" {
+			return base64.StdEncoding.EncodeToString([]byte(auth.Username + ":" + auth.Password))
+		}
+	}
+
+	if config.CredsStore != "" {
+		return helperCredentials(config.CredsStore, registry)
+	}
+
+	return ""
+}
+
+// helperCredentials returns the Base64 encoded basic credentials
+// of registry from the docker-credential-<helper> program, if any.
+func helperCredentials(helper, registry string) string {
+	cmd := exec.Command("docker-credential-"+helper, "get")
+	cmd.Stdin = strings.NewReader(registry)
+
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+
+	var creds struct {
+		Username string `json:"Username"`
+		Secret   string `json:"Secret"`
+	}
+
+	if err := json.Unmarshal(out, &creds); err != nil || creds.Username == "" {
+		return ""
+	}
+
+	return base64.StdEncoding.EncodeToString([]byte(creds.Username + ":" + creds.Secret))
+}
@@ -0,0 +1,301 @@
+package policy_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/reposaur/reposaur/internal/policy"
+)
+
+// stubRegistry serves a single bundle at reposaur/policies:v1
+// and requires a bearer token issued with basic credentials.
+type stubRegistry struct {
+	srv        *httptest.Server
+	bundle     []byte
+	corrupt    bool
+	blobPulls  int64
+	tokenCalls int64
+}
+
+func newStubRegistry(t *testing.T, files map[string]string) *stubRegistry {
+	t.Helper()
+
+	return startStubRegistry(t, "127.0.0.1:0", files)
+}
+
+func startStubRegistry(t *testing.T, addr string, files map[string]string) *stubRegistry {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	gz := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gz)
+
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o600, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	} else if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		t.Skipf("listen on %s: %v", addr, err)
+	}
+
+	r := &stubRegistry{bundle: buf.Bytes()}
+	r.srv = &httptest.Server{Listener: l, Config: &http.Server{Handler: r}}
+	r.srv.Start()
+	t.Cleanup(r.srv.Close)
+
+	return r
+}
+
+func (r *stubRegistry) digest() string {
+	sum := sha256.Sum256(r.bundle)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func (r *stubRegistry) manifest() []byte {
+	manifest, _ := json.Marshal(map[string]interface{}{
+		"schemaVersion": 2,
+		"mediaType":     "application/vnd.oci.image.manifest.v1+json",
+		"layers": []map[string]interface{}{
+			{
+				"mediaType": "application/vnd.oci.image.layer.v1.tar+gzip",
+				"digest":    r.digest(),
+				"size":      len(r.bundle),
+			},
+		},
+	})
+
+	return manifest
+}
+
+func (r *stubRegistry) manifestDigest() string {
+	sum := sha256.Sum256(r.manifest())
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func (r *stubRegistry) host() string {
+	return strings.TrimPrefix(r.srv.URL, "http://")
+}
+
+func (r *stubRegistry) ref() string {
+	return "oci://" + r.host() + "/reposaur/policies:v1"
+}
+
+func (r *stubRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Path == "/token" {
+		atomic.AddInt64(&r.tokenCalls, 1)
+
+		if user, pass, ok := req.BasicAuth(); !ok || user != "reposaur" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]string{"token": "test-token"})
+
+		return
+	}
+
+	if req.Header.Get("Authorization") != "Bearer test-token" {
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="registry",scope="repository:reposaur/policies:pull"`, r.srv.URL))
+		w.WriteHeader(http.StatusUnauthorized)
+
+		return
+	}
+
+	// manifests are served by tag and by any digest,
+	// so the client is the one verifying the latter
+	if strings.HasPrefix(req.URL.Path, "/v2/reposaur/policies/manifests/") {
+		_, _ = w.Write(r.manifest())
+		return
+	}
+
+	switch req.URL.Path {
+	case "/v2/reposaur/policies/blobs/" + r.digest():
+		atomic.AddInt64(&r.blobPulls, 1)
+
+		if r.corrupt {
+			_, _ = w.Write(append(append([]byte{}, r.bundle...), 0))
+			return
+		}
+
+		_, _ = w.Write(r.bundle)
+
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// setDockerConfig writes a Docker configuration with
+// credentials for registry and points DOCKER_CONFIG to it.
+func setDockerConfig(t *testing.T, registry string) {
+	dir := t.TempDir()
+
+	config := fmt.Sprintf(`{"auths": {%q: {"auth": %q}}}`, registry, base64.StdEncoding.EncodeToString([]byte("reposaur:secret")))
+
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("DOCKER_CONFIG", dir)
+}
+
+func TestLoadOCI(t *testing.T) {
+	registry := newStubRegistry(t, map[string]string{
+		"repository/policy.rego": testPolicy,
+		".manifest":              `{"revision": "v1"}`,
+	})
+
+	setDockerConfig(t, registry.host())
+
+	ctx := context.Background()
+	cacheDir := t.TempDir()
+
+	for i := 0; i < 2; i++ {
+		engine, err := policy.Load(ctx, []string{registry.ref()}, policy.WithOCICacheDir(cacheDir))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		report, err := engine.Check(ctx, "repository", map[string]interface{}{"visibility": "public"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if result := report.Results["repository/violation/not_internal"]; result == nil || result.Passed {
+			t.Errorf("expected repository/violation/not_internal to fail, got %+v", result)
+		}
+	}
+
+	if pulls := atomic.LoadInt64(&registry.blobPulls); pulls != 1 {
+		t.Errorf("expected bundle to be pulled once and then cached, got %d pulls", pulls)
+	}
+
+	if atomic.LoadInt64(&registry.tokenCalls) == 0 {
+		t.Error("expected a token to be requested")
+	}
+}
+
+func TestLoadOCIDigestMismatch(t *testing.T) {
+	registry := newStubRegistry(t, map[string]string{
+		"repository/policy.rego": testPolicy,
+	})
+	registry.corrupt = true
+
+	setDockerConfig(t, registry.host())
+
+	_, err := policy.Load(context.Background(), []string{registry.ref()}, policy.WithOCICacheDir(t.TempDir()))
+	if err == nil || !strings.Contains(err.Error(), "digest mismatch") {
+		t.Errorf("expected digest mismatch error, got %v", err)
+	}
+}
+
+func TestLoadOCIUnauthorized(t *testing.T) {
+	registry := newStubRegistry(t, map[string]string{
+		"repository/policy.rego": testPolicy,
+	})
+
+	t.Setenv("DOCKER_CONFIG", t.TempDir())
+
+	_, err := policy.Load(context.Background(), []string{registry.ref()}, policy.WithOCICacheDir(t.TempDir()))
+	if err == nil {
+		t.Error("expected error pulling without credentials")
+	}
+}
+
+func TestLoadOCIByDigest(t *testing.T) {
+	registry := newStubRegistry(t, map[string]string{
+		"repository/policy.rego": testPolicy,
+	})
+
+	setDockerConfig(t, registry.host())
+
+	ref := "oci://" + registry.host() + "/reposaur/policies@" + registry.manifestDigest()
+
+	if _, err := policy.Load(context.Background(), []string{ref}, policy.WithOCICacheDir(t.TempDir())); err != nil {
+		t.Fatal(err)
+	}
+
+	sum := sha256.Sum256([]byte("another manifest"))
+	ref = "oci://" + registry.host() + "/reposaur/policies@sha256:" + hex.EncodeToString(sum[:])
+
+	_, err := policy.Load(context.Background(), []string{ref}, policy.WithOCICacheDir(t.TempDir()))
+	if err == nil || !strings.Contains(err.Error(), "manifest digest mismatch") {
+		t.Errorf("expected manifest digest mismatch error, got %v", err)
+	}
+}
+
+func TestLoadOCIIPv6(t *testing.T) {
+	registry := startStubRegistry(t, "[::1]:0", map[string]string{
+		"repository/policy.rego": testPolicy,
+	})
+
+	setDockerConfig(t, registry.host())
+
+	if _, err := policy.Load(context.Background(), []string{registry.ref()}, policy.WithOCICacheDir(t.TempDir())); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadOCICredentialHelper(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("credential helper stub is a shell script")
+	}
+
+	registry := newStubRegistry(t, map[string]string{
+		"repository/policy.rego": testPolicy,
+	})
+
+	bin := t.TempDir()
+	helper := "#!/bin/sh\ncat > /dev/null\necho '{\"Username\": \"reposaur\", \"Secret\": \"secret\"}'\n"
+
+	if err := os.WriteFile(filepath.Join(bin, "docker-credential-stub"), []byte(helper), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	for _, config := range []string{
+		fmt.Sprintf(`{"credHelpers": {%q: "stub"}}`, registry.host()),
+		`{"credsStore": "stub"}`,
+	} {
+		dir := t.TempDir()
+
+		if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(config), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		t.Setenv("DOCKER_CONFIG", dir)
+
+		if _, err := policy.Load(context.Background(), []string{registry.ref()}, policy.WithOCICacheDir(t.TempDir())); err != nil {
+			t.Errorf("%s: %v", config, err)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/reposaur/reposaur/internal/builtins"
+	"github.com/reposaur/reposaur/pkg/output"
+	"github.com/reposaur/reposaur/pkg/util"
+)
+
+// Builtin is a custom built-in function available to the policies
+// of an engine, in addition to the ones registered globally.
+type Builtin struct {
+	Decl *rego.Function
+	Impl rego.BuiltinDyn
+
+	// Deterministic is true if the function always returns the
+	// same result for the same arguments, so the reports of the
+	// policies calling it can be memoized (see WithEvalCache).
+	Deterministic bool
+}
+
+// WithClient sets the HTTP client used by the built-in functions
+// when evaluating the policies, instead of the one they were
+// registered with.
+func WithClient(client *http.Client) Option {
+	return func(e *Engine) {
+		e.client = client
+	}
+}
+
+// WithConcurrency sets the maximum number of namespaces checked
+// concurrently by CheckAll. Unlimited if n isn't positive.
+func WithConcurrency(n int) Option {
+	return func(e *Engine) {
+		e.concurrency = n
+	}
+}
+
+// WithTimeout sets the maximum duration of each check. Rules that
+// aren't evaluated before it elapses are marked as timed out, like
+// when the context's deadline is exceeded.
+func WithTimeout(d time.Duration) Option {
+	return func(e *Engine) {
+		e.timeout = d
+	}
+}
+
+// WithPrintWriter sets where the output of print calls in the
+// policies is written to, os.Stderr by default.
+func WithPrintWriter(w io.Writer) Option {
+	return func(e *Engine) {
+		e.printWriter = w
+	}
+}
+
+// WithCapabilities restricts the built-in functions and features
+// the policies can use. Policies using anything else fail to compile.
+// Custom built-ins (see WithCustomBuiltins) are always available.
+func WithCapabilities(c *ast.Capabilities) Option {
+	return func(e *Engine) {
+		e.capabilities = c
+	}
+}
+
+// WithCustomBuiltins makes the built-in functions available to the
+// engine's policies only. Their names can't be the same as a globally
+// registered built-in's.
+func WithCustomBuiltins(builtins ...Builtin) Option {
+	return func(e *Engine) {
+		e.customBuiltins = append(e.customBuiltins, builtins...)
+	}
+}
+
+// WithUIDFunc sets how the UIDs of the rules are computed, which
+// key them and their results in reports, output.DefaultUID by
+// default. Rules with the same UID are considered the same rule:
+// only the first one is checked, ordered by policy set, namespace,
+// ID and kind, and the engine's own rules come before the ones of
+// the policy sets.
+func WithUIDFunc(f output.UIDFunc) Option {
+	return func(e *Engine) {
+		e.uidFunc = f
+	}
+}
+
+// ReadOnlyMethods are the HTTP methods of requests that
+// don't mutate anything, see WithAllowedMethods.
+var ReadOnlyMethods = []string{http.MethodGet, http.MethodHead}
+
+// WithAllowedMethods restricts the HTTP methods of the requests the
+// policies can do with github.request to methods, e.g. ReadOnlyMethods
+// to guarantee they don't mutate anything. Requests with any other
+// method fail the evaluation, and so do github.graphql mutations
+// unless POST is allowed. Every method is allowed by default.
+func WithAllowedMethods(methods ...string) Option {
+	return func(e *Engine) {
+		e.allowedMethods = append([]string{}, methods...)
+	}
+}
+
+// WithBuiltinOptions configures the built-in functions when
+// evaluating the policies, instead of the options they were
+// registered with. Without opts the built-ins use their defaults.
+func WithBuiltinOptions(opts ...builtins.Option) Option {
+	return func(e *Engine) {
+		e.builtinOpts = append(append([]builtins.Option{}, e.builtinOpts...), opts...)
+	}
+}
+
+// customBuiltinDecls returns the declarations of the custom built-ins
+// by name, to compile the policies with.
+func (e *Engine) customBuiltinDecls() (map[string]*ast.Builtin, error) {
+	decls := make(map[string]*ast.Builtin, len(e.customBuiltins))
+
+	for _, b := range e.customBuiltins {
+		if b.Decl == nil || b.Impl == nil {
+			return nil, fmt.Errorf("custom builtin without declaration or implementation")
+		}
+
+		if _, ok := ast.BuiltinMap[b.Decl.Name]; ok {
+			return nil, fmt.Errorf("custom builtin %s: already registered", b.Decl.Name)
+		}
+
+		decls[b.Decl.Name] = &ast.Builtin{
+			Name: b.Decl.Name,
+			Decl: b.Decl.Decl,
+		}
+	}
+
+	return decls, nil
+}
+
+// customBuiltinFuncs returns the Rego options making
+// the custom built-ins available to an evaluation.
+func (e *Engine) customBuiltinFuncs() []func(*rego.Rego) {
+	opts := make([]func(*rego.Rego), 0, len(e.customBuiltins))
+
+	for _, b := range e.customBuiltins {
+		opts = append(opts, rego.FunctionDyn(b.Decl, b.Impl))
+	}
+
+	return opts
+}
+
+// checkContext returns a copy of ctx with the engine's timeout,
+// HTTP client, built-in options and allowed methods, if set. The
+// returned function must be called to release its resources.
+func (e *Engine) checkContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if e.client != nil {
+		ctx = util.NewClientContext(ctx, e.client)
+	}
+
+	if e.builtinOpts != nil {
+		ctx = builtins.NewOptionsContext(ctx, e.builtinOpts...)
+	}
+
+	if e.allowedMethods != nil {
+		ctx = util.NewAllowedMethodsContext(ctx, e.allowedMethods)
+	}
+
+	if e.timeout > 0 {
+		return context.WithTimeout(ctx, e.timeout)
+	}
+
+	return ctx, func() {}
+}
@@ -0,0 +1,237 @@
+package policy_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/types"
+	"github.com/reposaur/reposaur/internal/policy"
+	"github.com/reposaur/reposaur/pkg/util"
+)
+
+// doubleBuiltin is a custom built-in that doubles a number.
+var doubleBuiltin = policy.Builtin{
+	Decl: &rego.Function{
+		Name: "custom.double",
+		Decl: types.NewFunction(types.Args(types.N), types.N),
+	},
+	Impl: func(_ rego.BuiltinContext, terms []*ast.Term) (*ast.Term, error) {
+		var n int
+		if err := ast.As(terms[0].Value, &n); err != nil {
+			return nil, err
+		}
+
+		return ast.IntNumberTerm(n * 2), nil
+	},
+}
+
+func TestWithCustomBuiltins(t *testing.T) {
+	engine := loadTestEngine(t, []string{`
+package repository
+
+violation_double {
+	custom.double(input.stars) > 10
+}
+`}, policy.WithCustomBuiltins(doubleBuiltin))
+
+	report, err := engine.Check(context.Background(), "repository", map[string]interface{}{"stars": 6})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result := report.Results["repository/violation/double"]; result == nil || !result.Failed() {
+		t.Errorf("expected the rule using the custom builtin to fail, got %+v", result)
+	}
+}
+
+func TestWithCustomBuiltinsInvalid(t *testing.T) {
+	dir := t.TempDir()
+	src := `package repository
+
+violation_double {
+	custom.double(input.stars) > 10
+}
+`
+
+	if err := os.WriteFile(filepath.Join(dir, "policy.rego"), []byte(src), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := loadEngine(dir); err == nil {
+		t.Error("expected an unknown builtin to fail to compile")
+	}
+
+	shadow := doubleBuiltin
+	shadow.Decl = &rego.Function{Name: "count", Decl: doubleBuiltin.Decl.Decl}
+
+	if _, err := loadEngine(dir, policy.WithCustomBuiltins(doubleBuiltin, shadow)); err == nil || !strings.Contains(err.Error(), "count") {
+		t.Errorf("expected a builtin shadowing a global one to fail, got %v", err)
+	}
+}
+
+func TestWithCapabilities(t *testing.T) {
+	dir := t.TempDir()
+	src := `package repository
+
+violation_upper {
+	upper(input.name) == "REPOSAUR"
+}
+`
+
+	if err := os.WriteFile(filepath.Join(dir, "policy.rego"), []byte(src), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	caps := ast.CapabilitiesForThisVersion()
+	builtins := caps.Builtins[:0]
+
+	for _, b := range caps.Builtins {
+		if b.Name != "upper" {
+			builtins = append(builtins, b)
+		}
+	}
+
+	caps.Builtins = builtins
+
+	if _, err := loadEngine(dir, policy.WithCapabilities(caps)); err == nil {
+		t.Error("expected a builtin missing from the capabilities to fail to compile")
+	}
+
+	if _, err := loadEngine(dir, policy.WithCapabilities(ast.CapabilitiesForThisVersion())); err != nil {
+		t.Errorf("expected the policy to compile, got %s", err)
+	}
+}
+
+func TestWithPrintWriter(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	engine := loadTestEngine(t, []string{`
+package repository
+
+violation_print {
+	print("checking", input.name)
+}
+`}, policy.WithPrintWriter(buf))
+
+	if _, err := engine.Check(context.Background(), "repository", map[string]interface{}{"name": "reposaur"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(buf.String(), "checking reposaur") {
+		t.Errorf("expected the print output to be written, got %q", buf.String())
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	engine := loadTestEngine(t, []string{slowPolicy}, policy.WithTimeout(75*time.Millisecond))
+
+	report, err := engine.Check(context.Background(), "repository", map[string]interface{}{"visibility": "public"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if a := report.Results["repository/violation/a"]; a.TimedOut {
+		t.Errorf("expected a to be evaluated, got %+v", a)
+	}
+
+	if c := report.Results["repository/violation/c"]; !c.TimedOut {
+		t.Errorf("expected c to time out, got %+v", c)
+	}
+}
+
+func TestWithConcurrency(t *testing.T) {
+	var running, max int32
+
+	track := policy.Builtin{
+		Decl: &rego.Function{
+			Name: "custom.track",
+			Decl: types.NewFunction(types.Args(), types.B),
+		},
+		Impl: func(_ rego.BuiltinContext, _ []*ast.Term) (*ast.Term, error) {
+			n := atomic.AddInt32(&running, 1)
+			defer atomic.AddInt32(&running, -1)
+
+			for {
+				m := atomic.LoadInt32(&max)
+				if n <= m || atomic.CompareAndSwapInt32(&max, m, n) {
+					break
+				}
+			}
+
+			time.Sleep(20 * time.Millisecond)
+
+			return ast.BooleanTerm(true), nil
+		},
+	}
+
+	policies := make([]string, 4)
+	for i := range policies {
+		policies[i] = "package ns" + string(rune('a'+i)) + "\n\nviolation_track {\n\tcustom.track()\n}\n"
+	}
+
+	engine := loadTestEngine(t, policies, policy.WithCustomBuiltins(track), policy.WithConcurrency(1))
+
+	reports, err := engine.CheckAll(context.Background(), map[string]interface{}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(reports) != 4 {
+		t.Fatalf("expected 4 reports, got %d", len(reports))
+	}
+
+	if m := atomic.LoadInt32(&max); m != 1 {
+		t.Errorf("expected a single namespace to be checked at a time, got %d", m)
+	}
+}
+
+func TestWithClient(t *testing.T) {
+	client := &http.Client{}
+
+	usesClient := policy.Builtin{
+		Decl: &rego.Function{
+			Name: "custom.uses_client",
+			Decl: types.NewFunction(types.Args(), types.B),
+		},
+		Impl: func(bctx rego.BuiltinContext, _ []*ast.Term) (*ast.Term, error) {
+			c, ok := util.ClientFromContext(bctx.Context)
+			if !ok {
+				return nil, errors.New("no client in context")
+			}
+
+			return ast.BooleanTerm(c == client), nil
+		},
+	}
+
+	engine := loadTestEngine(t, []string{`
+package repository
+
+violation_client {
+	custom.uses_client()
+}
+`}, policy.WithCustomBuiltins(usesClient), policy.WithClient(client))
+
+	report, err := engine.Check(context.Background(), "repository", map[string]interface{}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result := report.Results["repository/violation/client"]; result == nil || !result.Failed() {
+		t.Errorf("expected the engine's client to be used, got %+v", result)
+	}
+}
+
+// loadEngine loads the policies in dir with opts.
+func loadEngine(dir string, opts ...policy.Option) (*policy.Engine, error) {
+	return policy.Load(context.Background(), []string{dir}, opts...)
+}
@@ -0,0 +1,71 @@
+package policy_test
+
+import (
+	"context"
+	"testing"
+)
+
+const polarityPolicy = `
+package repository
+
+violation_public {
+	input.visibility == "public"
+}
+
+allow_internal {
+	input.visibility == "internal"
+}
+`
+
+func TestCheckPolarity(t *testing.T) {
+	engine := loadTestEngine(t, []string{polarityPolicy})
+
+	cases := []struct {
+		visibility string
+		expected   map[string]bool
+	}{
+		{
+			visibility: "internal",
+			expected: map[string]bool{
+				"repository/violation/public": true,
+				"repository/allow/internal":   true,
+			},
+		},
+		{
+			visibility: "public",
+			expected: map[string]bool{
+				"repository/violation/public": false,
+				"repository/allow/internal":   false,
+			},
+		},
+		{
+			visibility: "private",
+			expected: map[string]bool{
+				"repository/violation/public": true,
+				"repository/allow/internal":   false,
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.visibility, func(t *testing.T) {
+			report, err := engine.Check(context.Background(), "repository", map[string]interface{}{
+				"visibility": c.visibility,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for uid, passed := range c.expected {
+				result, ok := report.Results[uid]
+				if !ok {
+					t.Fatalf("expected result for %s", uid)
+				}
+
+				if result.Passed != passed {
+					t.Errorf("expected %s passed to be %v, got %v", uid, passed, result.Passed)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/reposaur/reposaur/pkg/output"
+)
+
+// Recheck executes only the rules that failed in prior against
+// input and returns prior updated with their new results. Results
+// of rules that passed or were skipped are kept as they were. Rules
+// that failed but no longer exist in the loaded policies are
+// removed from the report.
+func (e *Engine) Recheck(ctx context.Context, namespace string, input interface{}, prior output.Report) (output.Report, error) {
+	failed := map[string]bool{}
+
+	for _, result := range prior.Results {
+		if !result.Passed && !result.Skipped {
+			failed[result.Rule.UID()] = true
+		}
+	}
+
+	rechecked, err := e.check(ctx, namespace, input, func(rule *output.Rule) bool {
+		return failed[rule.UID()]
+	})
+	if err != nil {
+		return output.Report{}, fmt.Errorf("recheck: %w", err)
+	}
+
+	report := output.Report{
+		Rules:      map[string]*output.Rule{},
+		Results:    map[string]*output.Result{},
+		Properties: prior.Properties,
+		Stale:      rechecked.Stale,
+		DecisionID: rechecked.DecisionID,
+		Timestamp:  rechecked.Timestamp,
+		Input:      rechecked.Input,
+	}
+
+	for uid, rule := range prior.Rules {
+		if !failed[uid] {
+			report.AddRule(rule)
+		}
+	}
+
+	for _, result := range prior.Results {
+		if !failed[result.Rule.UID()] {
+			report.AddResult(result)
+		}
+	}
+
+	for _, rule := range rechecked.SortedRules() {
+		report.AddRule(rule)
+	}
+
+	for _, result := range rechecked.SortedResults() {
+		report.AddResult(result)
+	}
+
+	return report, nil
+}
@@ -0,0 +1,109 @@
+package policy_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/reposaur/reposaur/pkg/output"
+)
+
+const recheckPolicy = `
+package repository
+
+violation_not_internal {
+	input.visibility != "internal"
+}
+
+warn_no_description {
+	not input.description
+}
+
+note_archived {
+	input.archived
+}
+`
+
+func TestRecheck(t *testing.T) {
+	engine := loadTestEngine(t, []string{recheckPolicy})
+	ctx := context.Background()
+
+	prior, err := engine.Check(ctx, "repository", map[string]interface{}{
+		"visibility": "public",
+		"archived":   false,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A rule that failed in a policy set that's
+	// no longer loaded.
+	removed := &output.Rule{ID: "removed", Kind: "violation", Namespace: "repository"}
+	prior.AddRule(removed)
+	prior.AddResult(&output.Result{Rule: removed, Passed: false})
+
+	// The archived rule passed before, it shouldn't be
+	// executed again even though it now fails.
+	report, err := engine.Recheck(ctx, "repository", map[string]interface{}{
+		"visibility":  "internal",
+		"description": "",
+		"archived":    true,
+	}, prior)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]bool{
+		"repository/violation/not_internal": true,
+		"repository/warn/no_description":    true,
+		"repository/note/archived":          true,
+	}
+
+	if len(report.Results) != len(expected) {
+		t.Fatalf("expected %d results, got %d", len(expected), len(report.Results))
+	}
+
+	if report.RuleCount != len(expected) {
+		t.Errorf("expected rule count to be %d, got %d", len(expected), report.RuleCount)
+	}
+
+	for uid, passed := range expected {
+		result, ok := report.Results[uid]
+		if !ok {
+			t.Fatalf("expected result for %s", uid)
+		}
+
+		if result.Passed != passed {
+			t.Errorf("expected %s passed to be %v, got %v", uid, passed, result.Passed)
+		}
+	}
+
+	if report.Results["repository/note/archived"] != prior.Results["repository/note/archived"] {
+		t.Error("expected passing result to be kept from the prior report")
+	}
+
+	if report.DecisionID == prior.DecisionID {
+		t.Error("expected recheck to have a new decision ID")
+	}
+}
+
+func TestRecheckNothingFailed(t *testing.T) {
+	engine := loadTestEngine(t, []string{recheckPolicy})
+	ctx := context.Background()
+	input := map[string]interface{}{"visibility": "internal", "description": "Test"}
+
+	prior, err := engine.Check(ctx, "repository", input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := engine.Recheck(ctx, "repository", input, prior)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for uid, result := range prior.Results {
+		if report.Results[uid] != result {
+			t.Errorf("expected %s result to be kept from the prior report", uid)
+		}
+	}
+}
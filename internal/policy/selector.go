@@ -0,0 +1,65 @@
+package policy
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/open-policy-agent/opa/util"
+)
+
+// undefinedInput is used as the input of queries whose
+// input selector doesn't resolve, leaving `input` undefined.
+type undefinedInput struct{}
+
+// selectInput returns the part of input referenced by the JSON
+// pointer (RFC 6901) selector. An empty selector selects the whole
+// input. If the selector doesn't resolve, undefinedInput is returned.
+func selectInput(input interface{}, selector string) (interface{}, error) {
+	if selector == "" {
+		return input, nil
+	}
+
+	if !strings.HasPrefix(selector, "/") {
+		return nil, fmt.Errorf("invalid input selector %q: must start with /", selector)
+	}
+
+	// Inputs that weren't decoded from JSON (e.g. structs)
+	// are converted so they can be traversed.
+	switch input.(type) {
+	case map[string]interface{}, []interface{}:
+	default:
+		if err := util.RoundTrip(&input); err != nil {
+			return nil, fmt.Errorf("input selector %q: %w", selector, err)
+		}
+	}
+
+	curr := input
+
+	for _, token := range strings.Split(selector[1:], "/") {
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+
+		switch v := curr.(type) {
+		case map[string]interface{}:
+			next, ok := v[token]
+			if !ok {
+				return undefinedInput{}, nil
+			}
+
+			curr = next
+
+		case []interface{}:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(v) {
+				return undefinedInput{}, nil
+			}
+
+			curr = v[i]
+
+		default:
+			return undefinedInput{}, nil
+		}
+	}
+
+	return curr, nil
+}
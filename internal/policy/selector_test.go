@@ -0,0 +1,76 @@
+package policy_test
+
+import (
+	"context"
+	"testing"
+)
+
+const selectorPolicy = `
+package repository
+
+# METADATA
+# custom:
+#   input: /security_and_analysis/secret_scanning
+violation_secret_scanning_disabled {
+	input.status != "enabled"
+}
+
+# METADATA
+# custom:
+#   input: /topics/0
+violation_first_topic_not_team {
+	not startswith(input, "team-")
+}
+
+# METADATA
+# custom:
+#   input: /missing
+violation_missing_defined {
+	input
+}
+
+# METADATA
+# custom:
+#   input: /missing
+violation_missing_undefined {
+	not input
+}
+
+violation_not_internal {
+	input.visibility != "internal"
+}
+`
+
+func TestCheckInputSelector(t *testing.T) {
+	engine := loadTestEngine(t, []string{selectorPolicy})
+
+	report, err := engine.Check(context.Background(), "repository", map[string]interface{}{
+		"visibility": "internal",
+		"topics":     []interface{}{"team-platform", "go"},
+		"security_and_analysis": map[string]interface{}{
+			"secret_scanning": map[string]interface{}{"status": "disabled"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]bool{
+		"repository/violation/secret_scanning_disabled": false,
+		"repository/violation/first_topic_not_team":     true,
+		"repository/violation/missing_defined":          true,
+		"repository/violation/missing_undefined":        false,
+		"repository/violation/not_internal":             true,
+	}
+
+	for uid, passed := range expected {
+		result, ok := report.Results[uid]
+		if !ok {
+			t.Fatalf("expected result for %s", uid)
+		}
+
+		if result.Passed != passed {
+			t.Errorf("expected %s passed to be %v, got %v", uid, passed, result.Passed)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrDuplicatePolicySet is returned by Load when
+// two policy sets have the same name.
+var ErrDuplicatePolicySet = errors.New("duplicate policy set")
+
+type policySetPaths struct {
+	name  string
+	paths []string
+}
+
+// policySet is a named set of policies loaded
+// and compiled in isolation by its own engine.
+type policySet struct {
+	name   string
+	engine *Engine
+}
+
+// WithPolicySet loads the policies in paths as a named set, with
+// its own compiler, so they're evaluated in isolation from the
+// other policies and sets: rules of a set can't shadow or reference
+// the ones of another, even if they share a namespace. Rules of a
+// set are attributed to it (see output.Rule.Set) and their results
+// are part of the same reports as the others.
+func WithPolicySet(name string, paths ...string) Option {
+	return func(e *Engine) {
+		e.setPaths = append(e.setPaths, policySetPaths{name: name, paths: paths})
+	}
+}
+
+// withSetName makes the engine load the policies of
+// the set name, without loading any other sets.
+func withSetName(name string) Option {
+	return func(e *Engine) {
+		e.setName = name
+		e.setPaths = nil
+	}
+}
+
+// loadPolicySets loads each of the engine's policy
+// sets with its own engine, configured with opts.
+func (e *Engine) loadPolicySets(ctx context.Context, opts []Option) error {
+	seen := map[string]bool{}
+
+	for _, set := range e.setPaths {
+		if set.name == "" {
+			return errors.New("policy set without name")
+		}
+
+		if seen[set.name] {
+			return fmt.Errorf("%w: %s", ErrDuplicatePolicySet, set.name)
+		}
+
+		seen[set.name] = true
+
+		setOpts := append(append([]Option{}, opts...), withSetName(set.name))
+
+		engine, err := Load(ctx, set.paths, setOpts...)
+		if err != nil {
+			return fmt.Errorf("policy set %s: %w", set.name, err)
+		}
+
+		e.sets = append(e.sets, &policySet{name: set.name, engine: engine})
+	}
+
+	return nil
+}
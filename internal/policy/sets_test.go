@@ -0,0 +1,123 @@
+package policy_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/reposaur/reposaur/internal/policy"
+)
+
+// writeTestPolicy writes policy to a new temporary
+// directory and returns the directory's path.
+func writeTestPolicy(t *testing.T, policy string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(dir, "policy.rego"), []byte(policy), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return dir
+}
+
+func TestCheckPolicySets(t *testing.T) {
+	// both sets define the same namespace and complete
+	// rules, which would conflict if compiled together
+	internal := writeTestPolicy(t, `
+package repository
+
+visibility := "internal"
+
+violation_visibility {
+	input.visibility != visibility
+}
+`)
+
+	vendor := writeTestPolicy(t, `
+package repository
+
+visibility := "public"
+
+violation_visibility {
+	input.visibility != visibility
+}
+`)
+
+	engine, err := policy.Load(context.Background(), nil,
+		policy.WithPolicySet("internal", internal),
+		policy.WithPolicySet("vendor", vendor),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ns := engine.Namespaces(); len(ns) != 1 || ns[0] != "repository" {
+		t.Errorf("expected the repository namespace, got %v", ns)
+	}
+
+	report, err := engine.Check(context.Background(), "repository", map[string]interface{}{"visibility": "public"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]bool{
+		"internal:repository/violation/visibility": false,
+		"vendor:repository/violation/visibility":   true,
+	}
+
+	if len(report.Results) != len(cases) {
+		t.Errorf("expected %d results, got %d", len(cases), len(report.Results))
+	}
+
+	for uid, passed := range cases {
+		result, ok := report.Results[uid]
+		if !ok {
+			t.Errorf("expected a result for %s", uid)
+			continue
+		}
+
+		if result.Passed != passed {
+			t.Errorf("expected %s passed to be %v", uid, passed)
+		}
+	}
+
+	if rules := engine.Catalog()["repository"]; len(rules) != 2 || rules[0].Set != "internal" || rules[1].Set != "vendor" {
+		t.Errorf("expected a rule of each set in the catalog, got %v", rules)
+	}
+}
+
+func TestCheckPolicySetsWithDefaultPolicies(t *testing.T) {
+	engine := loadTestEngine(t, []string{testPolicy}, policy.WithPolicySet("vendor", writeTestPolicy(t, `
+package repository
+
+violation_not_internal {
+	input.visibility == "private"
+}
+`)))
+
+	report, err := engine.Check(context.Background(), "repository", map[string]interface{}{"visibility": "private"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result, ok := report.Results["repository/violation/not_internal"]; !ok || result.Passed || result.Rule.Set != "" {
+		t.Errorf("expected the default policies' rule to fail without a set, got %v", result)
+	}
+
+	if result, ok := report.Results["vendor:repository/violation/not_internal"]; !ok || result.Passed || result.Rule.Set != "vendor" {
+		t.Errorf("expected the vendor's rule to fail attributed to it, got %v", result)
+	}
+}
+
+func TestLoadDuplicatePolicySet(t *testing.T) {
+	dir := writeTestPolicy(t, testPolicy)
+
+	_, err := policy.Load(context.Background(), nil, policy.WithPolicySet("a", dir), policy.WithPolicySet("a", dir))
+	if !errors.Is(err, policy.ErrDuplicatePolicySet) {
+		t.Errorf("expected ErrDuplicatePolicySet, got %v", err)
+	}
+}
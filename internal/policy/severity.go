@@ -0,0 +1,50 @@
+package policy
+
+import (
+	"fmt"
+)
+
+// securitySeverityKey is the custom annotation
+// holding a rule's security severity.
+const securitySeverityKey = "security-severity"
+
+// WithNamespaceSecuritySeverity sets the default security severity
+// of the rules in each namespace of severities, applied to rules
+// without one in their annotations. Severities are scores (e.g. 8.5)
+// or named levels (critical, high, medium or low). A `security-severity`
+// in the package-scoped annotations of a namespace takes precedence.
+func WithNamespaceSecuritySeverity(severities map[string]string) Option {
+	return func(e *Engine) {
+		if e.namespaceSeverities == nil {
+			e.namespaceSeverities = map[string]string{}
+		}
+
+		for ns, sev := range severities {
+			e.namespaceSeverities[ns] = sev
+		}
+	}
+}
+
+// namespaceSecuritySeverity returns the default security severity
+// of the rules in namespace, i.e. the `security-severity` of the
+// package-scoped annotations of any of its modules, falling back
+// to the one set with WithNamespaceSecuritySeverity.
+func (e *Engine) namespaceSecuritySeverity(namespace string) string {
+	for _, mod := range e.modules {
+		if moduleNamespace(mod) != namespace {
+			continue
+		}
+
+		for _, a := range mod.Annotations {
+			if a.Scope != "package" {
+				continue
+			}
+
+			if sev, ok := a.Custom[securitySeverityKey]; ok {
+				return fmt.Sprintf("%v", sev)
+			}
+		}
+	}
+
+	return e.namespaceSeverities[namespace]
+}
@@ -0,0 +1,84 @@
+package policy_test
+
+import (
+	"testing"
+
+	"github.com/reposaur/reposaur/internal/policy"
+)
+
+func TestNamespaceSecuritySeverity(t *testing.T) {
+	const annotated = `
+# METADATA
+# scope: package
+# custom:
+#   security-severity: high
+package security
+
+violation_inherited {
+	true
+}
+
+# METADATA
+# custom:
+#   security-severity: 9.5
+violation_overridden {
+	true
+}
+`
+
+	const plain = `
+package repository
+
+violation_inherited {
+	true
+}
+
+# METADATA
+# custom:
+#   security-severity: low
+warn_overridden {
+	true
+}
+`
+
+	const unset = `
+package pull_request
+
+warn_kind_default {
+	true
+}
+`
+
+	engine := loadTestEngine(t, []string{annotated, plain, unset}, policy.WithNamespaceSecuritySeverity(map[string]string{
+		"security":   "2",
+		"repository": "8.1",
+	}))
+
+	cases := map[string]string{
+		"security/violation/inherited":   "7",
+		"security/violation/overridden":  "9.5",
+		"repository/violation/inherited": "8.1",
+		"repository/warn/overridden":     "1",
+		"pull_request/warn/kind_default": "4",
+	}
+
+	for ns, rules := range engine.Catalog() {
+		for _, rule := range rules {
+			expected, ok := cases[rule.UID()]
+			if !ok {
+				t.Errorf("unexpected rule %s in %s", rule.UID(), ns)
+				continue
+			}
+
+			if rule.SecuritySeverity != expected {
+				t.Errorf("expected %s to have security severity %s, got %s", rule.UID(), expected, rule.SecuritySeverity)
+			}
+
+			delete(cases, rule.UID())
+		}
+	}
+
+	for uid := range cases {
+		t.Errorf("expected rule %s in the catalog", uid)
+	}
+}
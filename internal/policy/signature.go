@@ -0,0 +1,93 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/bundle"
+)
+
+// ErrUnsignedBundle is returned by Load when bundles must be
+// verified (see WithTrustedKey) and a policy path isn't signed.
+var ErrUnsignedBundle = errors.New("bundle isn't signed")
+
+// defaultSigningAlgorithm is the algorithm of trusted
+// keys that don't set one, like in OPA.
+const defaultSigningAlgorithm = "RS256"
+
+// WithTrustedKey makes Load verify that every policy path is a
+// bundle (a directory or a tarball, e.g. pulled from an OCI registry)
+// signed with one of the trusted keys, rejecting unsigned and tampered
+// bundles. Bundles are signed like OPA bundles, e.g. with `opa sign`.
+// The key is a PEM encoded public key, or the secret of HMAC algorithms,
+// and alg defaults to RS256. Can be used multiple times.
+func WithTrustedKey(id, alg, key string) Option {
+	return func(e *Engine) {
+		if alg == "" {
+			alg = defaultSigningAlgorithm
+		}
+
+		if e.trustedKeys == nil {
+			e.trustedKeys = map[string]*bundle.KeyConfig{}
+		}
+
+		e.trustedKeys[id] = &bundle.KeyConfig{Key: key, Algorithm: alg}
+	}
+}
+
+// loadSignedBundles reads the bundles in paths, verifying their
+// signature with the trusted keys, and returns their modules.
+func (e *Engine) loadSignedBundles(paths []string) (map[string]*ast.Module, error) {
+	modules := map[string]*ast.Module{}
+
+	for _, path := range paths {
+		b, err := e.readSignedBundle(path)
+		if err != nil {
+			return nil, fmt.Errorf("verify bundle %s: %w", path, err)
+		}
+
+		for _, mf := range b.Modules {
+			modules[filepath.ToSlash(filepath.Join(path, mf.Path))] = mf.Parsed
+		}
+	}
+
+	return modules, nil
+}
+
+func (e *Engine) readSignedBundle(path string) (bundle.Bundle, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return bundle.Bundle{}, err
+	}
+
+	var loader bundle.DirectoryLoader
+
+	if info.IsDir() {
+		loader = bundle.NewDirectoryLoader(path)
+	} else {
+		f, err := os.Open(path)
+		if err != nil {
+			return bundle.Bundle{}, err
+		}
+		defer f.Close()
+
+		loader = bundle.NewTarballLoaderWithBaseURL(f, path)
+	}
+
+	b, err := bundle.NewCustomReader(loader).
+		WithBundleVerificationConfig(bundle.NewVerificationConfig(e.trustedKeys, "", "", nil)).
+		WithProcessAnnotations(true).
+		Read()
+	if err != nil {
+		return bundle.Bundle{}, err
+	}
+
+	if len(b.Signatures.Signatures) == 0 {
+		return bundle.Bundle{}, ErrUnsignedBundle
+	}
+
+	return b, nil
+}
@@ -0,0 +1,104 @@
+package policy_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/open-policy-agent/opa/bundle"
+	"github.com/reposaur/reposaur/internal/policy"
+)
+
+const testSigningSecret = "reposaur-secret"
+
+// writeSignedBundle writes a bundle tarball with the policy, signed
+// with testSigningSecret like `opa build --signing-key` does. If
+// tampered isn't empty, it replaces the policy after signing.
+func writeSignedBundle(t *testing.T, p, tampered string) string {
+	t.Helper()
+
+	b := bundle.Bundle{
+		Manifest: bundle.Manifest{Revision: "1"},
+		Data:     map[string]interface{}{},
+		Modules: []bundle.ModuleFile{
+			{URL: "/policy.rego", Path: "/policy.rego", Raw: []byte(p)},
+		},
+	}
+
+	if err := b.GenerateSignature(bundle.NewSigningConfig(testSigningSecret, "HS256", ""), "reposaur", false); err != nil {
+		t.Fatal(err)
+	}
+
+	if tampered != "" {
+		b.Modules[0].Raw = []byte(tampered)
+	}
+
+	path := filepath.Join(t.TempDir(), "bundle.tar.gz")
+
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if err := bundle.NewWriter(f).Write(b); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestLoadSignedBundle(t *testing.T) {
+	path := writeSignedBundle(t, testPolicy, "")
+
+	engine, err := policy.Load(context.Background(), []string{path}, policy.WithTrustedKey("reposaur", "HS256", testSigningSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := engine.Check(context.Background(), "repository", map[string]interface{}{"visibility": "public"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(report.Results) != 2 {
+		t.Errorf("expected the bundle's rules to be checked, got %d results", len(report.Results))
+	}
+}
+
+func TestLoadTamperedBundle(t *testing.T) {
+	path := writeSignedBundle(t, testPolicy, testPolicy+"\nallow_everything { true }\n")
+
+	_, err := policy.Load(context.Background(), []string{path}, policy.WithTrustedKey("reposaur", "HS256", testSigningSecret))
+	if err == nil {
+		t.Fatal("expected a tampered bundle to be rejected")
+	}
+}
+
+func TestLoadBundleUntrustedKey(t *testing.T) {
+	path := writeSignedBundle(t, testPolicy, "")
+
+	_, err := policy.Load(context.Background(), []string{path}, policy.WithTrustedKey("reposaur", "HS256", "another-secret"))
+	if err == nil {
+		t.Fatal("expected a bundle signed with an untrusted key to be rejected")
+	}
+}
+
+func TestLoadUnsignedBundle(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(dir, "policy.rego"), []byte(testPolicy), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := policy.Load(context.Background(), []string{dir}, policy.WithTrustedKey("reposaur", "HS256", testSigningSecret))
+	if !errors.Is(err, policy.ErrUnsignedBundle) {
+		t.Fatalf("expected ErrUnsignedBundle, got %v", err)
+	}
+
+	if _, err := policy.Load(context.Background(), []string{dir}); err != nil {
+		t.Errorf("expected unsigned policies to load without trusted keys, got %v", err)
+	}
+}
@@ -0,0 +1,47 @@
+package policy
+
+import "time"
+
+// timestampKeys are the input keys checked, in order of
+// preference, to find when a subject was last changed.
+var timestampKeys = []string{"pushed_at", "updated_at"}
+
+// isStale returns true if the engine has a since cutoff and the
+// input's last change timestamp is before it. Inputs without a
+// timestamp are never stale.
+func (e Engine) isStale(input interface{}) bool {
+	if e.since.IsZero() {
+		return false
+	}
+
+	ts, ok := inputTimestamp(input)
+	if !ok {
+		return false
+	}
+
+	return ts.Before(e.since)
+}
+
+// inputTimestamp reads the last change timestamp from the input.
+func inputTimestamp(input interface{}) (time.Time, bool) {
+	m, ok := input.(map[string]interface{})
+	if !ok {
+		return time.Time{}, false
+	}
+
+	for _, k := range timestampKeys {
+		v, ok := m[k].(string)
+		if !ok {
+			continue
+		}
+
+		ts, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			continue
+		}
+
+		return ts, true
+	}
+
+	return time.Time{}, false
+}
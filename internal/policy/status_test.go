@@ -0,0 +1,106 @@
+package policy_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/reposaur/reposaur/internal/policy"
+)
+
+const statusPolicy = `
+package repository
+
+violation_stable {
+	true
+}
+
+# METADATA
+# custom:
+#   experimental: true
+violation_experimental {
+	true
+}
+
+# METADATA
+# custom:
+#   deprecated: true
+warn_deprecated {
+	true
+}
+`
+
+func TestCheckRuleStatus(t *testing.T) {
+	cases := []struct {
+		name     string
+		opts     []policy.Option
+		expected []string
+	}{
+		{
+			name: "all rules",
+			expected: []string{
+				"repository/violation/stable",
+				"repository/violation/experimental",
+				"repository/warn/deprecated",
+			},
+		},
+		{
+			name: "without experimental",
+			opts: []policy.Option{policy.WithoutExperimental()},
+			expected: []string{
+				"repository/violation/stable",
+				"repository/warn/deprecated",
+			},
+		},
+		{
+			name: "without deprecated",
+			opts: []policy.Option{policy.WithoutDeprecated()},
+			expected: []string{
+				"repository/violation/stable",
+				"repository/violation/experimental",
+			},
+		},
+		{
+			name:     "without both",
+			opts:     []policy.Option{policy.WithoutExperimental(), policy.WithoutDeprecated()},
+			expected: []string{"repository/violation/stable"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			engine := loadTestEngine(t, []string{statusPolicy}, c.opts...)
+
+			report, err := engine.Check(context.Background(), "repository", map[string]interface{}{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(report.Results) != len(c.expected) {
+				t.Fatalf("expected %d results, got %d", len(c.expected), len(report.Results))
+			}
+
+			for _, uid := range c.expected {
+				if _, ok := report.Results[uid]; !ok {
+					t.Errorf("expected result for %s", uid)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckDeprecatedNotice(t *testing.T) {
+	engine := loadTestEngine(t, []string{statusPolicy})
+
+	report, err := engine.Check(context.Background(), "repository", map[string]interface{}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if notice := report.Results["repository/warn/deprecated"].Notice; notice != policy.DeprecatedNotice {
+		t.Errorf("expected deprecated notice, got %q", notice)
+	}
+
+	if notice := report.Results["repository/violation/stable"].Notice; notice != "" {
+		t.Errorf("expected no notice, got %q", notice)
+	}
+}
@@ -0,0 +1,61 @@
+package policy_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/reposaur/reposaur/internal/policy"
+)
+
+const libraryPolicy = `
+package lib.github
+
+is_public(repo) {
+	repo.visibility == "public"
+}
+`
+
+const typoPolicy = `
+package organization
+
+deny_members_can_fork {
+	input.members_can_fork_private_repositories
+}
+`
+
+func TestLoadStrict(t *testing.T) {
+	dir := t.TempDir()
+
+	for name, p := range map[string]string{
+		"repository.rego":   testPolicy,
+		"lib.rego":          libraryPolicy,
+		"organization.rego": typoPolicy,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(p), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx := context.Background()
+
+	if _, err := policy.Load(ctx, []string{dir}); err != nil {
+		t.Fatalf("expected policies to load without strict mode, got %s", err)
+	}
+
+	_, err := policy.Load(ctx, []string{dir}, policy.WithStrict())
+	if !errors.Is(err, policy.ErrEmptyNamespace) {
+		t.Fatalf("expected ErrEmptyNamespace, got %v", err)
+	}
+
+	if msg := err.Error(); !strings.Contains(msg, "lib.github, organization") {
+		t.Errorf("expected error to list the empty namespaces, got %s", msg)
+	}
+}
+
+func TestLoadStrictValid(t *testing.T) {
+	loadTestEngine(t, []string{testPolicy, otherNamespacePolicy}, policy.WithStrict())
+}
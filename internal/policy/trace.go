@@ -0,0 +1,81 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/topdown"
+	"github.com/reposaur/reposaur/pkg/output"
+)
+
+// ErrRuleNotFound is returned when a rule that none
+// of the loaded policies provide is requested.
+var ErrRuleNotFound = errors.New("rule not found")
+
+// Trace evaluates the rules with ruleID in namespace against input,
+// like Check does, with tracing enabled and returns the pretty
+// printed trace. The rule's ID is its name without the kind prefix,
+// e.g. `not_internal` for `violation_not_internal`. If rules of
+// different kinds share the ID, every one of them is traced.
+func (e *Engine) Trace(ctx context.Context, namespace, ruleID string, input interface{}) (string, error) {
+	if !e.hasNamespace(namespace) {
+		return "", fmt.Errorf("trace: %w: %s", ErrNamespaceNotFound, namespace)
+	}
+
+	ctx, cancel := e.checkContext(ctx)
+	defer cancel()
+
+	var rules []*output.Rule
+
+	for _, mod := range e.Modules() {
+		if moduleNamespace(mod) != namespace {
+			continue
+		}
+
+		for _, rule := range e.moduleRules(namespace, mod) {
+			if rule.ID == ruleID {
+				rules = append(rules, rule)
+			}
+		}
+	}
+
+	if len(rules) == 0 {
+		return "", fmt.Errorf("trace: %w: %s.%s", ErrRuleNotFound, namespace, ruleID)
+	}
+
+	output.SortRules(rules)
+
+	with, err := e.queryFixtures(ctx, namespace, input)
+	if err != nil {
+		return "", fmt.Errorf("trace: query fixtures: %s: %w", namespace, err)
+	}
+
+	buf := &strings.Builder{}
+
+	for _, rule := range rules {
+		ruleInput, err := selectInput(input, rule.Input)
+		if err != nil {
+			return "", fmt.Errorf("trace: select input: %s: %w", rule.UID(), err)
+		}
+
+		query := fmt.Sprintf("data.%s.%s_%s", rule.Namespace, rule.Kind, rule.ID)
+		tracer := topdown.NewBufferTracer()
+
+		regoInstance, err := e.buildRegoInstance(query, ruleInput, with, rego.QueryTracer(tracer))
+		if err != nil {
+			return "", fmt.Errorf("trace: %s: %w", rule.UID(), err)
+		}
+
+		if _, err := regoInstance.Eval(ctx); err != nil {
+			return "", fmt.Errorf("trace: %s: query eval: %w", rule.UID(), err)
+		}
+
+		fmt.Fprintf(buf, "# %s\n", rule.UID())
+		topdown.PrettyTraceWithLocation(buf, *tracer)
+	}
+
+	return buf.String(), nil
+}
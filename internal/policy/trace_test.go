@@ -0,0 +1,40 @@
+package policy_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/reposaur/reposaur/internal/policy"
+)
+
+func TestTrace(t *testing.T) {
+	engine := loadTestEngine(t, []string{testPolicy})
+
+	trace, err := engine.Trace(context.Background(), "repository", "not_internal", map[string]interface{}{
+		"visibility": "public",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if trace == "" {
+		t.Fatal("expected trace output")
+	}
+
+	for _, s := range []string{"repository/violation/not_internal", "Enter data.repository.violation_not_internal", "Eval neq("} {
+		if !strings.Contains(trace, s) {
+			t.Errorf("expected trace to contain %q, got:\n%s", s, trace)
+		}
+	}
+}
+
+func TestTraceRuleNotFound(t *testing.T) {
+	engine := loadTestEngine(t, []string{testPolicy})
+
+	_, err := engine.Trace(context.Background(), "repository", "not_public", map[string]interface{}{})
+	if !errors.Is(err, policy.ErrRuleNotFound) {
+		t.Errorf("expected ErrRuleNotFound, got %v", err)
+	}
+}
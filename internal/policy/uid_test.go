@@ -0,0 +1,104 @@
+package policy_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/reposaur/reposaur/internal/policy"
+	"github.com/reposaur/reposaur/pkg/output"
+)
+
+const internalVisibilityPolicy = `
+package repository
+
+violation_visibility {
+	input.visibility != "internal"
+}
+`
+
+const publicVisibilityPolicy = `
+package repository
+
+violation_visibility {
+	input.visibility != "public"
+}
+`
+
+// withoutSetUID computes the UID of a rule without its policy set,
+// so the same rule in several sets is deduplicated.
+func withoutSetUID(r output.Rule) string {
+	return r.Namespace + "/" + r.Kind + "/" + r.ID
+}
+
+func TestWithUIDFuncCollision(t *testing.T) {
+	engine, err := policy.Load(context.Background(), []string{writeTestPolicy(t, internalVisibilityPolicy)},
+		policy.WithPolicySet("vendor", writeTestPolicy(t, publicVisibilityPolicy)),
+		policy.WithUIDFunc(withoutSetUID),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := engine.Check(context.Background(), "repository", map[string]interface{}{"visibility": "public"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(report.Rules) != 1 || len(report.Results) != 1 {
+		t.Fatalf("expected the rules to collide, got %d rules and %d results", len(report.Rules), len(report.Results))
+	}
+
+	// the engine's own rule takes precedence over the set's
+	result := report.Results["repository/violation/visibility"]
+	if result == nil || result.Rule.Set != "" || !result.Failed() {
+		t.Errorf("expected the engine's own rule to be checked, got %+v", result)
+	}
+}
+
+func TestWithUIDFuncWithinNamespace(t *testing.T) {
+	// a UID without the kind makes the warning collide with
+	// the violation, which comes first ordered by kind
+	engine := loadTestEngine(t, []string{`
+package repository
+
+warn_visibility {
+	input.visibility != "internal"
+}
+
+violation_visibility {
+	input.visibility != "internal"
+}
+`}, policy.WithUIDFunc(func(r output.Rule) string {
+		return r.Namespace + "/" + r.ID
+	}))
+
+	report, err := engine.Check(context.Background(), "repository", map[string]interface{}{"visibility": "public"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := report.Results["repository/visibility"]
+	if len(report.Results) != 1 || result == nil || result.Rule.Kind != "violation" {
+		t.Errorf("expected only the violation to be checked, got %v", report.Results)
+	}
+}
+
+func TestDefaultUIDPolicySets(t *testing.T) {
+	engine, err := policy.Load(context.Background(), []string{writeTestPolicy(t, internalVisibilityPolicy)},
+		policy.WithPolicySet("vendor", writeTestPolicy(t, publicVisibilityPolicy)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := engine.Check(context.Background(), "repository", map[string]interface{}{"visibility": "public"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, uid := range []string{"repository/violation/visibility", "vendor:repository/violation/visibility"} {
+		if _, ok := report.Results[uid]; !ok {
+			t.Errorf("expected a result for %s, got %v", uid, report.Results)
+		}
+	}
+}
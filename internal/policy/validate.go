@@ -0,0 +1,80 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/reposaur/reposaur/pkg/cache"
+)
+
+// ValidationError is an error evaluating a rule, or the
+// fixtures of a namespace, against one of the samples
+// given to Validate.
+type ValidationError struct {
+	// Sample is the index of the sample input.
+	Sample int
+
+	// Namespace is the namespace being evaluated.
+	Namespace string
+
+	// Rule is the UID of the rule that failed to evaluate,
+	// empty if the namespace's fixtures failed instead.
+	Rule string
+
+	Err error
+}
+
+func (v ValidationError) Error() string {
+	if v.Rule == "" {
+		return fmt.Sprintf("sample %d: %s: fixtures: %s", v.Sample, v.Namespace, v.Err)
+	}
+
+	return fmt.Sprintf("sample %d: %s: %s", v.Sample, v.Rule, v.Err)
+}
+
+func (v ValidationError) Unwrap() error {
+	return v.Err
+}
+
+// Validate evaluates every rule of every namespace against each of
+// the samples and returns the evaluation errors, e.g. a built-in
+// function halting with invalid arguments. Findings aren't reported,
+// a rule failing is valid. Every rule is evaluated, regardless of
+// WithSince and the experimental and deprecated exclusions.
+//
+// Errors are ordered by sample and namespace.
+func (e *Engine) Validate(ctx context.Context, samples []interface{}) []ValidationError {
+	var errs []ValidationError
+
+	if e.cache != nil {
+		ctx = cache.NewContext(ctx, e.cache)
+	}
+
+	catalog := e.Catalog()
+
+	namespaces := make([]string, 0, len(catalog))
+	for namespace := range catalog {
+		namespaces = append(namespaces, namespace)
+	}
+
+	sort.Strings(namespaces)
+
+	for i, sample := range samples {
+		for _, namespace := range namespaces {
+			with, err := e.queryFixtures(ctx, namespace, sample)
+			if err != nil {
+				errs = append(errs, ValidationError{Sample: i, Namespace: namespace, Err: err})
+				continue
+			}
+
+			for _, rule := range catalog[namespace] {
+				if _, err := e.evalRule(ctx, rule, sample, with); err != nil {
+					errs = append(errs, ValidationError{Sample: i, Namespace: namespace, Rule: rule.UID(), Err: err})
+				}
+			}
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,52 @@
+package policy_test
+
+import (
+	"context"
+	"testing"
+)
+
+const brokenPolicy = `
+package repository
+
+violation_not_internal {
+	input.visibility != "internal"
+}
+
+violation_too_many_stars {
+	to_number(input.stargazers_count) > 1000
+}
+`
+
+func TestValidate(t *testing.T) {
+	engine := loadTestEngine(t, []string{brokenPolicy, otherNamespacePolicy})
+
+	samples := []interface{}{
+		map[string]interface{}{"visibility": "public", "stargazers_count": 10},
+		map[string]interface{}{"visibility": "internal", "stargazers_count": "many"},
+	}
+
+	errs := engine.Validate(context.Background(), samples)
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %d: %v", len(errs), errs)
+	}
+
+	if errs[0].Sample != 1 {
+		t.Errorf("expected error in sample 1, got %d", errs[0].Sample)
+	}
+
+	if errs[0].Rule != "repository/violation/too_many_stars" {
+		t.Errorf("expected error in too_many_stars, got %s", errs[0].Rule)
+	}
+}
+
+func TestValidateClean(t *testing.T) {
+	engine := loadTestEngine(t, []string{testPolicy})
+
+	errs := engine.Validate(context.Background(), []interface{}{
+		map[string]interface{}{},
+		map[string]interface{}{"visibility": "internal", "description": "A repository"},
+	})
+	if len(errs) != 0 {
+		t.Errorf("expected no errors, got %v", errs)
+	}
+}
@@ -0,0 +1,80 @@
+package policy_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/reposaur/reposaur/internal/policy"
+)
+
+const valuePolicy = `
+package repository
+
+violation_stale_branches = {"msg": "Repository has stale branches", "branches": branches} {
+	branches := [b.name | b := input.branches[_]; b.stale]
+	count(branches) > 0
+}
+
+violation_unprotected_branch[{"msg": msg, "branch": branch.name}] {
+	branch := input.branches[_]
+	not branch.protected
+	msg := sprintf("Branch %s isn't protected", [branch.name])
+}
+`
+
+var valueInput = map[string]interface{}{
+	"branches": []interface{}{
+		map[string]interface{}{"name": "main", "protected": true},
+		map[string]interface{}{"name": "old", "stale": true},
+	},
+}
+
+func TestCheckRawValues(t *testing.T) {
+	engine := loadTestEngine(t, []string{valuePolicy}, policy.WithRawValues())
+
+	report, err := engine.Check(context.Background(), "repository", valueInput)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := json.Marshal(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded struct {
+		Results map[string]struct {
+			Value map[string]interface{} `json:"value"`
+		} `json:"results"`
+	}
+
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	stale := decoded.Results["repository/violation/stale_branches"].Value
+	if branches, ok := stale["branches"].([]interface{}); !ok || len(branches) != 1 || branches[0] != "old" {
+		t.Errorf("expected the stale branches in the value, got %v", stale)
+	}
+
+	unprotected := decoded.Results["repository/violation/unprotected_branch"].Value
+	if unprotected["branch"] != "old" {
+		t.Errorf("expected the finding's element as the value, got %v", unprotected)
+	}
+}
+
+func TestCheckWithoutRawValues(t *testing.T) {
+	engine := loadTestEngine(t, []string{valuePolicy})
+
+	report, err := engine.Check(context.Background(), "repository", valueInput)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for key, result := range report.Results {
+		if result.Value != nil {
+			t.Errorf("expected %s not to have a value, got %v", key, result.Value)
+		}
+	}
+}
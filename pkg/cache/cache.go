@@ -0,0 +1,125 @@
+// Package cache provides a cache shared between evaluations,
+// consulted by built-in functions before doing requests.
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// Cache stores values by key. Implementations
+// must be safe for concurrent use.
+type Cache interface {
+	// Get returns the value stored with key and
+	// true, or false if there's none.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+
+	// Set stores value with key.
+	Set(ctx context.Context, key string, value []byte) error
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying c.
+func NewContext(ctx context.Context, c Cache) context.Context {
+	return context.WithValue(ctx, contextKey{}, c)
+}
+
+// FromContext returns the cache carried by ctx, if any.
+func FromContext(ctx context.Context) (Cache, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+
+	c, ok := ctx.Value(contextKey{}).(Cache)
+
+	return c, ok
+}
+
+type memoryEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// Memory is an in-memory Cache whose entries expire after a
+// fixed duration. It can be bounded to a maximum number of
+// entries, evicting the least recently used ones.
+type Memory struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	entries    map[string]*list.Element
+	order      *list.List
+}
+
+// NewMemory creates an in-memory cache whose entries
+// expire after ttl. Entries never expire if ttl is zero.
+func NewMemory(ttl time.Duration) *Memory {
+	return NewLRU(ttl, 0)
+}
+
+// NewLRU creates an in-memory cache whose entries expire after
+// ttl and that holds at most maxEntries, evicting the least
+// recently used ones. It's unbounded if maxEntries is zero.
+func NewLRU(ttl time.Duration, maxEntries int) *Memory {
+	return &Memory{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    map[string]*list.Element{},
+		order:      list.New(),
+	}
+}
+
+func (m *Memory) Get(_ context.Context, key string) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	el, ok := m.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	e := el.Value.(*memoryEntry)
+
+	if !e.expires.IsZero() && time.Now().After(e.expires) {
+		m.remove(el)
+		return nil, false, nil
+	}
+
+	m.order.MoveToFront(el)
+
+	return e.value, true, nil
+}
+
+func (m *Memory) Set(_ context.Context, key string, value []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e := &memoryEntry{key: key, value: value}
+	if m.ttl > 0 {
+		e.expires = time.Now().Add(m.ttl)
+	}
+
+	if el, ok := m.entries[key]; ok {
+		el.Value = e
+		m.order.MoveToFront(el)
+
+		return nil
+	}
+
+	m.entries[key] = m.order.PushFront(e)
+
+	if m.maxEntries > 0 && m.order.Len() > m.maxEntries {
+		m.remove(m.order.Back())
+	}
+
+	return nil
+}
+
+func (m *Memory) remove(el *list.Element) {
+	m.order.Remove(el)
+	delete(m.entries, el.Value.(*memoryEntry).key)
+}
@@ -0,0 +1,49 @@
+package cache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/reposaur/reposaur/pkg/cache"
+)
+
+func TestMemoryExpires(t *testing.T) {
+	ctx := context.Background()
+	c := cache.NewMemory(10 * time.Millisecond)
+
+	if err := c.Set(ctx, "key", []byte("value")); err != nil {
+		t.Fatal(err)
+	}
+
+	if v, ok, _ := c.Get(ctx, "key"); !ok || string(v) != "value" {
+		t.Fatalf("expected value to be cached, got %q", v)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	if _, ok, _ := c.Get(ctx, "key"); ok {
+		t.Error("expected value to have expired")
+	}
+}
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	c := cache.NewLRU(0, 2)
+
+	_ = c.Set(ctx, "a", []byte("a"))
+	_ = c.Set(ctx, "b", []byte("b"))
+
+	// a is used, so b is the least recently used
+	if _, ok, _ := c.Get(ctx, "a"); !ok {
+		t.Fatal("expected a to be cached")
+	}
+
+	_ = c.Set(ctx, "c", []byte("c"))
+
+	for key, expected := range map[string]bool{"a": true, "b": false, "c": true} {
+		if _, ok, _ := c.Get(ctx, key); ok != expected {
+			t.Errorf("expected %s to be cached: %t, got %t", key, expected, ok)
+		}
+	}
+}
@@ -0,0 +1,294 @@
+// Package cassette records HTTP interactions and replays them, so
+// policies doing requests (e.g. with `github.request`) can be tested
+// deterministically against responses recorded once from the API.
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+// ErrInteractionNotFound is returned by a Replayer when
+// a request doesn't match any recorded interaction.
+var ErrInteractionNotFound = errors.New("interaction not found")
+
+// Cassette is a list of recorded HTTP interactions.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a request and the response it got.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is a recorded request. Headers aren't
+// recorded, so credentials aren't leaked.
+type Request struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Body   string `json:"body,omitempty"`
+}
+
+// Response is a recorded response. Only the headers policies
+// may depend on are recorded (see recordedHeaders), so cookies
+// or credentials echoed by the server aren't leaked.
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body"`
+}
+
+// recordedHeaders are the response headers recorded, in
+// canonical form. Headers with a prefix ending with a dash
+// match every header with that prefix.
+var recordedHeaders = []string{
+	"Content-Type",
+	"Link",
+	"Retry-After",
+	"X-Ratelimit-",
+}
+
+// Load reads a cassette from the file in path.
+func Load(path string) (*Cassette, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("load cassette: %w", err)
+	}
+
+	var c Cassette
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("load cassette: %w", err)
+	}
+
+	return &c, nil
+}
+
+// Save writes the cassette to the file in path.
+func (c *Cassette) Save(path string) error {
+	b, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("save cassette: %w", err)
+	}
+
+	if err := os.WriteFile(path, b, 0o600); err != nil {
+		return fmt.Errorf("save cassette: %w", err)
+	}
+
+	return nil
+}
+
+// Recorder is an http.RoundTripper that does requests
+// with another RoundTripper and records the interactions.
+type Recorder struct {
+	next     http.RoundTripper
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+// NewRecorder creates a Recorder that does requests with next,
+// or http.DefaultTransport if nil.
+func NewRecorder(next http.RoundTripper) *Recorder {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return &Recorder{next: next}
+}
+
+// RoundTrip does req with the next RoundTripper and records the
+// interaction. The request isn't modified: its body is read from
+// GetBody if set, or else a clone of it is done.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, req, err := requestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := readBody(&resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Request: Request{
+			Method: req.Method,
+			URL:    req.URL.String(),
+			Body:   reqBody,
+		},
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Header:     filterHeader(resp.Header),
+			Body:       respBody,
+		},
+	})
+
+	return resp, nil
+}
+
+// Cassette returns a copy of the interactions recorded so far.
+func (r *Recorder) Cassette() *Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return &Cassette{
+		Interactions: append([]Interaction(nil), r.cassette.Interactions...),
+	}
+}
+
+// Replayer is an http.RoundTripper that responds to requests
+// with the responses recorded in a cassette, without doing
+// any request.
+type Replayer struct {
+	mu           sync.Mutex
+	interactions []Interaction
+	played       map[int]bool
+}
+
+// NewReplayer creates a Replayer of the interactions in c.
+func NewReplayer(c *Cassette) *Replayer {
+	return &Replayer{
+		interactions: c.Interactions,
+		played:       map[int]bool{},
+	}
+}
+
+// RoundTrip responds with the first interaction not replayed yet
+// whose method, URL and body match the request's. Once every
+// matching interaction was replayed, the last one is repeated.
+// Returns ErrInteractionNotFound if none match.
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, req, err := requestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	match := -1
+
+	for i, in := range r.interactions {
+		if in.Request.Method != req.Method || in.Request.URL != req.URL.String() || in.Request.Body != body {
+			continue
+		}
+
+		match = i
+
+		if !r.played[i] {
+			break
+		}
+	}
+
+	if match < 0 {
+		return nil, fmt.Errorf("%w: %s %s", ErrInteractionNotFound, req.Method, req.URL)
+	}
+
+	r.played[match] = true
+	in := r.interactions[match].Response
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", in.StatusCode, http.StatusText(in.StatusCode)),
+		StatusCode:    in.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        in.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewBufferString(in.Body)),
+		ContentLength: int64(len(in.Body)),
+		Request:       req,
+	}, nil
+}
+
+// requestBody returns the body of req and the request to do with
+// it, without modifying req. The body is read from GetBody if set,
+// so req can be done as is, or else read from a clone of req whose
+// body is replaced so it can be read again.
+func requestBody(req *http.Request) (string, *http.Request, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return "", req, nil
+	}
+
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return "", nil, err
+		}
+
+		b, err := readBody(&body)
+		if err != nil {
+			return "", nil, err
+		}
+
+		return b, req, nil
+	}
+
+	clone := req.Clone(req.Context())
+
+	b, err := readBody(&clone.Body)
+	if err != nil {
+		return "", nil, err
+	}
+
+	clone.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader(b)), nil
+	}
+
+	return b, clone, nil
+}
+
+// filterHeader returns the recorded headers of h (see
+// recordedHeaders), or nil if it doesn't have any.
+func filterHeader(h http.Header) http.Header {
+	var filtered http.Header
+
+	for name, values := range h {
+		for _, recorded := range recordedHeaders {
+			if name == recorded || (strings.HasSuffix(recorded, "-") && strings.HasPrefix(name, recorded)) {
+				if filtered == nil {
+					filtered = http.Header{}
+				}
+
+				filtered[name] = append([]string(nil), values...)
+
+				break
+			}
+		}
+	}
+
+	return filtered
+}
+
+// readBody reads the body and replaces it
+// so it can be read again.
+func readBody(body *io.ReadCloser) (string, error) {
+	if *body == nil || *body == http.NoBody {
+		return "", nil
+	}
+
+	b, err := io.ReadAll(*body)
+	if err != nil {
+		return "", err
+	}
+
+	_ = (*body).Close()
+	*body = io.NopCloser(bytes.NewReader(b))
+
+	return string(b), nil
+}
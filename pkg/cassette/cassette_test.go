@@ -0,0 +1,191 @@
+package cassette_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/reposaur/reposaur/pkg/cassette"
+)
+
+func get(t *testing.T, client *http.Client, url string) (int, string) {
+	t.Helper()
+
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return resp.StatusCode, string(b)
+}
+
+func TestRecordThenReplay(t *testing.T) {
+	var calls int
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"path": "` + r.URL.Path + `", "call": ` + string(rune('0'+calls)) + `}`))
+	}))
+
+	recorder := cassette.NewRecorder(nil)
+	client := &http.Client{Transport: recorder}
+
+	_, first := get(t, client, srv.URL+"/repos/reposaur/test")
+	_, second := get(t, client, srv.URL+"/repos/reposaur/test")
+	status, _ := get(t, client, srv.URL+"/missing")
+
+	if status != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", status)
+	}
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	if err := recorder.Cassette().Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	// replaying doesn't do any request
+	srv.Close()
+
+	c, err := cassette.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client = &http.Client{Transport: cassette.NewReplayer(c)}
+
+	if _, body := get(t, client, srv.URL+"/repos/reposaur/test"); body != first {
+		t.Errorf("expected first response %s, got %s", first, body)
+	}
+
+	if _, body := get(t, client, srv.URL+"/repos/reposaur/test"); body != second {
+		t.Errorf("expected second response %s, got %s", second, body)
+	}
+
+	// exhausted interactions repeat the last one
+	if _, body := get(t, client, srv.URL+"/repos/reposaur/test"); body != second {
+		t.Errorf("expected second response to be repeated, got %s", body)
+	}
+
+	if status, _ := get(t, client, srv.URL+"/missing"); status != http.StatusNotFound {
+		t.Errorf("expected replayed 404, got %d", status)
+	}
+
+	resp, err := client.Get(srv.URL + "/unknown")
+	if !errors.Is(err, cassette.ErrInteractionNotFound) {
+		t.Errorf("expected ErrInteractionNotFound, got %v", err)
+	}
+
+	if resp != nil {
+		resp.Body.Close()
+	}
+}
+
+func TestReplayMatchesBody(t *testing.T) {
+	c := &cassette.Cassette{
+		Interactions: []cassette.Interaction{
+			{
+				Request:  cassette.Request{Method: http.MethodPost, URL: "https://api.github.com/graphql", Body: `{"query":"a"}`},
+				Response: cassette.Response{StatusCode: http.StatusOK, Body: "a"},
+			},
+			{
+				Request:  cassette.Request{Method: http.MethodPost, URL: "https://api.github.com/graphql", Body: `{"query":"b"}`},
+				Response: cassette.Response{StatusCode: http.StatusOK, Body: "b"},
+			},
+		},
+	}
+
+	client := &http.Client{Transport: cassette.NewReplayer(c)}
+
+	resp, err := client.Post("https://api.github.com/graphql", "application/json", strings.NewReader(`{"query":"b"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if b, _ := io.ReadAll(resp.Body); string(b) != "b" {
+		t.Errorf("expected response b, got %s", b)
+	}
+}
+
+func TestRecordDoesntModifyRequest(t *testing.T) {
+	var received []string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		received = append(received, string(b))
+	}))
+	defer srv.Close()
+
+	recorder := cassette.NewRecorder(nil)
+
+	for _, body := range []io.Reader{
+		strings.NewReader(`{"query":"a"}`),               // with GetBody
+		io.NopCloser(strings.NewReader(`{"query":"b"}`)), // without
+	} {
+		req, err := http.NewRequest(http.MethodPost, srv.URL+"/graphql", body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		reqBody := req.Body
+
+		resp, err := recorder.RoundTrip(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		if req.Body != reqBody {
+			t.Error("expected the request's body not to be replaced")
+		}
+	}
+
+	expected := []string{`{"query":"a"}`, `{"query":"b"}`}
+
+	for i, in := range recorder.Cassette().Interactions {
+		if in.Request.Body != expected[i] || received[i] != expected[i] {
+			t.Errorf("expected body %s to be sent and recorded, got %s and %s", expected[i], received[i], in.Request.Body)
+		}
+	}
+}
+
+func TestRecordFiltersResponseHeaders(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-RateLimit-Remaining", "4999")
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.Header().Set("X-Token-Echo", "secret")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	recorder := cassette.NewRecorder(nil)
+	get(t, &http.Client{Transport: recorder}, srv.URL)
+
+	header := recorder.Cassette().Interactions[0].Response.Header
+
+	if header.Get("Content-Type") != "application/json" || header.Get("X-RateLimit-Remaining") != "4999" {
+		t.Errorf("expected the content type and rate limit headers to be recorded, got %v", header)
+	}
+
+	if header.Get("Set-Cookie") != "" || header.Get("X-Token-Echo") != "" {
+		t.Errorf("expected other headers not to be recorded, got %v", header)
+	}
+}
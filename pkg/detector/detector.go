@@ -56,6 +56,13 @@ var namespaceToReportPropertiesMap = map[string]string{
 			"name": "{{.name}}"
 		}
 	`,
+	"repository_event": `
+		{
+			"owner": "{{.repository.owner.login}}",
+			"repo": "{{.repository.name}}",
+			"action": "{{.action}}"
+		}
+	`,
 }
 
 // DetectNamespace will attempt to detect some data's
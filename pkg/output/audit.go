@@ -0,0 +1,131 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// AuditTrail is a timestamped record of every decision of a run: the
+// rules evaluated, a hash of their input, the API requests done and
+// their outcome, e.g. to archive as compliance evidence. It's safe
+// for concurrent use.
+type AuditTrail struct {
+	mu        sync.Mutex
+	decisions []AuditDecision
+}
+
+// AuditDecision is the record of a single decision, i.e. the
+// evaluation of a namespace's rules against an input.
+type AuditDecision struct {
+	DecisionID string    `json:"decision_id"`
+	Namespace  string    `json:"namespace"`
+	Set        string    `json:"set,omitempty"`
+	Timestamp  time.Time `json:"timestamp"`
+	DurationMS int64     `json:"duration_ms"`
+
+	// InputHash is the hex-encoded SHA-256
+	// hash of the JSON-encoded input.
+	InputHash string `json:"input_hash"`
+
+	// Cached is true if the outcome was taken from the
+	// evaluation cache instead of evaluating the rules.
+	Cached bool `json:"cached,omitempty"`
+
+	Rules    []AuditRule    `json:"rules"`
+	Requests []AuditRequest `json:"requests"`
+}
+
+// AuditRule is the outcome of a rule in a decision, its
+// status (see Result.Status) and message. Rules with a set
+// value have an outcome for each of their findings.
+type AuditRule struct {
+	UID     string `json:"uid"`
+	Finding int    `json:"finding,omitempty"`
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// AuditRequest is an HTTP request done by the built-in functions
+// during a decision. StatusCode is zero if the request failed.
+type AuditRequest struct {
+	Method     string    `json:"method"`
+	URL        string    `json:"url"`
+	StatusCode int       `json:"status_code"`
+	Error      string    `json:"error,omitempty"`
+	Timestamp  time.Time `json:"timestamp"`
+	DurationMS int64     `json:"duration_ms"`
+}
+
+// NewAuditTrail returns an empty audit trail.
+func NewAuditTrail() *AuditTrail {
+	return &AuditTrail{}
+}
+
+// NewAuditDecision returns the record of the decision
+// that produced report, with the outcome of its results.
+func NewAuditDecision(report Report, namespace, inputHash string, requests []AuditRequest) AuditDecision {
+	d := AuditDecision{
+		DecisionID: report.DecisionID,
+		Namespace:  namespace,
+		Timestamp:  report.Timestamp,
+		InputHash:  inputHash,
+		Rules:      make([]AuditRule, 0, len(report.Results)),
+		Requests:   requests,
+	}
+
+	if d.Requests == nil {
+		d.Requests = []AuditRequest{}
+	}
+
+	for _, result := range report.SortedResults() {
+		d.Rules = append(d.Rules, AuditRule{
+			UID:     result.Rule.UID(),
+			Finding: result.Finding,
+			Status:  result.Status(),
+			Message: result.Message,
+		})
+	}
+
+	return d
+}
+
+// Add adds the record of a decision to the trail.
+func (t *AuditTrail) Add(d AuditDecision) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.decisions = append(t.decisions, d)
+}
+
+// Decisions returns the decisions of the
+// trail, ordered by when they started.
+func (t *AuditTrail) Decisions() []AuditDecision {
+	t.mu.Lock()
+	decisions := append([]AuditDecision{}, t.decisions...)
+	t.mu.Unlock()
+
+	sort.SliceStable(decisions, func(i, j int) bool {
+		return decisions[i].Timestamp.Before(decisions[j].Timestamp)
+	})
+
+	return decisions
+}
+
+// MarshalJSON encodes the trail as an object
+// with its decisions (see Decisions).
+func (t *AuditTrail) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Decisions []AuditDecision `json:"decisions"`
+	}{t.Decisions()})
+}
+
+// Write writes the trail to w as indented JSON.
+func (t *AuditTrail) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(t)
+}
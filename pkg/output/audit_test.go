@@ -0,0 +1,94 @@
+package output_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/reposaur/reposaur/pkg/output"
+)
+
+func TestNewAuditDecision(t *testing.T) {
+	report := newTestReport(map[string]bool{"not_internal": true, "has_description": false})
+	report.DecisionID = "decision"
+	report.Timestamp = time.Date(2022, time.June, 1, 0, 0, 0, 0, time.UTC)
+	report.Results["repository/violation/not_internal"].Message = "Repository isn't internal"
+
+	requests := []output.AuditRequest{{Method: "GET", URL: "https://api.github.com/repos/reposaur/reposaur", StatusCode: 200}}
+
+	d := output.NewAuditDecision(report, "repository", "hash", requests)
+
+	if d.DecisionID != "decision" || d.Namespace != "repository" || d.InputHash != "hash" || !d.Timestamp.Equal(report.Timestamp) {
+		t.Errorf("unexpected decision %+v", d)
+	}
+
+	expected := []output.AuditRule{
+		{UID: "repository/violation/has_description", Status: output.StatusPassed},
+		{UID: "repository/violation/not_internal", Status: output.StatusFailed, Message: "Repository isn't internal"},
+	}
+
+	if len(d.Rules) != len(expected) {
+		t.Fatalf("expected %d rules, got %+v", len(expected), d.Rules)
+	}
+
+	for i := range expected {
+		if d.Rules[i] != expected[i] {
+			t.Errorf("expected %+v, got %+v", expected[i], d.Rules[i])
+		}
+	}
+
+	if len(d.Requests) != 1 || d.Requests[0] != requests[0] {
+		t.Errorf("expected the requests, got %+v", d.Requests)
+	}
+
+	if d := output.NewAuditDecision(report, "repository", "hash", nil); d.Requests == nil {
+		t.Error("expected an empty list of requests")
+	}
+}
+
+func TestAuditTrailWrite(t *testing.T) {
+	trail := output.NewAuditTrail()
+	start := time.Date(2022, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+	wg := sync.WaitGroup{}
+
+	for i := 2; i >= 0; i-- {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			trail.Add(output.AuditDecision{
+				DecisionID: string(rune('a' + i)),
+				Timestamp:  start.Add(time.Duration(i) * time.Second),
+			})
+		}(i)
+	}
+
+	wg.Wait()
+
+	buf := &bytes.Buffer{}
+	if err := trail.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded struct {
+		Decisions []output.AuditDecision `json:"decisions"`
+	}
+
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(decoded.Decisions) != 3 {
+		t.Fatalf("expected 3 decisions, got %d", len(decoded.Decisions))
+	}
+
+	for i, d := range decoded.Decisions {
+		if d.DecisionID != string(rune('a'+i)) {
+			t.Errorf("expected the decisions ordered by timestamp, got %s at %d", d.DecisionID, i)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package output
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+const baselineVersion = 1
+
+// Baseline is a set of known failing results, identified by their
+// fingerprint. Applying a baseline to a report suppresses the known
+// results, so that only new findings surface.
+type Baseline struct {
+	Version      int      `json:"version"`
+	Fingerprints []string `json:"fingerprints"`
+}
+
+// NewBaseline creates a baseline with every failing result
+// in reports, including those already suppressed.
+func NewBaseline(reports []Report) Baseline {
+	b := Baseline{
+		Version:      baselineVersion,
+		Fingerprints: []string{},
+	}
+
+	seen := map[string]bool{}
+
+	for _, report := range reports {
+		for _, result := range report.SortedResults() {
+			if result.Passed || result.Skipped || result.TimedOut {
+				continue
+			}
+
+			if fp := Fingerprint(report, result); !seen[fp] {
+				seen[fp] = true
+				b.Fingerprints = append(b.Fingerprints, fp)
+			}
+		}
+	}
+
+	sort.Strings(b.Fingerprints)
+
+	return b
+}
+
+// ReadBaseline decodes a baseline written by Baseline.Write.
+func ReadBaseline(r io.Reader) (Baseline, error) {
+	var b Baseline
+
+	if err := json.NewDecoder(r).Decode(&b); err != nil {
+		return Baseline{}, fmt.Errorf("read baseline: %w", err)
+	}
+
+	if b.Version != baselineVersion {
+		return Baseline{}, fmt.Errorf("read baseline: unsupported version %d", b.Version)
+	}
+
+	return b, nil
+}
+
+// Write encodes the baseline to w.
+func (b Baseline) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(b)
+}
+
+// Apply marks the failing results of report that are in
+// the baseline as suppressed.
+func (b Baseline) Apply(report Report) {
+	known := make(map[string]bool, len(b.Fingerprints))
+	for _, fp := range b.Fingerprints {
+		known[fp] = true
+	}
+
+	for _, result := range report.Results {
+		if result.Failed() && known[Fingerprint(report, result)] {
+			result.Suppressed = true
+		}
+	}
+}
+
+// Fingerprint identifies a result by the subject of its report,
+// i.e. the report's properties (or the result's, see MergeReports),
+// its rule's UID and, for findings with a message or location, a
+// hash of those, so each finding of a rule is identified regardless
+// of the order they're found in.
+func Fingerprint(report Report, result *Result) string {
+	fp := propertiesKey(resultProperties(report, result)) + ":" + result.Rule.UID()
+
+	if result.Message == "" && result.Location == nil {
+		return fp
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00", result.Message)
+
+	if result.Location != nil {
+		fmt.Fprintf(h, "%s\x00%d", result.Location.Path, result.Location.Line)
+	}
+
+	return fp + "#" + hex.EncodeToString(h.Sum(nil))[:12]
+}
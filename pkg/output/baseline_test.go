@@ -0,0 +1,125 @@
+package output_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/reposaur/reposaur/pkg/output"
+)
+
+func newSubjectReport(repo string, failing map[string]bool) output.Report {
+	report := newTestReport(failing)
+	report.Properties = output.ReportProperties{"owner": "reposaur", "repo": repo}
+
+	return report
+}
+
+func TestNewBaseline(t *testing.T) {
+	reports := []output.Report{
+		newSubjectReport("a", map[string]bool{"x": true, "y": false}),
+		newSubjectReport("b", map[string]bool{"x": true, "y": true}),
+	}
+
+	baseline := output.NewBaseline(reports)
+
+	expected := []string{
+		"owner=reposaur,repo=a:repository/violation/x",
+		"owner=reposaur,repo=b:repository/violation/x",
+		"owner=reposaur,repo=b:repository/violation/y",
+	}
+
+	if !reflect.DeepEqual(expected, baseline.Fingerprints) {
+		t.Errorf("expected fingerprints %v, got %v", expected, baseline.Fingerprints)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := baseline.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	read, err := output.ReadBaseline(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(baseline, read) {
+		t.Errorf("expected baseline to be read back as %v, got %v", baseline, read)
+	}
+}
+
+func TestBaselineApply(t *testing.T) {
+	baseline := output.NewBaseline([]output.Report{
+		newSubjectReport("a", map[string]bool{"x": true, "y": false}),
+	})
+
+	// y is a new finding in a, and x is a new finding in b
+	// despite being known in a.
+	reports := []output.Report{
+		newSubjectReport("a", map[string]bool{"x": true, "y": true}),
+		newSubjectReport("b", map[string]bool{"x": true}),
+	}
+
+	for _, r := range reports {
+		baseline.Apply(r)
+	}
+
+	expected := []map[string]bool{
+		{"repository/violation/x": true, "repository/violation/y": false},
+		{"repository/violation/x": false},
+	}
+
+	for i, r := range reports {
+		for uid, suppressed := range expected[i] {
+			result := r.Results[uid]
+
+			if result.Suppressed != suppressed {
+				t.Errorf("expected report %d %s suppressed to be %v", i, uid, suppressed)
+			}
+
+			if result.Failed() == suppressed {
+				t.Errorf("expected report %d %s failed to be %v", i, uid, !suppressed)
+			}
+		}
+	}
+
+	if n := len(output.NewBaseline(reports).Fingerprints); n != 3 {
+		t.Errorf("expected new baseline to include suppressed results, got %d fingerprints", n)
+	}
+}
+
+func TestReadBaselineUnsupportedVersion(t *testing.T) {
+	if _, err := output.ReadBaseline(bytes.NewBufferString(`{"version": 2, "fingerprints": []}`)); err == nil {
+		t.Error("expected error reading an unsupported version")
+	}
+}
+
+func TestBaselineFindings(t *testing.T) {
+	newReport := func(messages ...string) output.Report {
+		report := newSubjectReport("a", map[string]bool{})
+		rule := &output.Rule{ID: "x", Kind: "violation", Severity: output.ErrorSeverity, Namespace: "repository"}
+
+		report.AddRule(rule)
+
+		for i, msg := range messages {
+			report.AddResult(&output.Result{Rule: rule, Message: msg, Finding: i})
+		}
+
+		return report
+	}
+
+	baseline := output.NewBaseline([]output.Report{newReport("uses actions/checkout@v2")})
+
+	// a new finding of the known rule is found before the
+	// known finding, which becomes the rule's second finding
+	report := newReport("uses actions/cache@v2", "uses actions/checkout@v2")
+	baseline.Apply(report)
+
+	if report.Results["repository/violation/x"].Suppressed {
+		t.Error("expected the new finding not to be suppressed")
+	}
+
+	if !report.Results["repository/violation/x#1"].Suppressed {
+		t.Error("expected the known finding to be suppressed")
+	}
+}
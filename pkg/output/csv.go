@@ -0,0 +1,75 @@
+package output
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+)
+
+// Result statuses, as written in CSV reports.
+const (
+	StatusPassed     = "passed"
+	StatusFailed     = "failed"
+	StatusSkipped    = "skipped"
+	StatusSuppressed = "suppressed"
+	StatusTimedOut   = "timed_out"
+)
+
+var csvHeader = []string{"subject", "namespace", "rule", "kind", "severity", "status", "message"}
+
+// WriteCSV writes the results of the reports to w as CSV, with a
+// header followed by one row per result. Reports are written in
+// order and their results are sorted like in SortedResults.
+func WriteCSV(w io.Writer, reports ...Report) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, report := range reports {
+		for _, result := range report.SortedResults() {
+			message := result.Rule.Title
+			if result.Message != "" {
+				message = result.Message
+			}
+
+			err := cw.Write([]string{
+				reportSubject(resultProperties(report, result)),
+				result.Rule.Namespace,
+				result.Rule.ID,
+				result.Rule.Kind,
+				result.Rule.Severity,
+				result.Status(),
+				message,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
+
+// reportSubject returns a name for the subject of a report
+// from its properties, e.g. owner/repo for repositories.
+func reportSubject(props ReportProperties) string {
+	owner, hasOwner := props["owner"]
+	repo, hasRepo := props["repo"]
+
+	switch {
+	case hasOwner && hasRepo:
+		return fmt.Sprintf("%v/%v", owner, repo)
+	case props["login"] != nil:
+		return fmt.Sprint(props["login"])
+	case props["number"] != nil:
+		return fmt.Sprintf("#%v", props["number"])
+	case props["id"] != nil:
+		return fmt.Sprint(props["id"])
+	}
+
+	return ""
+}
@@ -0,0 +1,118 @@
+package output_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"testing"
+
+	"github.com/reposaur/reposaur/pkg/output"
+)
+
+func TestWriteCSV(t *testing.T) {
+	report := newTestReport(map[string]bool{"a": true, "b": false, "c": true})
+	report.Properties = output.ReportProperties{"owner": "reposaur", "repo": "test"}
+	report.Results["repository/violation/a"].Message = "Uses actions/checkout, unpinned\nin ci.yml"
+	report.Results["repository/violation/c"].Suppressed = true
+
+	buf := &bytes.Buffer{}
+	if err := output.WriteCSV(buf, report); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "subject,namespace,rule,kind,severity,status,message\n" +
+		"reposaur/test,repository,a,violation,error,failed,\"Uses actions/checkout, unpinned\nin ci.yml\"\n" +
+		"reposaur/test,repository,b,violation,error,passed,b\n" +
+		"reposaur/test,repository,c,violation,error,suppressed,c\n"
+
+	if buf.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+
+	records, err := csv.NewReader(buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := records[1][6]; got != "Uses actions/checkout, unpinned\nin ci.yml" {
+		t.Errorf("expected message to round-trip, got %q", got)
+	}
+}
+
+func TestWriteCSVMultipleReports(t *testing.T) {
+	first := newTestReport(map[string]bool{"a": true})
+	first.Properties = output.ReportProperties{"login": "reposaur"}
+
+	second := newTestReport(map[string]bool{"a": false})
+	second.Properties = output.ReportProperties{"id": 1, "number": 42}
+
+	buf := &bytes.Buffer{}
+	if err := output.WriteCSV(buf, first, second); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := csv.NewReader(buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var subjects []string
+	for _, r := range records[1:] {
+		subjects = append(subjects, r[0])
+	}
+
+	if expected := []string{"reposaur", "#42"}; !reflect.DeepEqual(subjects, expected) {
+		t.Errorf("expected subjects %v, got %v", expected, subjects)
+	}
+}
+
+func TestWriteCSVMergedReport(t *testing.T) {
+	first := newTestReport(map[string]bool{"a": true})
+	first.Properties = output.ReportProperties{"owner": "reposaur", "repo": "first"}
+
+	second := newTestReport(map[string]bool{"a": false})
+	second.Properties = output.ReportProperties{"owner": "reposaur", "repo": "second"}
+
+	buf := &bytes.Buffer{}
+	if err := output.WriteCSV(buf, output.MergeReports([]output.Report{first, second})); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := csv.NewReader(buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var subjects []string
+	for _, r := range records[1:] {
+		subjects = append(subjects, r[0])
+	}
+
+	if expected := []string{"reposaur/first", "reposaur/second"}; !reflect.DeepEqual(subjects, expected) {
+		t.Errorf("expected subjects %v, got %v", expected, subjects)
+	}
+}
+
+func TestWriteCSVSeverityOrder(t *testing.T) {
+	report := newMixedSeverityReport()
+	report.Order = output.SeverityOrder
+
+	buf := &bytes.Buffer{}
+	if err := output.WriteCSV(buf, report); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := csv.NewReader(buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []string
+	for _, row := range rows[1:] {
+		ids = append(ids, row[2])
+	}
+
+	if expected := []string{"d", "c", "e", "g", "b", "a", "f"}; !reflect.DeepEqual(ids, expected) {
+		t.Errorf("expected rows %v, got %v", expected, ids)
+	}
+}
@@ -0,0 +1,71 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// DecisionLog is a report in OPA's decision log format, so
+// Reposaur's decisions can be ingested alongside other OPA
+// deployments' decisions.
+type DecisionLog struct {
+	Labels     map[string]string           `json:"labels"`
+	DecisionID string                      `json:"decision_id"`
+	Path       string                      `json:"path"`
+	Input      interface{}                 `json:"input"`
+	Result     map[string]DecisionLogEntry `json:"result"`
+	Timestamp  time.Time                   `json:"timestamp"`
+}
+
+// DecisionLogEntry is the outcome of a single rule, or
+// of one of its findings.
+type DecisionLogEntry struct {
+	Passed  bool   `json:"passed"`
+	Skipped bool   `json:"skipped"`
+	Query   string `json:"query,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// NewDecisionLog converts report to a decision log. The decision's
+// path is the namespace of the report's rules and the result holds
+// every rule's outcome, keyed like the report's results, i.e. by the
+// rule's UID followed by the finding's index after the first one.
+func NewDecisionLog(report Report) DecisionLog {
+	dl := DecisionLog{
+		Labels:     map[string]string{"app": "reposaur"},
+		DecisionID: report.DecisionID,
+		Input:      report.Input,
+		Result:     map[string]DecisionLogEntry{},
+		Timestamp:  report.Timestamp,
+	}
+
+	for _, result := range report.SortedResults() {
+		if dl.Path == "" {
+			dl.Path = result.Rule.Namespace
+		}
+
+		dl.Result[result.Key()] = DecisionLogEntry{
+			Passed:  result.Passed,
+			Skipped: result.Skipped,
+			Query:   result.Query,
+			Message: result.Message,
+		}
+	}
+
+	return dl
+}
+
+// WriteDecisionLogs writes a decision log for each of the
+// reports to w, one JSON object per line like OPA does.
+func WriteDecisionLogs(w io.Writer, reports []Report) error {
+	enc := json.NewEncoder(w)
+
+	for _, r := range reports {
+		if err := enc.Encode(NewDecisionLog(r)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
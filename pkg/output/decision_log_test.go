@@ -0,0 +1,97 @@
+package output_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/reposaur/reposaur/pkg/output"
+)
+
+func TestWriteDecisionLogs(t *testing.T) {
+	report := newTestReport(map[string]bool{
+		"a": false,
+		"b": true,
+	})
+	report.DecisionID = "3f3b2c4e-1d0a-4b8e-9f6a-2c1d0e9b8a7f"
+	report.Timestamp = time.Date(2022, time.June, 1, 12, 0, 0, 0, time.UTC)
+	report.Input = map[string]interface{}{"name": "test"}
+
+	other := newTestReport(map[string]bool{"a": true})
+	other.DecisionID = "8e1f0c2a-6b4d-4c3e-a1f2-0b9c8d7e6f5a"
+
+	buf := &bytes.Buffer{}
+
+	if err := output.WriteDecisionLogs(buf, []output.Report{report, other}); err != nil {
+		t.Fatal(err)
+	}
+
+	var lines []map[string]interface{}
+
+	scanner := bufio.NewScanner(buf)
+	for scanner.Scan() {
+		var line map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("expected each line to be a JSON object: %s", err)
+		}
+
+		lines = append(lines, line)
+	}
+
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 decisions, got %d", len(lines))
+	}
+
+	decision := lines[0]
+
+	for _, key := range []string{"labels", "decision_id", "path", "input", "result", "timestamp"} {
+		if _, ok := decision[key]; !ok {
+			t.Errorf("expected decision to have %s", key)
+		}
+	}
+
+	if decision["decision_id"] != report.DecisionID {
+		t.Errorf("expected decision_id to be %s, got %v", report.DecisionID, decision["decision_id"])
+	}
+
+	if decision["timestamp"] != "2022-06-01T12:00:00Z" {
+		t.Errorf("expected timestamp to be RFC3339, got %v", decision["timestamp"])
+	}
+
+	if decision["path"] != "repository" {
+		t.Errorf("expected path to be repository, got %v", decision["path"])
+	}
+
+	if name := decision["input"].(map[string]interface{})["name"]; name != "test" {
+		t.Errorf("expected input to be included, got %v", decision["input"])
+	}
+
+	result := decision["result"].(map[string]interface{})
+
+	if passed := result["repository/violation/b"].(map[string]interface{})["passed"]; passed != false {
+		t.Errorf("expected repository/violation/b to have failed, got %v", passed)
+	}
+
+	if lines[1]["decision_id"] != other.DecisionID {
+		t.Errorf("expected second decision_id to be %s, got %v", other.DecisionID, lines[1]["decision_id"])
+	}
+}
+
+func TestDecisionLogFindings(t *testing.T) {
+	report := newTestReport(map[string]bool{"a": true})
+	rule := report.Rules["repository/violation/a"]
+
+	report.AddResult(&output.Result{Rule: rule, Message: "second", Finding: 1})
+
+	dl := output.NewDecisionLog(report)
+
+	if len(dl.Result) != 2 {
+		t.Fatalf("expected an entry for each finding, got %v", dl.Result)
+	}
+
+	if msg := dl.Result["repository/violation/a#1"].Message; msg != "second" {
+		t.Errorf("expected second finding's message, got %q", msg)
+	}
+}
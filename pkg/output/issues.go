@@ -0,0 +1,359 @@
+package output
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const (
+	issueMarkerPrefix = "<!-- reposaur:"
+	issueMarkerSuffix = " -->"
+	aggregateIssueID  = "aggregate"
+)
+
+var issueMarkerRegex = regexp.MustCompile(`<!-- reposaur:(\S+) -->`)
+
+// IssueOptions controls how PublishIssues creates issues.
+type IssueOptions struct {
+	// Aggregate creates a single issue listing every failing
+	// rule instead of one issue per failing rule.
+	Aggregate bool
+
+	// Labels are added to every issue created.
+	Labels []string
+}
+
+type issue struct {
+	Number int      `json:"number,omitempty"`
+	Title  string   `json:"title,omitempty"`
+	Body   string   `json:"body,omitempty"`
+	State  string   `json:"state,omitempty"`
+	Labels []string `json:"labels,omitempty"`
+
+	// PullRequest is set when the issue is a pull request,
+	// which are also returned by the issues API.
+	PullRequest interface{} `json:"pull_request,omitempty"`
+}
+
+// PublishIssues creates or updates GitHub issues in owner/repo for
+// every failing result in the report. Issues are identified by a
+// marker in their body, so subsequent runs update existing issues
+// instead of opening duplicates. Issues are closed once every result
+// of their rule passes, so issues of rules that aren't in the report
+// (e.g. of other namespaces) or that were skipped aren't closed.
+func PublishIssues(ctx context.Context, client *http.Client, owner, repo string, report Report, opts IssueOptions) error {
+	existing, err := listReposaurIssues(ctx, client, owner, repo)
+	if err != nil {
+		return fmt.Errorf("publish issues: %w", err)
+	}
+
+	var (
+		wanted    = map[string]issue{}
+		wantedIDs []string
+	)
+
+	// rules with a set value fail with a result per
+	// finding, which are grouped in their rule's issue
+	var (
+		failing  = map[string][]*Result{}
+		ruleUIDs []string
+	)
+
+	for _, result := range report.SortedResults() {
+		if !result.Failed() {
+			continue
+		}
+
+		uid := result.Rule.UID()
+		if _, ok := failing[uid]; !ok {
+			ruleUIDs = append(ruleUIDs, uid)
+		}
+
+		failing[uid] = append(failing[uid], result)
+	}
+
+	if opts.Aggregate {
+		if len(ruleUIDs) > 0 {
+			wanted[aggregateIssueID] = newAggregateIssue(ruleUIDs, failing, opts)
+			wantedIDs = append(wantedIDs, aggregateIssueID)
+		}
+	} else {
+		for _, uid := range ruleUIDs {
+			wanted[uid] = newRuleIssue(failing[uid], opts)
+			wantedIDs = append(wantedIDs, uid)
+		}
+	}
+
+	for _, id := range wantedIDs {
+		is := wanted[id]
+
+		if curr, ok := existing[id]; ok {
+			is.State = "open"
+			is.Labels = nil
+
+			if err := updateIssue(ctx, client, owner, repo, curr.Number, is); err != nil {
+				return fmt.Errorf("publish issues: update #%d: %w", curr.Number, err)
+			}
+
+			continue
+		}
+
+		if err := createIssue(ctx, client, owner, repo, is); err != nil {
+			return fmt.Errorf("publish issues: create '%s': %w", is.Title, err)
+		}
+	}
+
+	for id, curr := range existing {
+		if _, ok := wanted[id]; ok || curr.State != "open" || !issueResolved(report, id) {
+			continue
+		}
+
+		if err := updateIssue(ctx, client, owner, repo, curr.Number, issue{State: "closed"}); err != nil {
+			return fmt.Errorf("publish issues: close #%d: %w", curr.Number, err)
+		}
+	}
+
+	return nil
+}
+
+// issueResolved returns true if the issue with id can be closed,
+// i.e. its rule (or every rule, for the aggregate issue) is in the
+// report and all of its results passed.
+func issueResolved(report Report, id string) bool {
+	if id != aggregateIssueID {
+		if _, ok := report.Rules[id]; !ok {
+			return false
+		}
+	}
+
+	found := false
+
+	for _, result := range report.Results {
+		if id != aggregateIssueID && result.Rule.UID() != id {
+			continue
+		}
+
+		if result.Status() != StatusPassed {
+			return false
+		}
+
+		found = true
+	}
+
+	return found
+}
+
+// newRuleIssue returns the issue of the failing results
+// of a rule, listing the findings with a message or location.
+func newRuleIssue(results []*Result, opts IssueOptions) issue {
+	rule := results[0].Rule
+
+	body := &strings.Builder{}
+	body.WriteString(rule.Description)
+	body.WriteString("\n\n")
+
+	var findings []string
+	for _, result := range results {
+		if f := issueFinding(result); f != "" {
+			findings = append(findings, f)
+		}
+	}
+
+	if len(findings) > 0 {
+		fmt.Fprintf(body, "## Findings\n\n%s\n\n", strings.Join(findings, "\n"))
+	}
+
+	if rule.Remediation != "" {
+		fmt.Fprintf(body, "## Remediation\n\n%s\n\n", rule.Remediation)
+	}
+
+	if rule.URL != "" {
+		fmt.Fprintf(body, "More information: %s\n\n", rule.URL)
+	}
+
+	body.WriteString(issueMarker(rule.UID()))
+
+	return issue{
+		Title:  fmt.Sprintf("Reposaur: %s", rule.Title),
+		Body:   body.String(),
+		Labels: opts.Labels,
+	}
+}
+
+func newAggregateIssue(ruleUIDs []string, failing map[string][]*Result, opts IssueOptions) issue {
+	body := &strings.Builder{}
+	body.WriteString("The following rules are failing:\n\n")
+
+	for _, uid := range ruleUIDs {
+		fmt.Fprintf(body, "- **%s** (`%s`)\n", failing[uid][0].Rule.Title, uid)
+	}
+
+	body.WriteString("\n")
+	body.WriteString(issueMarker(aggregateIssueID))
+
+	return issue{
+		Title:  "Reposaur: failing policies",
+		Body:   body.String(),
+		Labels: opts.Labels,
+	}
+}
+
+// issueFinding returns the list item of a failing result with its
+// message and location, or empty if it doesn't have either.
+func issueFinding(result *Result) string {
+	var parts []string
+
+	if result.Message != "" {
+		parts = append(parts, result.Message)
+	}
+
+	if loc := result.Location; loc != nil && loc.Path != "" {
+		if loc.Line > 0 {
+			parts = append(parts, fmt.Sprintf("`%s:%d`", loc.Path, loc.Line))
+		} else {
+			parts = append(parts, fmt.Sprintf("`%s`", loc.Path))
+		}
+	}
+
+	if len(parts) == 0 {
+		return ""
+	}
+
+	return "- " + strings.Join(parts, " in ")
+}
+
+func issueMarker(id string) string {
+	return issueMarkerPrefix + id + issueMarkerSuffix
+}
+
+// listReposaurIssues returns every issue in the repository that
+// contains a Reposaur marker, keyed by the marker's ID.
+func listReposaurIssues(ctx context.Context, client *http.Client, owner, repo string) (map[string]issue, error) {
+	issues := map[string]issue{}
+	next := fmt.Sprintf("/repos/%s/%s/issues?state=all&per_page=100", url.PathEscape(owner), url.PathEscape(repo))
+
+	for next != "" {
+		req, err := newGitHubRequest(ctx, http.MethodGet, next, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		var page []issue
+
+		err = decodeGitHubResponse(resp, &page)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, is := range page {
+			if is.PullRequest != nil {
+				continue
+			}
+
+			if m := issueMarkerRegex.FindStringSubmatch(is.Body); m != nil {
+				issues[m[1]] = is
+			}
+		}
+
+		next = nextPageURL(resp.Header.Get("Link"))
+	}
+
+	return issues, nil
+}
+
+func createIssue(ctx context.Context, client *http.Client, owner, repo string, is issue) error {
+	path := fmt.Sprintf("/repos/%s/%s/issues", url.PathEscape(owner), url.PathEscape(repo))
+
+	req, err := newGitHubRequest(ctx, http.MethodPost, path, is)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	return decodeGitHubResponse(resp, nil)
+}
+
+func updateIssue(ctx context.Context, client *http.Client, owner, repo string, number int, is issue) error {
+	path := fmt.Sprintf("/repos/%s/%s/issues/%d", url.PathEscape(owner), url.PathEscape(repo), number)
+
+	req, err := newGitHubRequest(ctx, http.MethodPatch, path, is)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	return decodeGitHubResponse(resp, nil)
+}
+
+func newGitHubRequest(ctx context.Context, method, path string, body interface{}) (*http.Request, error) {
+	buf := &bytes.Buffer{}
+
+	if body != nil {
+		enc := json.NewEncoder(buf)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(body); err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, path, buf)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("User-Agent", "reposaur")
+	req.Header.Set("Content-Type", "application/json")
+
+	return req, nil
+}
+
+func decodeGitHubResponse(resp *http.Response, v interface{}) error {
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		var body struct {
+			Message string `json:"message"`
+		}
+
+		_ = json.NewDecoder(resp.Body).Decode(&body)
+
+		return fmt.Errorf("%s %s: %d %s", resp.Request.Method, resp.Request.URL.Path, resp.StatusCode, body.Message)
+	}
+
+	if v == nil {
+		return nil
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+var linkNextRegex = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+// nextPageURL extracts the URL of the next page
+// from a Link header, if any.
+func nextPageURL(link string) string {
+	if m := linkNextRegex.FindStringSubmatch(link); m != nil {
+		return m[1]
+	}
+
+	return ""
+}
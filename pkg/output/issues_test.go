@@ -0,0 +1,293 @@
+package output_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/reposaur/reposaur/pkg/output"
+)
+
+type stubIssue struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+	Body   string `json:"body"`
+	State  string `json:"state"`
+}
+
+// stubIssuesAPI is an in-memory implementation of the
+// subset of the GitHub issues API used by PublishIssues.
+type stubIssuesAPI struct {
+	mu      sync.Mutex
+	issues  []*stubIssue
+	patches int
+}
+
+func (s *stubIssuesAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	const base = "/repos/reposaur/test/issues"
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == base:
+		// serve one issue per page to exercise pagination
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page == 0 {
+			page = 1
+		}
+
+		var items []*stubIssue
+		if page <= len(s.issues) {
+			items = s.issues[page-1 : page]
+		}
+
+		if page < len(s.issues) {
+			w.Header().Set("Link", fmt.Sprintf(`<%s?page=%d>; rel="next"`, base, page+1))
+		}
+
+		_ = json.NewEncoder(w).Encode(items)
+
+	case r.Method == http.MethodPost && r.URL.Path == base:
+		is := &stubIssue{State: "open"}
+		_ = json.NewDecoder(r.Body).Decode(is)
+		is.Number = len(s.issues) + 1
+		s.issues = append(s.issues, is)
+
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(is)
+
+	case r.Method == http.MethodPatch && strings.HasPrefix(r.URL.Path, base+"/"):
+		n, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, base+"/"))
+		is := s.issues[n-1]
+		s.patches++
+
+		var patch stubIssue
+		_ = json.NewDecoder(r.Body).Decode(&patch)
+
+		if patch.Title != "" {
+			is.Title = patch.Title
+		}
+
+		if patch.Body != "" {
+			is.Body = patch.Body
+		}
+
+		if patch.State != "" {
+			is.State = patch.State
+		}
+
+		_ = json.NewEncoder(w).Encode(is)
+
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+type rewriteTransport struct {
+	target *url.URL
+}
+
+func (t rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.URL.Scheme = t.target.Scheme
+	req.URL.Host = t.target.Host
+
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func newStubClient(t *testing.T, handler http.Handler) *http.Client {
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	u, _ := url.Parse(srv.URL)
+
+	return &http.Client{Transport: rewriteTransport{target: u}}
+}
+
+func newTestReport(failing map[string]bool) output.Report {
+	report := output.Report{
+		Rules:   map[string]*output.Rule{},
+		Results: map[string]*output.Result{},
+	}
+
+	for id, fails := range failing {
+		rule := &output.Rule{
+			ID:          id,
+			Title:       id,
+			Kind:        "violation",
+			Severity:    output.ErrorSeverity,
+			Description: "Description of " + id,
+			Namespace:   "repository",
+		}
+
+		report.AddRule(rule)
+		report.AddResult(&output.Result{Rule: rule, Passed: !fails})
+	}
+
+	return report
+}
+
+func TestPublishIssuesPerRule(t *testing.T) {
+	api := &stubIssuesAPI{}
+	client := newStubClient(t, api)
+	ctx := context.Background()
+
+	report := newTestReport(map[string]bool{"a": true, "b": true, "c": false})
+
+	if err := output.PublishIssues(ctx, client, "reposaur", "test", report, output.IssueOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(api.issues) != 2 {
+		t.Fatalf("expected 2 issues to be created, got %d", len(api.issues))
+	}
+
+	// rerun with the same report must not duplicate issues
+	if err := output.PublishIssues(ctx, client, "reposaur", "test", report, output.IssueOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(api.issues) != 2 {
+		t.Fatalf("expected issues to be updated, got %d issues", len(api.issues))
+	}
+
+	// rule 'a' stops firing, its issue must be closed
+	report = newTestReport(map[string]bool{"a": false, "b": true, "c": false})
+
+	if err := output.PublishIssues(ctx, client, "reposaur", "test", report, output.IssueOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, is := range api.issues {
+		expected := "open"
+		if strings.Contains(is.Body, "repository/violation/a") {
+			expected = "closed"
+		}
+
+		if is.State != expected {
+			t.Errorf("expected issue '%s' to be %s, got %s", is.Title, expected, is.State)
+		}
+	}
+}
+
+func TestPublishIssuesFindings(t *testing.T) {
+	api := &stubIssuesAPI{}
+	client := newStubClient(t, api)
+	ctx := context.Background()
+
+	report := output.Report{
+		Rules:   map[string]*output.Rule{},
+		Results: map[string]*output.Result{},
+	}
+
+	rule := &output.Rule{ID: "unpinned_action", Title: "Unpinned action", Kind: "violation", Namespace: "repository"}
+	report.AddRule(rule)
+
+	for i, path := range []string{"ci.yml", "release.yml", "lint.yml"} {
+		report.AddResult(&output.Result{
+			Rule:     rule,
+			Finding:  i,
+			Message:  "Action isn't pinned",
+			Location: &output.Location{Path: ".github/workflows/" + path, Line: 10},
+		})
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := output.PublishIssues(ctx, client, "reposaur", "test", report, output.IssueOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(api.issues) != 1 {
+		t.Fatalf("expected 1 issue for the rule, got %d", len(api.issues))
+	}
+
+	if api.patches != 1 {
+		t.Errorf("expected the issue to be updated once, got %d updates", api.patches)
+	}
+
+	for _, path := range []string{"ci.yml", "release.yml", "lint.yml"} {
+		if finding := "Action isn't pinned in `.github/workflows/" + path + ":10`"; !strings.Contains(api.issues[0].Body, finding) {
+			t.Errorf("expected the issue to list %q, got %q", finding, api.issues[0].Body)
+		}
+	}
+}
+
+func TestPublishIssuesKeepsUnresolved(t *testing.T) {
+	api := &stubIssuesAPI{}
+	client := newStubClient(t, api)
+	ctx := context.Background()
+
+	if err := output.PublishIssues(ctx, client, "reposaur", "test", newTestReport(map[string]bool{"a": true}), output.IssueOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// reports without rule 'a', e.g. of another namespace
+	other := newTestReport(map[string]bool{"b": false})
+
+	skipped := newTestReport(map[string]bool{"a": false})
+	for _, result := range skipped.Results {
+		result.Skipped = true
+	}
+
+	timedOut := newTestReport(map[string]bool{"a": false})
+	for _, result := range timedOut.Results {
+		result.TimedOut = true
+	}
+
+	for name, report := range map[string]output.Report{"other namespace": other, "skipped": skipped, "timed out": timedOut} {
+		if err := output.PublishIssues(ctx, client, "reposaur", "test", report, output.IssueOptions{}); err != nil {
+			t.Fatal(err)
+		}
+
+		if api.issues[0].State != "open" {
+			t.Fatalf("%s: expected the issue to stay open", name)
+		}
+	}
+
+	if err := output.PublishIssues(ctx, client, "reposaur", "test", newTestReport(map[string]bool{"a": false}), output.IssueOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if api.issues[0].State != "closed" {
+		t.Errorf("expected the issue to be closed once its rule passes, got %s", api.issues[0].State)
+	}
+}
+
+func TestPublishIssuesAggregate(t *testing.T) {
+	api := &stubIssuesAPI{}
+	client := newStubClient(t, api)
+	ctx := context.Background()
+	opts := output.IssueOptions{Aggregate: true}
+
+	report := newTestReport(map[string]bool{"a": true, "b": true})
+
+	if err := output.PublishIssues(ctx, client, "reposaur", "test", report, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := output.PublishIssues(ctx, client, "reposaur", "test", report, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(api.issues) != 1 {
+		t.Fatalf("expected 1 aggregate issue, got %d", len(api.issues))
+	}
+
+	report = newTestReport(map[string]bool{"a": false, "b": false})
+
+	if err := output.PublishIssues(ctx, client, "reposaur", "test", report, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	if api.issues[0].State != "closed" {
+		t.Errorf("expected aggregate issue to be closed, got %s", api.issues[0].State)
+	}
+}
@@ -0,0 +1,152 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// MaxMarkdownSize is the maximum size of the Markdown written
+// by WriteMarkdown, GitHub's limit for the body of comments.
+const MaxMarkdownSize = 65536
+
+// markdownNoteSize is reserved at the end of Markdown
+// reports for the truncation note and closing tags.
+const markdownNoteSize = 256
+
+// WriteMarkdown writes the report to w as Markdown suited for pull
+// request comments: a header with the number of results of each
+// status, followed by a collapsible section for each namespace
+// with failing results, listing them with their messages and
+// remediation. Reports that would exceed MaxMarkdownSize are
+// truncated, noting how many results were omitted.
+func WriteMarkdown(w io.Writer, r Report) error {
+	b := &strings.Builder{}
+
+	writeMarkdownHeader(b, r)
+
+	groups := map[string][]*Result{}
+	for _, result := range r.SortedResults() {
+		if result.Failed() {
+			ns := result.Rule.Namespace
+			groups[ns] = append(groups[ns], result)
+		}
+	}
+
+	namespaces := make([]string, 0, len(groups))
+	for ns := range groups {
+		namespaces = append(namespaces, ns)
+	}
+
+	sort.Strings(namespaces)
+
+	omitted := 0
+
+	for _, ns := range namespaces {
+		results := groups[ns]
+
+		if omitted > 0 {
+			omitted += len(results)
+			continue
+		}
+
+		section := fmt.Sprintf("\n<details>\n<summary><b>%s</b> (%d failed)</summary>\n\n", ns, len(results))
+		if b.Len()+len(section) > MaxMarkdownSize-markdownNoteSize {
+			omitted += len(results)
+			continue
+		}
+
+		b.WriteString(section)
+
+		for i, result := range results {
+			item := markdownResult(result)
+			if b.Len()+len(item) > MaxMarkdownSize-markdownNoteSize {
+				omitted += len(results) - i
+				break
+			}
+
+			b.WriteString(item)
+		}
+
+		b.WriteString("\n</details>\n")
+	}
+
+	if omitted > 0 {
+		fmt.Fprintf(b, "\n> **Note**\n> %d failing results were omitted to fit the comment size limit.\n", omitted)
+	}
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+func writeMarkdownHeader(b *strings.Builder, r Report) {
+	title := "Reposaur report"
+	if subject := reportSubject(r.Properties); subject != "" {
+		title += " for " + subject
+	}
+
+	icon := ":white_check_mark:"
+	if !r.Passed() {
+		icon = ":x:"
+	}
+
+	counts := r.CountByStatus()
+
+	fmt.Fprintf(b, "### %s %s\n\n", icon, title)
+	fmt.Fprintf(b, "**%d** failed, **%d** passed", counts[StatusFailed], counts[StatusPassed])
+
+	for _, status := range []string{StatusSuppressed, StatusSkipped, StatusTimedOut} {
+		if counts[status] > 0 {
+			fmt.Fprintf(b, ", **%d** %s", counts[status], strings.ReplaceAll(status, "_", " "))
+		}
+	}
+
+	b.WriteString("\n")
+}
+
+// markdownResult returns the list item of a failing
+// result, with its message and remediation.
+func markdownResult(result *Result) string {
+	rule := result.Rule
+	b := &strings.Builder{}
+
+	fmt.Fprintf(b, "- **%s** `%s`", markdownLine(rule.Title), rule.Severity)
+
+	if subject := reportSubject(result.Properties); subject != "" {
+		fmt.Fprintf(b, " in %s", markdownLine(subject))
+	}
+
+	if result.Message != "" {
+		fmt.Fprintf(b, ": %s", markdownLine(result.Message))
+	}
+
+	b.WriteString("\n")
+
+	if rule.Remediation != "" {
+		fmt.Fprintf(b, "  <br>Remediation: %s", markdownLine(rule.Remediation))
+	}
+
+	if rule.URL != "" {
+		if rule.Remediation == "" {
+			b.WriteString("  <br>")
+		} else {
+			b.WriteString(" ")
+		}
+
+		fmt.Fprintf(b, "[Learn more](%s)", rule.URL)
+	}
+
+	if rule.Remediation != "" || rule.URL != "" {
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// markdownLine joins the lines of s, so
+// it doesn't break out of a list item.
+func markdownLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,91 @@
+package output_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/reposaur/reposaur/pkg/output"
+)
+
+func TestWriteMarkdown(t *testing.T) {
+	report := newTestReport(map[string]bool{"a": true, "b": false, "c": true, "d": true})
+	report.Properties = output.ReportProperties{"owner": "reposaur", "repo": "test"}
+	report.Results["repository/violation/a"].Message = "Uses actions/checkout,\nunpinned"
+	report.Rules["repository/violation/a"].Remediation = "Pin actions to a commit SHA"
+	report.Rules["repository/violation/a"].URL = "https://example.com/a"
+	report.Rules["repository/violation/c"].Namespace = "organization"
+	report.Results["repository/violation/d"].Suppressed = true
+
+	buf := &bytes.Buffer{}
+	if err := output.WriteMarkdown(buf, report); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "### :x: Reposaur report for reposaur/test\n\n" +
+		"**2** failed, **1** passed, **1** suppressed\n" +
+		"\n<details>\n<summary><b>organization</b> (1 failed)</summary>\n\n" +
+		"- **c** `error`\n" +
+		"\n</details>\n" +
+		"\n<details>\n<summary><b>repository</b> (1 failed)</summary>\n\n" +
+		"- **a** `error`: Uses actions/checkout, unpinned\n" +
+		"  <br>Remediation: Pin actions to a commit SHA [Learn more](https://example.com/a)\n" +
+		"\n</details>\n"
+
+	if buf.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
+
+func TestWriteMarkdownPassed(t *testing.T) {
+	report := newTestReport(map[string]bool{"a": false})
+
+	buf := &bytes.Buffer{}
+	if err := output.WriteMarkdown(buf, report); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "### :white_check_mark: Reposaur report\n\n**0** failed, **1** passed\n"
+
+	if buf.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
+
+func TestWriteMarkdownTruncates(t *testing.T) {
+	failing := map[string]bool{}
+	for i := 0; i < 1000; i++ {
+		failing[fmt.Sprintf("rule_%03d", i)] = true
+	}
+
+	report := newTestReport(failing)
+	for _, result := range report.Results {
+		result.Message = strings.Repeat("x", 100)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := output.WriteMarkdown(buf, report); err != nil {
+		t.Fatal(err)
+	}
+
+	md := buf.String()
+
+	if len(md) > output.MaxMarkdownSize {
+		t.Errorf("expected at most %d bytes, got %d", output.MaxMarkdownSize, len(md))
+	}
+
+	listed := strings.Count(md, "\n- **rule_")
+	if listed == 0 || listed == 1000 {
+		t.Fatalf("expected some results to be listed, got %d", listed)
+	}
+
+	note := fmt.Sprintf("%d failing results were omitted", 1000-listed)
+	if !strings.Contains(md, note) {
+		t.Errorf("expected note %q, got:\n%s", note, md[len(md)-300:])
+	}
+
+	if !strings.HasSuffix(md, "comment size limit.\n") || strings.Count(md, "<details>") != strings.Count(md, "</details>") {
+		t.Error("expected the truncated report to be well-formed")
+	}
+}
@@ -2,7 +2,10 @@ package output
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/open-policy-agent/opa/ast"
 )
@@ -16,7 +19,7 @@ const (
 )
 
 var SeverityRuleMap = map[string][]string{
-	ErrorSeverity:   {"error", "fail", "violation"},
+	ErrorSeverity:   {"error", "fail", "violation", "allow"},
 	WarningSeverity: {"warn"},
 	NoteSeverity:    {"note", "info"},
 }
@@ -27,11 +30,78 @@ var SecuritySeverityMap = map[string]string{
 	NoteSeverity:    "1",
 }
 
+// SecuritySeverityLevels maps the named security severity levels
+// to the score they stand for, so annotations can use either.
+var SecuritySeverityLevels = map[string]string{
+	"critical": "9",
+	"high":     "7",
+	"medium":   "4",
+	"low":      "1",
+}
+
+// AffirmativeKinds are the rule kinds that describe the expected
+// state, so they pass when they're defined. Rules of every other
+// kind fail when they're defined.
+var AffirmativeKinds = []string{"allow"}
+
 type Report struct {
 	Rules      map[string]*Rule   `json:"rules"`
 	Results    map[string]*Result `json:"results"`
 	RuleCount  int                `json:"ruleCount"`
 	Properties ReportProperties   `json:"properties"`
+
+	// Stale is true when the subject wasn't changed since the
+	// engine's cutoff and its rules weren't evaluated.
+	Stale bool `json:"stale,omitempty"`
+
+	// DecisionID uniquely identifies the evaluation
+	// that produced the report.
+	DecisionID string `json:"decision_id,omitempty"`
+
+	// Timestamp is when the evaluation started and Input
+	// is the data it was evaluated against.
+	Timestamp time.Time   `json:"-"`
+	Input     interface{} `json:"-"`
+
+	// PromoteWarningsToFailures makes failing warning rules
+	// count toward the failure of the report, i.e. treats
+	// warnings as errors in Passed and ExitCode.
+	PromoteWarningsToFailures bool `json:"-"`
+
+	// Order is the order of the results in SortedResults,
+	// and so in the writers. Defaults to RuleOrder.
+	Order ResultOrder `json:"-"`
+}
+
+// ResultOrder is an order of a report's results.
+type ResultOrder string
+
+const (
+	// RuleOrder orders results by their rule's namespace, ID and kind.
+	RuleOrder ResultOrder = "rule"
+
+	// SeverityOrder orders results by their rule's severity, most
+	// severe first, then by their rule's security severity score,
+	// highest first, and then like RuleOrder. Rules without a
+	// (known) severity or score come after the ones with one.
+	SeverityOrder ResultOrder = "severity"
+)
+
+// severityRanks ranks the severities, higher is more severe.
+var severityRanks = map[string]int{
+	ErrorSeverity:   3,
+	WarningSeverity: 2,
+	NoteSeverity:    1,
+}
+
+// ParseResultOrder returns the result order named s.
+func ParseResultOrder(s string) (ResultOrder, error) {
+	switch order := ResultOrder(strings.ToLower(s)); order {
+	case RuleOrder, SeverityOrder:
+		return order, nil
+	}
+
+	return "", fmt.Errorf("unknown result order '%s'", s)
 }
 
 func (r *Report) AddRule(rule *Rule) {
@@ -40,7 +110,161 @@ func (r *Report) AddRule(rule *Rule) {
 }
 
 func (r *Report) AddResult(result *Result) {
-	r.Results[result.Rule.UID()] = result
+	r.Results[result.Key()] = result
+}
+
+// Passed returns true if none of the report's results failed or
+// timed out with a severity that causes failure. Note results never
+// cause failure and warning results only do when warnings are
+// promoted, see PromoteWarningsToFailures. Timed out results fail
+// the report as their rules may have failed.
+func (r Report) Passed() bool {
+	for _, result := range r.Results {
+		if !result.Failed() && !result.TimedOut {
+			continue
+		}
+
+		if result.Rule.CausesFailure() {
+			return false
+		}
+
+		if r.PromoteWarningsToFailures && result.Rule.Severity == WarningSeverity {
+			return false
+		}
+	}
+
+	return true
+}
+
+// HasFailures returns true if the report has failing or timed out
+// results that cause its failure, i.e. it's the opposite of Passed.
+func (r Report) HasFailures() bool {
+	return !r.Passed()
+}
+
+// HasWarnings returns true if any of the report's warning
+// results failed, whether or not warnings are promoted to
+// failures.
+func (r Report) HasWarnings() bool {
+	for _, result := range r.Results {
+		if result.Failed() && result.Rule.Severity == WarningSeverity {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CountByKind returns the number of failing
+// results of the report by their rule's kind.
+func (r Report) CountByKind() map[string]int {
+	counts := map[string]int{}
+
+	for _, result := range r.Results {
+		if result.Failed() {
+			counts[result.Rule.Kind]++
+		}
+	}
+
+	return counts
+}
+
+// CountByStatus returns the number of results of
+// the report by their status (see Result.Status).
+func (r Report) CountByStatus() map[string]int {
+	counts := map[string]int{}
+
+	for _, result := range r.Results {
+		counts[result.Status()]++
+	}
+
+	return counts
+}
+
+// ExitCode returns the code a process checking
+// the report should exit with: 0 if it passed,
+// and 1 otherwise.
+func (r Report) ExitCode() int {
+	if r.Passed() {
+		return 0
+	}
+
+	return 1
+}
+
+// SortedRules returns the report's rules ordered
+// by namespace, ID and kind.
+func (r Report) SortedRules() []*Rule {
+	rules := make([]*Rule, 0, len(r.Rules))
+	for _, rule := range r.Rules {
+		rules = append(rules, rule)
+	}
+
+	SortRules(rules)
+
+	return rules
+}
+
+// SortRules sorts rules by namespace, ID and kind.
+func SortRules(rules []*Rule) {
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].less(rules[j])
+	})
+}
+
+// SortedResults returns the report's results in order, the
+// report's Order unless one is given, and the findings of a
+// rule in the order they were found.
+func (r Report) SortedResults(order ...ResultOrder) []*Result {
+	results := make([]*Result, 0, len(r.Results))
+	for _, result := range r.Results {
+		results = append(results, result)
+	}
+
+	bySeverity := r.Order == SeverityOrder
+	if len(order) > 0 {
+		bySeverity = order[0] == SeverityOrder
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+
+		if a.Rule.UID() == b.Rule.UID() {
+			if sa, sb := propertiesKey(a.Properties), propertiesKey(b.Properties); sa != sb {
+				return sa < sb
+			}
+
+			return a.Finding < b.Finding
+		}
+
+		if bySeverity {
+			if ra, rb := severityRanks[a.Rule.Severity], severityRanks[b.Rule.Severity]; ra != rb {
+				return ra > rb
+			}
+
+			if sa, sb := a.Rule.securitySeverityScore(), b.Rule.securitySeverityScore(); sa != sb {
+				return sa > sb
+			}
+		}
+
+		return a.Rule.less(b.Rule)
+	})
+
+	return results
+}
+
+// GroupByNamespace returns the report's results grouped by their
+// rule's namespace. Results in each group are sorted like in
+// SortedResults.
+func (r Report) GroupByNamespace() map[string][]*Result {
+	groups := map[string][]*Result{}
+
+	for _, result := range r.SortedResults() {
+		ns := result.Rule.Namespace
+		groups[ns] = append(groups[ns], result)
+	}
+
+	return groups
 }
 
 type ReportProperties map[string]interface{}
@@ -50,6 +274,75 @@ type Result struct {
 	Query   string `json:"query"`
 	Skipped bool   `json:"skipped"`
 	Passed  bool   `json:"passed"`
+	Notice  string `json:"notice,omitempty"`
+
+	// Message and Location are set when the rule's value
+	// is an object with a `msg` and a `path` and `line`.
+	Message  string    `json:"message,omitempty"`
+	Location *Location `json:"location,omitempty"`
+
+	// Value is the value of the rule (or of the finding's element
+	// in set rules), only set if the engine keeps raw values.
+	Value interface{} `json:"value,omitempty"`
+
+	// Suppressed is true when the result failed but
+	// it's a known issue, e.g. listed in a Baseline.
+	Suppressed bool `json:"suppressed,omitempty"`
+
+	// TimedOut is true when the rule wasn't evaluated
+	// because the check's deadline was exceeded.
+	TimedOut bool `json:"timed_out,omitempty"`
+
+	// Finding is the index of the result among the results of
+	// its rule, as rules with a set value (e.g. partial set rules)
+	// produce a failing result for each element.
+	Finding int `json:"finding,omitempty"`
+
+	// Properties are the properties of the report the result
+	// was produced in, only set in merged reports to tell their
+	// subjects apart (see MergeReports).
+	Properties ReportProperties `json:"properties,omitempty"`
+}
+
+// Key returns the key of the result in a report: its rule's
+// UID, followed by "#" and the finding's index for every
+// finding of the rule after the first one.
+func (r Result) Key() string {
+	if r.Finding == 0 {
+		return r.Rule.UID()
+	}
+
+	return fmt.Sprintf("%s#%d", r.Rule.UID(), r.Finding)
+}
+
+// Status returns the status of the result: skipped, timed
+// out, suppressed, passed or failed, in this order.
+func (r Result) Status() string {
+	switch {
+	case r.Skipped:
+		return StatusSkipped
+	case r.TimedOut:
+		return StatusTimedOut
+	case r.Suppressed:
+		return StatusSuppressed
+	case r.Passed:
+		return StatusPassed
+	}
+
+	return StatusFailed
+}
+
+// Location is a line of a file in the subject,
+// e.g. of a workflow in a repository.
+type Location struct {
+	Path string `json:"path"`
+	Line int    `json:"line,omitempty"`
+}
+
+// Failed returns true if the result's rule was
+// evaluated and failed, and it isn't suppressed.
+func (r Result) Failed() bool {
+	return !r.Passed && !r.Skipped && !r.TimedOut && !r.Suppressed
 }
 
 type Rule struct {
@@ -61,9 +354,56 @@ type Rule struct {
 	Description      string   `json:"description"`
 	Namespace        string   `json:"namespace"`
 	Tags             []string `json:"tags"`
+	Remediation      string   `json:"remediation,omitempty"`
+	URL              string   `json:"url,omitempty"`
+
+	// Input is a JSON pointer (RFC 6901) selecting the part
+	// of the input the rule is evaluated against.
+	Input string `json:"input,omitempty"`
+
+	Experimental bool `json:"experimental,omitempty"`
+	Deprecated   bool `json:"deprecated,omitempty"`
+
+	// Set is the name of the policy set the rule
+	// belongs to, if it was loaded as part of one.
+	Set string `json:"set,omitempty"`
+
+	// CustomUID overrides the rule's UID if it's set,
+	// e.g. computed by a UIDFunc (see policy.WithUIDFunc).
+	CustomUID string `json:"uid,omitempty"`
 }
 
-func NewRule(namespace string, rule *ast.Rule, as *ast.Annotations) (*Rule, error) {
+// UIDFunc computes the unique ID of a rule. Rules and results are
+// keyed by it in reports, so rules with the same UID are considered
+// the same rule and only the first one is checked.
+type UIDFunc func(Rule) string
+
+// RuleOption changes the defaults of a rule created by NewRule,
+// which its annotations override.
+type RuleOption func(*Rule)
+
+// WithDefaultSecuritySeverity sets the security severity of a rule
+// that doesn't have one in its annotations, e.g. the default of its
+// namespace, instead of deriving it from the rule's kind.
+func WithDefaultSecuritySeverity(severity string) RuleOption {
+	return func(r *Rule) {
+		if severity != "" {
+			r.SecuritySeverity = normalizeSecuritySeverity(severity)
+		}
+	}
+}
+
+// normalizeSecuritySeverity returns the score of a named
+// security severity level or severity as is otherwise.
+func normalizeSecuritySeverity(severity string) string {
+	if score, ok := SecuritySeverityLevels[strings.ToLower(severity)]; ok {
+		return score
+	}
+
+	return severity
+}
+
+func NewRule(namespace string, rule *ast.Rule, as *ast.Annotations, opts ...RuleOption) (*Rule, error) {
 	headSplit := strings.SplitN(rule.Head.Name.String(), "_", 2)
 
 	if len(headSplit) != 2 {
@@ -102,6 +442,10 @@ func NewRule(namespace string, rule *ast.Rule, as *ast.Annotations) (*Rule, erro
 		Namespace:        namespace,
 	}
 
+	for _, opt := range opts {
+		opt(&r)
+	}
+
 	if as != nil {
 		if as.Title != "" {
 			r.Title = as.Title
@@ -122,7 +466,27 @@ func NewRule(namespace string, rule *ast.Rule, as *ast.Annotations) (*Rule, erro
 		}
 
 		if secSev, ok := as.Custom["security-severity"]; ok {
-			r.SecuritySeverity = fmt.Sprintf("%v", secSev)
+			r.SecuritySeverity = normalizeSecuritySeverity(fmt.Sprintf("%v", secSev))
+		}
+
+		if remediation, ok := as.Custom["remediation"].(string); ok {
+			r.Remediation = remediation
+		}
+
+		if url, ok := as.Custom["url"].(string); ok {
+			r.URL = url
+		}
+
+		if input, ok := as.Custom["input"].(string); ok {
+			r.Input = input
+		}
+
+		if experimental, ok := as.Custom["experimental"].(bool); ok {
+			r.Experimental = experimental
+		}
+
+		if deprecated, ok := as.Custom["deprecated"].(bool); ok {
+			r.Deprecated = deprecated
 		}
 	}
 
@@ -133,10 +497,72 @@ func (r Rule) CausesFailure() bool {
 	return r.Severity == ErrorSeverity
 }
 
+// Affirmative returns true if the rule passes when it's defined.
+// See AffirmativeKinds.
+func (r Rule) Affirmative() bool {
+	for _, k := range AffirmativeKinds {
+		if k == r.Kind {
+			return true
+		}
+	}
+
+	return false
+}
+
+// securitySeverityScore returns the rule's security
+// severity score, or -1 if it doesn't have a valid one.
+func (r Rule) securitySeverityScore() float64 {
+	score, err := strconv.ParseFloat(r.SecuritySeverity, 64)
+	if err != nil {
+		return -1
+	}
+
+	return score
+}
+
+func (r Rule) less(other *Rule) bool {
+	if r.Set != other.Set {
+		return r.Set < other.Set
+	}
+
+	if r.Namespace != other.Namespace {
+		return r.Namespace < other.Namespace
+	}
+
+	if r.ID != other.ID {
+		return r.ID < other.ID
+	}
+
+	return r.Kind < other.Kind
+}
+
+// UID returns the unique ID of the rule, its CustomUID if it's
+// set or the one computed by DefaultUID otherwise.
 func (r Rule) UID() string {
+	if r.CustomUID != "" {
+		return r.CustomUID
+	}
+
+	return DefaultUID(r)
+}
+
+// DefaultUID returns the default unique ID of a rule, made of its
+// namespace, kind and ID (e.g. "repository/violation/not_internal"),
+// prefixed with the name of its policy set and a colon if it has one
+// (e.g. "vendor:repository/violation/not_internal"). Rules of
+// different sets don't collide, even if they share a namespace.
+func DefaultUID(r Rule) string {
+	if r.Set != "" {
+		return fmt.Sprintf("%s:%s/%s/%s", r.Set, r.Namespace, r.Kind, r.ID)
+	}
+
 	return fmt.Sprintf("%s/%s/%s", r.Namespace, r.Kind, r.ID)
 }
 
+// MergeReports merges reports, e.g. of several subjects, into a single
+// report. The results of reports with properties are tagged with them
+// (see Result.Properties) and keyed by them too, so the results of a
+// rule for different subjects don't collide.
 func MergeReports(reports []Report) Report {
 	report := Report{
 		Rules:   make(map[string]*Rule),
@@ -146,14 +572,57 @@ func MergeReports(reports []Report) Report {
 	for _, r := range reports {
 		report.RuleCount += r.RuleCount
 
+		if r.Order != "" {
+			report.Order = r.Order
+		}
+
 		for k, v := range r.Rules {
 			report.Rules[k] = v
 		}
 
+		prefix := ""
+		if len(r.Properties) > 0 {
+			prefix = propertiesKey(r.Properties) + ":"
+		}
+
 		for k, v := range r.Results {
-			report.Results[k] = v
+			if prefix != "" && v.Properties == nil {
+				tagged := *v
+				tagged.Properties = r.Properties
+				v = &tagged
+			}
+
+			report.Results[prefix+k] = v
 		}
 	}
 
 	return report
 }
+
+// resultProperties returns the properties of the subject of result
+// in report, i.e. the result's own if it was merged from another one.
+func resultProperties(report Report, result *Result) ReportProperties {
+	if result.Properties != nil {
+		return result.Properties
+	}
+
+	return report.Properties
+}
+
+// propertiesKey returns props as comma-separated key=value
+// pairs sorted by key, identifying the subject they describe.
+func propertiesKey(props ReportProperties) string {
+	keys := make([]string, 0, len(props))
+	for k := range props {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, fmt.Sprintf("%s=%v", k, props[k]))
+	}
+
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,573 @@
+package output_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/reposaur/reposaur/pkg/output"
+)
+
+func newMultiNamespaceReport() output.Report {
+	report := output.Report{
+		Rules:   map[string]*output.Rule{},
+		Results: map[string]*output.Result{},
+	}
+
+	rules := []*output.Rule{
+		{ID: "b", Kind: "violation", Namespace: "repository"},
+		{ID: "a", Kind: "warn", Namespace: "repository"},
+		{ID: "a", Kind: "violation", Namespace: "repository"},
+		{ID: "c", Kind: "note", Namespace: "organization"},
+		{ID: "z", Kind: "violation", Namespace: "issue"},
+	}
+
+	for _, rule := range rules {
+		report.AddRule(rule)
+		report.AddResult(&output.Result{Rule: rule})
+	}
+
+	return report
+}
+
+func TestReportSortedRules(t *testing.T) {
+	expected := []string{
+		"issue/violation/z",
+		"organization/note/c",
+		"repository/violation/a",
+		"repository/warn/a",
+		"repository/violation/b",
+	}
+
+	// maps are iterated in random order, repeat to
+	// make sure the order is stable
+	for i := 0; i < 10; i++ {
+		rules := newMultiNamespaceReport().SortedRules()
+
+		for j, rule := range rules {
+			if rule.UID() != expected[j] {
+				t.Fatalf("expected rule %d to be %s, got %s", j, expected[j], rule.UID())
+			}
+		}
+	}
+}
+
+func TestReportSortedResults(t *testing.T) {
+	expected := []string{
+		"issue/violation/z",
+		"organization/note/c",
+		"repository/violation/a",
+		"repository/warn/a",
+		"repository/violation/b",
+	}
+
+	for i := 0; i < 10; i++ {
+		results := newMultiNamespaceReport().SortedResults()
+
+		for j, result := range results {
+			if result.Rule.UID() != expected[j] {
+				t.Fatalf("expected result %d to be %s, got %s", j, expected[j], result.Rule.UID())
+			}
+		}
+	}
+}
+
+// newMixedSeverityReport returns a report with results of rules of
+// every severity, some with a security severity score and one
+// without a severity.
+func newMixedSeverityReport() output.Report {
+	report := output.Report{
+		Rules:   map[string]*output.Rule{},
+		Results: map[string]*output.Result{},
+	}
+
+	rules := []*output.Rule{
+		{Namespace: "repository", Kind: "note", ID: "a", Severity: output.NoteSeverity, SecuritySeverity: "1"},
+		{Namespace: "repository", Kind: "warn", ID: "b", Severity: output.WarningSeverity, SecuritySeverity: "4"},
+		{Namespace: "repository", Kind: "violation", ID: "c", Severity: output.ErrorSeverity, SecuritySeverity: "7"},
+		{Namespace: "organization", Kind: "violation", ID: "d", Severity: output.ErrorSeverity, SecuritySeverity: "9"},
+		{Namespace: "organization", Kind: "violation", ID: "e", Severity: output.ErrorSeverity},
+		{Namespace: "issue", Kind: "custom", ID: "f"},
+		{Namespace: "issue", Kind: "warn", ID: "g", Severity: output.WarningSeverity, SecuritySeverity: "4"},
+	}
+
+	for _, rule := range rules {
+		report.AddRule(rule)
+		report.AddResult(&output.Result{Rule: rule})
+	}
+
+	return report
+}
+
+func TestReportSortedResultsBySeverity(t *testing.T) {
+	expected := []string{
+		"organization/violation/d",
+		"repository/violation/c",
+		"organization/violation/e",
+		"issue/warn/g",
+		"repository/warn/b",
+		"repository/note/a",
+		"issue/custom/f",
+	}
+
+	assertOrder := func(results []*output.Result) {
+		t.Helper()
+
+		if len(results) != len(expected) {
+			t.Fatalf("expected %d results, got %d", len(expected), len(results))
+		}
+
+		for i, result := range results {
+			if result.Rule.UID() != expected[i] {
+				t.Fatalf("expected result %d to be %s, got %s", i, expected[i], result.Rule.UID())
+			}
+		}
+	}
+
+	for i := 0; i < 10; i++ {
+		assertOrder(newMixedSeverityReport().SortedResults(output.SeverityOrder))
+	}
+
+	report := newMixedSeverityReport()
+	report.Order = output.SeverityOrder
+
+	assertOrder(report.SortedResults())
+
+	// an explicit order takes precedence over the report's
+	if results := report.SortedResults(output.RuleOrder); results[0].Rule.UID() != "issue/custom/f" {
+		t.Errorf("expected the results ordered by rule, got %s first", results[0].Rule.UID())
+	}
+}
+
+func TestParseResultOrder(t *testing.T) {
+	for _, s := range []string{"rule", "severity", "Severity"} {
+		if _, err := output.ParseResultOrder(s); err != nil {
+			t.Errorf("expected %q to be valid, got %v", s, err)
+		}
+	}
+
+	if _, err := output.ParseResultOrder("kind"); err == nil {
+		t.Error("expected an unknown order to fail")
+	}
+}
+
+// parseRule parses a module with a single annotated rule
+// and returns it as an output.Rule.
+func parseRule(t *testing.T, src string) *output.Rule {
+	t.Helper()
+
+	mod, err := ast.ParseModuleWithOpts("test.rego", src, ast.ParserOptions{ProcessAnnotation: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var annotations *ast.Annotations
+	if len(mod.Annotations) > 0 {
+		annotations = mod.Annotations[0]
+	}
+
+	rule, err := output.NewRule("repository", mod.Rules[0], annotations)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return rule
+}
+
+func TestNewRuleRemediation(t *testing.T) {
+	rule := parseRule(t, `
+package repository
+
+# METADATA
+# title: Forking is enabled
+# custom:
+#   remediation: Uncheck the "Allow forking" option
+#   url: https://example.com/forking
+violation_forking_enabled {
+	input.allow_forking
+}
+`)
+
+	if expected := `Uncheck the "Allow forking" option`; rule.Remediation != expected {
+		t.Errorf("expected remediation to be '%s', got '%s'", expected, rule.Remediation)
+	}
+
+	if expected := "https://example.com/forking"; rule.URL != expected {
+		t.Errorf("expected url to be '%s', got '%s'", expected, rule.URL)
+	}
+}
+
+func TestNewRuleWithoutRemediation(t *testing.T) {
+	rule := parseRule(t, `
+package repository
+
+# METADATA
+# title: Forking is enabled
+violation_forking_enabled {
+	input.allow_forking
+}
+`)
+
+	if rule.Remediation != "" || rule.URL != "" {
+		t.Errorf("expected no remediation and url, got '%s' and '%s'", rule.Remediation, rule.URL)
+	}
+}
+
+func TestNewRuleStatus(t *testing.T) {
+	rule := parseRule(t, `
+package repository
+
+# METADATA
+# custom:
+#   experimental: true
+#   deprecated: true
+violation_forking_enabled {
+	input.allow_forking
+}
+`)
+
+	if !rule.Experimental || !rule.Deprecated {
+		t.Errorf("expected rule to be experimental and deprecated, got %v and %v", rule.Experimental, rule.Deprecated)
+	}
+
+	rule = parseRule(t, `
+package repository
+
+violation_forking_enabled {
+	input.allow_forking
+}
+`)
+
+	if rule.Experimental || rule.Deprecated {
+		t.Errorf("expected rule not to be experimental or deprecated, got %v and %v", rule.Experimental, rule.Deprecated)
+	}
+}
+
+func TestReportGroupByNamespace(t *testing.T) {
+	groups := newMultiNamespaceReport().GroupByNamespace()
+
+	expected := map[string][]string{
+		"issue":        {"issue/violation/z"},
+		"organization": {"organization/note/c"},
+		"repository": {
+			"repository/violation/a",
+			"repository/warn/a",
+			"repository/violation/b",
+		},
+	}
+
+	if len(groups) != len(expected) {
+		t.Fatalf("expected %d groups, got %d", len(expected), len(groups))
+	}
+
+	for ns, uids := range expected {
+		results := groups[ns]
+
+		if len(results) != len(uids) {
+			t.Fatalf("expected %d results in %s, got %d", len(uids), ns, len(results))
+		}
+
+		for i, uid := range uids {
+			if results[i].Rule.UID() != uid {
+				t.Errorf("expected result %d in %s to be %s, got %s", i, ns, uid, results[i].Rule.UID())
+			}
+		}
+	}
+}
+
+func TestReportPromoteWarningsToFailures(t *testing.T) {
+	newReport := func(results ...*output.Result) output.Report {
+		report := output.Report{
+			Rules:   map[string]*output.Rule{},
+			Results: map[string]*output.Result{},
+		}
+
+		for _, result := range results {
+			report.AddRule(result.Rule)
+			report.AddResult(result)
+		}
+
+		return report
+	}
+
+	var (
+		violation = &output.Rule{ID: "a", Kind: "violation", Severity: output.ErrorSeverity, Namespace: "repository"}
+		warn      = &output.Rule{ID: "b", Kind: "warn", Severity: output.WarningSeverity, Namespace: "repository"}
+		note      = &output.Rule{ID: "c", Kind: "note", Severity: output.NoteSeverity, Namespace: "repository"}
+	)
+
+	cases := []struct {
+		name     string
+		results  []*output.Result
+		passed   bool
+		promoted bool
+	}{
+		{
+			name:     "failing warning",
+			results:  []*output.Result{{Rule: warn}, {Rule: violation, Passed: true}},
+			passed:   true,
+			promoted: false,
+		},
+		{
+			name:     "failing violation",
+			results:  []*output.Result{{Rule: violation}, {Rule: warn, Passed: true}},
+			passed:   false,
+			promoted: false,
+		},
+		{
+			name:     "failing note",
+			results:  []*output.Result{{Rule: note}},
+			passed:   true,
+			promoted: true,
+		},
+		{
+			name:     "skipped warning",
+			results:  []*output.Result{{Rule: warn, Skipped: true}},
+			passed:   true,
+			promoted: true,
+		},
+		{
+			name:     "suppressed warning",
+			results:  []*output.Result{{Rule: warn, Suppressed: true}},
+			passed:   true,
+			promoted: true,
+		},
+		{
+			name:     "timed out warning",
+			results:  []*output.Result{{Rule: warn, TimedOut: true}},
+			passed:   true,
+			promoted: false,
+		},
+		{
+			name:     "timed out violation",
+			results:  []*output.Result{{Rule: violation, TimedOut: true}, {Rule: warn, Passed: true}},
+			passed:   false,
+			promoted: false,
+		},
+		{
+			name:     "timed out note",
+			results:  []*output.Result{{Rule: note, TimedOut: true}},
+			passed:   true,
+			promoted: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			report := newReport(c.results...)
+
+			if report.Passed() != c.passed {
+				t.Errorf("expected passed to be %v, got %v", c.passed, report.Passed())
+			}
+
+			report.PromoteWarningsToFailures = true
+
+			if report.Passed() != c.promoted {
+				t.Errorf("expected passed to be %v with promotion, got %v", c.promoted, report.Passed())
+			}
+
+			expectedCode := 1
+			if c.promoted {
+				expectedCode = 0
+			}
+
+			if report.ExitCode() != expectedCode {
+				t.Errorf("expected exit code %d with promotion, got %d", expectedCode, report.ExitCode())
+			}
+		})
+	}
+}
+
+func TestReportPredicates(t *testing.T) {
+	var (
+		violation = &output.Rule{ID: "a", Kind: "violation", Severity: output.ErrorSeverity, Namespace: "repository"}
+		fail      = &output.Rule{ID: "b", Kind: "fail", Severity: output.ErrorSeverity, Namespace: "repository"}
+		warn      = &output.Rule{ID: "c", Kind: "warn", Severity: output.WarningSeverity, Namespace: "repository"}
+		note      = &output.Rule{ID: "d", Kind: "note", Severity: output.NoteSeverity, Namespace: "repository"}
+	)
+
+	cases := []struct {
+		name        string
+		results     []*output.Result
+		failures    bool
+		warnings    bool
+		countKind   map[string]int
+		countStatus map[string]int
+	}{
+		{
+			name:        "all passed",
+			results:     []*output.Result{{Rule: violation, Passed: true}, {Rule: warn, Passed: true}},
+			countKind:   map[string]int{},
+			countStatus: map[string]int{output.StatusPassed: 2},
+		},
+		{
+			name:        "failing violation and fail",
+			results:     []*output.Result{{Rule: violation}, {Rule: fail}, {Rule: warn, Passed: true}},
+			failures:    true,
+			countKind:   map[string]int{"violation": 1, "fail": 1},
+			countStatus: map[string]int{output.StatusFailed: 2, output.StatusPassed: 1},
+		},
+		{
+			name:        "failing warning and note",
+			results:     []*output.Result{{Rule: warn}, {Rule: note}, {Rule: violation, Passed: true}},
+			warnings:    true,
+			countKind:   map[string]int{"warn": 1, "note": 1},
+			countStatus: map[string]int{output.StatusFailed: 2, output.StatusPassed: 1},
+		},
+		{
+			name: "suppressed, skipped and timed out",
+			results: []*output.Result{
+				{Rule: violation, Suppressed: true},
+				{Rule: warn, Skipped: true},
+				{Rule: fail, TimedOut: true},
+			},
+			failures:  true,
+			countKind: map[string]int{},
+			countStatus: map[string]int{
+				output.StatusSuppressed: 1,
+				output.StatusSkipped:    1,
+				output.StatusTimedOut:   1,
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			report := output.Report{
+				Rules:   map[string]*output.Rule{},
+				Results: map[string]*output.Result{},
+			}
+
+			for _, result := range c.results {
+				report.AddRule(result.Rule)
+				report.AddResult(result)
+			}
+
+			if report.HasFailures() != c.failures {
+				t.Errorf("expected HasFailures to be %v", c.failures)
+			}
+
+			if report.HasFailures() == report.Passed() || report.HasFailures() != (report.ExitCode() == 1) {
+				t.Error("expected HasFailures to be consistent with Passed and ExitCode")
+			}
+
+			if report.HasWarnings() != c.warnings {
+				t.Errorf("expected HasWarnings to be %v", c.warnings)
+			}
+
+			if got := report.CountByKind(); !reflect.DeepEqual(got, c.countKind) {
+				t.Errorf("expected counts by kind %v, got %v", c.countKind, got)
+			}
+
+			if got := report.CountByStatus(); !reflect.DeepEqual(got, c.countStatus) {
+				t.Errorf("expected counts by status %v, got %v", c.countStatus, got)
+			}
+		})
+	}
+}
+
+func TestReportHasFailuresPromotedWarnings(t *testing.T) {
+	warn := &output.Rule{ID: "a", Kind: "warn", Severity: output.WarningSeverity, Namespace: "repository"}
+
+	report := output.Report{
+		Rules:   map[string]*output.Rule{},
+		Results: map[string]*output.Result{},
+	}
+
+	report.AddRule(warn)
+	report.AddResult(&output.Result{Rule: warn})
+
+	if report.HasFailures() || !report.HasWarnings() {
+		t.Error("expected a failing warning not to be a failure")
+	}
+
+	report.PromoteWarningsToFailures = true
+
+	if !report.HasFailures() {
+		t.Error("expected a failing warning to be a failure when warnings are promoted")
+	}
+}
+
+func TestMergeReports(t *testing.T) {
+	first := newTestReport(map[string]bool{"a": true})
+	first.Properties = output.ReportProperties{"owner": "reposaur", "repo": "first"}
+
+	second := newTestReport(map[string]bool{"a": false})
+	second.Properties = output.ReportProperties{"owner": "reposaur", "repo": "second"}
+
+	merged := output.MergeReports([]output.Report{second, first})
+
+	if len(merged.Results) != 2 {
+		t.Fatalf("expected the results of both subjects, got %d", len(merged.Results))
+	}
+
+	var repos []interface{}
+	for _, result := range merged.SortedResults() {
+		repos = append(repos, result.Properties["repo"])
+	}
+
+	if expected := []interface{}{"first", "second"}; !reflect.DeepEqual(repos, expected) {
+		t.Errorf("expected results of %v, got %v", expected, repos)
+	}
+
+	if merged.Passed() {
+		t.Error("expected merged report to fail")
+	}
+
+	// the merged reports' results aren't modified
+	for _, report := range []output.Report{first, second} {
+		for _, result := range report.Results {
+			if result.Properties != nil {
+				t.Errorf("expected result of %v not to be tagged", report.Properties)
+			}
+		}
+	}
+
+	fingerprints := map[string]bool{}
+	for _, result := range merged.Results {
+		fingerprints[output.Fingerprint(merged, result)] = true
+	}
+
+	if !fingerprints[output.Fingerprint(first, first.Results["repository/violation/a"])] {
+		t.Error("expected merged results to keep the fingerprint of their subject")
+	}
+}
+
+func TestRuleUID(t *testing.T) {
+	rule := output.Rule{Namespace: "repository", Kind: "violation", ID: "not_internal"}
+
+	if uid := rule.UID(); uid != "repository/violation/not_internal" {
+		t.Errorf("unexpected UID %s", uid)
+	}
+
+	rule.Set = "vendor"
+
+	if uid := rule.UID(); uid != "vendor:repository/violation/not_internal" {
+		t.Errorf("unexpected UID %s", uid)
+	}
+
+	rule.CustomUID = "custom/not_internal"
+
+	if uid := rule.UID(); uid != rule.CustomUID {
+		t.Errorf("expected the custom UID, got %s", uid)
+	}
+
+	if uid := output.DefaultUID(rule); uid != "vendor:repository/violation/not_internal" {
+		t.Errorf("expected the default UID to ignore the custom one, got %s", uid)
+	}
+
+	b, err := json.Marshal(rule)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded output.Rule
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded.UID() != rule.UID() {
+		t.Errorf("expected the UID to be stable when encoded, got %s", decoded.UID())
+	}
+}
@@ -19,7 +19,7 @@ func NewSarifReport(report Report) (*sarif.Report, error) {
 		run.Properties[k] = v
 	}
 
-	for _, rule := range report.Rules {
+	for _, rule := range report.SortedRules() {
 		props := sarif.Properties{}
 
 		if len(rule.Tags) > 0 {
@@ -30,30 +30,44 @@ func NewSarifReport(report Report) (*sarif.Report, error) {
 			props["security-severity"] = rule.SecuritySeverity
 		}
 
-		run.AddRule(rule.UID()).
+		help := rule.Description
+		if rule.Remediation != "" {
+			help += "\n\n## Remediation\n\n" + rule.Remediation
+		}
+
+		sarifRule := run.AddRule(rule.UID()).
 			WithName(rule.Title).
 			WithDescription(rule.Title).
 			WithFullDescription(
 				sarif.NewMultiformatMessageString(rule.Description).
 					WithMarkdown(rule.Description),
 			).
-			WithMarkdownHelp(rule.Description).
+			WithMarkdownHelp(help).
 			WithProperties(props)
+
+		if rule.URL != "" {
+			sarifRule.WithHelpURI(rule.URL)
+		}
 	}
 
-	for _, result := range report.Results {
-		if !result.Passed && !result.Skipped {
-			run.AddResult(result.Rule.UID()).
+	for _, result := range report.SortedResults() {
+		if result.Failed() {
+			message := result.Rule.Title
+			if result.Message != "" {
+				message = result.Message
+			}
+
+			sarifResult := run.AddResult(result.Rule.UID()).
 				WithLevel(strings.ToLower(result.Rule.Severity)).
-				WithMessage(sarif.NewTextMessage(result.Rule.Title)).
+				WithMessage(sarif.NewTextMessage(message)).
 				WithLocation(
-					sarif.NewLocationWithPhysicalLocation(
-						sarif.NewPhysicalLocation().
-							WithArtifactLocation(
-								sarif.NewSimpleArtifactLocation("."),
-							),
-					),
+					sarif.NewLocationWithPhysicalLocation(newSarifPhysicalLocation(result.Location)),
 				)
+
+			// results of merged reports keep their subject
+			if len(result.Properties) > 0 {
+				sarifResult.WithProperties(sarif.Properties(result.Properties))
+			}
 		}
 	}
 
@@ -61,3 +75,21 @@ func NewSarifReport(report Report) (*sarif.Report, error) {
 
 	return sr, nil
 }
+
+// newSarifPhysicalLocation returns the location of a result, which
+// is the repository's root unless the result is located in a file.
+func newSarifPhysicalLocation(loc *Location) *sarif.PhysicalLocation {
+	if loc == nil || loc.Path == "" {
+		return sarif.NewPhysicalLocation().
+			WithArtifactLocation(sarif.NewSimpleArtifactLocation("."))
+	}
+
+	pl := sarif.NewPhysicalLocation().
+		WithArtifactLocation(sarif.NewSimpleArtifactLocation(loc.Path))
+
+	if loc.Line > 0 {
+		pl.WithRegion(sarif.NewRegion().WithStartLine(loc.Line))
+	}
+
+	return pl
+}
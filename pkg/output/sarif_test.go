@@ -0,0 +1,43 @@
+package output_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/reposaur/reposaur/pkg/output"
+)
+
+func TestNewSarifReportRemediation(t *testing.T) {
+	report := newTestReport(map[string]bool{"a": true, "b": true})
+	report.Rules["repository/violation/a"].Remediation = "Do something"
+	report.Rules["repository/violation/a"].URL = "https://example.com/a"
+
+	sr, err := output.NewSarifReport(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rules := sr.Runs[0].Tool.Driver.Rules
+
+	if len(rules) != 2 {
+		t.Fatalf("expected 2 rules, got %d", len(rules))
+	}
+
+	withRemediation, withoutRemediation := rules[0], rules[1]
+
+	if withRemediation.HelpURI == nil || *withRemediation.HelpURI != "https://example.com/a" {
+		t.Errorf("expected help uri to be set, got %v", withRemediation.HelpURI)
+	}
+
+	if help := *withRemediation.Help.Markdown; !strings.Contains(help, "Do something") {
+		t.Errorf("expected help to contain remediation, got '%s'", help)
+	}
+
+	if withoutRemediation.HelpURI != nil {
+		t.Errorf("expected no help uri, got %s", *withoutRemediation.HelpURI)
+	}
+
+	if help := *withoutRemediation.Help.Markdown; strings.Contains(help, "Remediation") {
+		t.Errorf("expected help not to contain remediation, got '%s'", help)
+	}
+}
@@ -0,0 +1,108 @@
+package output
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"unicode"
+)
+
+// SuppressionDirective marks inline suppressions in scanned
+// files, e.g. `# reposaur:ignore unpinned_action`.
+const SuppressionDirective = "reposaur:ignore"
+
+// ApplyInlineSuppressions marks the failing results of report located
+// in the file at path as suppressed if its content has a suppression
+// comment for them, at the end of the result's line or on its own in
+// the line before. The comment lists the rules it suppresses after
+// SuppressionDirective, separated by spaces or commas, by ID (e.g.
+// unpinned_action), Rego rule name (e.g. violation_unpinned_action) or
+// UID. Comments without rules suppress every rule. It fails without
+// suppressing any result if content can't be scanned, e.g. if a line
+// is too long.
+func ApplyInlineSuppressions(report Report, path string, content []byte) error {
+	suppressions, err := parseSuppressions(content)
+	if err != nil {
+		return err
+	}
+
+	for _, result := range report.Results {
+		loc := result.Location
+		if !result.Failed() || loc == nil || loc.Path != path || loc.Line == 0 {
+			continue
+		}
+
+		if s, ok := suppressions[loc.Line]; ok && s.suppresses(result.Rule) {
+			result.Suppressed = true
+		} else if s, ok := suppressions[loc.Line-1]; ok && s.standalone && s.suppresses(result.Rule) {
+			result.Suppressed = true
+		}
+	}
+
+	return nil
+}
+
+// suppression is a suppression comment.
+type suppression struct {
+	// rules are the rules suppressed, or every
+	// rule if empty. See ApplyInlineSuppressions.
+	rules []string
+
+	// standalone is true if the comment is on its own line,
+	// so it applies to the next line.
+	standalone bool
+}
+
+// parseSuppressions returns the suppression comments
+// in content by line number (starting at 1).
+func parseSuppressions(content []byte) (map[int]suppression, error) {
+	suppressions := map[int]suppression{}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+
+		i := strings.Index(text, SuppressionDirective)
+		if i < 0 {
+			continue
+		}
+
+		rest := text[i+len(SuppressionDirective):]
+
+		// e.g. reposaur:ignored isn't a directive
+		if rest != "" && !strings.ContainsAny(rest[:1], " \t,") {
+			continue
+		}
+
+		suppressions[line] = suppression{
+			rules: strings.FieldsFunc(rest, func(r rune) bool {
+				return r == ' ' || r == '\t' || r == ','
+			}),
+			// only comment markers, e.g. # or //, precede it
+			standalone: strings.IndexFunc(text[:i], func(r rune) bool {
+				return unicode.IsLetter(r) || unicode.IsDigit(r)
+			}) < 0,
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return suppressions, nil
+}
+
+// suppresses returns true if the comment suppresses rule.
+func (s suppression) suppresses(rule *Rule) bool {
+	if len(s.rules) == 0 {
+		return true
+	}
+
+	for _, r := range s.rules {
+		if r == rule.ID || r == rule.Kind+"_"+rule.ID || r == rule.UID() {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,117 @@
+package output_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/reposaur/reposaur/pkg/output"
+)
+
+const testWorkflow = `name: CI
+on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      # reposaur:ignore unpinned_action
+      - uses: actions/checkout@v3
+      - uses: actions/setup-go@v3 # reposaur:ignore violation_unpinned_action, write_permissions
+      - uses: actions/cache@v3
+      - uses: docker/login-action@v2 # reposaur:ignore
+      - uses: actions/upload-artifact@v3 # reposaur:ignore other_rule
+      - uses: actions/download-artifact@v3 # reposaur:ignored
+`
+
+func TestApplyInlineSuppressions(t *testing.T) {
+	rule := &output.Rule{ID: "unpinned_action", Kind: "violation", Namespace: "repository"}
+
+	report := output.Report{
+		Rules:   map[string]*output.Rule{},
+		Results: map[string]*output.Result{},
+	}
+
+	report.AddRule(rule)
+
+	lines := map[int]bool{
+		8:  true,  // comment on the previous line
+		9:  true,  // comment listing the Rego rule name
+		10: false, // no comment
+		11: true,  // comment without rules
+		12: false, // comment for another rule
+		13: false, // not a directive
+	}
+
+	for line := range lines {
+		report.AddResult(&output.Result{
+			Rule:     rule,
+			Finding:  line,
+			Location: &output.Location{Path: ".github/workflows/ci.yml", Line: line},
+		})
+	}
+
+	other := &output.Result{
+		Rule:     rule,
+		Finding:  100,
+		Location: &output.Location{Path: ".github/workflows/release.yml", Line: 8},
+	}
+
+	passed := &output.Result{
+		Rule:     rule,
+		Finding:  101,
+		Passed:   true,
+		Location: &output.Location{Path: ".github/workflows/ci.yml", Line: 8},
+	}
+
+	report.AddResult(other)
+	report.AddResult(passed)
+
+	if err := output.ApplyInlineSuppressions(report, ".github/workflows/ci.yml", []byte(testWorkflow)); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, result := range report.Results {
+		if result == other || result == passed {
+			continue
+		}
+
+		if expected := lines[result.Location.Line]; result.Suppressed != expected {
+			t.Errorf("line %d: expected suppressed to be %v", result.Location.Line, expected)
+		}
+	}
+
+	if other.Suppressed || passed.Suppressed {
+		t.Error("expected results in other files and passing results not to be suppressed")
+	}
+
+	if len(report.Results) != len(lines)+2 {
+		t.Errorf("expected suppressed results to be kept, got %d results", len(report.Results))
+	}
+}
+
+func TestApplyInlineSuppressionsLineTooLong(t *testing.T) {
+	rule := &output.Rule{ID: "unpinned_action", Kind: "violation", Namespace: "repository"}
+
+	report := output.Report{
+		Rules:   map[string]*output.Rule{},
+		Results: map[string]*output.Result{},
+	}
+
+	report.AddRule(rule)
+
+	result := &output.Result{
+		Rule:     rule,
+		Location: &output.Location{Path: ".github/workflows/ci.yml", Line: 2},
+	}
+
+	report.AddResult(result)
+
+	content := "# reposaur:ignore\n- uses: actions/checkout@v3\n" + strings.Repeat("x", 1<<20) + "\n"
+
+	if err := output.ApplyInlineSuppressions(report, ".github/workflows/ci.yml", []byte(content)); err == nil {
+		t.Error("expected error scanning a line that's too long")
+	}
+
+	if result.Suppressed {
+		t.Error("expected result not to be suppressed")
+	}
+}
@@ -0,0 +1,84 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/template"
+)
+
+// TemplateFuncs are the functions available in report templates,
+// besides the text/template built-ins:
+//
+//	json     encodes a value as JSON
+//	subject  names the subject of a report, e.g. owner/repo
+//	failed   selects the failing results
+//	status   selects the results with a status, e.g. "suppressed"
+//	kind     selects the results of rules of a kind, e.g. "warn"
+//	severity selects the results of rules of a severity, e.g. "error"
+//	count    returns the number of results
+//
+// Selectors take the results last, so they can be chained in
+// pipelines, e.g. {{ .SortedResults | failed | kind "warn" | count }}.
+var TemplateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	"subject": func(r Report) string {
+		return reportSubject(r.Properties)
+	},
+	"failed": func(results []*Result) []*Result {
+		return filterResults(results, func(r *Result) bool { return r.Failed() })
+	},
+	"status": func(status string, results []*Result) []*Result {
+		return filterResults(results, func(r *Result) bool { return r.Status() == status })
+	},
+	"kind": func(kind string, results []*Result) []*Result {
+		return filterResults(results, func(r *Result) bool { return r.Rule.Kind == kind })
+	},
+	"severity": func(severity string, results []*Result) []*Result {
+		return filterResults(results, func(r *Result) bool { return r.Rule.Severity == severity })
+	},
+	"count": func(results []*Result) int {
+		return len(results)
+	},
+}
+
+// WriteTemplate writes the report to w formatted by tmpl, a
+// text/template executed with the report and TemplateFuncs,
+// e.g. to summarize it as Markdown in a pull request comment.
+func WriteTemplate(w io.Writer, r Report, tmpl string) error {
+	t, err := parseTemplate("report", tmpl)
+	if err != nil {
+		return err
+	}
+
+	if err := t.Execute(w, r); err != nil {
+		return fmt.Errorf("execute template: %w", err)
+	}
+
+	return nil
+}
+
+func parseTemplate(name, tmpl string) (*template.Template, error) {
+	t, err := template.New(name).Funcs(TemplateFuncs).Parse(tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("parse template: %w", err)
+	}
+
+	return t, nil
+}
+
+// filterResults returns the results matching keep, in order.
+func filterResults(results []*Result, keep func(*Result) bool) []*Result {
+	filtered := []*Result{}
+
+	for _, r := range results {
+		if keep(r) {
+			filtered = append(filtered, r)
+		}
+	}
+
+	return filtered
+}
@@ -0,0 +1,49 @@
+package output_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/reposaur/reposaur/pkg/output"
+)
+
+func TestWriteTemplate(t *testing.T) {
+	report := newTestReport(map[string]bool{"a": true, "b": false, "c": true, "d": true})
+	report.Properties = output.ReportProperties{"owner": "reposaur", "repo": "test"}
+	report.Rules["repository/violation/c"].Kind = "warn"
+	report.Rules["repository/violation/c"].Severity = output.WarningSeverity
+	report.Results["repository/violation/d"].Suppressed = true
+
+	tmpl := `## {{ subject . }}
+{{ with .SortedResults | failed }}{{ count . }} failed, {{ . | kind "warn" | count }} warnings:
+{{ range . }}- {{ .Rule.Title }} ({{ .Rule.Severity }})
+{{ end }}{{ end }}{{ .SortedResults | status "suppressed" | count }} suppressed
+`
+
+	buf := &bytes.Buffer{}
+	if err := output.WriteTemplate(buf, report, tmpl); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "## reposaur/test\n" +
+		"2 failed, 1 warnings:\n" +
+		"- a (error)\n" +
+		"- c (warning)\n" +
+		"1 suppressed\n"
+
+	if buf.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
+
+func TestWriteTemplateErrors(t *testing.T) {
+	report := newTestReport(map[string]bool{"a": true})
+
+	if err := output.WriteTemplate(&bytes.Buffer{}, report, "{{ .Missing"); err == nil {
+		t.Error("expected a parse error")
+	}
+
+	if err := output.WriteTemplate(&bytes.Buffer{}, report, "{{ .Missing }}"); err == nil {
+		t.Error("expected an execution error")
+	}
+}
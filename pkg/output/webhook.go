@@ -0,0 +1,124 @@
+package output
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// DefaultWebhookRetryDelay is the delay before the first retry of
+// PostWebhook, doubled after each retry.
+const DefaultWebhookRetryDelay = time.Second
+
+// WebhookOptions controls how PostWebhook sends reports.
+type WebhookOptions struct {
+	// Client is the HTTP client used to post the
+	// report, defaults to http.DefaultClient.
+	Client *http.Client
+
+	// Headers are added to the request, e.g. an Authorization
+	// header. The Content-Type defaults to application/json.
+	Headers map[string]string
+
+	// Template shapes the payload, e.g. as a Slack message. It's
+	// a text/template executed with the report and TemplateFuncs,
+	// e.g. `json` encoding values as JSON. The report is encoded
+	// as JSON if empty.
+	Template string
+
+	// Retries is the number of times the request is retried
+	// if it fails with a network error, a 5xx or a 429 status,
+	// waiting RetryDelay (doubled each time) between attempts.
+	Retries    int
+	RetryDelay time.Duration
+}
+
+// PostWebhook posts report to the webhook at url, as JSON or
+// shaped by the options' template, retrying failed requests.
+func PostWebhook(ctx context.Context, url string, report Report, opts WebhookOptions) error {
+	body, err := webhookPayload(report, opts.Template)
+	if err != nil {
+		return fmt.Errorf("post webhook: %w", err)
+	}
+
+	client := opts.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	delay := opts.RetryDelay
+	if delay <= 0 {
+		delay = DefaultWebhookRetryDelay
+	}
+
+	for attempt := 0; ; attempt++ {
+		retry, err := postWebhook(ctx, client, url, body, opts.Headers)
+		if err == nil {
+			return nil
+		} else if !retry || attempt >= opts.Retries {
+			return fmt.Errorf("post webhook: %w", err)
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return fmt.Errorf("post webhook: %w", ctx.Err())
+		}
+
+		delay *= 2
+	}
+}
+
+// postWebhook does a single request and returns whether
+// it can be retried if it's unsuccessful.
+func postWebhook(ctx context.Context, client *http.Client, url string, body []byte, headers map[string]string) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+
+	req.Header.Set("User-Agent", "reposaur")
+	req.Header.Set("Content-Type", "application/json")
+
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
+		return false, nil
+	}
+
+	retry := resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests
+
+	return retry, fmt.Errorf("unexpected status %d", resp.StatusCode)
+}
+
+func webhookPayload(report Report, tmpl string) ([]byte, error) {
+	if tmpl == "" {
+		return json.Marshal(report)
+	}
+
+	t, err := parseTemplate("webhook", tmpl)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := &bytes.Buffer{}
+	if err := t.Execute(buf, report); err != nil {
+		return nil, fmt.Errorf("execute template: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,106 @@
+package output_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/reposaur/reposaur/pkg/output"
+)
+
+func TestPostWebhook(t *testing.T) {
+	report := newTestReport(map[string]bool{"a": true, "b": false})
+
+	var attempts int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("expected the authorization header, got '%s'", r.Header.Get("Authorization"))
+		}
+
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("expected a JSON content type, got '%s'", r.Header.Get("Content-Type"))
+		}
+
+		var got output.Report
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+			return
+		}
+
+		if len(got.Results) != 2 || got.Results["repository/violation/a"].Passed {
+			t.Errorf("expected the report as payload, got %+v", got.Results)
+		}
+	}))
+	defer srv.Close()
+
+	err := output.PostWebhook(context.Background(), srv.URL, report, output.WebhookOptions{
+		Headers:    map[string]string{"Authorization": "Bearer secret"},
+		Retries:    2,
+		RetryDelay: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if attempts != 3 {
+		t.Errorf("expected 3 attempts, got %d", attempts)
+	}
+}
+
+func TestPostWebhookTemplate(t *testing.T) {
+	report := newTestReport(map[string]bool{"a": true, "b": false})
+
+	var payload string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		payload = string(b)
+	}))
+	defer srv.Close()
+
+	tmpl := `{"text": {{ json (printf "Passed: %v" .Passed) }}, "blocks": [` +
+		`{{ range $i, $r := .SortedResults }}{{ if $i }}, {{ end }}{{ json $r.Status }}{{ end }}]}`
+
+	err := output.PostWebhook(context.Background(), srv.URL, report, output.WebhookOptions{Template: tmpl})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"text": "Passed: false", "blocks": ["failed", "passed"]}`
+	if payload != expected {
+		t.Errorf("expected payload %s, got %s", expected, payload)
+	}
+}
+
+func TestPostWebhookClientError(t *testing.T) {
+	var attempts int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	err := output.PostWebhook(context.Background(), srv.URL, newTestReport(nil), output.WebhookOptions{
+		Retries:    3,
+		RetryDelay: time.Millisecond,
+	})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	if attempts != 1 {
+		t.Errorf("expected client errors not to be retried, got %d attempts", attempts)
+	}
+}
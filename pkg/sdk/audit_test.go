@@ -0,0 +1,51 @@
+package sdk_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/reposaur/reposaur/pkg/output"
+	"github.com/reposaur/reposaur/pkg/sdk"
+)
+
+func TestCheckWithAuditTrail(t *testing.T) {
+	client := newStubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"enforce_admins": map[string]interface{}{"enabled": true},
+		})
+	}))
+
+	ctx := context.Background()
+	trail := output.NewAuditTrail()
+
+	rs, err := sdk.New(ctx, []string{"testdata/cache"}, sdk.WithHTTPClient(client), sdk.WithAuditTrail(trail))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := rs.Check(ctx, "repository", newRepos(1)[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	decisions := trail.Decisions()
+	if len(decisions) != 1 {
+		t.Fatalf("expected 1 decision, got %d", len(decisions))
+	}
+
+	d := decisions[0]
+
+	if len(d.Rules) != 1 || d.Rules[0].UID != "repository/violation/unprotected_branch" || d.Rules[0].Status != output.StatusPassed {
+		t.Errorf("unexpected rules %+v", d.Rules)
+	}
+
+	if len(d.Requests) != 1 {
+		t.Fatalf("expected 1 request, got %+v", d.Requests)
+	}
+
+	if r := d.Requests[0]; r.Method != http.MethodGet || !strings.HasSuffix(r.URL, "/branches/main/protection") || r.StatusCode != http.StatusOK {
+		t.Errorf("unexpected request %+v", r)
+	}
+}
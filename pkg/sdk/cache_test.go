@@ -0,0 +1,97 @@
+package sdk_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/reposaur/reposaur/pkg/cache"
+	"github.com/reposaur/reposaur/pkg/sdk"
+)
+
+func TestCheckWithCache(t *testing.T) {
+	var calls int64
+
+	client := newStubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&calls, 1)
+
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"enforce_admins": map[string]interface{}{"enabled": true},
+		})
+	}))
+
+	ctx := context.Background()
+
+	rs, err := sdk.New(ctx, []string{"testdata/cache"}, sdk.WithHTTPClient(client), sdk.WithCache(cache.NewMemory(0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo := newRepos(1)[0]
+
+	for i := 0; i < 3; i++ {
+		report, err := rs.Check(ctx, "repository", repo)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		result, ok := report.Results["repository/violation/unprotected_branch"]
+		if !ok || !result.Passed {
+			t.Errorf("expected check %d to pass, got %v", i, result)
+		}
+	}
+
+	if calls := atomic.LoadInt64(&calls); calls != 1 {
+		t.Errorf("expected 1 request across checks, got %d", calls)
+	}
+}
+
+func TestCheckAllSharesCache(t *testing.T) {
+	var calls int64
+
+	client := newStubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&calls, 1)
+
+		// overlap the requests of both namespaces
+		time.Sleep(20 * time.Millisecond)
+
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"enforce_admins": map[string]interface{}{"enabled": true},
+		})
+	}))
+
+	ctx := context.Background()
+
+	rs, err := sdk.New(ctx, []string{"testdata/checkall"}, sdk.WithHTTPClient(client))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reports, err := rs.CheckAll(ctx, newRepos(1)[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"repository":        "repository/violation/unprotected_branch",
+		"branch_protection": "branch_protection/violation/admins_not_enforced",
+	}
+
+	if len(reports) != len(expected) {
+		t.Fatalf("expected %d reports, got %d", len(expected), len(reports))
+	}
+
+	for ns, uid := range expected {
+		result, ok := reports[ns].Results[uid]
+		if !ok || !result.Passed {
+			t.Errorf("expected %s to pass, got %v", uid, result)
+		}
+	}
+
+	if calls := atomic.LoadInt64(&calls); calls != 1 {
+		t.Errorf("expected 1 request across namespaces, got %d", calls)
+	}
+}
@@ -0,0 +1,52 @@
+package sdk_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/reposaur/reposaur/pkg/cassette"
+	"github.com/reposaur/reposaur/pkg/sdk"
+)
+
+func TestWithInterceptor(t *testing.T) {
+	repo := newRepos(1)[0]
+
+	c := &cassette.Cassette{
+		Interactions: []cassette.Interaction{
+			{
+				Request: cassette.Request{
+					Method: http.MethodGet,
+					URL:    "/repos/reposaur/repo-0/branches/main/protection",
+				},
+				Response: cassette.Response{
+					StatusCode: http.StatusNotFound,
+					Body:       `{"message": "Branch not protected"}`,
+				},
+			},
+		},
+	}
+
+	ctx := context.Background()
+
+	rs, err := sdk.New(
+		ctx,
+		[]string{"testdata/network"},
+		sdk.WithHTTPClient(&http.Client{}),
+		sdk.WithInterceptor(func(http.RoundTripper) http.RoundTripper {
+			return cassette.NewReplayer(c)
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := rs.Check(ctx, "repository", repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result := report.Results["repository/violation/unprotected_branch"]; result.Passed {
+		t.Error("expected unprotected_branch to fail with the replayed response")
+	}
+}
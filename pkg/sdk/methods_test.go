@@ -0,0 +1,116 @@
+package sdk_test
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/reposaur/reposaur/pkg/sdk"
+)
+
+const mutatingPolicy = `
+package repository
+
+violation_issue_created {
+	resp := github.request("POST /repos/{owner}/{repo}/issues", {
+		"owner": input.owner.login,
+		"repo": input.name,
+		"title": "Created by a policy",
+	})
+
+	resp.status == 201
+}
+`
+
+const graphqlMutatingPolicy = `
+package repository
+
+violation_starred {
+	resp := github.graphql("mutation($id: ID!) { addStar(input: {starrableId: $id}) { clientMutationId } }", {
+		"id": input.name,
+	})
+
+	resp.statusCode == 200
+}
+`
+
+func TestCheckReadOnly(t *testing.T) {
+	var calls int64
+
+	client := newStubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&calls, 1)
+
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"number": 1}`))
+	}))
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "policy.rego"), []byte(mutatingPolicy), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+
+	rs, err := sdk.New(ctx, []string{dir}, sdk.WithHTTPClient(client), sdk.WithAllowedMethods(sdk.ReadOnlyMethods...))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = rs.Check(ctx, "repository", newRepos(1)[0])
+	if err == nil || !strings.Contains(err.Error(), "method POST isn't allowed") {
+		t.Errorf("expected the POST request to be blocked, got %v", err)
+	}
+
+	if calls := atomic.LoadInt64(&calls); calls != 0 {
+		t.Errorf("expected no requests, got %d", calls)
+	}
+
+	// every method is allowed by default
+	rs, err = sdk.New(ctx, []string{dir}, sdk.WithHTTPClient(client))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := rs.Check(ctx, "repository", newRepos(1)[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result := report.Results["repository/violation/issue_created"]; result == nil || !result.Failed() {
+		t.Errorf("expected the POST request to be done, got %+v", result)
+	}
+}
+
+func TestCheckReadOnlyGraphQLMutation(t *testing.T) {
+	var calls int64
+
+	client := newStubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&calls, 1)
+		_, _ = w.Write([]byte(`{"data": {}}`))
+	}))
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "policy.rego"), []byte(graphqlMutatingPolicy), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+
+	rs, err := sdk.New(ctx, []string{dir}, sdk.WithHTTPClient(client), sdk.WithAllowedMethods(sdk.ReadOnlyMethods...))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = rs.Check(ctx, "repository", newRepos(1)[0])
+	if err == nil || !strings.Contains(err.Error(), "graphql mutation: method POST isn't allowed") {
+		t.Errorf("expected the mutation to be blocked, got %v", err)
+	}
+
+	if calls := atomic.LoadInt64(&calls); calls != 0 {
+		t.Errorf("expected no requests, got %d", calls)
+	}
+}
@@ -0,0 +1,171 @@
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/reposaur/reposaur/pkg/output"
+)
+
+// enterpriseOrgsQuery lists a page of the
+// organizations of an enterprise.
+const enterpriseOrgsQuery = `query($slug: String!, $cursor: String) {
+	enterprise(slug: $slug) {
+		organizations(first: 100, after: $cursor) {
+			nodes { login }
+			pageInfo { hasNextPage endCursor }
+		}
+	}
+}`
+
+// OrgProperty is the property ScanOrgs and ScanEnterprise
+// tag the results of every repository with.
+const OrgProperty = "org"
+
+// ScanOrgs executes the repository policies against every repository
+// (or a sample, see WithSampling) of each of orgs, as a single scan:
+// at most the configured concurrency of repositories are checked at a
+// time across every organization (see WithConcurrency). The reports
+// of the repositories are merged into a single report with
+// output.MergeReports, with every result tagged with the properties
+// of its repository and its organization (see OrgProperty).
+func (sdk Reposaur) ScanOrgs(ctx context.Context, orgs []string) (output.Report, error) {
+	var (
+		repos  []interface{}
+		repoOf []string
+		seen   = map[string]bool{}
+	)
+
+	for _, org := range orgs {
+		if seen[org] {
+			continue
+		}
+
+		seen[org] = true
+
+		orgRepos, err := sdk.orgRepos(ctx, org)
+		if err != nil {
+			return output.Report{}, fmt.Errorf("scan orgs: %w", err)
+		}
+
+		for range orgRepos {
+			repoOf = append(repoOf, org)
+		}
+
+		repos = append(repos, orgRepos...)
+	}
+
+	reports, err := sdk.CheckMany(ctx, "repository", repos)
+	if err != nil {
+		return output.Report{}, fmt.Errorf("scan orgs: %w", err)
+	}
+
+	for i, report := range reports {
+		if report.Properties == nil {
+			report.Properties = output.ReportProperties{}
+		}
+
+		report.Properties[OrgProperty] = repoOf[i]
+		reports[i] = report
+	}
+
+	return output.MergeReports(reports), nil
+}
+
+// ScanEnterprise executes the repository policies against the
+// repositories of every organization of the enterprise with the
+// slug, using ScanOrgs.
+func (sdk Reposaur) ScanEnterprise(ctx context.Context, slug string) (output.Report, error) {
+	orgs, err := sdk.listEnterpriseOrgs(ctx, slug)
+	if err != nil {
+		return output.Report{}, fmt.Errorf("scan enterprise: %w", err)
+	}
+
+	sdk.logger.Info().
+		Str("enterprise", slug).
+		Strs("orgs", orgs).
+		Msgf("scanning %d organizations", len(orgs))
+
+	return sdk.ScanOrgs(ctx, orgs)
+}
+
+// listEnterpriseOrgs returns the logins of the organizations
+// of the enterprise with the slug, using the GraphQL API as
+// the REST API doesn't list them.
+func (sdk Reposaur) listEnterpriseOrgs(ctx context.Context, slug string) ([]string, error) {
+	var (
+		orgs   []string
+		cursor *string
+	)
+
+	for {
+		body, err := json.Marshal(map[string]interface{}{
+			"query":     enterpriseOrgsQuery,
+			"variables": map[string]interface{}{"slug": slug, "cursor": cursor},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, "/graphql", bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("User-Agent", "reposaur")
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := sdk.httpClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		var page struct {
+			Data struct {
+				Enterprise *struct {
+					Organizations struct {
+						Nodes []struct {
+							Login string `json:"login"`
+						} `json:"nodes"`
+						PageInfo struct {
+							HasNextPage bool   `json:"hasNextPage"`
+							EndCursor   string `json:"endCursor"`
+						} `json:"pageInfo"`
+					} `json:"organizations"`
+				} `json:"enterprise"`
+			} `json:"data"`
+			Errors []struct {
+				Message string `json:"message"`
+			} `json:"errors"`
+		}
+
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("list %s organizations: unexpected status %d", slug, resp.StatusCode)
+		} else if err != nil {
+			return nil, err
+		} else if len(page.Errors) > 0 {
+			return nil, fmt.Errorf("list %s organizations: %s", slug, page.Errors[0].Message)
+		} else if page.Data.Enterprise == nil {
+			return nil, fmt.Errorf("list %s organizations: enterprise not found", slug)
+		}
+
+		conn := page.Data.Enterprise.Organizations
+
+		for _, node := range conn.Nodes {
+			orgs = append(orgs, node.Login)
+		}
+
+		if !conn.PageInfo.HasNextPage {
+			return orgs, nil
+		}
+
+		next := conn.PageInfo.EndCursor
+		cursor = &next
+	}
+}
@@ -0,0 +1,169 @@
+package sdk_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/reposaur/reposaur/pkg/sdk"
+)
+
+// newOrgRepos returns n repositories owned by org.
+func newOrgRepos(org string, n int) []interface{} {
+	var repos []interface{}
+
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("repo-%d", i)
+
+		repos = append(repos, map[string]interface{}{
+			"name":           name,
+			"full_name":      org + "/" + name,
+			"owner":          map[string]interface{}{"login": org},
+			"default_branch": "main",
+			"visibility":     "internal",
+			"description":    "A repository",
+		})
+	}
+
+	return repos
+}
+
+// newEnterpriseStubClient serves the organizations of the acme
+// enterprise, a page at a time, and the repositories of each one.
+func newEnterpriseStubClient(t *testing.T, repos map[string][]interface{}) *http.Client {
+	pages := [][]string{{"alpha"}, {"beta"}}
+
+	return newStubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/graphql" {
+			var body struct {
+				Variables struct {
+					Slug   string  `json:"slug"`
+					Cursor *string `json:"cursor"`
+				} `json:"variables"`
+			}
+
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			if body.Variables.Slug != "acme" {
+				_, _ = w.Write([]byte(`{"data": {"enterprise": null}, "errors": [{"message": "Could not resolve to an Enterprise"}]}`))
+				return
+			}
+
+			page := 0
+			if body.Variables.Cursor != nil {
+				page = 1
+			}
+
+			var nodes []map[string]string
+			for _, login := range pages[page] {
+				nodes = append(nodes, map[string]string{"login": login})
+			}
+
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{
+					"enterprise": map[string]interface{}{
+						"organizations": map[string]interface{}{
+							"nodes":    nodes,
+							"pageInfo": map[string]interface{}{"hasNextPage": page == 0, "endCursor": "cursor"},
+						},
+					},
+				},
+			})
+
+			return
+		}
+
+		org := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/orgs/"), "/repos")
+
+		orgRepos, ok := repos[org]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(orgRepos)
+	}))
+}
+
+func TestScanOrgs(t *testing.T) {
+	repos := map[string][]interface{}{
+		"alpha": newOrgRepos("alpha", 2),
+		"beta":  newOrgRepos("beta", 3),
+	}
+
+	ctx := context.Background()
+
+	rs, err := sdk.New(ctx, []string{"testdata/policy"}, sdk.WithHTTPClient(newEnterpriseStubClient(t, repos)), sdk.WithConcurrency(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := rs.ScanOrgs(ctx, []string{"beta", "alpha", "beta"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// each repository's results are kept, sorted by subject
+	expected := []string{"alpha/repo-0", "alpha/repo-1", "beta/repo-0", "beta/repo-1", "beta/repo-2"}
+
+	var got []string
+
+	for _, result := range report.SortedResults() {
+		if result.Rule.ID == "not_internal" {
+			got = append(got, fmt.Sprintf("%s/%s", result.Properties[sdk.OrgProperty], result.Properties["repo"]))
+		}
+	}
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected results of %v, got %v", expected, got)
+	}
+
+	if len(report.Results) != 2*len(expected) {
+		t.Errorf("expected %d results, got %d", 2*len(expected), len(report.Results))
+	}
+
+	if _, err := rs.ScanOrgs(ctx, []string{"alpha", "missing"}); err == nil {
+		t.Error("expected an error for an unknown organization")
+	}
+}
+
+func TestScanEnterprise(t *testing.T) {
+	repos := map[string][]interface{}{
+		"alpha": newOrgRepos("alpha", 1),
+		"beta":  newOrgRepos("beta", 2),
+	}
+
+	ctx := context.Background()
+
+	rs, err := sdk.New(ctx, []string{"testdata/policy"}, sdk.WithHTTPClient(newEnterpriseStubClient(t, repos)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := rs.ScanEnterprise(ctx, "acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	orgs := map[interface{}]int{}
+	for _, result := range report.Results {
+		if result.Rule.ID == "not_internal" {
+			orgs[result.Properties[sdk.OrgProperty]]++
+		}
+	}
+
+	if orgs["alpha"] != 1 || orgs["beta"] != 2 {
+		t.Errorf("expected the repositories of every organization, got %v", orgs)
+	}
+
+	if _, err := rs.ScanEnterprise(ctx, "unknown"); err == nil || !strings.Contains(err.Error(), "Could not resolve") {
+		t.Errorf("expected an error for an unknown enterprise, got %v", err)
+	}
+}
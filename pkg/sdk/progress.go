@@ -0,0 +1,28 @@
+package sdk
+
+import "github.com/reposaur/reposaur/pkg/output"
+
+// Progress describes how far CheckMany (or ScanOrg) is
+// after a subject was checked.
+type Progress struct {
+	// Done is the number of subjects checked so far,
+	// including the one in Report, out of Total.
+	Done  int
+	Total int
+
+	// Report is the report of the latest subject checked.
+	Report output.Report
+}
+
+// ProgressFunc is called by CheckMany after each subject is
+// checked. Calls are made from a single goroutine, in the order
+// subjects finish, so it's safe to update state without locking.
+type ProgressFunc func(Progress)
+
+// WithProgress sets a function called after each subject is
+// checked by CheckMany and ScanOrg, e.g. to display progress.
+func WithProgress(fn ProgressFunc) Option {
+	return func(sdk *Reposaur) {
+		sdk.progress = fn
+	}
+}
@@ -0,0 +1,53 @@
+package sdk_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/reposaur/reposaur/pkg/sdk"
+)
+
+func TestCheckManyProgress(t *testing.T) {
+	const total = 10
+
+	var (
+		calls []sdk.Progress
+		seen  = map[interface{}]int{}
+	)
+
+	progress := func(p sdk.Progress) {
+		calls = append(calls, p)
+		seen[p.Report.Properties["repo"]]++
+	}
+
+	ctx := context.Background()
+
+	rs, err := sdk.New(ctx, []string{"testdata/policy"}, sdk.WithOffline(), sdk.WithConcurrency(4), sdk.WithProgress(progress))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := rs.CheckMany(ctx, "", newRepos(total)); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(calls) != total {
+		t.Fatalf("expected %d progress calls, got %d", total, len(calls))
+	}
+
+	for i, p := range calls {
+		if p.Done != i+1 || p.Total != total {
+			t.Errorf("expected call %d to be %d/%d, got %d/%d", i, i+1, total, p.Done, p.Total)
+		}
+	}
+
+	if len(seen) != total {
+		t.Errorf("expected a call for each of the %d subjects, got %d", total, len(seen))
+	}
+
+	for repo, n := range seen {
+		if n != 1 {
+			t.Errorf("expected a single call for %v, got %d", repo, n)
+		}
+	}
+}
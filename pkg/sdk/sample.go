@@ -0,0 +1,117 @@
+package sdk
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math"
+	"sort"
+)
+
+// Sampling selects a subset of the repositories scanned by
+// ScanOrg. Selection is deterministic: with the same Seed,
+// a repository is either always or never selected, regardless
+// of the other repositories in the organization.
+type Sampling struct {
+	// Rate is the fraction of repositories selected, between
+	// 0 and 1. Zero selects every repository.
+	Rate float64
+
+	// Max is the maximum number of repositories selected.
+	// Zero means no maximum.
+	Max int
+
+	// Seed changes which repositories are selected.
+	Seed int64
+}
+
+// WithSampling makes ScanOrg check only a sample of the
+// organization's repositories, e.g. for quick health checks of
+// very large organizations. The sampled repositories are logged.
+// Rates outside of 0 and 1 and negative maximums are ignored.
+func WithSampling(s Sampling) Option {
+	return func(sdk *Reposaur) {
+		if s.Rate < 0 || s.Rate > 1 {
+			s.Rate = 0
+		}
+
+		if s.Max < 0 {
+			s.Max = 0
+		}
+
+		sdk.sampling = s
+	}
+}
+
+// enabled returns true if the sampling selects
+// a subset of the repositories.
+func (s Sampling) enabled() bool {
+	return (s.Rate > 0 && s.Rate < 1) || s.Max > 0
+}
+
+// sample returns the repositories selected, in the same order. Each
+// repository is ranked by a hash of the seed and its full name, those
+// ranked under the rate are selected, up to max in rank order.
+func (s Sampling) sample(repos []interface{}) []interface{} {
+	if !s.enabled() {
+		return repos
+	}
+
+	type ranked struct {
+		index int
+		rank  uint64
+	}
+
+	var selected []ranked
+
+	threshold := uint64(math.MaxUint64)
+	if s.Rate > 0 && s.Rate < 1 {
+		threshold = uint64(s.Rate * math.MaxUint64)
+	}
+
+	for i, repo := range repos {
+		if rank := s.rank(repoFullName(repo)); rank <= threshold {
+			selected = append(selected, ranked{index: i, rank: rank})
+		}
+	}
+
+	if s.Max > 0 && len(selected) > s.Max {
+		sort.Slice(selected, func(i, j int) bool {
+			return selected[i].rank < selected[j].rank
+		})
+
+		selected = selected[:s.Max]
+	}
+
+	sort.Slice(selected, func(i, j int) bool {
+		return selected[i].index < selected[j].index
+	})
+
+	sampled := make([]interface{}, 0, len(selected))
+	for _, r := range selected {
+		sampled = append(sampled, repos[r.index])
+	}
+
+	return sampled
+}
+
+func (s Sampling) rank(name string) uint64 {
+	h := sha256.New()
+
+	seed := make([]byte, 8)
+	binary.BigEndian.PutUint64(seed, uint64(s.Seed))
+
+	_, _ = h.Write(seed)
+	_, _ = h.Write([]byte(name))
+
+	return binary.BigEndian.Uint64(h.Sum(nil))
+}
+
+func repoFullName(repo interface{}) string {
+	if m, ok := repo.(map[string]interface{}); ok {
+		if name, ok := m["full_name"].(string); ok {
+			return name
+		}
+	}
+
+	return ""
+}
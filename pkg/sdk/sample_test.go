@@ -0,0 +1,109 @@
+package sdk_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/reposaur/reposaur/pkg/output"
+	"github.com/reposaur/reposaur/pkg/sdk"
+	"github.com/rs/zerolog"
+)
+
+func newOrgReposClient(t *testing.T, repos []interface{}) *http.Client {
+	return newStubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/orgs/reposaur/repos" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(repos)
+	}))
+}
+
+func scanSampled(t *testing.T, repos []interface{}, sampling sdk.Sampling) []string {
+	t.Helper()
+
+	ctx := context.Background()
+
+	rs, err := sdk.New(
+		ctx,
+		[]string{"testdata/policy"},
+		sdk.WithHTTPClient(newOrgReposClient(t, repos)),
+		sdk.WithLogger(zerolog.Nop()),
+		sdk.WithSampling(sampling),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reports, err := rs.ScanOrg(ctx, "reposaur")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return reportRepos(reports)
+}
+
+func reportRepos(reports []output.Report) []string {
+	var names []string
+
+	for _, r := range reports {
+		names = append(names, r.Properties["repo"].(string))
+	}
+
+	return names
+}
+
+func TestScanOrgSamplingDeterministic(t *testing.T) {
+	repos := newRepos(200)
+	sampling := sdk.Sampling{Rate: 0.1, Seed: 42}
+
+	first := scanSampled(t, repos, sampling)
+	second := scanSampled(t, repos, sampling)
+
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("expected same sample with the same seed, got %v and %v", first, second)
+	}
+
+	if len(first) == 0 || len(first) > 50 {
+		t.Errorf("expected about 20 repositories sampled, got %d", len(first))
+	}
+
+	other := scanSampled(t, repos, sdk.Sampling{Rate: 0.1, Seed: 7})
+	if reflect.DeepEqual(first, other) {
+		t.Errorf("expected a different sample with a different seed, got %v", other)
+	}
+
+	// a repository's selection doesn't depend on the others
+	subset := scanSampled(t, repos[:100], sampling)
+	for _, name := range subset {
+		found := false
+		for _, n := range first {
+			found = found || n == name
+		}
+
+		if !found {
+			t.Errorf("expected %s to be sampled from every repository too", name)
+		}
+	}
+}
+
+func TestScanOrgSamplingMax(t *testing.T) {
+	repos := newRepos(50)
+
+	sampled := scanSampled(t, repos, sdk.Sampling{Max: 5, Seed: 1})
+	if len(sampled) != 5 {
+		t.Fatalf("expected 5 repositories sampled, got %d", len(sampled))
+	}
+
+	if again := scanSampled(t, repos, sdk.Sampling{Max: 5, Seed: 1}); !reflect.DeepEqual(sampled, again) {
+		t.Errorf("expected same sample with the same seed, got %v and %v", sampled, again)
+	}
+
+	if all := scanSampled(t, repos, sdk.Sampling{}); len(all) != len(repos) {
+		t.Errorf("expected every repository without sampling, got %d", len(all))
+	}
+}
@@ -0,0 +1,238 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sync"
+
+	"github.com/reposaur/reposaur/pkg/detector"
+	"github.com/reposaur/reposaur/pkg/output"
+)
+
+// DefaultConcurrency is the default maximum number of
+// subjects checked concurrently by CheckMany.
+const DefaultConcurrency = 10
+
+// WithConcurrency sets the maximum number of subjects checked
+// concurrently by CheckMany and ScanOrg. Values lower than 1
+// are ignored.
+func WithConcurrency(n int) Option {
+	return func(sdk *Reposaur) {
+		if n > 0 {
+			sdk.concurrency = n
+		}
+	}
+}
+
+// CheckMany executes the policies against every item in data, with
+// at most the configured concurrency (see WithConcurrency). If
+// namespace is empty, it's detected from each item. Reports are
+// returned in the same order as data.
+//
+// If a progress function is set (see WithProgress), it's called
+// after each item is checked.
+func (sdk Reposaur) CheckMany(ctx context.Context, namespace string, data []interface{}) ([]output.Report, error) {
+	var (
+		wg      = sync.WaitGroup{}
+		sem     = make(chan struct{}, sdk.concurrency)
+		reports = make([]output.Report, len(data))
+		errs    = make([]error, len(data))
+	)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	checked, stopProgress := sdk.startProgress(len(data))
+
+	for i, d := range data {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			stopProgress()
+			return nil, ctx.Err()
+		}
+
+		wg.Add(1)
+
+		go func(i int, d interface{}) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			reports[i], errs[i] = sdk.checkDetect(ctx, namespace, d)
+			if errs[i] != nil {
+				cancel()
+				return
+			}
+
+			if checked != nil {
+				checked <- reports[i]
+			}
+		}(i, d)
+	}
+
+	wg.Wait()
+	stopProgress()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return reports, nil
+}
+
+// startProgress starts a goroutine calling the progress function
+// for every report sent to the returned channel, which is nil if
+// there's no progress function. The returned function closes the
+// channel and waits for the pending calls.
+func (sdk Reposaur) startProgress(total int) (chan<- output.Report, func()) {
+	if sdk.progress == nil {
+		return nil, func() {}
+	}
+
+	var (
+		checked = make(chan output.Report, total)
+		done    = make(chan struct{})
+	)
+
+	go func() {
+		defer close(done)
+
+		n := 0
+		for report := range checked {
+			n++
+			sdk.progress(Progress{Done: n, Total: total, Report: report})
+		}
+	}()
+
+	return checked, func() {
+		close(checked)
+		<-done
+	}
+}
+
+// ScanOrg executes the repository policies against every
+// repository in org, or a sample of them (see WithSampling),
+// using CheckMany.
+func (sdk Reposaur) ScanOrg(ctx context.Context, org string) ([]output.Report, error) {
+	repos, err := sdk.orgRepos(ctx, org)
+	if err != nil {
+		return nil, fmt.Errorf("scan org: %w", err)
+	}
+
+	return sdk.CheckMany(ctx, "repository", repos)
+}
+
+// orgRepos returns the repositories of org to
+// scan, sampled if sampling is enabled.
+func (sdk Reposaur) orgRepos(ctx context.Context, org string) ([]interface{}, error) {
+	repos, err := sdk.listOrgRepos(ctx, org)
+	if err != nil {
+		return nil, err
+	}
+
+	if sdk.sampling.enabled() {
+		total := len(repos)
+		repos = sdk.sampling.sample(repos)
+
+		names := make([]string, 0, len(repos))
+		for _, repo := range repos {
+			names = append(names, repoFullName(repo))
+		}
+
+		sdk.logger.Info().
+			Str("org", org).
+			Int("total", total).
+			Strs("sampled", names).
+			Msgf("sampled %d of %d repositories", len(repos), total)
+	}
+
+	return repos, nil
+}
+
+// checkDetect executes the policies against data, detecting
+// the namespace if empty and the report properties.
+func (sdk Reposaur) checkDetect(ctx context.Context, namespace string, data interface{}) (output.Report, error) {
+	var err error
+
+	if namespace == "" {
+		namespace, err = detector.DetectNamespace(data)
+		if err != nil {
+			return output.Report{}, err
+		}
+	}
+
+	props, err := detector.DetectReportProperties(namespace, data)
+	if err != nil {
+		return output.Report{}, err
+	}
+
+	report, err := sdk.Check(ctx, namespace, data)
+	if err != nil {
+		return output.Report{}, err
+	}
+
+	report.Properties = props
+
+	if sdk.baseline != nil {
+		sdk.baseline.Apply(report)
+	}
+
+	if sdk.readSource != nil {
+		if err := sdk.applyInlineSuppressions(ctx, report); err != nil {
+			return output.Report{}, fmt.Errorf("inline suppressions: %w", err)
+		}
+	}
+
+	return report, nil
+}
+
+var linkNextRegex = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+func (sdk Reposaur) listOrgRepos(ctx context.Context, org string) ([]interface{}, error) {
+	var (
+		repos []interface{}
+		next  = fmt.Sprintf("/orgs/%s/repos?per_page=100", org)
+	)
+
+	for next != "" {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, next, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("User-Agent", "reposaur")
+
+		resp, err := sdk.httpClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		var page []interface{}
+
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("list %s repositories: unexpected status %d", org, resp.StatusCode)
+		} else if err != nil {
+			return nil, err
+		}
+
+		repos = append(repos, page...)
+
+		next = ""
+		if m := linkNextRegex.FindStringSubmatch(resp.Header.Get("Link")); m != nil {
+			next = m[1]
+		}
+	}
+
+	return repos, nil
+}
@@ -0,0 +1,156 @@
+package sdk_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/types"
+	"github.com/reposaur/reposaur/pkg/sdk"
+)
+
+var tracker = struct {
+	sync.Mutex
+	active, max int
+}{}
+
+func init() {
+	rego.RegisterBuiltin1(
+		&rego.Function{
+			Name: "test.track",
+			Decl: types.NewFunction(types.Args(types.A), types.B),
+		},
+		func(_ rego.BuiltinContext, _ *ast.Term) (*ast.Term, error) {
+			tracker.Lock()
+			tracker.active++
+			if tracker.active > tracker.max {
+				tracker.max = tracker.active
+			}
+			tracker.Unlock()
+
+			time.Sleep(5 * time.Millisecond)
+
+			tracker.Lock()
+			tracker.active--
+			tracker.Unlock()
+
+			return ast.BooleanTerm(true), nil
+		},
+	)
+}
+
+type rewriteTransport struct {
+	target *url.URL
+}
+
+func (t rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.URL.Scheme = t.target.Scheme
+	req.URL.Host = t.target.Host
+
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func newStubClient(t *testing.T, handler http.Handler) *http.Client {
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	u, _ := url.Parse(srv.URL)
+
+	return &http.Client{Transport: rewriteTransport{target: u}}
+}
+
+func newRepos(n int) []interface{} {
+	var repos []interface{}
+
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("repo-%d", i)
+
+		repos = append(repos, map[string]interface{}{
+			"name":           name,
+			"full_name":      "reposaur/" + name,
+			"owner":          map[string]interface{}{"login": "reposaur"},
+			"default_branch": "main",
+			"visibility":     "internal",
+			"description":    "A repository",
+		})
+	}
+
+	return repos
+}
+
+func TestCheckManyConcurrencyLimit(t *testing.T) {
+	const limit = 3
+
+	ctx := context.Background()
+
+	rs, err := sdk.New(ctx, []string{"testdata/concurrency"}, sdk.WithOffline(), sdk.WithConcurrency(limit))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reports, err := rs.CheckMany(ctx, "", newRepos(20))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(reports) != 20 {
+		t.Fatalf("expected 20 reports, got %d", len(reports))
+	}
+
+	for i, r := range reports {
+		if expected := fmt.Sprintf("repo-%d", i); r.Properties["repo"] != expected {
+			t.Errorf("expected report %d to be for %s, got %v", i, expected, r.Properties["repo"])
+		}
+	}
+
+	if tracker.max > limit {
+		t.Errorf("expected at most %d concurrent evaluations, got %d", limit, tracker.max)
+	}
+}
+
+func TestScanOrg(t *testing.T) {
+	repos := newRepos(3)
+
+	client := newStubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/orgs/reposaur/repos" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page == 0 {
+			page = 1
+		}
+
+		if page < len(repos) {
+			w.Header().Set("Link", fmt.Sprintf(`</orgs/reposaur/repos?page=%d>; rel="next"`, page+1))
+		}
+
+		_ = json.NewEncoder(w).Encode(repos[page-1 : page])
+	}))
+
+	ctx := context.Background()
+
+	rs, err := sdk.New(ctx, []string{"testdata/policy"}, sdk.WithHTTPClient(client))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reports, err := rs.ScanOrg(ctx, "reposaur")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(reports) != len(repos) {
+		t.Fatalf("expected %d reports, got %d", len(repos), len(reports))
+	}
+}
@@ -2,11 +2,18 @@ package sdk
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
 	"os"
+	"path/filepath"
+	"time"
 
+	"github.com/open-policy-agent/opa/ast"
 	"github.com/reposaur/reposaur/internal/builtins"
 	"github.com/reposaur/reposaur/internal/policy"
+	"github.com/reposaur/reposaur/pkg/cache"
 	"github.com/reposaur/reposaur/pkg/output"
 	"github.com/reposaur/reposaur/pkg/util"
 	"github.com/rs/zerolog"
@@ -20,9 +27,19 @@ type Option func(*Reposaur)
 // started with several options that control configuration, logging and
 // the client to GitHub.
 type Reposaur struct {
-	logger     zerolog.Logger
-	engine     *policy.Engine
-	httpClient *http.Client
+	logger      zerolog.Logger
+	engine      *policy.Engine
+	httpClient  *http.Client
+	engineOpts  []policy.Option
+	builtinOpts []builtins.Option
+	concurrency int
+	offline     bool
+	baseline    *output.Baseline
+	readSource  SourceReader
+	sampling    Sampling
+	progress    ProgressFunc
+
+	interceptors []func(http.RoundTripper) http.RoundTripper
 }
 
 // New returns a new Reposaur instance, loading and
@@ -34,12 +51,11 @@ type Reposaur struct {
 // if Reposaur can find the relevant information in environment
 // variables, namely (in this order of preference):
 //
-//   * A client with a token if:
-//     * `GITHUB_TOKEN` or `GH_TOKEN` is present
-//   * A client authenticated as an installation if all the following are present:
-//     * `GITHUB_APP_ID` or `GH_APP_ID`
-//     * `GITHUB_INSTALLATION_ID` or `GH_INSTALLATION_ID`
-//     * `GITHUB_APP_PRIVAATE_KEY` or `GH_APP_PRIVATE_KEY` (Base64 encoded)
+//   - A client with a token if `GITHUB_TOKEN` or `GH_TOKEN` is present.
+//   - A client authenticated as an installation if `GITHUB_APP_ID` or
+//     `GH_APP_ID`, `GITHUB_INSTALLATION_ID` or `GH_INSTALLATION_ID`, and
+//     `GITHUB_APP_PRIVATE_KEY` or `GH_APP_PRIVATE_KEY` (see
+//     util.LoadPrivateKey) are all present.
 //
 // The default HTTP client will use the default host `api.github.com`. Can
 // be customized using the `GITHUB_HOST` or `GH_HOST` environment variables.
@@ -49,13 +65,20 @@ func New(ctx context.Context, policyPaths []string, opts ...Option) (*Reposaur,
 	logger := zerolog.New(cw).With().Timestamp().Logger()
 
 	sdk := &Reposaur{
-		logger: logger,
+		logger:      logger,
+		concurrency: DefaultConcurrency,
 	}
 
 	for _, opt := range opts {
 		opt(sdk)
 	}
 
+	// offline takes precedence over any
+	// client, whatever the options' order
+	if sdk.offline {
+		sdk.httpClient = util.NewOfflineHTTPClient()
+	}
+
 	if sdk.httpClient == nil {
 		httpClient, err := createClient(ctx, sdk.logger)
 		if err != nil {
@@ -65,11 +88,33 @@ func New(ctx context.Context, policyPaths []string, opts ...Option) (*Reposaur,
 		sdk.httpClient = httpClient
 	}
 
-	builtins.RegisterBuiltins(sdk.httpClient)
+	if len(sdk.interceptors) > 0 {
+		client := *sdk.httpClient
+
+		if client.Transport == nil {
+			client.Transport = http.DefaultTransport
+		}
+
+		for _, intercept := range sdk.interceptors {
+			client.Transport = intercept(client.Transport)
+		}
+
+		sdk.httpClient = &client
+	}
+
+	builtins.RegisterBuiltins(sdk.httpClient, sdk.builtinOpts...)
+
+	// the built-ins are registered globally, so the engine carries
+	// this instance's client and options in case another one
+	// registers them
+	sdk.engineOpts = append(sdk.engineOpts,
+		policy.WithClient(sdk.httpClient),
+		policy.WithBuiltinOptions(sdk.builtinOpts...),
+	)
 
 	var err error
 
-	sdk.engine, err = policy.Load(ctx, policyPaths)
+	sdk.engine, err = policy.Load(ctx, policyPaths, sdk.engineOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -92,6 +137,220 @@ func WithHTTPClient(client *http.Client) Option {
 	}
 }
 
+// WithInterceptor wraps the transport of the HTTP client used by
+// Reposaur's built-in functions with intercept, e.g. to record and
+// replay requests in tests (see the cassette package). Interceptors
+// are applied in order, so the last one sees requests first.
+func WithInterceptor(intercept func(http.RoundTripper) http.RoundTripper) Option {
+	return func(sdk *Reposaur) {
+		sdk.interceptors = append(sdk.interceptors, intercept)
+	}
+}
+
+// WithOffline disables network access. Policies calling built-in
+// functions that do HTTP requests, like `github.request`, fail
+// with util.ErrOffline, even if a client is set with WithHTTPClient.
+func WithOffline() Option {
+	return func(sdk *Reposaur) {
+		sdk.offline = true
+	}
+}
+
+// WithSince makes Reposaur skip data that wasn't pushed or
+// updated after t. See policy.WithSince.
+func WithSince(t time.Time) Option {
+	return func(sdk *Reposaur) {
+		sdk.engineOpts = append(sdk.engineOpts, policy.WithSince(t))
+	}
+}
+
+// WithoutExperimental makes Reposaur skip rules marked
+// as experimental. See policy.WithoutExperimental.
+func WithoutExperimental() Option {
+	return func(sdk *Reposaur) {
+		sdk.engineOpts = append(sdk.engineOpts, policy.WithoutExperimental())
+	}
+}
+
+// WithoutDeprecated makes Reposaur skip rules marked
+// as deprecated. See policy.WithoutDeprecated.
+func WithoutDeprecated() Option {
+	return func(sdk *Reposaur) {
+		sdk.engineOpts = append(sdk.engineOpts, policy.WithoutDeprecated())
+	}
+}
+
+// WithStrict makes New fail if any namespace doesn't have
+// rules with a known kind. See policy.WithStrict.
+func WithStrict() Option {
+	return func(sdk *Reposaur) {
+		sdk.engineOpts = append(sdk.engineOpts, policy.WithStrict())
+	}
+}
+
+// WithRawValues makes the results carry the value of
+// their rule. See policy.WithRawValues.
+func WithRawValues() Option {
+	return func(sdk *Reposaur) {
+		sdk.engineOpts = append(sdk.engineOpts, policy.WithRawValues())
+	}
+}
+
+// WithCache sets a cache shared by every check, consulted
+// by built-in functions like `github.request` before doing
+// requests. See policy.WithCache.
+func WithCache(c cache.Cache) Option {
+	return func(sdk *Reposaur) {
+		sdk.engineOpts = append(sdk.engineOpts, policy.WithCache(c))
+	}
+}
+
+// WithEvalCache memoizes reports in c, so unchanged inputs aren't
+// evaluated again by unchanged policies. See policy.WithEvalCache.
+func WithEvalCache(c cache.Cache) Option {
+	return func(sdk *Reposaur) {
+		sdk.engineOpts = append(sdk.engineOpts, policy.WithEvalCache(c))
+	}
+}
+
+// WithPolicySet loads the policies in paths as a named set,
+// evaluated in isolation from the others. See policy.WithPolicySet.
+func WithPolicySet(name string, paths ...string) Option {
+	return func(sdk *Reposaur) {
+		sdk.engineOpts = append(sdk.engineOpts, policy.WithPolicySet(name, paths...))
+	}
+}
+
+// WithAPIVersion sets the version of the GitHub REST API requested
+// by the built-in functions, builtins.DefaultAPIVersion by default.
+func WithAPIVersion(version string) Option {
+	return func(sdk *Reposaur) {
+		sdk.builtinOpts = append(sdk.builtinOpts, builtins.WithAPIVersion(version))
+	}
+}
+
+// WithNamespaceSecuritySeverity sets the default security severity
+// of the rules in each namespace. See policy.WithNamespaceSecuritySeverity.
+func WithNamespaceSecuritySeverity(severities map[string]string) Option {
+	return func(sdk *Reposaur) {
+		sdk.engineOpts = append(sdk.engineOpts, policy.WithNamespaceSecuritySeverity(severities))
+	}
+}
+
+// WithMaxResponseSize sets the maximum size in bytes of the responses
+// read by `github.request` and `github.graphql`, which fail when it's
+// exceeded, builtins.DefaultMaxResponseSize by default.
+func WithMaxResponseSize(size int64) Option {
+	return func(sdk *Reposaur) {
+		sdk.builtinOpts = append(sdk.builtinOpts, builtins.WithMaxResponseSize(size))
+	}
+}
+
+// WithStatsRetry sets how many times the requests of `github.contributors`
+// and `github.commit_activity` are retried while GitHub is computing the
+// statistics, and the delay before the first retry, doubled after each one.
+// builtins.DefaultStatsRetries and builtins.DefaultStatsRetryDelay by default.
+func WithStatsRetry(retries int, delay time.Duration) Option {
+	return func(sdk *Reposaur) {
+		sdk.builtinOpts = append(sdk.builtinOpts, builtins.WithStatsRetry(retries, delay))
+	}
+}
+
+// DefaultDNSTimeout is a sensible maximum duration of the DNS
+// lookups of `net.is_private_ip`, see WithDNSResolution.
+const DefaultDNSTimeout = builtins.DefaultDNSTimeout
+
+// WithDNSResolution makes `net.is_private_ip` resolve host names,
+// bounding each lookup to timeout (e.g. DefaultDNSTimeout). Host
+// names aren't resolved by default.
+func WithDNSResolution(timeout time.Duration) Option {
+	return func(sdk *Reposaur) {
+		sdk.builtinOpts = append(sdk.builtinOpts, builtins.WithDNSResolution(timeout))
+	}
+}
+
+// WithTrustedKey makes New reject policies that aren't bundles
+// signed with one of the trusted keys. See policy.WithTrustedKey.
+func WithTrustedKey(id, alg, key string) Option {
+	return func(sdk *Reposaur) {
+		sdk.engineOpts = append(sdk.engineOpts, policy.WithTrustedKey(id, alg, key))
+	}
+}
+
+// Builtin is a custom built-in function available to the
+// policies, see WithCustomBuiltins.
+type Builtin = policy.Builtin
+
+// WithCustomBuiltins makes the built-in functions available to the
+// policies of this instance only. See policy.WithCustomBuiltins.
+func WithCustomBuiltins(builtins ...Builtin) Option {
+	return func(sdk *Reposaur) {
+		sdk.engineOpts = append(sdk.engineOpts, policy.WithCustomBuiltins(builtins...))
+	}
+}
+
+// WithCapabilities restricts the built-in functions and features
+// the policies can use. See policy.WithCapabilities.
+func WithCapabilities(c *ast.Capabilities) Option {
+	return func(sdk *Reposaur) {
+		sdk.engineOpts = append(sdk.engineOpts, policy.WithCapabilities(c))
+	}
+}
+
+// WithTimeout sets the maximum duration of each check, marking the
+// rules that aren't evaluated in time as timed out. See policy.WithTimeout.
+func WithTimeout(d time.Duration) Option {
+	return func(sdk *Reposaur) {
+		sdk.engineOpts = append(sdk.engineOpts, policy.WithTimeout(d))
+	}
+}
+
+// ReadOnlyMethods are the HTTP methods of requests that
+// don't mutate anything, see WithAllowedMethods.
+var ReadOnlyMethods = policy.ReadOnlyMethods
+
+// WithAllowedMethods restricts the HTTP methods of the requests the
+// policies can do with github.request, e.g. to ReadOnlyMethods. See
+// policy.WithAllowedMethods.
+func WithAllowedMethods(methods ...string) Option {
+	return func(sdk *Reposaur) {
+		sdk.engineOpts = append(sdk.engineOpts, policy.WithAllowedMethods(methods...))
+	}
+}
+
+// WithUIDFunc sets how the UIDs of the rules are computed, which
+// key them and their results in reports. See policy.WithUIDFunc.
+func WithUIDFunc(f output.UIDFunc) Option {
+	return func(sdk *Reposaur) {
+		sdk.engineOpts = append(sdk.engineOpts, policy.WithUIDFunc(f))
+	}
+}
+
+// WithAuditTrail records every decision of the checks in trail,
+// with the requests done by the built-in functions during it. See
+// policy.WithAuditTrail.
+func WithAuditTrail(trail *output.AuditTrail) Option {
+	return func(sdk *Reposaur) {
+		sdk.engineOpts = append(sdk.engineOpts, policy.WithAuditTrail(trail))
+	}
+}
+
+// WithPrintWriter sets where the output of print calls in
+// the policies is written to, os.Stderr by default.
+func WithPrintWriter(w io.Writer) Option {
+	return func(sdk *Reposaur) {
+		sdk.engineOpts = append(sdk.engineOpts, policy.WithPrintWriter(w))
+	}
+}
+
+// WithBaseline makes Reposaur suppress the failing results
+// that are known in baseline, so only new findings surface.
+func WithBaseline(baseline output.Baseline) Option {
+	return func(sdk *Reposaur) {
+		sdk.baseline = &baseline
+	}
+}
+
 // Logger returns Reposaur's logger.
 func (sdk Reposaur) Logger() zerolog.Logger {
 	return sdk.logger
@@ -117,6 +376,126 @@ func (sdk Reposaur) Check(ctx context.Context, namespace string, data interface{
 	return report, nil
 }
 
+// CheckAll executes the policies of every namespace against data,
+// returning the reports by namespace. See policy.Engine.CheckAll.
+func (sdk Reposaur) CheckAll(ctx context.Context, data interface{}) (map[string]output.Report, error) {
+	return sdk.engine.CheckAll(ctx, data)
+}
+
+// CheckAggregate executes the policies loaded with namespace once
+// against the whole collection of data.
+func (sdk Reposaur) CheckAggregate(ctx context.Context, namespace string, data []interface{}) (output.Report, error) {
+	return sdk.engine.CheckAggregate(ctx, namespace, data)
+}
+
+// Recheck executes only the policies that failed in prior against
+// data. See policy.Engine.Recheck.
+func (sdk Reposaur) Recheck(ctx context.Context, namespace string, data interface{}, prior output.Report) (output.Report, error) {
+	return sdk.engine.Recheck(ctx, namespace, data, prior)
+}
+
+// Validate evaluates every policy against each of the samples
+// and returns the evaluation errors. See policy.Engine.Validate.
+func (sdk Reposaur) Validate(ctx context.Context, samples []interface{}) []policy.ValidationError {
+	return sdk.engine.Validate(ctx, samples)
+}
+
+// CheckDir executes the policies against the data in every JSON
+// file in dir, e.g. an export of repositories' metadata. Reports are
+// keyed by file name. If namespace is empty, it's detected from the
+// data in each file.
+func (sdk Reposaur) CheckDir(ctx context.Context, namespace, dir string) (map[string]output.Report, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	reports := map[string]output.Report{}
+
+	for _, path := range paths {
+		report, err := sdk.checkFile(ctx, namespace, path)
+		if err != nil {
+			return nil, fmt.Errorf("check %s: %w", path, err)
+		}
+
+		reports[filepath.Base(path)] = report
+	}
+
+	return reports, nil
+}
+
+// CheckGlob executes the policies against the data in every file
+// matching pattern (e.g. repos/*.json), each a JSON document. Reports
+// are keyed by the path of the file, as matched by pattern. Files that
+// can't be read or aren't valid JSON don't abort the batch, their
+// errors are returned keyed by path instead. If namespace is empty,
+// it's detected from the data in each file. It fails if pattern
+// doesn't match any file.
+func (sdk Reposaur) CheckGlob(ctx context.Context, namespace, pattern string) (map[string]output.Report, map[string]error, error) {
+	paths, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(paths) == 0 {
+		return nil, nil, fmt.Errorf("no files match %s", pattern)
+	}
+
+	var (
+		reports   = map[string]output.Report{}
+		malformed = map[string]error{}
+	)
+
+	for _, path := range paths {
+		data, err := readJSONFile(path)
+		if err != nil {
+			malformed[path] = err
+			continue
+		}
+
+		report, err := sdk.checkDetect(ctx, namespace, data)
+		if err != nil {
+			return nil, nil, fmt.Errorf("check %s: %w", path, err)
+		}
+
+		reports[path] = report
+	}
+
+	return reports, malformed, nil
+}
+
+func (sdk Reposaur) checkFile(ctx context.Context, namespace, path string) (output.Report, error) {
+	data, err := readJSONFile(path)
+	if err != nil {
+		return output.Report{}, err
+	}
+
+	return sdk.checkDetect(ctx, namespace, data)
+}
+
+// readJSONFile decodes the JSON document in the file at path,
+// which must not be followed by anything else.
+func readJSONFile(path string) (interface{}, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var data interface{}
+
+	dec := json.NewDecoder(f)
+	if err := dec.Decode(&data); err != nil {
+		return nil, err
+	}
+
+	if err := dec.Decode(&struct{}{}); err != io.EOF {
+		return nil, fmt.Errorf("unexpected data after the JSON document")
+	}
+
+	return data, nil
+}
+
 func createClient(ctx context.Context, logger zerolog.Logger) (*http.Client, error) {
 	token := util.GetEnv(
 		"GITHUB_TOKEN",
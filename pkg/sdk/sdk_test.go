@@ -0,0 +1,253 @@
+package sdk_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/reposaur/reposaur/pkg/output"
+	"github.com/reposaur/reposaur/pkg/sdk"
+	"github.com/reposaur/reposaur/pkg/util"
+)
+
+func TestCheckDirOffline(t *testing.T) {
+	ctx := context.Background()
+
+	rs, err := sdk.New(ctx, []string{"testdata/policy"}, sdk.WithOffline())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reports, err := rs.CheckDir(ctx, "", "testdata/offline")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]map[string]bool{
+		"internal.json": {
+			"repository/violation/not_internal": true,
+			"repository/warn/description_empty": true,
+		},
+		"public.json": {
+			"repository/violation/not_internal": false,
+			"repository/warn/description_empty": false,
+		},
+	}
+
+	if len(reports) != len(expected) {
+		t.Fatalf("expected %d reports, got %d", len(expected), len(reports))
+	}
+
+	for file, results := range expected {
+		report, ok := reports[file]
+		if !ok {
+			t.Fatalf("expected report for %s", file)
+		}
+
+		if repo := report.Properties["repo"]; repo != strings.TrimSuffix(file, ".json") {
+			t.Errorf("expected %s report properties to have repo, got %v", file, repo)
+		}
+
+		for uid, passed := range results {
+			if report.Results[uid].Passed != passed {
+				t.Errorf("expected %s %s passed to be %v", file, uid, passed)
+			}
+		}
+	}
+}
+
+func TestCheckDirOfflineRequestFails(t *testing.T) {
+	ctx := context.Background()
+
+	rs, err := sdk.New(ctx, []string{"testdata/network"}, sdk.WithOffline())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = rs.CheckDir(ctx, "", "testdata/offline")
+	if err == nil {
+		t.Fatal("expected error calling github.request in offline mode")
+	}
+
+	if !strings.Contains(err.Error(), util.ErrOffline.Error()) {
+		t.Errorf("expected offline error, got %s", err)
+	}
+}
+
+func TestOfflineTakesPrecedenceOverClient(t *testing.T) {
+	ctx := context.Background()
+
+	client := newStubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("expected no requests, got %s", r.URL)
+	}))
+
+	// the client is set after going offline
+	rs, err := sdk.New(ctx, []string{"testdata/network"}, sdk.WithOffline(), sdk.WithHTTPClient(client))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = rs.CheckDir(ctx, "", "testdata/offline")
+	if err == nil || !strings.Contains(err.Error(), util.ErrOffline.Error()) {
+		t.Errorf("expected offline error, got %v", err)
+	}
+}
+
+func TestInstancesKeepTheirOptions(t *testing.T) {
+	ctx := context.Background()
+
+	newInstance := func(version string) (*sdk.Reposaur, *[]string) {
+		var (
+			mu       sync.Mutex
+			versions []string
+		)
+
+		client := newStubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			versions = append(versions, r.Header.Get("X-GitHub-Api-Version"))
+			mu.Unlock()
+
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"enforce_admins": map[string]interface{}{"enabled": true},
+			})
+		}))
+
+		rs, err := sdk.New(ctx, []string{"testdata/cache"}, sdk.WithHTTPClient(client), sdk.WithAPIVersion(version))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return rs, &versions
+	}
+
+	// the second instance registers the built-ins last
+	first, firstVersions := newInstance("2022-11-28")
+	second, secondVersions := newInstance("2023-01-01")
+
+	repo := newRepos(1)[0]
+
+	for _, rs := range []*sdk.Reposaur{first, second} {
+		if _, err := rs.Check(ctx, "repository", repo); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := *firstVersions; !reflect.DeepEqual(got, []string{"2022-11-28"}) {
+		t.Errorf("expected the first instance to request version 2022-11-28, got %v", got)
+	}
+
+	if got := *secondVersions; !reflect.DeepEqual(got, []string{"2023-01-01"}) {
+		t.Errorf("expected the second instance to request version 2023-01-01, got %v", got)
+	}
+}
+
+func TestCheckDirBaseline(t *testing.T) {
+	ctx := context.Background()
+
+	rs, err := sdk.New(ctx, []string{"testdata/policy"}, sdk.WithOffline())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reports, err := rs.CheckDir(ctx, "", "testdata/offline")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	baseline := output.NewBaseline([]output.Report{reports["public.json"]})
+
+	rs, err = sdk.New(ctx, []string{"testdata/policy"}, sdk.WithOffline(), sdk.WithBaseline(baseline))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reports, err = rs.CheckDir(ctx, "", "testdata/offline")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for uid, result := range reports["public.json"].Results {
+		if !result.Passed && !result.Suppressed {
+			t.Errorf("expected public.json %s to be suppressed", uid)
+		}
+	}
+}
+
+func TestCheckGlob(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"internal.json":  `{"name": "internal", "full_name": "reposaur/internal", "owner": {"login": "reposaur"}, "visibility": "internal"}`,
+		"public.json":    `{"name": "public", "full_name": "reposaur/public", "owner": {"login": "reposaur"}, "visibility": "public", "description": "A repository"}`,
+		"malformed.json": `{"name": "malformed",`,
+		"trailing.json":  `{"name": "trailing"} {`,
+		"ignored.txt":    `{}`,
+	}
+
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rs, err := sdk.New(ctx, []string{"testdata/policy"}, sdk.WithOffline())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reports, malformed, err := rs.CheckGlob(ctx, "", filepath.Join(dir, "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]bool{
+		filepath.Join(dir, "internal.json"): true,
+		filepath.Join(dir, "public.json"):   false,
+	}
+
+	if len(reports) != len(expected) {
+		t.Fatalf("expected %d reports, got %d", len(expected), len(reports))
+	}
+
+	for path, passed := range expected {
+		report, ok := reports[path]
+		if !ok {
+			t.Fatalf("expected report for %s", path)
+		}
+
+		if result := report.Results["repository/violation/not_internal"]; result.Passed != passed {
+			t.Errorf("expected %s passed to be %v", path, passed)
+		}
+	}
+
+	for _, name := range []string{"malformed.json", "trailing.json"} {
+		if malformed[filepath.Join(dir, name)] == nil {
+			t.Errorf("expected %s to be reported as malformed, got %v", name, malformed)
+		}
+	}
+
+	if len(malformed) != 2 {
+		t.Errorf("expected 2 malformed files, got %v", malformed)
+	}
+}
+
+func TestCheckGlobNoMatches(t *testing.T) {
+	ctx := context.Background()
+
+	rs, err := sdk.New(ctx, []string{"testdata/policy"}, sdk.WithOffline())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err = rs.CheckGlob(ctx, "", filepath.Join(t.TempDir(), "*.json"))
+	if err == nil || !strings.Contains(err.Error(), "no files match") {
+		t.Errorf("expected no files match error, got %v", err)
+	}
+}
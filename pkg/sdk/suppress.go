@@ -0,0 +1,73 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/reposaur/reposaur/pkg/output"
+)
+
+// SourceReader reads the content of a file of the subject of
+// report, e.g. a workflow of a repository, at path. It returns
+// nil if the file doesn't exist.
+type SourceReader func(ctx context.Context, report output.Report, path string) ([]byte, error)
+
+// WithInlineSuppressions makes Reposaur read the files that failing
+// results are located in with read, and suppress the results with a
+// suppression comment in the file (see output.ApplyInlineSuppressions).
+func WithInlineSuppressions(read SourceReader) Option {
+	return func(sdk *Reposaur) {
+		sdk.readSource = read
+	}
+}
+
+// DirSourceReader returns a SourceReader reading files relative to
+// dir, e.g. a repository checked out by CI. Every subject's files are
+// read from dir.
+func DirSourceReader(dir string) SourceReader {
+	return func(_ context.Context, _ output.Report, path string) ([]byte, error) {
+		b, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(path)))
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+
+		return b, err
+	}
+}
+
+// applyInlineSuppressions reads the files that failing results of
+// report are located in and applies their suppression comments.
+func (sdk Reposaur) applyInlineSuppressions(ctx context.Context, report output.Report) error {
+	seen := map[string]bool{}
+
+	for _, result := range report.Results {
+		if result.Failed() && result.Location != nil {
+			seen[result.Location.Path] = true
+		}
+	}
+
+	paths := make([]string, 0, len(seen))
+	for path := range seen {
+		paths = append(paths, path)
+	}
+
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		content, err := sdk.readSource(ctx, report, path)
+		if err != nil {
+			return fmt.Errorf("read %s: %w", path, err)
+		}
+
+		if err := output.ApplyInlineSuppressions(report, path, content); err != nil {
+			return fmt.Errorf("suppressions in %s: %w", path, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package sdk_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/reposaur/reposaur/pkg/sdk"
+)
+
+func TestCheckManyInlineSuppressions(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	workflow := "steps:\n" +
+		"  # reposaur:ignore unpinned_action\n" +
+		"  - uses: actions/checkout@v3\n" +
+		"  - uses: actions/setup-go@v3\n"
+
+	if err := os.MkdirAll(filepath.Join(dir, ".github", "workflows"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, ".github", "workflows", "ci.yml"), []byte(workflow), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	rs, err := sdk.New(ctx, []string{"testdata/suppress"}, sdk.WithOffline(), sdk.WithInlineSuppressions(sdk.DirSourceReader(dir)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo := newRepos(1)[0].(map[string]interface{})
+	repo["steps"] = []interface{}{
+		map[string]interface{}{"uses": "actions/checkout@v3", "path": ".github/workflows/ci.yml", "line": 3},
+		map[string]interface{}{"uses": "actions/setup-go@v3", "path": ".github/workflows/ci.yml", "line": 4},
+		map[string]interface{}{"uses": "actions/cache@v3", "path": ".github/workflows/missing.yml", "line": 3},
+	}
+
+	reports, err := rs.CheckMany(ctx, "repository", []interface{}{repo})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	suppressed := map[string]bool{}
+
+	for _, result := range reports[0].Results {
+		if result.Passed {
+			t.Fatalf("expected %s to fail", result.Message)
+		}
+
+		suppressed[result.Message] = result.Suppressed
+	}
+
+	expected := map[string]bool{
+		"actions/checkout@v3 isn't pinned to a commit SHA": true,
+		"actions/setup-go@v3 isn't pinned to a commit SHA": false,
+		"actions/cache@v3 isn't pinned to a commit SHA":    false,
+	}
+
+	for msg, s := range expected {
+		if got, ok := suppressed[msg]; !ok || got != s {
+			t.Errorf("expected %q suppressed to be %v, got %v", msg, s, suppressed)
+		}
+	}
+
+	if reports[0].ExitCode() != 1 {
+		t.Error("expected the unsuppressed results to fail the report")
+	}
+}
@@ -0,0 +1,11 @@
+package branch_protection
+
+violation_admins_not_enforced {
+	resp := github.request("GET /repos/{owner}/{repo}/branches/{branch}/protection", {
+		"owner": input.owner.login,
+		"repo": input.name,
+		"branch": input.default_branch,
+	})
+
+	not resp.body.enforce_admins.enabled
+}
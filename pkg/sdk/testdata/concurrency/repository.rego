@@ -0,0 +1,5 @@
+package repository
+
+violation_tracked {
+	test.track(input.name)
+}
@@ -0,0 +1,9 @@
+package repository
+
+violation_not_internal {
+	input.visibility != "internal"
+}
+
+warn_description_empty {
+	input.description == null
+}
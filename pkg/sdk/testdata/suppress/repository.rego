@@ -0,0 +1,12 @@
+package repository
+
+violation_unpinned_action[result] {
+	step := input.steps[_]
+	not regex.match("@[0-9a-f]{40}$", step.uses)
+
+	result := {
+		"msg": sprintf("%s isn't pinned to a commit SHA", [step.uses]),
+		"path": step.path,
+		"line": step.line,
+	}
+}
@@ -2,8 +2,11 @@ package util
 
 import (
 	"context"
-	"encoding/base64"
+	"errors"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/bradleyfalzon/ghinstallation/v2"
@@ -13,13 +16,25 @@ import (
 )
 
 const (
-	defaultGitHubHost = "api.github.com"
-	retryAfterHeader  = "Retry-After"
+	defaultGitHubHost        = "api.github.com"
+	retryAfterHeader         = "Retry-After"
+	rateLimitRemainingHeader = "X-RateLimit-Remaining"
+	rateLimitResetHeader     = "X-RateLimit-Reset"
 )
 
+// MaxRateLimitRetries is the number of times a request that hit
+// a rate limit is retried, after waiting for it to be reset. The
+// rate limited response is returned once they're exhausted.
+const MaxRateLimitRetries = 3
+
+// ErrOffline happens when a request is done
+// using a client created by NewOfflineHTTPClient.
+var ErrOffline = errors.New("network access is disabled in offline mode")
+
 type githubTransport struct {
 	logger    zerolog.Logger
 	transport http.RoundTripper
+	backoff   *backoff
 }
 
 func (t githubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
@@ -36,39 +51,121 @@ func (t githubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 }
 
 func (t *githubTransport) throttle(req *http.Request) (*http.Response, error) {
-	resp, err := t.transport.RoundTrip(req)
-	if err != nil {
-		return nil, err
+	for attempt := 0; ; attempt++ {
+		// wait for any rate limit hit by concurrent requests,
+		// instead of hitting it again
+		if err := t.backoff.wait(req.Context()); err != nil {
+			return nil, err
+		}
+
+		resp, err := t.transport.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+
+		retryAfter, ok := rateLimitWait(resp)
+		if !ok || attempt >= MaxRateLimitRetries {
+			return resp, nil
+		}
+
+		// requests with a body that can't be
+		// read again can't be retried either
+		if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+			return resp, nil
+		}
+
+		resp.Body.Close()
+
+		t.logger.Info().
+			Str("path", req.URL.Path).
+			Dur("retry after", retryAfter).
+			Int("attempt", attempt+1).
+			Msg("Hit rate limit. Waiting before trying...")
+
+		t.backoff.pause(retryAfter)
+
+		if req.GetBody != nil {
+			if req.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// rateLimitWait returns how long to wait before retrying if
+// the response indicates a secondary (Retry-After) or primary
+// (X-RateLimit-Remaining) rate limit was hit.
+func rateLimitWait(resp *http.Response) (time.Duration, bool) {
+	if v := resp.Header.Get(retryAfterHeader); v != "" {
+		if secs, err := strconv.Atoi(v); err == nil {
+			return time.Duration(secs) * time.Second, true
+		}
+	}
+
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return 0, false
 	}
 
-	retryAfterVal := resp.Header.Get(retryAfterHeader)
-	if retryAfterVal == "" {
-		return resp, nil
+	if resp.Header.Get(rateLimitRemainingHeader) != "0" {
+		return 0, false
 	}
 
-	retryAfter, err := time.ParseDuration(retryAfterVal + "s")
+	reset, err := strconv.ParseInt(resp.Header.Get(rateLimitResetHeader), 10, 64)
 	if err != nil {
-		return nil, err
+		return 0, false
 	}
 
-	logger := t.logger.With().
-		Str("path", req.URL.Path).
-		Dur("retry after", retryAfter).
-		Logger()
+	return time.Until(time.Unix(reset, 0)), true
+}
 
-	logger.Info().Msg("Hit secondary rate limit. Waiting before trying...")
-	time.Sleep(retryAfter)
-	logger.Info().Msg("Continuing...")
+// backoff is shared by every request done by a client, so
+// that a rate limit hit by one of them pauses all of them.
+type backoff struct {
+	mu    sync.Mutex
+	until time.Time
+}
 
-	return t.RoundTrip(req)
+func newBackoff() *backoff {
+	return &backoff{}
+}
+
+func (b *backoff) pause(d time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if until := time.Now().Add(d); until.After(b.until) {
+		b.until = until
+	}
+}
+
+func (b *backoff) wait(ctx context.Context) error {
+	b.mu.Lock()
+	d := time.Until(b.until)
+	b.mu.Unlock()
+
+	if d <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
 // NewTokenHTTPClient creates an http.Client with a
 // oauth2.StaticTokenSource using the provided token.
-func NewTokenHTTPClient(ctx context.Context, logger zerolog.Logger, token string) *http.Client {
+// The underlying transport is created by NewTransport.
+func NewTokenHTTPClient(ctx context.Context, logger zerolog.Logger, token string, opts ...TransportOption) *http.Client {
 	ghTransport := &githubTransport{
 		logger:    logger,
-		transport: http.DefaultTransport,
+		transport: NewTransport(opts...),
+		backoff:   newBackoff(),
 	}
 
 	ctx = context.WithValue(ctx, oauth2.HTTPClient, &http.Client{
@@ -91,28 +188,140 @@ func NewTokenHTTPClient(ctx context.Context, logger zerolog.Logger, token string
 
 // NewInstallationHTTPClient creates an http.Client with authenticated
 // using an app's installation token. The token is refreshed
-// automatically.
+// automatically, including when it's rejected before it's
+// expected to expire (see NewReauthTransport).
 //
-// The Private Key provided must be Base64 encoded.
-func NewInstallationHTTPClient(ctx context.Context, logger zerolog.Logger, appID, installationID int64, appPrivKey string) (*http.Client, error) {
-	// private key is base64 encoded
-	privKey, err := base64.RawStdEncoding.DecodeString(appPrivKey)
+// The Private Key provided can be any spec accepted by LoadPrivateKey.
+// The underlying transport is created by NewTransport.
+func NewInstallationHTTPClient(ctx context.Context, logger zerolog.Logger, appID, installationID int64, appPrivKey string, opts ...TransportOption) (*http.Client, error) {
+	privKey, err := LoadPrivateKey(appPrivKey)
 	if err != nil {
 		return nil, err
 	}
 
 	ghTransport := githubTransport{
 		logger:    logger,
-		transport: http.DefaultTransport,
+		transport: NewTransport(opts...),
+		backoff:   newBackoff(),
 	}
 
-	installationTransport, err := ghinstallation.New(ghTransport, appID, installationID, privKey)
-	if err != nil {
-		return nil, err
-	}
+	appsTransport := ghinstallation.NewAppsTransportFromPrivateKey(ghTransport, appID, privKey)
+
+	// tokens are cached by the installation transport until they
+	// expire, but a token can be revoked or expire mid-run without
+	// the transport noticing, so it's replaced when rejected
+	installationTransport := NewReauthTransport(func() http.RoundTripper {
+		return ghinstallation.NewFromAppsTransport(appsTransport, installationID)
+	})
 
 	cacheTransport := httpcache.NewMemoryCacheTransport()
 	cacheTransport.Transport = installationTransport
 
 	return cacheTransport.Client(), nil
 }
+
+type offlineTransport struct{}
+
+func (offlineTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return nil, ErrOffline
+}
+
+// NewOfflineHTTPClient creates an http.Client that fails
+// every request with ErrOffline.
+func NewOfflineHTTPClient() *http.Client {
+	return &http.Client{
+		Transport: offlineTransport{},
+	}
+}
+
+type clientContextKey struct{}
+
+// NewClientContext returns a copy of ctx carrying client, used by
+// the built-in functions instead of the one they were registered with.
+func NewClientContext(ctx context.Context, client *http.Client) context.Context {
+	return context.WithValue(ctx, clientContextKey{}, client)
+}
+
+// ClientFromContext returns the HTTP client carried by ctx, if any.
+func ClientFromContext(ctx context.Context) (*http.Client, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+
+	client, ok := ctx.Value(clientContextKey{}).(*http.Client)
+
+	return client, ok && client != nil
+}
+
+type allowedMethodsContextKey struct{}
+
+// NewAllowedMethodsContext returns a copy of ctx restricting the
+// HTTP methods of the requests done by github.request to methods.
+func NewAllowedMethodsContext(ctx context.Context, methods []string) context.Context {
+	allowed := make([]string, 0, len(methods))
+	for _, m := range methods {
+		allowed = append(allowed, strings.ToUpper(m))
+	}
+
+	return context.WithValue(ctx, allowedMethodsContextKey{}, allowed)
+}
+
+// AllowedMethodsFromContext returns the HTTP methods allowed
+// by ctx, if it restricts them (see NewAllowedMethodsContext).
+func AllowedMethodsFromContext(ctx context.Context) ([]string, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+
+	methods, ok := ctx.Value(allowedMethodsContextKey{}).([]string)
+
+	return methods, ok
+}
+
+// RequestRecorder records the HTTP requests done by the built-in
+// functions with a context carrying it (see NewRecorderContext).
+type RequestRecorder interface {
+	RecordRequest(req *http.Request, resp *http.Response, err error, started time.Time)
+}
+
+type recorderContextKey struct{}
+
+// NewRecorderContext returns a copy of ctx carrying rec.
+func NewRecorderContext(ctx context.Context, rec RequestRecorder) context.Context {
+	return context.WithValue(ctx, recorderContextKey{}, rec)
+}
+
+// RecorderFromContext returns the request recorder carried by ctx, if any.
+func RecorderFromContext(ctx context.Context) (RequestRecorder, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+
+	rec, ok := ctx.Value(recorderContextKey{}).(RequestRecorder)
+
+	return rec, ok
+}
+
+// NewRecordingTransport wraps transport, or http.DefaultTransport
+// if it's nil, recording every request done with it to rec.
+func NewRecordingTransport(transport http.RoundTripper, rec RequestRecorder) http.RoundTripper {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	return recordingTransport{transport: transport, rec: rec}
+}
+
+type recordingTransport struct {
+	transport http.RoundTripper
+	rec       RequestRecorder
+}
+
+func (t recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	started := time.Now()
+
+	resp, err := t.transport.RoundTrip(req)
+	t.rec.RecordRequest(req, resp, err, started)
+
+	return resp, err
+}
@@ -0,0 +1,87 @@
+package util_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/reposaur/reposaur/pkg/util"
+	"github.com/rs/zerolog"
+)
+
+// newRateLimitedClient returns a token client whose requests are
+// done against a server that always responds with a rate limit,
+// waiting retryAfter, and counts the requests in calls.
+func newRateLimitedClient(t *testing.T, retryAfter string, calls *int64) *http.Client {
+	t.Helper()
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(calls, 1)
+
+		w.Header().Set("Retry-After", retryAfter)
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	t.Cleanup(srv.Close)
+
+	t.Setenv("GITHUB_HOST", strings.TrimPrefix(srv.URL, "https://"))
+
+	trustServer := func(tr *http.Transport) {
+		tr.TLSClientConfig = srv.Client().Transport.(*http.Transport).TLSClientConfig
+	}
+
+	return util.NewTokenHTTPClient(context.Background(), zerolog.Nop(), "token", trustServer)
+}
+
+func TestRateLimitRetriesAreBounded(t *testing.T) {
+	var calls int64
+
+	client := newRateLimitedClient(t, "0", &calls)
+
+	resp, err := client.Get("https://api.github.com/orgs/reposaur")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("expected the rate limited response, got %d", resp.StatusCode)
+	}
+
+	if calls := atomic.LoadInt64(&calls); calls != util.MaxRateLimitRetries+1 {
+		t.Errorf("expected %d requests, got %d", util.MaxRateLimitRetries+1, calls)
+	}
+}
+
+func TestRateLimitWaitIsCancelled(t *testing.T) {
+	var calls int64
+
+	client := newRateLimitedClient(t, "60", &calls)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.github.com/orgs/reposaur", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	started := time.Now()
+
+	_, err = client.Do(req)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the wait to be cancelled, got %v", err)
+	}
+
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Errorf("expected the wait to stop when cancelled, took %s", elapsed)
+	}
+
+	if calls := atomic.LoadInt64(&calls); calls != 1 {
+		t.Errorf("expected 1 request, got %d", calls)
+	}
+}
@@ -0,0 +1,121 @@
+package util
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// KeySourceFunc resolves a private key reference
+// into the key's contents.
+type KeySourceFunc func(ref string) ([]byte, error)
+
+var (
+	keySourcesMu sync.RWMutex
+	keySources   = map[string]KeySourceFunc{
+		"file": os.ReadFile,
+		"env": func(ref string) ([]byte, error) {
+			if v := os.Getenv(ref); v != "" {
+				return []byte(v), nil
+			}
+
+			return nil, fmt.Errorf("environment variable %s is empty", ref)
+		},
+	}
+)
+
+// RegisterKeySource registers a source of private keys for the
+// scheme, e.g. to load keys from a secrets manager with
+// "vault://path/to/key".
+func RegisterKeySource(scheme string, fn KeySourceFunc) {
+	keySourcesMu.Lock()
+	defer keySourcesMu.Unlock()
+
+	keySources[scheme] = fn
+}
+
+// LoadPrivateKey loads a GitHub App's RSA private key from spec,
+// which is one of:
+//
+//   - `file://<path>` to read the key from a file
+//   - `env://<name>` to read the key from an environment variable
+//   - `<scheme>://<ref>` for sources added with RegisterKeySource
+//   - The key itself, PEM or Base64 encoded PEM
+//
+// Keys can be in PKCS#1 or PKCS#8 format.
+func LoadPrivateKey(spec string) (*rsa.PrivateKey, error) {
+	data := []byte(spec)
+
+	if i := strings.Index(spec, "://"); i > 0 {
+		scheme, ref := spec[:i], spec[i+3:]
+
+		keySourcesMu.RLock()
+		fn, ok := keySources[scheme]
+		keySourcesMu.RUnlock()
+
+		if !ok {
+			return nil, fmt.Errorf("load private key: unknown source '%s'", scheme)
+		}
+
+		var err error
+
+		data, err = fn(ref)
+		if err != nil {
+			return nil, fmt.Errorf("load private key: %w", err)
+		}
+	}
+
+	key, err := ParsePrivateKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("load private key: %w", err)
+	}
+
+	return key, nil
+}
+
+// ParsePrivateKey parses a PEM or Base64 encoded PEM RSA
+// private key, in PKCS#1 or PKCS#8 format.
+func ParsePrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+
+	if block == nil {
+		decoded, err := decodeBase64(strings.TrimSpace(string(data)))
+		if err != nil {
+			return nil, errors.New("key is neither PEM nor Base64 encoded")
+		}
+
+		if block, _ = pem.Decode(decoded); block == nil {
+			return nil, errors.New("key is not PEM encoded")
+		}
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, errors.New("key is neither in PKCS#1 nor PKCS#8 format")
+	}
+
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("key is not an RSA private key")
+	}
+
+	return key, nil
+}
+
+func decodeBase64(s string) ([]byte, error) {
+	if b, err := base64.StdEncoding.DecodeString(s); err == nil {
+		return b, nil
+	}
+
+	return base64.RawStdEncoding.DecodeString(s)
+}
@@ -0,0 +1,128 @@
+package util_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/reposaur/reposaur/pkg/util"
+)
+
+func generateKeyPEM(t *testing.T, pkcs8 bool) (*rsa.PrivateKey, []byte) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block := &pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	}
+
+	if pkcs8 {
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		block = &pem.Block{Type: "PRIVATE KEY", Bytes: der}
+	}
+
+	return key, pem.EncodeToMemory(block)
+}
+
+func TestLoadPrivateKeyFromFile(t *testing.T) {
+	for _, pkcs8 := range []bool{false, true} {
+		key, keyPEM := generateKeyPEM(t, pkcs8)
+
+		path := filepath.Join(t.TempDir(), "key.pem")
+		if err := os.WriteFile(path, keyPEM, 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		loaded, err := util.LoadPrivateKey("file://" + path)
+		if err != nil {
+			t.Fatalf("pkcs8=%v: %s", pkcs8, err)
+		}
+
+		if !key.Equal(loaded) {
+			t.Errorf("pkcs8=%v: loaded key doesn't match", pkcs8)
+		}
+	}
+}
+
+func TestLoadPrivateKeyFromBase64Env(t *testing.T) {
+	for _, pkcs8 := range []bool{false, true} {
+		key, keyPEM := generateKeyPEM(t, pkcs8)
+
+		t.Setenv("TEST_APP_PRIVATE_KEY", base64.StdEncoding.EncodeToString(keyPEM))
+
+		loaded, err := util.LoadPrivateKey("env://TEST_APP_PRIVATE_KEY")
+		if err != nil {
+			t.Fatalf("pkcs8=%v: %s", pkcs8, err)
+		}
+
+		if !key.Equal(loaded) {
+			t.Errorf("pkcs8=%v: loaded key doesn't match", pkcs8)
+		}
+	}
+}
+
+func TestLoadPrivateKeyInline(t *testing.T) {
+	key, keyPEM := generateKeyPEM(t, false)
+
+	for _, spec := range []string{
+		string(keyPEM),
+		base64.RawStdEncoding.EncodeToString(keyPEM),
+	} {
+		loaded, err := util.LoadPrivateKey(spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !key.Equal(loaded) {
+			t.Error("loaded key doesn't match")
+		}
+	}
+}
+
+func TestLoadPrivateKeyErrors(t *testing.T) {
+	for _, spec := range []string{
+		"not a key",
+		"unknown://key",
+		"file:///does/not/exist",
+		base64.StdEncoding.EncodeToString([]byte("not a pem")),
+	} {
+		if _, err := util.LoadPrivateKey(spec); err == nil {
+			t.Errorf("expected error loading '%s'", spec)
+		}
+	}
+}
+
+func TestRegisterKeySource(t *testing.T) {
+	key, keyPEM := generateKeyPEM(t, true)
+
+	util.RegisterKeySource("secrets", func(ref string) ([]byte, error) {
+		if ref != "app-key" {
+			t.Errorf("expected ref to be app-key, got %s", ref)
+		}
+
+		return keyPEM, nil
+	})
+
+	loaded, err := util.LoadPrivateKey("secrets://app-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !key.Equal(loaded) {
+		t.Error("loaded key doesn't match")
+	}
+}
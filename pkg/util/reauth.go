@@ -0,0 +1,74 @@
+package util
+
+import (
+	"net/http"
+	"sync"
+)
+
+// reauthTransport does requests with the credentials of a transport
+// created by refresh, e.g. one with a cached installation token. When
+// a request is rejected as unauthorized, i.e. the credentials expired,
+// the transport is replaced by a new one and the request is retried once.
+type reauthTransport struct {
+	mu        sync.Mutex
+	transport http.RoundTripper
+	refresh   func() http.RoundTripper
+}
+
+// NewReauthTransport creates a transport that re-authenticates when
+// a request fails with 401 Unauthorized: it replaces the transport
+// created by refresh with a new one, e.g. with a new installation
+// token, and retries the request once. Concurrent requests rejected
+// with the same credentials trigger a single refresh.
+func NewReauthTransport(refresh func() http.RoundTripper) http.RoundTripper {
+	return &reauthTransport{
+		transport: refresh(),
+		refresh:   refresh,
+	}
+}
+
+func (t *reauthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	current := t.current()
+
+	resp, err := current.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	// requests with a body that can't be
+	// sent again aren't retried
+	if req.Body != nil && req.GetBody == nil {
+		return resp, nil
+	}
+
+	resp.Body.Close()
+
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+
+	return t.reauth(current).RoundTrip(retry)
+}
+
+func (t *reauthTransport) current() http.RoundTripper {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.transport
+}
+
+// reauth replaces the transport if it's still the stale one,
+// and returns the transport to retry the request with.
+func (t *reauthTransport) reauth(stale http.RoundTripper) http.RoundTripper {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.transport == stale {
+		t.transport = t.refresh()
+	}
+
+	return t.transport
+}
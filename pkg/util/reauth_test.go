@@ -0,0 +1,150 @@
+package util_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/reposaur/reposaur/pkg/util"
+)
+
+// tokenTransport authenticates requests with a token,
+// like the transport of an installation does.
+type tokenTransport struct {
+	token string
+}
+
+func (t tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "token "+t.token)
+
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// newReauthTestTransport returns a transport using a new
+// token each time it's refreshed, and the number of times
+// it was refreshed (including the initial token).
+func newReauthTestTransport() (http.RoundTripper, *int32) {
+	var refreshes int32
+
+	tr := util.NewReauthTransport(func() http.RoundTripper {
+		n := atomic.AddInt32(&refreshes, 1)
+		return tokenTransport{token: fmt.Sprint(n)}
+	})
+
+	return tr, &refreshes
+}
+
+func TestReauthTransport(t *testing.T) {
+	var requests int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+
+		// the first token expired mid-run
+		if r.Header.Get("Authorization") == "token 1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		_, _ = w.Write(body)
+	}))
+	defer srv.Close()
+
+	tr, refreshes := newReauthTestTransport()
+	client := &http.Client{Transport: tr}
+
+	resp, err := client.Post(srv.URL, "text/plain", strings.NewReader("reposaur"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+
+	if resp.StatusCode != http.StatusOK || string(body) != "reposaur" {
+		t.Errorf("expected the request to be retried with its body, got %d %q", resp.StatusCode, body)
+	}
+
+	if *refreshes != 2 || requests != 2 {
+		t.Errorf("expected 1 refresh and 2 requests, got %d and %d", *refreshes-1, requests)
+	}
+
+	resp, err = client.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if *refreshes != 2 || requests != 3 {
+		t.Errorf("expected the new token to be reused, got %d refreshes and %d requests", *refreshes-1, requests)
+	}
+}
+
+func TestReauthTransportConcurrent(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "token 1" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer srv.Close()
+
+	tr, refreshes := newReauthTestTransport()
+	client := &http.Client{Transport: tr}
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			resp, err := client.Get(srv.URL)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("expected status 200, got %d", resp.StatusCode)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if *refreshes != 2 {
+		t.Errorf("expected a single refresh, got %d", *refreshes-1)
+	}
+}
+
+func TestReauthTransportUnauthorized(t *testing.T) {
+	var requests int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	tr, _ := newReauthTestTransport()
+	client := &http.Client{Transport: tr}
+
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusUnauthorized || requests != 2 {
+		t.Errorf("expected a single retry returning 401, got %d after %d requests", resp.StatusCode, requests)
+	}
+}
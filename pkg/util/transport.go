@@ -0,0 +1,65 @@
+package util
+
+import (
+	"net/http"
+	"time"
+)
+
+const (
+	// DefaultMaxIdleConnsPerHost is higher than net/http's default
+	// (2), as every request of a scan goes to the same host. With the
+	// default, concurrent requests keep opening new connections.
+	DefaultMaxIdleConnsPerHost = 100
+	DefaultIdleConnTimeout     = 90 * time.Second
+	DefaultTLSHandshakeTimeout = 10 * time.Second
+)
+
+// TransportOption represents a Transport option
+// that can change a particular setting.
+type TransportOption func(*http.Transport)
+
+// NewTransport creates an http.Transport based on http.DefaultTransport
+// tuned for doing many concurrent requests to the GitHub API.
+func NewTransport(opts ...TransportOption) *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+
+	t.MaxIdleConns = DefaultMaxIdleConnsPerHost
+	t.MaxIdleConnsPerHost = DefaultMaxIdleConnsPerHost
+	t.IdleConnTimeout = DefaultIdleConnTimeout
+	t.TLSHandshakeTimeout = DefaultTLSHandshakeTimeout
+
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	return t
+}
+
+// WithMaxIdleConnsPerHost sets the maximum number of idle (keep-alive)
+// connections kept per host. The maximum number of idle connections
+// across every host is raised to n if lower.
+func WithMaxIdleConnsPerHost(n int) TransportOption {
+	return func(t *http.Transport) {
+		t.MaxIdleConnsPerHost = n
+
+		if t.MaxIdleConns != 0 && t.MaxIdleConns < n {
+			t.MaxIdleConns = n
+		}
+	}
+}
+
+// WithIdleConnTimeout sets how long an idle
+// connection is kept before being closed.
+func WithIdleConnTimeout(d time.Duration) TransportOption {
+	return func(t *http.Transport) {
+		t.IdleConnTimeout = d
+	}
+}
+
+// WithTLSHandshakeTimeout sets the maximum
+// time waiting for a TLS handshake.
+func WithTLSHandshakeTimeout(d time.Duration) TransportOption {
+	return func(t *http.Transport) {
+		t.TLSHandshakeTimeout = d
+	}
+}
@@ -0,0 +1,97 @@
+package util_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/reposaur/reposaur/pkg/util"
+)
+
+func TestNewTransport(t *testing.T) {
+	tr := util.NewTransport()
+
+	if tr.MaxIdleConnsPerHost != util.DefaultMaxIdleConnsPerHost {
+		t.Errorf("expected max idle conns per host to be %d, got %d", util.DefaultMaxIdleConnsPerHost, tr.MaxIdleConnsPerHost)
+	}
+
+	if tr.IdleConnTimeout != util.DefaultIdleConnTimeout {
+		t.Errorf("expected idle conn timeout to be %s, got %s", util.DefaultIdleConnTimeout, tr.IdleConnTimeout)
+	}
+
+	if tr.TLSHandshakeTimeout != util.DefaultTLSHandshakeTimeout {
+		t.Errorf("expected TLS handshake timeout to be %s, got %s", util.DefaultTLSHandshakeTimeout, tr.TLSHandshakeTimeout)
+	}
+
+	if tr == http.DefaultTransport {
+		t.Error("expected http.DefaultTransport not to be modified")
+	}
+}
+
+func TestNewTransportOptions(t *testing.T) {
+	tr := util.NewTransport(
+		util.WithMaxIdleConnsPerHost(500),
+		util.WithIdleConnTimeout(time.Minute),
+		util.WithTLSHandshakeTimeout(5*time.Second),
+	)
+
+	if tr.MaxIdleConnsPerHost != 500 || tr.MaxIdleConns != 500 {
+		t.Errorf("expected 500 idle conns (per host), got %d (%d)", tr.MaxIdleConns, tr.MaxIdleConnsPerHost)
+	}
+
+	if tr.IdleConnTimeout != time.Minute {
+		t.Errorf("expected idle conn timeout to be 1m, got %s", tr.IdleConnTimeout)
+	}
+
+	if tr.TLSHandshakeTimeout != 5*time.Second {
+		t.Errorf("expected TLS handshake timeout to be 5s, got %s", tr.TLSHandshakeTimeout)
+	}
+}
+
+// benchmarkTransport does batches of 50 concurrent
+// requests, like a scan of an organization does.
+func benchmarkTransport(b *testing.B, tr *http.Transport) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"name": "reposaur"}`))
+	}))
+	defer srv.Close()
+
+	tr.TLSClientConfig = srv.Client().Transport.(*http.Transport).TLSClientConfig
+	client := &http.Client{Transport: tr}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		var wg sync.WaitGroup
+
+		for j := 0; j < 50; j++ {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				resp, err := client.Get(srv.URL)
+				if err != nil {
+					b.Error(err)
+					return
+				}
+
+				_, _ = io.Copy(io.Discard, resp.Body)
+				resp.Body.Close()
+			}()
+		}
+
+		wg.Wait()
+	}
+}
+
+func BenchmarkDefaultTransport(b *testing.B) {
+	benchmarkTransport(b, http.DefaultTransport.(*http.Transport).Clone())
+}
+
+func BenchmarkTunedTransport(b *testing.B) {
+	benchmarkTransport(b, util.NewTransport())
+}
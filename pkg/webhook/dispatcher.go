@@ -0,0 +1,125 @@
+// Package webhook routes GitHub webhook events
+// to the policy namespaces that check them.
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/reposaur/reposaur/pkg/detector"
+	"github.com/reposaur/reposaur/pkg/output"
+)
+
+// ErrUnroutedEvent is returned by Dispatch when
+// no route matches the event.
+var ErrUnroutedEvent = errors.New("event not routed")
+
+// Checker executes the policies of a namespace against input,
+// e.g. policy.Engine or sdk.Reposaur.
+type Checker interface {
+	Check(ctx context.Context, namespace string, input interface{}) (output.Report, error)
+}
+
+// Route checks an event's payload, or part of it,
+// with the policies of a namespace.
+type Route struct {
+	Namespace string
+
+	// Input is the property of the payload checked, e.g.
+	// "pull_request". The whole payload is checked if empty.
+	Input string
+
+	// Parse builds the input checked from the payload, e.g.
+	// ParseRepositoryEvent. It takes precedence over Input.
+	Parse func(payload map[string]interface{}) (interface{}, error)
+}
+
+// Routes maps event types to the routes that check them. Types are
+// either an event (e.g. "pull_request") or an event and action (e.g.
+// "pull_request.opened"), which takes precedence over the former.
+type Routes map[string][]Route
+
+// Dispatcher checks events with the
+// namespaces they're routed to.
+type Dispatcher struct {
+	checker Checker
+	routes  Routes
+}
+
+// NewDispatcher creates a Dispatcher that checks
+// events with checker according to routes.
+func NewDispatcher(checker Checker, routes Routes) *Dispatcher {
+	return &Dispatcher{
+		checker: checker,
+		routes:  routes,
+	}
+}
+
+// Match returns the routes of an event, given its type (e.g. the
+// X-GitHub-Event header) and the payload's action, if any.
+func (d *Dispatcher) Match(event, action string) []Route {
+	if action != "" {
+		if routes, ok := d.routes[event+"."+action]; ok {
+			return routes
+		}
+	}
+
+	return d.routes[event]
+}
+
+// Dispatch checks the payload of an event with every namespace it's
+// routed to and returns the reports in the routes' order. Reports'
+// properties are detected from the checked input when possible.
+// Returns ErrUnroutedEvent if no route matches the event.
+func (d *Dispatcher) Dispatch(ctx context.Context, event string, payload map[string]interface{}) ([]output.Report, error) {
+	action, _ := payload["action"].(string)
+
+	routes := d.Match(event, action)
+	if len(routes) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrUnroutedEvent, eventType(event, action))
+	}
+
+	reports := make([]output.Report, 0, len(routes))
+
+	for _, route := range routes {
+		var input interface{} = payload
+
+		if route.Parse != nil {
+			v, err := route.Parse(payload)
+			if err != nil {
+				return nil, fmt.Errorf("dispatch %s: %w", eventType(event, action), err)
+			}
+
+			input = v
+		} else if route.Input != "" {
+			v, ok := payload[route.Input]
+			if !ok {
+				return nil, fmt.Errorf("dispatch %s: payload doesn't have %s", eventType(event, action), route.Input)
+			}
+
+			input = v
+		}
+
+		report, err := d.checker.Check(ctx, route.Namespace, input)
+		if err != nil {
+			return nil, fmt.Errorf("dispatch %s: %w", eventType(event, action), err)
+		}
+
+		if props, err := detector.DetectReportProperties(route.Namespace, input); err == nil {
+			report.Properties = props
+		}
+
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
+
+func eventType(event, action string) string {
+	if action == "" {
+		return event
+	}
+
+	return event + "." + action
+}
@@ -0,0 +1,113 @@
+package webhook_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/reposaur/reposaur/pkg/output"
+	"github.com/reposaur/reposaur/pkg/webhook"
+)
+
+type check struct {
+	namespace string
+	input     interface{}
+}
+
+type recordingChecker struct {
+	checks []check
+}
+
+func (c *recordingChecker) Check(_ context.Context, namespace string, input interface{}) (output.Report, error) {
+	c.checks = append(c.checks, check{namespace: namespace, input: input})
+	return output.Report{}, nil
+}
+
+var testRoutes = webhook.Routes{
+	"push":                {{Namespace: "repository", Input: "repository"}},
+	"pull_request":        {{Namespace: "pull_request", Input: "pull_request"}},
+	"pull_request.closed": {{Namespace: "repository", Input: "repository"}},
+	"repository": {
+		{Namespace: "repository", Input: "repository"},
+		{Namespace: "audit"},
+	},
+}
+
+func TestDispatch(t *testing.T) {
+	repo := map[string]interface{}{"name": "test"}
+	pr := map[string]interface{}{"number": 1}
+
+	cases := []struct {
+		event    string
+		payload  map[string]interface{}
+		expected []check
+	}{
+		{
+			event:    "push",
+			payload:  map[string]interface{}{"repository": repo},
+			expected: []check{{"repository", repo}},
+		},
+		{
+			event:    "pull_request",
+			payload:  map[string]interface{}{"action": "opened", "pull_request": pr, "repository": repo},
+			expected: []check{{"pull_request", pr}},
+		},
+		{
+			event:    "pull_request",
+			payload:  map[string]interface{}{"action": "closed", "pull_request": pr, "repository": repo},
+			expected: []check{{"repository", repo}},
+		},
+		{
+			event:   "repository",
+			payload: map[string]interface{}{"action": "publicized", "repository": repo},
+			expected: []check{
+				{"repository", repo},
+				{"audit", map[string]interface{}{"action": "publicized", "repository": repo}},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.event, func(t *testing.T) {
+			checker := &recordingChecker{}
+			d := webhook.NewDispatcher(checker, testRoutes)
+
+			reports, err := d.Dispatch(context.Background(), c.event, c.payload)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(reports) != len(c.expected) {
+				t.Errorf("expected %d reports, got %d", len(c.expected), len(reports))
+			}
+
+			if !reflect.DeepEqual(checker.checks, c.expected) {
+				t.Errorf("expected checks %v, got %v", c.expected, checker.checks)
+			}
+		})
+	}
+}
+
+func TestDispatchUnrouted(t *testing.T) {
+	checker := &recordingChecker{}
+	d := webhook.NewDispatcher(checker, testRoutes)
+
+	_, err := d.Dispatch(context.Background(), "issues", map[string]interface{}{"action": "opened"})
+	if !errors.Is(err, webhook.ErrUnroutedEvent) {
+		t.Errorf("expected ErrUnroutedEvent, got %v", err)
+	}
+
+	if len(checker.checks) != 0 {
+		t.Errorf("expected no checks, got %v", checker.checks)
+	}
+}
+
+func TestDispatchMissingInput(t *testing.T) {
+	d := webhook.NewDispatcher(&recordingChecker{}, testRoutes)
+
+	_, err := d.Dispatch(context.Background(), "push", map[string]interface{}{})
+	if err == nil {
+		t.Error("expected an error for a payload without the route's input")
+	}
+}
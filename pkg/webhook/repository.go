@@ -0,0 +1,110 @@
+package webhook
+
+import (
+	"fmt"
+)
+
+// RepositoryEventNamespace is the namespace checking repository
+// events, e.g. a repository being made public or transferred.
+const RepositoryEventNamespace = "repository_event"
+
+// RepositoryEventActions are the actions of repository
+// events routed by RepositoryEventRoutes.
+var RepositoryEventActions = []string{
+	"created",
+	"deleted",
+	"archived",
+	"unarchived",
+	"publicized",
+	"privatized",
+	"renamed",
+	"transferred",
+}
+
+// RepositoryEventRoutes routes each of the RepositoryEventActions
+// to RepositoryEventNamespace, parsed with ParseRepositoryEvent. They
+// can be added to other routes, e.g. to check the repository itself.
+func RepositoryEventRoutes() Routes {
+	routes := Routes{}
+
+	for _, action := range RepositoryEventActions {
+		routes["repository."+action] = []Route{{
+			Namespace: RepositoryEventNamespace,
+			Parse:     ParseRepositoryEvent,
+		}}
+	}
+
+	return routes
+}
+
+// ParseRepositoryEvent builds the input of RepositoryEventNamespace
+// from the payload of a repository event: its action, the repository
+// as it is after the event, the login of the sender and the previous
+// values of the repository's name, owner and visibility changed by
+// the event, e.g.
+//
+//	{
+//		"action": "transferred",
+//		"repository": {"name": "reposaur", "owner": {"login": "new-owner"}, ...},
+//		"sender": "octocat",
+//		"previous": {"owner": "old-owner"}
+//	}
+func ParseRepositoryEvent(payload map[string]interface{}) (interface{}, error) {
+	action, _ := payload["action"].(string)
+
+	repo, ok := payload["repository"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("parse repository event: payload doesn't have repository")
+	}
+
+	event := map[string]interface{}{
+		"action":     action,
+		"repository": repo,
+		"sender":     lookupString(payload, "sender", "login"),
+		"previous":   map[string]interface{}{},
+	}
+
+	previous := event["previous"].(map[string]interface{})
+
+	switch action {
+	case "renamed":
+		if name := lookupString(payload, "changes", "repository", "name", "from"); name != "" {
+			previous["name"] = name
+		}
+
+	case "transferred":
+		owner := lookupString(payload, "changes", "owner", "from", "organization", "login")
+		if owner == "" {
+			owner = lookupString(payload, "changes", "owner", "from", "user", "login")
+		}
+
+		if owner != "" {
+			previous["owner"] = owner
+		}
+
+	case "publicized":
+		previous["visibility"] = "private"
+
+	case "privatized":
+		previous["visibility"] = "public"
+	}
+
+	return event, nil
+}
+
+// lookupString returns the string at the path of
+// keys in v, or an empty string if there's none.
+func lookupString(v interface{}, keys ...string) string {
+	for _, k := range keys {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return ""
+		}
+
+		v = m[k]
+	}
+
+	s, _ := v.(string)
+
+	return s
+}
@@ -0,0 +1,115 @@
+package webhook_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/reposaur/reposaur/pkg/output"
+	"github.com/reposaur/reposaur/pkg/webhook"
+)
+
+func TestDispatchRepositoryEvents(t *testing.T) {
+	repo := map[string]interface{}{
+		"name":       "reposaur",
+		"owner":      map[string]interface{}{"login": "reposaur"},
+		"visibility": "public",
+	}
+
+	sender := map[string]interface{}{"login": "octocat"}
+
+	cases := []struct {
+		action   string
+		changes  map[string]interface{}
+		previous map[string]interface{}
+	}{
+		{
+			action:   "publicized",
+			previous: map[string]interface{}{"visibility": "private"},
+		},
+		{
+			action:   "privatized",
+			previous: map[string]interface{}{"visibility": "public"},
+		},
+		{
+			action: "renamed",
+			changes: map[string]interface{}{
+				"repository": map[string]interface{}{
+					"name": map[string]interface{}{"from": "old-name"},
+				},
+			},
+			previous: map[string]interface{}{"name": "old-name"},
+		},
+		{
+			action: "transferred",
+			changes: map[string]interface{}{
+				"owner": map[string]interface{}{
+					"from": map[string]interface{}{
+						"user": map[string]interface{}{"login": "octocat"},
+					},
+				},
+			},
+			previous: map[string]interface{}{"owner": "octocat"},
+		},
+		{
+			action:   "archived",
+			previous: map[string]interface{}{},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.action, func(t *testing.T) {
+			checker := &recordingChecker{}
+			d := webhook.NewDispatcher(checker, webhook.RepositoryEventRoutes())
+
+			payload := map[string]interface{}{
+				"action":     c.action,
+				"repository": repo,
+				"sender":     sender,
+			}
+
+			if c.changes != nil {
+				payload["changes"] = c.changes
+			}
+
+			reports, err := d.Dispatch(context.Background(), "repository", payload)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expected := []check{{
+				namespace: webhook.RepositoryEventNamespace,
+				input: map[string]interface{}{
+					"action":     c.action,
+					"repository": repo,
+					"sender":     "octocat",
+					"previous":   c.previous,
+				},
+			}}
+
+			if !reflect.DeepEqual(checker.checks, expected) {
+				t.Errorf("expected checks %v, got %v", expected, checker.checks)
+			}
+
+			props := output.ReportProperties{"owner": "reposaur", "repo": "reposaur", "action": c.action}
+			if len(reports) != 1 || !reflect.DeepEqual(reports[0].Properties, props) {
+				t.Errorf("expected report properties %v, got %v", props, reports)
+			}
+		})
+	}
+}
+
+func TestDispatchRepositoryEventsUnrouted(t *testing.T) {
+	d := webhook.NewDispatcher(&recordingChecker{}, webhook.RepositoryEventRoutes())
+
+	payload := map[string]interface{}{"action": "edited", "repository": map[string]interface{}{}}
+
+	if _, err := d.Dispatch(context.Background(), "repository", payload); !errors.Is(err, webhook.ErrUnroutedEvent) {
+		t.Errorf("expected ErrUnroutedEvent, got %v", err)
+	}
+
+	if _, err := d.Dispatch(context.Background(), "repository", map[string]interface{}{"action": "renamed"}); err == nil {
+		t.Error("expected an error for a payload without repository")
+	}
+}